@@ -1,7 +1,11 @@
 package discov
 
 import (
+	"context"
+	"errors"
+
 	"github.com/lukebull/go-zero-extern/core/discov/internal"
+	"github.com/lukebull/go-zero-extern/core/health"
 	"github.com/lukebull/go-zero-extern/core/lang"
 	"github.com/lukebull/go-zero-extern/core/logx"
 	"github.com/lukebull/go-zero-extern/core/proc"
@@ -29,9 +33,15 @@ type (
 		quit       *syncx.DoneChan
 		pauseChan  chan lang.PlaceholderType
 		resumeChan chan lang.PlaceholderType
+		registered *syncx.AtomicBool
 	}
 )
 
+const etcdProbePrefix = "etcd:"
+
+// ErrNotRegistered is an error that indicates the value is not registered in etcd.
+var ErrNotRegistered = errors.New("not registered in etcd")
+
 // NewPublisher returns a Publisher.
 // endpoints is the hosts of the etcd cluster.
 // key:value are a pair to be published.
@@ -48,6 +58,7 @@ func NewPublisher(endpoints []string, key, value string, tls bool, cafile, certf
 		quit:       syncx.NewDoneChan(),
 		pauseChan:  make(chan lang.PlaceholderType),
 		resumeChan: make(chan lang.PlaceholderType),
+		registered: syncx.NewAtomicBool(),
 	}
 
 	for _, opt := range opts {
@@ -74,8 +85,11 @@ func (p *Publisher) KeepAlive() error {
 	if err != nil {
 		return err
 	}
+	p.registered.Set(true)
+	health.AddReadinessProbe(etcdProbePrefix+p.key, p.probe)
 
-	proc.AddWrapUpListener(func() {
+	// deregisters on the unready phase, so the clients stop calling before draining.
+	proc.AddUnreadyListener(func() {
 		p.Stop()
 	})
 
@@ -153,7 +167,16 @@ func (p *Publisher) register(client internal.EtcdClient) (clientv3.LeaseID, erro
 	return lease, err
 }
 
+func (p *Publisher) probe(_ context.Context) error {
+	if p.registered.True() {
+		return nil
+	}
+
+	return ErrNotRegistered
+}
+
 func (p *Publisher) revoke(cli internal.EtcdClient) {
+	p.registered.Set(false)
 	if _, err := cli.Revoke(cli.Ctx(), p.lease); err != nil {
 		logx.Error(err)
 	}
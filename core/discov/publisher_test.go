@@ -0,0 +1,33 @@
+package discov
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/lukebull/go-zero-extern/core/discov/internal"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+func TestPublisherProbe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pub := NewPublisher([]string{"localhost:2379"}, "probe", "value", false, "", "", "")
+	if err := pub.probe(context.Background()); err != ErrNotRegistered {
+		t.Fatalf("expected not registered, got %v", err)
+	}
+
+	pub.registered.Set(true)
+	if err := pub.probe(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	cli := internal.NewMockEtcdClient(ctrl)
+	cli.EXPECT().Ctx().Return(context.Background()).AnyTimes()
+	cli.EXPECT().Revoke(gomock.Any(), gomock.Any()).Return(&clientv3.LeaseRevokeResponse{}, nil)
+	pub.revoke(cli)
+	if err := pub.probe(context.Background()); err != ErrNotRegistered {
+		t.Fatalf("expected not registered after revoked, got %v", err)
+	}
+}
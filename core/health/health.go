@@ -0,0 +1,216 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/core/syncx"
+	"github.com/lukebull/go-zero-extern/core/threading"
+	"github.com/lukebull/go-zero-extern/core/timex"
+)
+
+const (
+	// StatusUp means healthy.
+	StatusUp = "up"
+	// StatusDown means unhealthy.
+	StatusDown = "down"
+
+	lifecycleCheck = "lifecycle"
+	probeTimeout   = time.Second
+)
+
+// ErrShuttingDown is an error that indicates the process is shutting down.
+var ErrShuttingDown = errors.New("shutting down")
+
+type (
+	// A Probe checks a component, returns an error if the component is unhealthy.
+	Probe func(ctx context.Context) error
+
+	// A Report is the result of the probes.
+	Report struct {
+		Status string           `json:"status"`
+		Checks map[string]Check `json:"checks,omitempty"`
+	}
+
+	// A Check is the result of a probe.
+	Check struct {
+		Status   string `json:"status"`
+		Error    string `json:"error,omitempty"`
+		Duration string `json:"duration"`
+	}
+
+	probeRegistry struct {
+		lock   sync.RWMutex
+		probes map[string]Probe
+	}
+)
+
+var (
+	ready           = syncx.ForAtomicBool(true)
+	livenessProbes  = newProbeRegistry()
+	readinessProbes = newProbeRegistry()
+)
+
+// AddLivenessProbe adds a probe with name to check if the process is alive,
+// the process is restarted if the liveness checks fail, so don't check the dependencies.
+// The probe with the same name is replaced.
+func AddLivenessProbe(name string, probe Probe) {
+	livenessProbes.add(name, probe)
+}
+
+// AddReadinessProbe adds a probe with name to check if the process is ready to serve,
+// like the connections to the databases. The probe with the same name is replaced.
+func AddReadinessProbe(name string, probe Probe) {
+	readinessProbes.add(name, probe)
+}
+
+// CheckLiveness runs the liveness probes.
+func CheckLiveness(ctx context.Context) Report {
+	return livenessProbes.check(ctx)
+}
+
+// CheckReadiness runs the readiness probes, and fails if the process is marked unready.
+func CheckReadiness(ctx context.Context) Report {
+	report := readinessProbes.check(ctx)
+	if !IsReady() {
+		report.Status = StatusDown
+		report.Checks[lifecycleCheck] = Check{
+			Status:   StatusDown,
+			Error:    ErrShuttingDown.Error(),
+			Duration: timex.ReprOfDuration(0),
+		}
+	}
+
+	return report
+}
+
+// IsReady checks if the process is ready to serve.
+func IsReady() bool {
+	return ready.True()
+}
+
+// LivenessHandler returns a http.Handler that responds the liveness report in json,
+// with 200 if alive, otherwise 503.
+func LivenessHandler() http.Handler {
+	return reportHandler(CheckLiveness)
+}
+
+// MarkReady marks the process ready to serve.
+func MarkReady() {
+	ready.Set(true)
+}
+
+// MarkUnready marks the process unready, like on shutdown, the readiness checks fail after that.
+func MarkUnready() {
+	ready.Set(false)
+}
+
+// ReadinessHandler returns a http.Handler that responds the readiness report in json,
+// with 200 if ready, otherwise 503.
+func ReadinessHandler() http.Handler {
+	return reportHandler(CheckReadiness)
+}
+
+// RemoveProbe removes the liveness and readiness probes with name.
+func RemoveProbe(name string) {
+	livenessProbes.remove(name)
+	readinessProbes.remove(name)
+}
+
+func newProbeRegistry() *probeRegistry {
+	return &probeRegistry{
+		probes: make(map[string]Probe),
+	}
+}
+
+func (r *probeRegistry) add(name string, probe Probe) {
+	r.lock.Lock()
+	r.probes[name] = probe
+	r.lock.Unlock()
+}
+
+func (r *probeRegistry) check(ctx context.Context) Report {
+	r.lock.RLock()
+	names := make([]string, 0, len(r.probes))
+	probes := make([]Probe, 0, len(r.probes))
+	for name, probe := range r.probes {
+		names = append(names, name)
+		probes = append(probes, probe)
+	}
+	r.lock.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	// the probes run concurrently, so that a slow probe doesn't delay the others.
+	checks := make([]Check, len(probes))
+	group := threading.NewRoutineGroup()
+	for i := range probes {
+		i := i
+		group.RunSafe(func() {
+			checks[i] = runProbe(ctx, probes[i])
+		})
+	}
+	group.Wait()
+
+	report := Report{
+		Status: StatusUp,
+		Checks: make(map[string]Check, len(checks)),
+	}
+	for i, check := range checks {
+		report.Checks[names[i]] = check
+		if check.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	return report
+}
+
+func (r *probeRegistry) remove(name string) {
+	r.lock.Lock()
+	delete(r.probes, name)
+	r.lock.Unlock()
+}
+
+func reportHandler(check func(ctx context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := check(r.Context())
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if report.Status == StatusUp {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			logx.Error(err)
+		}
+	})
+}
+
+func runProbe(ctx context.Context, probe Probe) (check Check) {
+	start := timex.Now()
+	check.Status = StatusDown
+	defer func() {
+		if p := recover(); p != nil {
+			logx.ErrorStack(p)
+			check.Error = fmt.Sprint(p)
+		}
+		check.Duration = timex.ReprOfDuration(timex.Since(start))
+	}()
+
+	if err := probe(ctx); err != nil {
+		check.Error = err.Error()
+		return
+	}
+
+	check.Status = StatusUp
+	return
+}
@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func serveReport(t *testing.T, handler http.Handler, path string) (int, Report) {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var report Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	return w.Code, report
+}
+
+func TestReadinessHandler(t *testing.T) {
+	defer MarkReady()
+
+	code, report := serveReport(t, ReadinessHandler(), "/readyz")
+	if code != http.StatusOK || report.Status != StatusUp {
+		t.Fatalf("expected ready, got %d %+v", code, report)
+	}
+
+	MarkUnready()
+	if IsReady() {
+		t.Fatal("expected unready")
+	}
+	code, report = serveReport(t, ReadinessHandler(), "/readyz")
+	if code != http.StatusServiceUnavailable || report.Checks[lifecycleCheck].Error != ErrShuttingDown.Error() {
+		t.Fatalf("expected unavailable, got %d %+v", code, report)
+	}
+
+	MarkReady()
+	if !IsReady() {
+		t.Fatal("expected ready again")
+	}
+}
+
+func TestProbes(t *testing.T) {
+	defer RemoveProbe("db")
+	defer RemoveProbe("cache")
+	defer RemoveProbe("slow")
+	defer RemoveProbe("panic")
+
+	AddReadinessProbe("db", func(ctx context.Context) error {
+		return nil
+	})
+	AddReadinessProbe("cache", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+	AddLivenessProbe("db", func(ctx context.Context) error {
+		return nil
+	})
+
+	code, report := serveReport(t, ReadinessHandler(), "/readyz")
+	if code != http.StatusServiceUnavailable || report.Status != StatusDown {
+		t.Fatalf("expected down, got %d %+v", code, report)
+	}
+	if report.Checks["db"].Status != StatusUp || report.Checks["cache"].Error != "connection refused" {
+		t.Fatalf("unexpected checks %+v", report.Checks)
+	}
+
+	code, report = serveReport(t, LivenessHandler(), "/healthz")
+	if code != http.StatusOK || report.Status != StatusUp || len(report.Checks) != 1 {
+		t.Fatalf("expected alive, got %d %+v", code, report)
+	}
+
+	// the probes are replaced by name, and limited by the timeout.
+	AddReadinessProbe("cache", func(ctx context.Context) error {
+		return nil
+	})
+	AddReadinessProbe("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	AddReadinessProbe("panic", func(ctx context.Context) error {
+		panic("bad probe")
+	})
+	start := time.Now()
+	report = CheckReadiness(context.Background())
+	if elapsed := time.Since(start); elapsed > probeTimeout*2 {
+		t.Fatalf("expected probes timed out, took %v", elapsed)
+	}
+	if report.Checks["cache"].Status != StatusUp || report.Checks["slow"].Status != StatusDown ||
+		report.Checks["panic"].Error != "bad probe" {
+		t.Fatalf("unexpected checks %+v", report.Checks)
+	}
+
+	RemoveProbe("slow")
+	RemoveProbe("panic")
+	if report = CheckReadiness(context.Background()); report.Status != StatusUp {
+		t.Fatalf("expected up after removed, got %+v", report)
+	}
+}
+
+func TestServeMux(t *testing.T) {
+	mux := newServeMux(Conf{
+		LivenessPath:  "/healthz",
+		ReadinessPath: "/readyz",
+	})
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		if code, report := serveReport(t, mux, path); code != http.StatusOK || report.Status != StatusUp {
+			t.Fatalf("expected up on %s, got %d %+v", path, code, report)
+		}
+	}
+}
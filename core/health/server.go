@@ -0,0 +1,54 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/core/proc"
+	"github.com/lukebull/go-zero-extern/core/threading"
+)
+
+// A Conf is the config of the health server, which is disabled if Host is empty.
+type Conf struct {
+	Host          string `json:",optional"`
+	Port          int    `json:",default=6061"`
+	LivenessPath  string `json:",default=/healthz"`
+	ReadinessPath string `json:",default=/readyz"`
+}
+
+var once sync.Once
+
+// StartServer starts the health server on a dedicated port, which keeps serving until
+// the shutdown phase, so that the readiness checks fail while draining.
+func StartServer(c Conf) {
+	once.Do(func() {
+		if len(c.Host) == 0 {
+			return
+		}
+
+		server := &http.Server{
+			Addr:    fmt.Sprintf("%s:%d", c.Host, c.Port),
+			Handler: newServeMux(c),
+		}
+		proc.AddShutdownListener(func() {
+			if err := server.Close(); err != nil {
+				logx.Error(err)
+			}
+		})
+		threading.GoSafe(func() {
+			logx.Infof("Starting health server at %s", server.Addr)
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logx.Error(err)
+			}
+		})
+	})
+}
+
+func newServeMux(c Conf) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle(c.LivenessPath, LivenessHandler())
+	mux.Handle(c.ReadinessPath, ReadinessHandler())
+	return mux
+}
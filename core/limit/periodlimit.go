@@ -23,6 +23,19 @@ elseif current == limit then
 else
     return 0
 end`
+	// quotaScript returns the count and the ttl of the key, resets the ttl if lost.
+	quotaScript = `local window = tonumber(ARGV[1])
+local current = redis.call("INCRBY", KEYS[1], 1)
+if current == 1 then
+    redis.call("expire", KEYS[1], window)
+    return {current, window}
+end
+local ttl = redis.call("ttl", KEYS[1])
+if ttl < 0 then
+    redis.call("expire", KEYS[1], window)
+    ttl = window
+end
+return {current, ttl}`
 	zoneDiff = 3600 * 8 // GMT+8 for our services
 )
 
@@ -48,6 +61,16 @@ type (
 	// PeriodOption defines the method to customize a PeriodLimit.
 	PeriodOption func(l *PeriodLimit)
 
+	// A Quota is the quota state of a key after taking a permit.
+	Quota struct {
+		// State is Allowed, HitQuota or OverQuota.
+		State     int
+		Limit     int
+		Remaining int
+		// Reset is the duration until the quota is reset.
+		Reset time.Duration
+	}
+
 	// A PeriodLimit is used to limit requests during a period of time.
 	PeriodLimit struct {
 		period     int
@@ -102,6 +125,32 @@ func (h *PeriodLimit) Take(key string) (int, error) {
 	}
 }
 
+// TakeQuota requests a permit, it returns the quota state of key,
+// which is used to tell the clients the remaining permits and when to retry.
+func (h *PeriodLimit) TakeQuota(key string) (Quota, error) {
+	resp, err := h.limitStore.Eval(quotaScript, []string{h.keyPrefix + key},
+		[]string{strconv.Itoa(h.calcExpireSeconds())})
+	if err != nil {
+		return Quota{State: Unknown}, err
+	}
+
+	vals, ok := resp.([]interface{})
+	if !ok || len(vals) != 2 {
+		return Quota{State: Unknown}, ErrUnknownCode
+	}
+
+	current, ok := vals[0].(int64)
+	if !ok {
+		return Quota{State: Unknown}, ErrUnknownCode
+	}
+	ttl, ok := vals[1].(int64)
+	if !ok {
+		return Quota{State: Unknown}, ErrUnknownCode
+	}
+
+	return newQuota(h.quota, int(current), time.Duration(ttl)*time.Second), nil
+}
+
 func (h *PeriodLimit) calcExpireSeconds() int {
 	if h.align {
 		unix := time.Now().Unix() + zoneDiff
@@ -117,3 +166,22 @@ func Align() PeriodOption {
 		l.align = true
 	}
 }
+
+func newQuota(limit, current int, reset time.Duration) Quota {
+	quota := Quota{
+		Limit:     limit,
+		Remaining: limit - current,
+		Reset:     reset,
+	}
+	switch {
+	case current < limit:
+		quota.State = Allowed
+	case current == limit:
+		quota.State = HitQuota
+	default:
+		quota.State = OverQuota
+		quota.Remaining = 0
+	}
+
+	return quota
+}
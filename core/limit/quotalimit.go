@@ -0,0 +1,139 @@
+package limit
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+	"github.com/lukebull/go-zero-extern/core/timex"
+)
+
+type (
+	// A QuotaLimiter limits the requests of each key to quota during a period of seconds.
+	// The quotas are shared in redis across the processes, and limited in process
+	// if redis is not set or unreachable.
+	QuotaLimiter struct {
+		period         int
+		quota          int
+		store          *redis.Redis
+		periodLimit    *PeriodLimit
+		rescueLock     sync.Mutex
+		redisAlive     uint32
+		rescueLimiter  *localQuotaLimiter
+		monitorStarted bool
+	}
+
+	localQuotaLimiter struct {
+		period    time.Duration
+		quota     int
+		lock      sync.Mutex
+		windows   map[string]*quotaWindow
+		nextSweep time.Duration
+	}
+
+	quotaWindow struct {
+		count    int
+		expireAt time.Duration
+	}
+)
+
+// NewQuotaLimiter returns a QuotaLimiter, store can be nil to limit in process.
+func NewQuotaLimiter(period, quota int, store *redis.Redis, keyPrefix string) *QuotaLimiter {
+	limiter := &QuotaLimiter{
+		period:        period,
+		quota:         quota,
+		store:         store,
+		rescueLimiter: newLocalQuotaLimiter(period, quota),
+	}
+	if store != nil {
+		limiter.periodLimit = NewPeriodLimit(period, quota, store, keyPrefix)
+		limiter.redisAlive = 1
+	}
+
+	return limiter
+}
+
+// Take requests a permit of key, it returns the quota state of key.
+func (l *QuotaLimiter) Take(key string) Quota {
+	if atomic.LoadUint32(&l.redisAlive) == 0 {
+		return l.rescueLimiter.take(key)
+	}
+
+	quota, err := l.periodLimit.TakeQuota(key)
+	if err != nil {
+		logx.Errorf("fail to use quota limiter: %s, use in-process limiter for rescue", err)
+		l.startMonitor()
+		return l.rescueLimiter.take(key)
+	}
+
+	return quota
+}
+
+func (l *QuotaLimiter) startMonitor() {
+	l.rescueLock.Lock()
+	defer l.rescueLock.Unlock()
+
+	if l.monitorStarted {
+		return
+	}
+
+	l.monitorStarted = true
+	atomic.StoreUint32(&l.redisAlive, 0)
+
+	go l.waitForRedis()
+}
+
+func (l *QuotaLimiter) waitForRedis() {
+	ticker := time.NewTicker(pingInterval)
+	defer func() {
+		ticker.Stop()
+		l.rescueLock.Lock()
+		l.monitorStarted = false
+		l.rescueLock.Unlock()
+	}()
+
+	for range ticker.C {
+		if l.store.Ping() {
+			atomic.StoreUint32(&l.redisAlive, 1)
+			return
+		}
+	}
+}
+
+func newLocalQuotaLimiter(period, quota int) *localQuotaLimiter {
+	return &localQuotaLimiter{
+		period:  time.Duration(period) * time.Second,
+		quota:   quota,
+		windows: make(map[string]*quotaWindow),
+	}
+}
+
+func (l *localQuotaLimiter) take(key string) Quota {
+	now := timex.Now()
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	// the expired windows are swept once a period to bound the memory of the stale keys.
+	if now >= l.nextSweep {
+		for k, w := range l.windows {
+			if now >= w.expireAt {
+				delete(l.windows, k)
+			}
+		}
+		l.nextSweep = now + l.period
+	}
+
+	window, ok := l.windows[key]
+	if !ok || now >= window.expireAt {
+		window = &quotaWindow{
+			expireAt: now + l.period,
+		}
+		l.windows[key] = window
+	}
+	window.count++
+
+	return newQuota(l.quota, window.count, window.expireAt-now)
+}
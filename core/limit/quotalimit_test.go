@@ -0,0 +1,112 @@
+package limit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriodLimit_TakeQuota(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer s.Close()
+
+	l := NewPeriodLimit(10, 2, redis.New(s.Addr()), "quota:")
+	quota, err := l.TakeQuota("first")
+	assert.Nil(t, err)
+	assert.Equal(t, Quota{State: Allowed, Limit: 2, Remaining: 1, Reset: 10 * time.Second}, quota)
+
+	quota, err = l.TakeQuota("first")
+	assert.Nil(t, err)
+	assert.Equal(t, HitQuota, quota.State)
+	assert.Equal(t, 0, quota.Remaining)
+
+	quota, err = l.TakeQuota("first")
+	assert.Nil(t, err)
+	assert.Equal(t, OverQuota, quota.State)
+	assert.Equal(t, 0, quota.Remaining)
+	assert.True(t, quota.Reset > 0)
+
+	s.FastForward(10 * time.Second)
+	quota, err = l.TakeQuota("first")
+	assert.Nil(t, err)
+	assert.Equal(t, Allowed, quota.State)
+
+	// the key without ttl gets the ttl back.
+	assert.Nil(t, s.Set("quota:second", "1"))
+	quota, err = l.TakeQuota("second")
+	assert.Nil(t, err)
+	assert.Equal(t, HitQuota, quota.State)
+	assert.Equal(t, 10*time.Second, s.TTL("quota:second"))
+}
+
+func TestPeriodLimit_TakeQuotaRedisUnavailable(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+	addr := s.Addr()
+	s.Close()
+
+	l := NewPeriodLimit(1, 1, redis.New(addr), "quota:")
+	quota, err := l.TakeQuota("first")
+	assert.NotNil(t, err)
+	assert.Equal(t, Unknown, quota.State)
+}
+
+func TestQuotaLimiter(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer s.Close()
+
+	store := redis.New(s.Addr())
+	l1 := NewQuotaLimiter(10, 3, store, "quota:")
+	l2 := NewQuotaLimiter(10, 3, store, "quota:")
+	assert.Equal(t, Allowed, l1.Take("key").State)
+	assert.Equal(t, Allowed, l2.Take("key").State)
+	// the quota is shared by the limiters in redis.
+	assert.Equal(t, HitQuota, l1.Take("key").State)
+	assert.Equal(t, OverQuota, l2.Take("key").State)
+	assert.Equal(t, Allowed, l2.Take("other").State)
+}
+
+func TestQuotaLimiter_Rescue(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+
+	l := NewQuotaLimiter(10, 2, redis.New(s.Addr()), "quota:")
+	assert.Equal(t, Allowed, l.Take("key").State)
+	s.Close()
+
+	// the in-process limiter takes over with its own counts.
+	assert.Equal(t, Allowed, l.Take("key").State)
+	assert.Equal(t, HitQuota, l.Take("key").State)
+	quota := l.Take("key")
+	assert.Equal(t, OverQuota, quota.State)
+	assert.Equal(t, 2, quota.Limit)
+	assert.True(t, quota.Reset > 0 && quota.Reset <= 10*time.Second)
+
+	assert.Nil(t, s.Restart())
+	assert.Eventually(t, func() bool {
+		l.Take("another")
+		return s.Exists("quota:another")
+	}, time.Second*3, pingInterval)
+	assert.Equal(t, Allowed, l.Take("third").State)
+	assert.True(t, s.Exists("quota:third"))
+}
+
+func TestQuotaLimiter_InProcess(t *testing.T) {
+	l := NewQuotaLimiter(1, 1, nil, "")
+	assert.Equal(t, HitQuota, l.Take("key").State)
+	assert.Equal(t, OverQuota, l.Take("key").State)
+	assert.Equal(t, HitQuota, l.Take("other").State)
+
+	l.rescueLimiter.period = time.Millisecond
+	l.rescueLimiter.nextSweep = 0
+	for _, w := range l.rescueLimiter.windows {
+		w.expireAt = 0
+	}
+	assert.Equal(t, HitQuota, l.Take("key").State)
+	assert.Equal(t, 1, len(l.rescueLimiter.windows))
+}
@@ -19,6 +19,24 @@ func WithDuration(d time.Duration) Logger {
 	}
 }
 
+func (l *durationLogger) Debug(v ...interface{}) {
+	if shouldLog(DebugLevel) {
+		l.write(infoLog, levelDebug, fmt.Sprint(v...))
+	}
+}
+
+func (l *durationLogger) Debugf(format string, v ...interface{}) {
+	if shouldLog(DebugLevel) {
+		l.write(infoLog, levelDebug, fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *durationLogger) Debugw(msg string, fields ...LogField) {
+	if shouldLog(DebugLevel) {
+		l.write(infoLog, levelDebug, msg, fields...)
+	}
+}
+
 func (l *durationLogger) Error(v ...interface{}) {
 	if shouldLog(ErrorLevel) {
 		l.write(errorLog, levelError, formatWithCaller(fmt.Sprint(v...), durationCallerDepth))
@@ -31,6 +49,12 @@ func (l *durationLogger) Errorf(format string, v ...interface{}) {
 	}
 }
 
+func (l *durationLogger) Errorw(msg string, fields ...LogField) {
+	if shouldLog(ErrorLevel) {
+		l.write(errorLog, levelError, formatWithCaller(msg, durationCallerDepth), fields...)
+	}
+}
+
 func (l *durationLogger) Info(v ...interface{}) {
 	if shouldLog(InfoLevel) {
 		l.write(infoLog, levelInfo, fmt.Sprint(v...))
@@ -43,6 +67,12 @@ func (l *durationLogger) Infof(format string, v ...interface{}) {
 	}
 }
 
+func (l *durationLogger) Infow(msg string, fields ...LogField) {
+	if shouldLog(InfoLevel) {
+		l.write(infoLog, levelInfo, msg, fields...)
+	}
+}
+
 func (l *durationLogger) Slow(v ...interface{}) {
 	if shouldLog(ErrorLevel) {
 		l.write(slowLog, levelSlow, fmt.Sprint(v...))
@@ -55,14 +85,38 @@ func (l *durationLogger) Slowf(format string, v ...interface{}) {
 	}
 }
 
+func (l *durationLogger) Sloww(msg string, fields ...LogField) {
+	if shouldLog(ErrorLevel) {
+		l.write(slowLog, levelSlow, msg, fields...)
+	}
+}
+
+func (l *durationLogger) Warn(v ...interface{}) {
+	if shouldLog(WarnLevel) {
+		l.write(errorLog, levelWarn, fmt.Sprint(v...))
+	}
+}
+
+func (l *durationLogger) Warnf(format string, v ...interface{}) {
+	if shouldLog(WarnLevel) {
+		l.write(errorLog, levelWarn, fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *durationLogger) Warnw(msg string, fields ...LogField) {
+	if shouldLog(WarnLevel) {
+		l.write(errorLog, levelWarn, msg, fields...)
+	}
+}
+
 func (l *durationLogger) WithDuration(duration time.Duration) Logger {
 	l.Duration = timex.ReprOfDuration(duration)
 	return l
 }
 
-func (l *durationLogger) write(writer io.Writer, level, content string) {
+func (l *durationLogger) write(writer io.Writer, level, content string, fields ...LogField) {
 	l.Timestamp = getTimestamp()
 	l.Level = level
 	l.Content = content
-	outputJson(writer, logEntry(*l))
+	outputJson(writer, logEntry(*l), fields...)
 }
@@ -0,0 +1,111 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/lang"
+)
+
+// the keys of logEntry and traceLogger, the fields with such keys are dropped.
+var reservedKeys = map[string]lang.PlaceholderType{
+	"@timestamp": lang.Placeholder,
+	"level":      lang.Placeholder,
+	"duration":   lang.Placeholder,
+	"content":    lang.Placeholder,
+	"trace":      lang.Placeholder,
+	"span":       lang.Placeholder,
+}
+
+type (
+	// A LogField is a key-value pair that is logged as a top-level key of the json log.
+	LogField struct {
+		Key   string
+		Value interface{}
+	}
+
+	fieldsKey struct{}
+)
+
+// ContextFields returns the fields that carried by ctx.
+func ContextFields(ctx context.Context) []LogField {
+	if ctx == nil {
+		return nil
+	}
+
+	fields, _ := ctx.Value(fieldsKey{}).([]LogField)
+	return fields
+}
+
+// ContextWithFields returns a context that carries the fields, which are logged
+// on every log line by WithContext, the later fields take precedence on the same keys.
+func ContextWithFields(ctx context.Context, fields ...LogField) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	existing := ContextFields(ctx)
+	merged := make([]LogField, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// Field returns a LogField with the given key and value.
+func Field(key string, value interface{}) LogField {
+	return LogField{
+		Key:   key,
+		Value: value,
+	}
+}
+
+// appendFields appends the fields as top-level keys into the json object in content.
+func appendFields(content []byte, fields []LogField) []byte {
+	last := make(map[string]int, len(fields))
+	for i, field := range fields {
+		last[field.Key] = i
+	}
+
+	var buf bytes.Buffer
+	buf.Write(bytes.TrimSuffix(content, []byte("}")))
+	for i, field := range fields {
+		if last[field.Key] != i {
+			continue
+		}
+		if _, ok := reservedKeys[field.Key]; ok {
+			continue
+		}
+
+		key, err := json.Marshal(field.Key)
+		if err != nil {
+			continue
+		}
+		val, err := json.Marshal(fieldValue(field.Value))
+		if err != nil {
+			val, _ = json.Marshal(fmt.Sprint(field.Value))
+		}
+
+		buf.WriteByte(',')
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes()
+}
+
+func fieldValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case error:
+		return val.Error()
+	case time.Duration:
+		return val.String()
+	default:
+		return v
+	}
+}
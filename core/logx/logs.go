@@ -23,8 +23,12 @@ import (
 )
 
 const (
-	// InfoLevel logs everything
-	InfoLevel = iota
+	// DebugLevel logs everything
+	DebugLevel uint32 = iota
+	// InfoLevel does not include debugs
+	InfoLevel
+	// WarnLevel includes warnings, errors, slows, stacks
+	WarnLevel
 	// ErrorLevel includes errors, slows, stacks
 	ErrorLevel
 	// SevereLevel only log severe messages
@@ -42,7 +46,9 @@ const (
 	volumeMode  = "volume"
 
 	levelAlert  = "alert"
+	levelDebug  = "debug"
 	levelInfo   = "info"
+	levelWarn   = "warn"
 	levelError  = "error"
 	levelSevere = "severe"
 	levelFatal  = "fatal"
@@ -64,7 +70,7 @@ var (
 
 	timeFormat   = "2006-01-02T15:04:05.000Z07"
 	writeConsole bool
-	logLevel     uint32
+	logLevel     = InfoLevel
 	infoLog      io.WriteCloser
 	errorLog     io.WriteCloser
 	severeLog    io.WriteCloser
@@ -96,12 +102,21 @@ type (
 
 	// A Logger represents a logger.
 	Logger interface {
+		Debug(...interface{})
+		Debugf(string, ...interface{})
+		Debugw(string, ...LogField)
 		Error(...interface{})
 		Errorf(string, ...interface{})
+		Errorw(string, ...LogField)
 		Info(...interface{})
 		Infof(string, ...interface{})
+		Infow(string, ...LogField)
 		Slow(...interface{})
 		Slowf(string, ...interface{})
+		Sloww(string, ...LogField)
+		Warn(...interface{})
+		Warnf(string, ...interface{})
+		Warnw(string, ...LogField)
 		WithDuration(time.Duration) Logger
 	}
 )
@@ -148,6 +163,10 @@ func Close() error {
 
 	atomic.StoreUint32(&initialized, 0)
 
+	if customWriter != nil {
+		return customWriter.Close()
+	}
+
 	if infoLog != nil {
 		if err := infoLog.Close(); err != nil {
 			return err
@@ -181,6 +200,21 @@ func Close() error {
 	return nil
 }
 
+// Debug writes v into access log in debug level.
+func Debug(v ...interface{}) {
+	debugSync(fmt.Sprint(v...))
+}
+
+// Debugf writes v with format into access log in debug level.
+func Debugf(format string, v ...interface{}) {
+	debugSync(fmt.Sprintf(format, v...))
+}
+
+// Debugw writes msg along with fields into access log in debug level.
+func Debugw(msg string, fields ...LogField) {
+	debugSync(msg, fields...)
+}
+
 // Disable disables the logging.
 func Disable() {
 	once.Do(func() {
@@ -215,6 +249,11 @@ func ErrorCallerf(callDepth int, format string, v ...interface{}) {
 	errorSync(fmt.Sprintf(format, v...), callDepth+callerInnerDepth)
 }
 
+// Errorw writes msg along with fields into error log.
+func Errorw(msg string, fields ...LogField) {
+	errorSync(msg, callerInnerDepth, fields...)
+}
+
 // ErrorStack writes v along with call stack into error log.
 func ErrorStack(v ...interface{}) {
 	// there is newline in stack string
@@ -237,6 +276,11 @@ func Infof(format string, v ...interface{}) {
 	infoSync(fmt.Sprintf(format, v...))
 }
 
+// Infow writes msg along with fields into access log.
+func Infow(msg string, fields ...LogField) {
+	infoSync(msg, fields...)
+}
+
 // Must checks if err is nil, otherwise logs the err and exits.
 func Must(err error) {
 	if err != nil {
@@ -272,6 +316,11 @@ func Slowf(format string, v ...interface{}) {
 	slowSync(fmt.Sprintf(format, v...))
 }
 
+// Sloww writes msg along with fields into slow log.
+func Sloww(msg string, fields ...LogField) {
+	slowSync(msg, fields...)
+}
+
 // Stat writes v into stat log.
 func Stat(v ...interface{}) {
 	statSync(fmt.Sprint(v...))
@@ -282,6 +331,21 @@ func Statf(format string, v ...interface{}) {
 	statSync(fmt.Sprintf(format, v...))
 }
 
+// Warn writes v into error log in warn level.
+func Warn(v ...interface{}) {
+	warnSync(fmt.Sprint(v...))
+}
+
+// Warnf writes v with format into error log in warn level.
+func Warnf(format string, v ...interface{}) {
+	warnSync(fmt.Sprintf(format, v...))
+}
+
+// Warnw writes msg along with fields into error log in warn level.
+func Warnw(msg string, fields ...LogField) {
+	warnSync(msg, fields...)
+}
+
 // WithCooldownMillis customizes logging on writing call stack interval.
 func WithCooldownMillis(millis int) LogOption {
 	return func(opts *logOptions) {
@@ -312,9 +376,15 @@ func createOutput(path string) (io.WriteCloser, error) {
 		options.gzipEnabled), options.gzipEnabled)
 }
 
-func errorSync(msg string, callDepth int) {
+func debugSync(msg string, fields ...LogField) {
+	if shouldLog(DebugLevel) {
+		output(infoLog, levelDebug, msg, fields...)
+	}
+}
+
+func errorSync(msg string, callDepth int, fields ...LogField) {
 	if shouldLog(ErrorLevel) {
-		outputError(errorLog, msg, callDepth)
+		outputError(errorLog, msg, callDepth, fields...)
 	}
 }
 
@@ -362,28 +432,33 @@ func handleOptions(opts []LogOption) {
 	}
 }
 
-func infoSync(msg string) {
+func infoSync(msg string, fields ...LogField) {
 	if shouldLog(InfoLevel) {
-		output(infoLog, levelInfo, msg)
+		output(infoLog, levelInfo, msg, fields...)
 	}
 }
 
-func output(writer io.Writer, level, msg string) {
+func output(writer io.Writer, level, msg string, fields ...LogField) {
 	info := logEntry{
 		Timestamp: getTimestamp(),
 		Level:     level,
 		Content:   msg,
 	}
-	outputJson(writer, info)
+	outputJson(writer, info, fields...)
 }
 
-func outputError(writer io.Writer, msg string, callDepth int) {
+func outputError(writer io.Writer, msg string, callDepth int, fields ...LogField) {
 	content := formatWithCaller(msg, callDepth)
-	output(writer, levelError, content)
+	output(writer, levelError, content, fields...)
 }
 
-func outputJson(writer io.Writer, info interface{}) {
-	if content, err := json.Marshal(info); err != nil {
+func outputJson(writer io.Writer, info interface{}, fields ...LogField) {
+	content, err := json.Marshal(info)
+	if err == nil && len(fields) > 0 {
+		content = appendFields(content, fields)
+	}
+
+	if err != nil {
 		log.Println(err.Error())
 	} else if atomic.LoadUint32(&initialized) == 0 || writer == nil {
 		log.Println(string(content))
@@ -394,8 +469,12 @@ func outputJson(writer io.Writer, info interface{}) {
 
 func setupLogLevel(c LogConf) {
 	switch c.Level {
+	case levelDebug:
+		SetLevel(DebugLevel)
 	case levelInfo:
 		SetLevel(InfoLevel)
+	case levelWarn:
+		SetLevel(WarnLevel)
 	case levelError:
 		SetLevel(ErrorLevel)
 	case levelSevere:
@@ -490,9 +569,9 @@ func shouldLog(level uint32) bool {
 	return atomic.LoadUint32(&logLevel) <= level
 }
 
-func slowSync(msg string) {
+func slowSync(msg string, fields ...LogField) {
 	if shouldLog(ErrorLevel) {
-		output(slowLog, levelSlow, msg)
+		output(slowLog, levelSlow, msg, fields...)
 	}
 }
 
@@ -508,6 +587,12 @@ func statSync(msg string) {
 	//}
 }
 
+func warnSync(msg string, fields ...LogField) {
+	if shouldLog(WarnLevel) {
+		output(errorLog, levelWarn, msg, fields...)
+	}
+}
+
 type logWriter struct {
 	logger *log.Logger
 }
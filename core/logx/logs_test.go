@@ -0,0 +1,144 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockWriter struct {
+	bytes.Buffer
+}
+
+func (mw *mockWriter) Close() error {
+	return nil
+}
+
+func (mw *mockWriter) entry(t *testing.T) map[string]interface{} {
+	var m map[string]interface{}
+	assert.Nil(t, json.Unmarshal(bytes.TrimSpace(mw.Bytes()), &m), mw.String())
+	mw.Reset()
+	return m
+}
+
+func setupMockWriters(t *testing.T) (info, errs *mockWriter) {
+	prevInfo, prevError, prevLevel := infoLog, errorLog, atomic.LoadUint32(&logLevel)
+	prevInitialized := atomic.LoadUint32(&initialized)
+	info, errs = new(mockWriter), new(mockWriter)
+	infoLog, errorLog = info, errs
+	atomic.StoreUint32(&initialized, 1)
+	t.Cleanup(func() {
+		infoLog, errorLog = prevInfo, prevError
+		atomic.StoreUint32(&initialized, prevInitialized)
+		SetLevel(prevLevel)
+	})
+
+	return
+}
+
+func TestInfow(t *testing.T) {
+	info, _ := setupMockWriters(t)
+
+	Infow("foo", Field("uid", 1), Field("err", errors.New("bad")), Field("level", "debug"),
+		Field("elapsed", time.Second), Field("uid", 2))
+	m := info.entry(t)
+	assert.Equal(t, "foo", m["content"])
+	assert.Equal(t, levelInfo, m["level"])
+	assert.Equal(t, float64(2), m["uid"])
+	assert.Equal(t, "bad", m["err"])
+	assert.Equal(t, "1s", m["elapsed"])
+}
+
+func TestErrorw(t *testing.T) {
+	_, errs := setupMockWriters(t)
+
+	Errorw("foo", Field("uid", "bar"))
+	m := errs.entry(t)
+	assert.True(t, strings.HasPrefix(m["content"].(string), "logs_test.go:"), m["content"])
+	assert.Equal(t, "bar", m["uid"])
+
+	WithDuration(time.Second).Errorw("foo")
+	m = errs.entry(t)
+	assert.True(t, strings.HasPrefix(m["content"].(string), "logs_test.go:"), m["content"])
+}
+
+func TestWithContextFields(t *testing.T) {
+	info, errs := setupMockWriters(t)
+
+	ctx := ContextWithFields(context.Background(), Field("uid", 1), Field("app", "foo"))
+	child := ContextWithFields(ctx, Field("uid", 2))
+	assert.Equal(t, ctx, ContextWithFields(ctx))
+	assert.Len(t, ContextFields(ctx), 2)
+	assert.Nil(t, ContextFields(context.Background()))
+
+	WithContext(child).Infow("bar", Field("app", "bar"), Field("path", "/"))
+	m := info.entry(t)
+	assert.Equal(t, "bar", m["content"])
+	assert.Equal(t, float64(2), m["uid"])
+	assert.Equal(t, "bar", m["app"])
+	assert.Equal(t, "/", m["path"])
+
+	WithContext(ctx).WithDuration(time.Millisecond).Info("baz")
+	m = info.entry(t)
+	assert.Equal(t, "baz", m["content"])
+	assert.Equal(t, float64(1), m["uid"])
+	assert.Equal(t, "1.0ms", m["duration"])
+
+	WithContext(ctx).Errorf("failed %d", 1)
+	m = errs.entry(t)
+	assert.True(t, strings.HasPrefix(m["content"].(string), "logs_test.go:"), m["content"])
+	assert.Equal(t, "foo", m["app"])
+}
+
+func TestLevels(t *testing.T) {
+	info, errs := setupMockWriters(t)
+
+	SetLevel(WarnLevel)
+	Debug("foo")
+	Infow("foo")
+	WithContext(context.Background()).Debugf("foo %d", 1)
+	WithDuration(time.Second).Info("foo")
+	assert.Equal(t, 0, info.Len())
+
+	Warnw("foo", Field("uid", 1))
+	m := errs.entry(t)
+	assert.Equal(t, levelWarn, m["level"])
+	assert.Equal(t, float64(1), m["uid"])
+	WithContext(context.Background()).Warnf("foo %d", 1)
+	assert.Equal(t, "foo 1", errs.entry(t)["content"])
+
+	SetLevel(ErrorLevel)
+	Warn("foo")
+	assert.Equal(t, 0, errs.Len())
+
+	SetLevel(DebugLevel)
+	Debugw("foo", Field("uid", 1))
+	m = info.entry(t)
+	assert.Equal(t, levelDebug, m["level"])
+	assert.Equal(t, float64(1), m["uid"])
+	WithDuration(time.Second).Debug("foo")
+	assert.Equal(t, levelDebug, info.entry(t)["level"])
+}
+
+func TestSetupLogLevel(t *testing.T) {
+	prevLevel := atomic.LoadUint32(&logLevel)
+	defer SetLevel(prevLevel)
+
+	for level, expect := range map[string]uint32{
+		levelDebug:  DebugLevel,
+		levelInfo:   InfoLevel,
+		levelWarn:   WarnLevel,
+		levelError:  ErrorLevel,
+		levelSevere: SevereLevel,
+	} {
+		setupLogLevel(LogConf{Level: level})
+		assert.Equal(t, expect, atomic.LoadUint32(&logLevel))
+	}
+}
@@ -0,0 +1,234 @@
+package sinks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/executors"
+	"github.com/lukebull/go-zero-extern/core/lang"
+	"github.com/lukebull/go-zero-extern/core/metric"
+	"github.com/lukebull/go-zero-extern/core/prometheus"
+	"github.com/lukebull/go-zero-extern/core/threading"
+)
+
+const (
+	defaultChunkBytes    = 1024 * 1024
+	defaultFlushInterval = time.Second
+	defaultQueueSize     = 10000
+	defaultWriterName    = "batch"
+)
+
+var (
+	// ErrWriterClosed is an error that indicates the writer is closed.
+	ErrWriterClosed = errors.New("log writer closed")
+
+	metricDropped = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "log",
+		Subsystem: "writer",
+		Name:      "dropped_total",
+		Help:      "log lines dropped by the writer on full queue.",
+		Labels:    []string{"writer"},
+	})
+
+	metricFailed = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "log",
+		Subsystem: "writer",
+		Name:      "failed_total",
+		Help:      "log lines failed to flush by the writer.",
+		Labels:    []string{"writer"},
+	})
+
+	metricQueued = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: "log",
+		Subsystem: "writer",
+		Name:      "queued",
+		Help:      "log lines queued in the writer.",
+		Labels:    []string{"writer"},
+	})
+)
+
+type (
+	// FlushFunc flushes the batched log lines, like sending to kafka,
+	// each line is a json log line without the trailing newline.
+	FlushFunc func(lines [][]byte) error
+
+	// BatchOption defines the method to customize a BatchWriter.
+	BatchOption func(options *batchOptions)
+
+	// A BatchWriter is a logx.Writer that batches the log lines and flushes them in background,
+	// the lines are dropped on the full queue, to never block the logging.
+	BatchWriter struct {
+		name     string
+		flush    FlushFunc
+		queue    chan []byte
+		executor *executors.ChunkExecutor
+		done     chan lang.PlaceholderType
+		stopped  chan lang.PlaceholderType
+		once     sync.Once
+		dropped  uint64
+		failed   uint64
+	}
+
+	batchOptions struct {
+		name          string
+		chunkBytes    int
+		flushInterval time.Duration
+		queueSize     int
+	}
+)
+
+// NewBatchWriter returns a BatchWriter that flushes the log lines with flush.
+func NewBatchWriter(flush FlushFunc, opts ...BatchOption) *BatchWriter {
+	options := batchOptions{
+		name:          defaultWriterName,
+		chunkBytes:    defaultChunkBytes,
+		flushInterval: defaultFlushInterval,
+		queueSize:     defaultQueueSize,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	w := &BatchWriter{
+		name:    options.name,
+		flush:   flush,
+		queue:   make(chan []byte, options.queueSize),
+		done:    make(chan lang.PlaceholderType),
+		stopped: make(chan lang.PlaceholderType),
+	}
+	w.executor = executors.NewChunkExecutor(w.execute, executors.WithChunkBytes(options.chunkBytes),
+		executors.WithFlushInterval(options.flushInterval))
+	threading.GoSafe(w.run)
+
+	return w
+}
+
+// Close flushes the queued log lines and closes w.
+func (w *BatchWriter) Close() error {
+	w.once.Do(func() {
+		close(w.done)
+		<-w.stopped
+	})
+
+	return nil
+}
+
+// Dropped returns the count of the log lines dropped on the full queue.
+func (w *BatchWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Failed returns the count of the log lines failed to flush.
+func (w *BatchWriter) Failed() uint64 {
+	return atomic.LoadUint64(&w.failed)
+}
+
+// Write queues the log line p, the line is dropped if the queue is full.
+func (w *BatchWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w.done:
+		return 0, ErrWriterClosed
+	default:
+	}
+
+	line := make([]byte, len(p))
+	copy(line, p)
+	select {
+	case w.queue <- trimNewline(line):
+		if prometheus.Enabled() {
+			metricQueued.Inc(w.name)
+		}
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+		if prometheus.Enabled() {
+			metricDropped.Inc(w.name)
+		}
+	}
+
+	return len(p), nil
+}
+
+func (w *BatchWriter) add(line []byte) {
+	if prometheus.Enabled() {
+		metricQueued.Add(-1, w.name)
+	}
+	_ = w.executor.Add(line, len(line))
+}
+
+func (w *BatchWriter) execute(tasks []interface{}) {
+	lines := make([][]byte, len(tasks))
+	for i, task := range tasks {
+		lines[i] = task.([]byte)
+	}
+
+	if err := w.flush(lines); err != nil {
+		atomic.AddUint64(&w.failed, uint64(len(lines)))
+		if prometheus.Enabled() {
+			metricFailed.Add(float64(len(lines)), w.name)
+		}
+		// not logged by logx, to avoid the failures feeding back into the writer.
+		fmt.Fprintf(os.Stderr, "log writer %s failed to flush %d lines, error: %v\n", w.name, len(lines), err)
+	}
+}
+
+func (w *BatchWriter) run() {
+	defer close(w.stopped)
+
+	for {
+		select {
+		case line := <-w.queue:
+			w.add(line)
+		case <-w.done:
+			for {
+				select {
+				case line := <-w.queue:
+					w.add(line)
+				default:
+					w.executor.Wait()
+					return
+				}
+			}
+		}
+	}
+}
+
+// WithChunkBytes customizes a BatchWriter to flush when the batched lines are up to size bytes.
+func WithChunkBytes(size int) BatchOption {
+	return func(options *batchOptions) {
+		options.chunkBytes = size
+	}
+}
+
+// WithFlushInterval customizes a BatchWriter to flush in the given interval.
+func WithFlushInterval(interval time.Duration) BatchOption {
+	return func(options *batchOptions) {
+		options.flushInterval = interval
+	}
+}
+
+// WithName customizes a BatchWriter with the given name, which is the label of the metrics.
+func WithName(name string) BatchOption {
+	return func(options *batchOptions) {
+		options.name = name
+	}
+}
+
+// WithQueueSize customizes a BatchWriter with the given queue size,
+// the lines are dropped if the queue is full.
+func WithQueueSize(size int) BatchOption {
+	return func(options *batchOptions) {
+		options.queueSize = size
+	}
+}
+
+func trimNewline(line []byte) []byte {
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		return line[:n-1]
+	}
+
+	return line
+}
@@ -0,0 +1,99 @@
+package sinks
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type collector struct {
+	lock  sync.Mutex
+	lines []string
+}
+
+func (c *collector) flush(lines [][]byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, line := range lines {
+		c.lines = append(c.lines, string(line))
+	}
+	return nil
+}
+
+func (c *collector) get() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]string(nil), c.lines...)
+}
+
+func TestBatchWriterChunk(t *testing.T) {
+	var c collector
+	w := NewBatchWriter(c.flush, WithChunkBytes(10), WithFlushInterval(time.Hour))
+	defer w.Close()
+
+	var expect []string
+	for i := 0; i < 10; i++ {
+		line := "line-" + strconv.Itoa(i)
+		expect = append(expect, line)
+		n, err := w.Write([]byte(line + "\n"))
+		assert.Nil(t, err)
+		assert.Equal(t, len(line)+1, n)
+	}
+	assert.Eventually(t, func() bool {
+		return len(c.get()) == len(expect)
+	}, time.Second, time.Millisecond*10)
+	assert.Equal(t, expect, c.get())
+}
+
+func TestBatchWriterClose(t *testing.T) {
+	var c collector
+	w := NewBatchWriter(c.flush, WithFlushInterval(time.Hour))
+	_, err := w.Write([]byte("foo\n"))
+	assert.Nil(t, err)
+	_, err = w.Write([]byte("bar"))
+	assert.Nil(t, err)
+
+	assert.Nil(t, w.Close())
+	assert.Equal(t, []string{"foo", "bar"}, c.get())
+	_, err = w.Write([]byte("baz"))
+	assert.Equal(t, ErrWriterClosed, err)
+	assert.Nil(t, w.Close())
+}
+
+func TestBatchWriterDrop(t *testing.T) {
+	var c collector
+	block := make(chan struct{})
+	w := NewBatchWriter(func(lines [][]byte) error {
+		<-block
+		return c.flush(lines)
+	}, WithName("drop"), WithChunkBytes(1), WithQueueSize(1), WithFlushInterval(time.Hour))
+
+	const total = 100
+	for i := 0; i < total; i++ {
+		_, err := w.Write([]byte("line"))
+		assert.Nil(t, err)
+	}
+	assert.True(t, w.Dropped() > 0)
+
+	close(block)
+	assert.Nil(t, w.Close())
+	assert.Equal(t, total, len(c.get())+int(w.Dropped()))
+}
+
+func TestBatchWriterFailed(t *testing.T) {
+	w := NewBatchWriter(func(lines [][]byte) error {
+		return errors.New("any")
+	}, WithFlushInterval(time.Hour))
+	for i := 0; i < 3; i++ {
+		_, err := w.Write([]byte("line"))
+		assert.Nil(t, err)
+	}
+
+	assert.Nil(t, w.Close())
+	assert.Equal(t, uint64(3), w.Failed())
+	assert.Equal(t, uint64(0), w.Dropped())
+}
@@ -0,0 +1,193 @@
+package sinks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// LokiFormat is the format of the loki push api.
+	LokiFormat = "loki"
+	// ElasticsearchFormat is the format of the elasticsearch bulk api.
+	ElasticsearchFormat = "elasticsearch"
+
+	contentType        = "Content-Type"
+	jsonContentType    = "application/json"
+	ndjsonContentType  = "application/x-ndjson"
+	defaultHttpTimeout = time.Second * 5
+	maxErrorBodyLen    = 1024
+)
+
+// ErrUnknownFormat is an error that indicates the format of the http writer is unknown.
+var ErrUnknownFormat = errors.New("unknown http log writer format")
+
+type (
+	// A HttpConf is the config of the writer that ships the logs by http in batches.
+	HttpConf struct {
+		// Url is the push api of loki, like http://loki:3100/loki/api/v1/push,
+		// or the bulk api of elasticsearch, like http://es:9200/_bulk.
+		Url    string
+		Format string `json:",default=loki,options=loki|elasticsearch"`
+		// Labels are the stream labels of loki.
+		Labels map[string]string `json:",optional"`
+		// Index is the index of elasticsearch.
+		Index string `json:",optional"`
+		// Headers are added to the requests, like Authorization.
+		Headers       map[string]string `json:",optional"`
+		Timeout       time.Duration     `json:",default=5s"`
+		ChunkBytes    int               `json:",default=1048576"`
+		FlushInterval time.Duration     `json:",default=1s"`
+		QueueSize     int               `json:",default=10000"`
+	}
+
+	httpSender struct {
+		conf   HttpConf
+		client *http.Client
+		encode func(lines [][]byte) ([]byte, string, error)
+	}
+
+	lokiStream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+)
+
+// NewHttpWriter returns a BatchWriter that ships the logs to loki or elasticsearch in batches.
+func NewHttpWriter(c HttpConf) (*BatchWriter, error) {
+	sender := &httpSender{
+		conf: c,
+	}
+	switch c.Format {
+	case LokiFormat:
+		sender.encode = sender.encodeLoki
+	case ElasticsearchFormat:
+		sender.encode = sender.encodeElasticsearch
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultHttpTimeout
+	}
+	sender.client = &http.Client{
+		Timeout: timeout,
+	}
+
+	opts := []BatchOption{WithName(c.Format)}
+	if c.ChunkBytes > 0 {
+		opts = append(opts, WithChunkBytes(c.ChunkBytes))
+	}
+	if c.FlushInterval > 0 {
+		opts = append(opts, WithFlushInterval(c.FlushInterval))
+	}
+	if c.QueueSize > 0 {
+		opts = append(opts, WithQueueSize(c.QueueSize))
+	}
+
+	return NewBatchWriter(sender.send, opts...), nil
+}
+
+func (s *httpSender) encodeElasticsearch(lines [][]byte) ([]byte, string, error) {
+	// the index can be omitted if it's in the url, like http://es:9200/logs/_bulk.
+	meta := map[string]string{}
+	if len(s.conf.Index) > 0 {
+		meta["_index"] = s.conf.Index
+	}
+	action, err := json.Marshal(map[string]map[string]string{
+		"index": meta,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.Write(action)
+		buf.WriteByte('\n')
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), ndjsonContentType, nil
+}
+
+// encodeLoki encodes the lines into one stream, the timestamps are the flushing time,
+// increased by nanoseconds to keep the lines in order.
+func (s *httpSender) encodeLoki(lines [][]byte) ([]byte, string, error) {
+	labels := s.conf.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	now := time.Now().UnixNano()
+	stream := lokiStream{
+		Stream: labels,
+		Values: make([][2]string, len(lines)),
+	}
+	for i, line := range lines {
+		stream.Values[i] = [2]string{strconv.FormatInt(now+int64(i), 10), string(line)}
+	}
+
+	body, err := json.Marshal(map[string][]lokiStream{
+		"streams": {stream},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return body, jsonContentType, nil
+}
+
+func (s *httpSender) send(lines [][]byte) error {
+	body, typ, err := s.encode(lines)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.conf.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(contentType, typ)
+	for k, v := range s.conf.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLen))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("status: %d, body: %s", resp.StatusCode, content)
+	}
+	if s.conf.Format == ElasticsearchFormat {
+		return bulkError(content)
+	}
+
+	return nil
+}
+
+// bulkError returns the error of the bulk response, which responds 200 on the failed items,
+// the errors key is at the beginning of the truncated response.
+func bulkError(content []byte) error {
+	if bytes.Contains(content, []byte(`"errors":true`)) {
+		return fmt.Errorf("bulk items failed: %s", content)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package sinks
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	lock     sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	respond  func(w http.ResponseWriter)
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	rec.lock.Lock()
+	rec.requests = append(rec.requests, r)
+	rec.bodies = append(rec.bodies, string(body))
+	rec.lock.Unlock()
+	if rec.respond != nil {
+		rec.respond(w)
+	}
+}
+
+func TestHttpWriterLoki(t *testing.T) {
+	rec := new(recorder)
+	svr := httptest.NewServer(rec)
+	defer svr.Close()
+
+	w, err := NewHttpWriter(HttpConf{
+		Url:    svr.URL + "/loki/api/v1/push",
+		Format: LokiFormat,
+		Labels: map[string]string{
+			"app": "foo",
+		},
+		Headers: map[string]string{
+			"X-Scope-OrgID": "tenant",
+		},
+		FlushInterval: time.Hour,
+	})
+	assert.Nil(t, err)
+	_, _ = w.Write([]byte(`{"level":"info","content":"foo"}` + "\n"))
+	_, _ = w.Write([]byte(`{"level":"error","content":"bar"}` + "\n"))
+	assert.Nil(t, w.Close())
+
+	assert.Len(t, rec.requests, 1)
+	assert.Equal(t, "/loki/api/v1/push", rec.requests[0].URL.Path)
+	assert.Equal(t, jsonContentType, rec.requests[0].Header.Get(contentType))
+	assert.Equal(t, "tenant", rec.requests[0].Header.Get("X-Scope-OrgID"))
+
+	var body struct {
+		Streams []lokiStream `json:"streams"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(rec.bodies[0]), &body))
+	assert.Len(t, body.Streams, 1)
+	assert.Equal(t, map[string]string{"app": "foo"}, body.Streams[0].Stream)
+	assert.Len(t, body.Streams[0].Values, 2)
+	assert.Equal(t, `{"level":"info","content":"foo"}`, body.Streams[0].Values[0][1])
+	assert.Equal(t, `{"level":"error","content":"bar"}`, body.Streams[0].Values[1][1])
+	assert.True(t, body.Streams[0].Values[0][0] < body.Streams[0].Values[1][0])
+	assert.Equal(t, uint64(0), w.Failed())
+}
+
+func TestHttpWriterElasticsearch(t *testing.T) {
+	rec := &recorder{
+		respond: func(w http.ResponseWriter) {
+			_, _ = w.Write([]byte(`{"took":1,"errors":false,"items":[]}`))
+		},
+	}
+	svr := httptest.NewServer(rec)
+	defer svr.Close()
+
+	w, err := NewHttpWriter(HttpConf{
+		Url:           svr.URL + "/_bulk",
+		Format:        ElasticsearchFormat,
+		Index:         "logs",
+		FlushInterval: time.Hour,
+	})
+	assert.Nil(t, err)
+	_, _ = w.Write([]byte(`{"content":"foo"}` + "\n"))
+	_, _ = w.Write([]byte(`{"content":"bar"}` + "\n"))
+	assert.Nil(t, w.Close())
+
+	assert.Len(t, rec.requests, 1)
+	assert.Equal(t, ndjsonContentType, rec.requests[0].Header.Get(contentType))
+	assert.Equal(t, strings.Join([]string{
+		`{"index":{"_index":"logs"}}`,
+		`{"content":"foo"}`,
+		`{"index":{"_index":"logs"}}`,
+		`{"content":"bar"}`,
+	}, "\n")+"\n", rec.bodies[0])
+	assert.Equal(t, uint64(0), w.Failed())
+}
+
+func TestHttpWriterFailures(t *testing.T) {
+	for _, respond := range []func(w http.ResponseWriter){
+		func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		},
+		func(w http.ResponseWriter) {
+			_, _ = w.Write([]byte(`{"took":1,"errors":true,"items":[]}`))
+		},
+	} {
+		svr := httptest.NewServer(&recorder{
+			respond: respond,
+		})
+
+		w, err := NewHttpWriter(HttpConf{
+			Url:           svr.URL + "/logs/_bulk",
+			Format:        ElasticsearchFormat,
+			FlushInterval: time.Hour,
+		})
+		assert.Nil(t, err)
+		_, _ = w.Write([]byte(`{"content":"foo"}`))
+		assert.Nil(t, w.Close())
+		assert.Equal(t, uint64(1), w.Failed())
+		svr.Close()
+	}
+
+	_, err := NewHttpWriter(HttpConf{
+		Url:    "http://localhost",
+		Format: "kafka",
+	})
+	assert.Equal(t, ErrUnknownFormat, err)
+}
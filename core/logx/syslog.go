@@ -1,15 +1,84 @@
 package logx
 
-import "log"
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+)
 
-type redirector struct{}
+// ErrSyslogNotSupported is an error that indicates syslog is not supported on the platform.
+var ErrSyslogNotSupported = errors.New("syslog is not supported on this platform")
+
+type (
+	redirector struct{}
+
+	// sysLogger is the subset of syslog.Writer that used by syslogWriter.
+	sysLogger interface {
+		Close() error
+		Crit(m string) error
+		Debug(m string) error
+		Err(m string) error
+		Info(m string) error
+		Warning(m string) error
+	}
+
+	syslogWriter struct {
+		logger sysLogger
+	}
+)
 
 // CollectSysLog redirects system log into logx info
 func CollectSysLog() {
 	log.SetOutput(new(redirector))
 }
 
+// NewSyslogWriter returns a Writer that writes the logs to the syslog daemon at raddr on network,
+// or the local syslog daemon if network is empty. The syslog severities follow the log levels.
+func NewSyslogWriter(network, raddr, tag string) (Writer, error) {
+	logger, err := dialSyslog(network, raddr, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return &syslogWriter{
+		logger: logger,
+	}, nil
+}
+
 func (r *redirector) Write(p []byte) (n int, err error) {
 	Info(string(p))
 	return len(p), nil
 }
+
+func (w *syslogWriter) Close() error {
+	return w.logger.Close()
+}
+
+func (w *syslogWriter) Write(p []byte) (int, error) {
+	var entry struct {
+		Level string `json:"level"`
+	}
+	// the lines that are not json are written in info severity.
+	_ = json.Unmarshal(p, &entry)
+
+	var err error
+	msg := strings.TrimSuffix(string(p), "\n")
+	switch entry.Level {
+	case levelDebug:
+		err = w.logger.Debug(msg)
+	case levelWarn, levelSlow:
+		err = w.logger.Warning(msg)
+	case levelError, levelAlert:
+		err = w.logger.Err(msg)
+	case levelSevere, levelFatal:
+		err = w.logger.Crit(msg)
+	default:
+		err = w.logger.Info(msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
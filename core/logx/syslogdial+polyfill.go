@@ -0,0 +1,7 @@
+// +build windows plan9
+
+package logx
+
+func dialSyslog(network, raddr, tag string) (sysLogger, error) {
+	return nil, ErrSyslogNotSupported
+}
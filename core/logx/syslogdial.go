@@ -0,0 +1,9 @@
+// +build !windows,!plan9
+
+package logx
+
+import "log/syslog"
+
+func dialSyslog(network, raddr, tag string) (sysLogger, error) {
+	return syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+}
@@ -0,0 +1,45 @@
+// +build !windows,!plan9
+
+package logx
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyslogWriter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	w, err := NewSyslogWriter("udp", conn.LocalAddr().String(), "test")
+	assert.Nil(t, err)
+	defer w.Close()
+
+	// the priority is facility LOG_USER (8) + severity
+	for line, priority := range map[string]string{
+		`{"level":"debug","content":"foo"}`:  "<15>",
+		`{"level":"info","content":"foo"}`:   "<14>",
+		`{"level":"warn","content":"foo"}`:   "<12>",
+		`{"level":"slow","content":"foo"}`:   "<12>",
+		`{"level":"error","content":"foo"}`:  "<11>",
+		`{"level":"severe","content":"foo"}`: "<10>",
+		"not json":                           "<14>",
+	} {
+		n, err := w.Write([]byte(line + "\n"))
+		assert.Nil(t, err)
+		assert.Equal(t, len(line)+1, n)
+
+		buf := make([]byte, 1024)
+		assert.Nil(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err = conn.ReadFrom(buf)
+		assert.Nil(t, err)
+		msg := string(buf[:n])
+		assert.True(t, strings.HasPrefix(msg, priority), msg)
+		assert.True(t, strings.HasSuffix(strings.TrimSpace(msg), line), msg)
+	}
+}
@@ -17,6 +17,24 @@ type traceLogger struct {
 	ctx   context.Context
 }
 
+func (l *traceLogger) Debug(v ...interface{}) {
+	if shouldLog(DebugLevel) {
+		l.write(infoLog, levelDebug, fmt.Sprint(v...))
+	}
+}
+
+func (l *traceLogger) Debugf(format string, v ...interface{}) {
+	if shouldLog(DebugLevel) {
+		l.write(infoLog, levelDebug, fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *traceLogger) Debugw(msg string, fields ...LogField) {
+	if shouldLog(DebugLevel) {
+		l.write(infoLog, levelDebug, msg, fields...)
+	}
+}
+
 func (l *traceLogger) Error(v ...interface{}) {
 	if shouldLog(ErrorLevel) {
 		l.write(errorLog, levelError, formatWithCaller(fmt.Sprint(v...), durationCallerDepth))
@@ -29,6 +47,12 @@ func (l *traceLogger) Errorf(format string, v ...interface{}) {
 	}
 }
 
+func (l *traceLogger) Errorw(msg string, fields ...LogField) {
+	if shouldLog(ErrorLevel) {
+		l.write(errorLog, levelError, formatWithCaller(msg, durationCallerDepth), fields...)
+	}
+}
+
 func (l *traceLogger) Info(v ...interface{}) {
 	if shouldLog(InfoLevel) {
 		l.write(infoLog, levelInfo, fmt.Sprint(v...))
@@ -41,6 +65,12 @@ func (l *traceLogger) Infof(format string, v ...interface{}) {
 	}
 }
 
+func (l *traceLogger) Infow(msg string, fields ...LogField) {
+	if shouldLog(InfoLevel) {
+		l.write(infoLog, levelInfo, msg, fields...)
+	}
+}
+
 func (l *traceLogger) Slow(v ...interface{}) {
 	if shouldLog(ErrorLevel) {
 		l.write(slowLog, levelSlow, fmt.Sprint(v...))
@@ -53,21 +83,48 @@ func (l *traceLogger) Slowf(format string, v ...interface{}) {
 	}
 }
 
+func (l *traceLogger) Sloww(msg string, fields ...LogField) {
+	if shouldLog(ErrorLevel) {
+		l.write(slowLog, levelSlow, msg, fields...)
+	}
+}
+
+func (l *traceLogger) Warn(v ...interface{}) {
+	if shouldLog(WarnLevel) {
+		l.write(errorLog, levelWarn, fmt.Sprint(v...))
+	}
+}
+
+func (l *traceLogger) Warnf(format string, v ...interface{}) {
+	if shouldLog(WarnLevel) {
+		l.write(errorLog, levelWarn, fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *traceLogger) Warnw(msg string, fields ...LogField) {
+	if shouldLog(WarnLevel) {
+		l.write(errorLog, levelWarn, msg, fields...)
+	}
+}
+
 func (l *traceLogger) WithDuration(duration time.Duration) Logger {
 	l.Duration = timex.ReprOfDuration(duration)
 	return l
 }
 
-func (l *traceLogger) write(writer io.Writer, level, content string) {
+func (l *traceLogger) write(writer io.Writer, level, content string, fields ...LogField) {
 	l.Timestamp = getTimestamp()
 	l.Level = level
 	l.Content = content
 	l.Trace = traceIdFromContext(l.ctx)
 	l.Span = spanIdFromContext(l.ctx)
-	outputJson(writer, l)
+	if ctxFields := ContextFields(l.ctx); len(ctxFields) > 0 {
+		fields = append(ctxFields[:len(ctxFields):len(ctxFields)], fields...)
+	}
+	outputJson(writer, l, fields...)
 }
 
-// WithContext sets ctx to log, for keeping tracing information.
+// WithContext sets ctx to log, for keeping tracing information and the fields carried by ctx.
 func WithContext(ctx context.Context) Logger {
 	return &traceLogger{
 		ctx: ctx,
@@ -0,0 +1,30 @@
+package logx
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// customWriter is the writer set by SetWriter, closed once on Close.
+var customWriter Writer
+
+// A Writer is used to write the logs, each Write is a json log line with a trailing newline.
+type Writer interface {
+	io.Writer
+	Close() error
+}
+
+// SetWriter sets w as the writer of all the logs, SetUp afterwards is ignored.
+// It should be called on initializing, before any logging.
+func SetWriter(w Writer) {
+	once.Do(func() {})
+	atomic.StoreUint32(&initialized, 1)
+	writeConsole = false
+	customWriter = w
+	infoLog = w
+	errorLog = w
+	severeLog = w
+	slowLog = w
+	statLog = w
+	stackLog = newLessWriter(w, options.logStackCooldownMills)
+}
@@ -0,0 +1,48 @@
+package logx
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type closeCountWriter struct {
+	mockWriter
+	closed int
+}
+
+func (w *closeCountWriter) Close() error {
+	w.closed++
+	return nil
+}
+
+func TestSetWriter(t *testing.T) {
+	prevInfo, prevError, prevSevere, prevSlow, prevStat, prevStack := infoLog, errorLog, severeLog,
+		slowLog, statLog, stackLog
+	prevInitialized, prevConsole := atomic.LoadUint32(&initialized), writeConsole
+	defer func() {
+		infoLog, errorLog, severeLog, slowLog, statLog, stackLog = prevInfo, prevError, prevSevere,
+			prevSlow, prevStat, prevStack
+		atomic.StoreUint32(&initialized, prevInitialized)
+		writeConsole = prevConsole
+		customWriter = nil
+	}()
+
+	w := new(closeCountWriter)
+	SetWriter(w)
+	Infow("foo", Field("uid", 1))
+	m := w.entry(t)
+	assert.Equal(t, "foo", m["content"])
+	assert.Equal(t, float64(1), m["uid"])
+	Slow("bar")
+	assert.Equal(t, levelSlow, w.entry(t)["level"])
+
+	// SetUp is ignored after SetWriter
+	assert.Nil(t, SetUp(LogConf{Mode: consoleMode}))
+	Error("baz")
+	assert.Equal(t, levelError, w.entry(t)["level"])
+
+	assert.Nil(t, Close())
+	assert.Equal(t, 1, w.closed)
+}
@@ -0,0 +1,545 @@
+package mapping
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lukebull/go-zero-extern/core/stringx"
+)
+
+const (
+	minLenOption   = "minlen"
+	maxLenOption   = "maxlen"
+	patternOption  = "pattern"
+	formatOption   = "format"
+	minItemsOption = "minitems"
+	maxItemsOption = "maxitems"
+	eqFieldOption  = "eqfield"
+	neFieldOption  = "nefield"
+	gtFieldOption  = "gtfield"
+	gteFieldOption = "gtefield"
+	ltFieldOption  = "ltfield"
+	lteFieldOption = "ltefield"
+
+	formatEmail    = "email"
+	formatUrl      = "url"
+	formatUuid     = "uuid"
+	formatIp       = "ip"
+	formatIpv4     = "ipv4"
+	formatIpv6     = "ipv6"
+	formatDate     = "date"
+	formatDateTime = "datetime"
+	dateLayout     = "2006-01-02"
+)
+
+var (
+	uuidRegex          = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	timeType           = reflect.TypeOf(time.Time{})
+	validationCache    = make(map[validationCacheKey]validationCacheValue)
+	validationLock     sync.RWMutex
+	errNotComparable   = errors.New("fields are not comparable")
+	crossFieldMessages = map[string]string{
+		eqFieldOption:  "must be equal to %s",
+		neFieldOption:  "must not be equal to %s",
+		gtFieldOption:  "must be greater than %s",
+		gteFieldOption: "must be greater than or equal to %s",
+		ltFieldOption:  "must be less than %s",
+		lteFieldOption: "must be less than or equal to %s",
+	}
+)
+
+type (
+	// A FieldError is a validation failure on a field.
+	FieldError struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	}
+
+	// ValidationErrors is a collection of the failed fields, returned by ValidateStruct.
+	ValidationErrors []FieldError
+
+	validationRules struct {
+		optional    bool
+		minLen      *int
+		maxLen      *int
+		pattern     *regexp.Regexp
+		format      string
+		minItems    *int
+		maxItems    *int
+		crossFields []crossFieldRule
+	}
+
+	crossFieldRule struct {
+		op    string
+		field string
+	}
+
+	fieldValidation struct {
+		index int
+		key   string
+		// anonymous fields are validated with the prefix of their parents.
+		anonymous bool
+		rules     *validationRules
+	}
+
+	validationCacheKey struct {
+		tp   reflect.Type
+		keys string
+	}
+
+	validationCacheValue struct {
+		fields []fieldValidation
+		err    error
+	}
+)
+
+func (ve ValidationErrors) Error() string {
+	var b strings.Builder
+	for i, fe := range ve {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(fe.Field)
+		b.WriteString(": ")
+		b.WriteString(fe.Message)
+	}
+
+	return b.String()
+}
+
+// ValidateStruct validates v with the validation options in the tags with given keys, like
+// `json:"name,minlen=3,maxlen=20"`, all the failed fields are collected into ValidationErrors.
+// Supported options are minlen, maxlen, pattern and format for strings, minitems and maxitems
+// for slices and maps, eqfield, nefield, gtfield, gtefield, ltfield and ltefield for comparing
+// with the sibling fields by their go names. Zero values of optional fields are not validated.
+// Patterns can't contain commas, because commas separate the options.
+func ValidateStruct(v interface{}, keys ...string) error {
+	rv := reflect.ValueOf(v)
+	if err := ValidatePtr(&rv); err != nil {
+		return err
+	}
+
+	rve := rv.Elem()
+	if rve.Kind() != reflect.Struct {
+		return errValueNotStruct
+	}
+
+	var errs ValidationErrors
+	if err := validateStruct(rve, "", keys, &errs); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// ValidateTagOptions checks if the validation options in the tag value are well-formed,
+// like `name,optional,minlen=3,pattern=^[a-z]+$`.
+func ValidateTagOptions(value string) error {
+	_, _, err := parseValidationRules(value)
+	return err
+}
+
+func checkCrossField(structValue, value reflect.Value, rule crossFieldRule) (string, error) {
+	other := structValue.FieldByName(rule.field)
+	if !other.IsValid() {
+		return "", fmt.Errorf("field %s in %s not exists", rule.field, rule.op)
+	}
+
+	other = reflect.Indirect(other)
+	value = reflect.Indirect(value)
+	if !other.IsValid() || !value.IsValid() {
+		return "", nil
+	}
+
+	cmp, err := compareValues(value, other)
+	if err != nil {
+		return "", fmt.Errorf("%s=%s: %v", rule.op, rule.field, err)
+	}
+
+	var ok bool
+	switch rule.op {
+	case eqFieldOption:
+		ok = cmp == 0
+	case neFieldOption:
+		ok = cmp != 0
+	case gtFieldOption:
+		ok = cmp > 0
+	case gteFieldOption:
+		ok = cmp >= 0
+	case ltFieldOption:
+		ok = cmp < 0
+	case lteFieldOption:
+		ok = cmp <= 0
+	}
+	if ok {
+		return "", nil
+	}
+
+	return fmt.Sprintf(crossFieldMessages[rule.op], rule.field), nil
+}
+
+func checkFormat(format, val string) bool {
+	switch format {
+	case formatEmail:
+		addr, err := mail.ParseAddress(val)
+		return err == nil && addr.Address == val
+	case formatUrl:
+		u, err := url.ParseRequestURI(val)
+		return err == nil && len(u.Scheme) > 0 && len(u.Host) > 0
+	case formatUuid:
+		return uuidRegex.MatchString(val)
+	case formatIp:
+		return net.ParseIP(val) != nil
+	case formatIpv4:
+		ip := net.ParseIP(val)
+		return ip != nil && ip.To4() != nil && !strings.Contains(val, ":")
+	case formatIpv6:
+		ip := net.ParseIP(val)
+		return ip != nil && strings.Contains(val, ":")
+	case formatDate:
+		_, err := time.Parse(dateLayout, val)
+		return err == nil
+	case formatDateTime:
+		_, err := time.Parse(time.RFC3339, val)
+		return err == nil
+	default:
+		return false
+	}
+}
+
+func checkRules(structValue, value reflect.Value, rules *validationRules) (string, error) {
+	if rules.optional && value.IsZero() {
+		return "", nil
+	}
+
+	for _, rule := range rules.crossFields {
+		if msg, err := checkCrossField(structValue, value, rule); err != nil || len(msg) > 0 {
+			return msg, err
+		}
+	}
+
+	value = reflect.Indirect(value)
+	if !value.IsValid() {
+		return "", nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return checkString(value.String(), rules)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return checkItems(value.Len(), rules)
+	}
+
+	if rules.minLen != nil || rules.maxLen != nil || rules.pattern != nil || len(rules.format) > 0 {
+		return "", fmt.Errorf("string options on %s value", value.Kind())
+	}
+	if rules.minItems != nil || rules.maxItems != nil {
+		return "", fmt.Errorf("items options on %s value", value.Kind())
+	}
+
+	return "", nil
+}
+
+func checkItems(n int, rules *validationRules) (string, error) {
+	if rules.minLen != nil || rules.maxLen != nil || rules.pattern != nil || len(rules.format) > 0 {
+		return "", errors.New("string options on collection value")
+	}
+
+	if rules.minItems != nil && n < *rules.minItems {
+		return fmt.Sprintf("must have at least %d items", *rules.minItems), nil
+	}
+	if rules.maxItems != nil && n > *rules.maxItems {
+		return fmt.Sprintf("must have at most %d items", *rules.maxItems), nil
+	}
+
+	return "", nil
+}
+
+func checkString(val string, rules *validationRules) (string, error) {
+	if rules.minItems != nil || rules.maxItems != nil {
+		return "", errors.New("items options on string value")
+	}
+
+	length := utf8.RuneCountInString(val)
+	if rules.minLen != nil && length < *rules.minLen {
+		return fmt.Sprintf("length must be at least %d", *rules.minLen), nil
+	}
+	if rules.maxLen != nil && length > *rules.maxLen {
+		return fmt.Sprintf("length must be at most %d", *rules.maxLen), nil
+	}
+	if rules.pattern != nil && !rules.pattern.MatchString(val) {
+		return fmt.Sprintf("must match pattern %s", rules.pattern.String()), nil
+	}
+	if len(rules.format) > 0 && !checkFormat(rules.format, val) {
+		return fmt.Sprintf("must be a valid %s", rules.format), nil
+	}
+
+	return "", nil
+}
+
+func compareValues(a, b reflect.Value) (int, error) {
+	if a.Type() == timeType && b.Type() == timeType {
+		ta, tb := a.Interface().(time.Time), b.Interface().(time.Time)
+		switch {
+		case ta.Before(tb):
+			return -1, nil
+		case ta.After(tb):
+			return 1, nil
+		default:
+			return 0, nil
+		}
+	}
+
+	if a.Kind() == reflect.String && b.Kind() == reflect.String {
+		return strings.Compare(a.String(), b.String()), nil
+	}
+
+	fa, ok := numberOf(a)
+	if !ok {
+		return 0, errNotComparable
+	}
+	fb, ok := numberOf(b)
+	if !ok {
+		return 0, errNotComparable
+	}
+
+	switch {
+	case fa < fb:
+		return -1, nil
+	case fa > fb:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+func getFieldValidations(tp reflect.Type, keys []string) ([]fieldValidation, error) {
+	cacheKey := validationCacheKey{
+		tp:   tp,
+		keys: strings.Join(keys, ","),
+	}
+	validationLock.RLock()
+	val, ok := validationCache[cacheKey]
+	validationLock.RUnlock()
+	if ok {
+		return val.fields, val.err
+	}
+
+	fields, err := parseFieldValidations(tp, keys)
+	validationLock.Lock()
+	validationCache[cacheKey] = validationCacheValue{
+		fields: fields,
+		err:    err,
+	}
+	validationLock.Unlock()
+
+	return fields, err
+}
+
+func joinFieldName(prefix, name string) string {
+	if len(prefix) == 0 {
+		return name
+	}
+
+	return prefix + string(delimiter) + name
+}
+
+func numberOf(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func parseFieldValidations(tp reflect.Type, keys []string) ([]fieldValidation, error) {
+	var fields []fieldValidation
+	for i := 0; i < tp.NumField(); i++ {
+		field := tp.Field(i)
+		// unexported fields
+		if len(field.PkgPath) > 0 && !field.Anonymous {
+			continue
+		}
+
+		fv := fieldValidation{
+			index:     i,
+			key:       field.Name,
+			anonymous: field.Anonymous,
+		}
+		for _, key := range keys {
+			value, ok := field.Tag.Lookup(key)
+			if !ok {
+				continue
+			}
+
+			name, rules, err := parseValidationRules(value)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %v", field.Name, err)
+			}
+
+			fv.key = stringx.TakeOne(name, field.Name)
+			fv.rules = rules
+			break
+		}
+
+		fields = append(fields, fv)
+	}
+
+	return fields, nil
+}
+
+func parseIntOption(option, val string) (*int, error) {
+	n, err := strconv.Atoi(val)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("wrong %s value %q", option, val)
+	}
+
+	return &n, nil
+}
+
+// parseValidationRules returns nil rules if no validation options in value.
+func parseValidationRules(value string) (string, *validationRules, error) {
+	segments := strings.Split(value, ",")
+	key := strings.TrimSpace(segments[0])
+
+	var rules validationRules
+	var hasRules bool
+	for _, segment := range segments[1:] {
+		option := strings.TrimSpace(segment)
+		if option == optionalOption || strings.HasPrefix(option, optionalOption+equalToken) {
+			rules.optional = true
+			continue
+		}
+
+		kv := strings.SplitN(option, equalToken, 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		name, val := kv[0], kv[1]
+		var err error
+		switch name {
+		case minLenOption:
+			rules.minLen, err = parseIntOption(name, val)
+		case maxLenOption:
+			rules.maxLen, err = parseIntOption(name, val)
+		case minItemsOption:
+			rules.minItems, err = parseIntOption(name, val)
+		case maxItemsOption:
+			rules.maxItems, err = parseIntOption(name, val)
+		case patternOption:
+			rules.pattern, err = regexp.Compile(val)
+		case formatOption:
+			switch val {
+			case formatEmail, formatUrl, formatUuid, formatIp, formatIpv4, formatIpv6, formatDate, formatDateTime:
+				rules.format = val
+			default:
+				err = fmt.Errorf("unknown format %q", val)
+			}
+		case eqFieldOption, neFieldOption, gtFieldOption, gteFieldOption, ltFieldOption, lteFieldOption:
+			if len(val) == 0 {
+				err = fmt.Errorf("empty field in %s", name)
+			}
+			rules.crossFields = append(rules.crossFields, crossFieldRule{
+				op:    name,
+				field: val,
+			})
+		default:
+			continue
+		}
+		if err != nil {
+			return "", nil, err
+		}
+
+		hasRules = true
+	}
+
+	if !hasRules {
+		return key, nil, nil
+	}
+
+	return key, &rules, nil
+}
+
+func validateStruct(rv reflect.Value, prefix string, keys []string, errs *ValidationErrors) error {
+	fields, err := getFieldValidations(rv.Type(), keys)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		value := rv.Field(field.index)
+		name := prefix
+		if !field.anonymous {
+			name = joinFieldName(prefix, field.key)
+		}
+
+		if field.rules != nil {
+			msg, err := checkRules(rv, value, field.rules)
+			if err != nil {
+				return fmt.Errorf("field %s: %v", name, err)
+			}
+
+			if len(msg) > 0 {
+				*errs = append(*errs, FieldError{
+					Field:   name,
+					Message: msg,
+				})
+			}
+		}
+
+		if err := validateValue(value, name, keys, errs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateValue(value reflect.Value, name string, keys []string, errs *ValidationErrors) error {
+	value = reflect.Indirect(value)
+	if !value.IsValid() {
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if value.Type() == timeType {
+			return nil
+		}
+
+		return validateStruct(value, name, keys, errs)
+	case reflect.Slice, reflect.Array:
+		elem := Deref(value.Type().Elem())
+		if elem.Kind() != reflect.Struct || elem == timeType {
+			return nil
+		}
+
+		for i := 0; i < value.Len(); i++ {
+			if err := validateValue(value.Index(i), fmt.Sprintf("%s[%d]", name, i), keys, errs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
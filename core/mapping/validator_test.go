@@ -0,0 +1,426 @@
+package mapping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateStructStringOptions(t *testing.T) {
+	type request struct {
+		Name  string `json:"name,minlen=3,maxlen=5"`
+		Code  string `json:"code,optional,pattern=^[a-z]+$"`
+		Email string `json:"email,optional,format=email"`
+		Site  string `json:"site,optional,format=url"`
+		Id    string `json:"id,optional,format=uuid"`
+		Ip    string `json:"ip,optional,format=ipv4"`
+		Day   string `json:"day,optional,format=date"`
+	}
+
+	tests := []struct {
+		name   string
+		req    request
+		expect ValidationErrors
+	}{
+		{
+			name: "valid",
+			req: request{
+				Name:  "foo",
+				Code:  "abc",
+				Email: "foo@example.com",
+				Site:  "https://example.com/a",
+				Id:    "123e4567-e89b-12d3-a456-426614174000",
+				Ip:    "127.0.0.1",
+				Day:   "2021-01-02",
+			},
+		},
+		{
+			name: "optional zero values",
+			req:  request{Name: "foo"},
+		},
+		{
+			name: "minlen",
+			req:  request{Name: "fo"},
+			expect: ValidationErrors{
+				{Field: "name", Message: "length must be at least 3"},
+			},
+		},
+		{
+			name: "maxlen counts runes",
+			req:  request{Name: "你好世界啊"},
+		},
+		{
+			name: "maxlen",
+			req:  request{Name: "foobar"},
+			expect: ValidationErrors{
+				{Field: "name", Message: "length must be at most 5"},
+			},
+		},
+		{
+			name: "pattern",
+			req:  request{Name: "foo", Code: "ABC"},
+			expect: ValidationErrors{
+				{Field: "code", Message: "must match pattern ^[a-z]+$"},
+			},
+		},
+		{
+			name: "formats",
+			req: request{
+				Name:  "foo",
+				Email: "foo",
+				Site:  "example.com",
+				Id:    "123",
+				Ip:    "::1",
+				Day:   "2021-13-02",
+			},
+			expect: ValidationErrors{
+				{Field: "email", Message: "must be a valid email"},
+				{Field: "site", Message: "must be a valid url"},
+				{Field: "id", Message: "must be a valid uuid"},
+				{Field: "ip", Message: "must be a valid ipv4"},
+				{Field: "day", Message: "must be a valid date"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateStruct(&test.req, "json")
+			if len(test.expect) == 0 {
+				assert.Nil(t, err)
+				return
+			}
+
+			assert.Equal(t, test.expect, err)
+		})
+	}
+}
+
+func TestValidateStructItemsOptions(t *testing.T) {
+	type request struct {
+		Tags   []string          `json:"tags,minitems=1,maxitems=2"`
+		Labels map[string]string `json:"labels,optional,maxitems=1"`
+	}
+
+	tests := []struct {
+		name   string
+		req    request
+		expect ValidationErrors
+	}{
+		{
+			name: "valid",
+			req:  request{Tags: []string{"a"}, Labels: map[string]string{"a": "b"}},
+		},
+		{
+			name: "minitems",
+			req:  request{},
+			expect: ValidationErrors{
+				{Field: "tags", Message: "must have at least 1 items"},
+			},
+		},
+		{
+			name: "maxitems",
+			req: request{
+				Tags:   []string{"a", "b", "c"},
+				Labels: map[string]string{"a": "b", "c": "d"},
+			},
+			expect: ValidationErrors{
+				{Field: "tags", Message: "must have at most 2 items"},
+				{Field: "labels", Message: "must have at most 1 items"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateStruct(&test.req, "json")
+			if len(test.expect) == 0 {
+				assert.Nil(t, err)
+				return
+			}
+
+			assert.Equal(t, test.expect, err)
+		})
+	}
+}
+
+func TestValidateStructCrossFields(t *testing.T) {
+	type request struct {
+		Password string    `json:"password"`
+		Confirm  string    `json:"confirm,eqfield=Password"`
+		Old      string    `json:"old,optional,nefield=Password"`
+		Min      int       `json:"min"`
+		Max      int64     `json:"max,gtfield=Min"`
+		Limit    *float64  `json:"limit,optional,gtefield=Min"`
+		Low      uint      `json:"low,ltfield=Min"`
+		High     int       `json:"high,ltefield=Max"`
+		Start    time.Time `json:"start"`
+		End      time.Time `json:"end,gtfield=Start"`
+	}
+
+	now := time.Now()
+	valid := func() request {
+		limit := 1.0
+		return request{
+			Password: "foo",
+			Confirm:  "foo",
+			Old:      "bar",
+			Min:      1,
+			Max:      2,
+			Limit:    &limit,
+			Low:      0,
+			High:     2,
+			Start:    now,
+			End:      now.Add(time.Second),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(r *request)
+		expect ValidationErrors
+	}{
+		{
+			name:   "valid",
+			modify: func(r *request) {},
+		},
+		{
+			name: "eqfield",
+			modify: func(r *request) {
+				r.Confirm = "bar"
+			},
+			expect: ValidationErrors{
+				{Field: "confirm", Message: "must be equal to Password"},
+			},
+		},
+		{
+			name: "nefield",
+			modify: func(r *request) {
+				r.Old = "foo"
+			},
+			expect: ValidationErrors{
+				{Field: "old", Message: "must not be equal to Password"},
+			},
+		},
+		{
+			name: "gtfield",
+			modify: func(r *request) {
+				r.Max = 1
+				r.High = 1
+			},
+			expect: ValidationErrors{
+				{Field: "max", Message: "must be greater than Min"},
+			},
+		},
+		{
+			name: "gtefield on pointer",
+			modify: func(r *request) {
+				limit := 0.5
+				r.Limit = &limit
+			},
+			expect: ValidationErrors{
+				{Field: "limit", Message: "must be greater than or equal to Min"},
+			},
+		},
+		{
+			name: "nil optional pointer",
+			modify: func(r *request) {
+				r.Limit = nil
+			},
+		},
+		{
+			name: "ltfield",
+			modify: func(r *request) {
+				r.Low = 1
+			},
+			expect: ValidationErrors{
+				{Field: "low", Message: "must be less than Min"},
+			},
+		},
+		{
+			name: "ltefield",
+			modify: func(r *request) {
+				r.High = 3
+			},
+			expect: ValidationErrors{
+				{Field: "high", Message: "must be less than or equal to Max"},
+			},
+		},
+		{
+			name: "gtfield on time",
+			modify: func(r *request) {
+				r.End = now
+			},
+			expect: ValidationErrors{
+				{Field: "end", Message: "must be greater than Start"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			req := valid()
+			test.modify(&req)
+			err := ValidateStruct(&req, "json")
+			if len(test.expect) == 0 {
+				assert.Nil(t, err)
+				return
+			}
+
+			assert.Equal(t, test.expect, err)
+		})
+	}
+}
+
+func TestValidateStructNested(t *testing.T) {
+	type (
+		item struct {
+			Name string `json:"name,minlen=2"`
+		}
+		Base struct {
+			Id string `json:"id,minlen=1"`
+		}
+		request struct {
+			Base
+			Owner item    `json:"owner"`
+			Extra *item   `json:"extra,optional"`
+			Items []item  `json:"items"`
+			Refs  []*item `json:"refs,optional"`
+		}
+	)
+
+	tests := []struct {
+		name   string
+		req    request
+		expect ValidationErrors
+	}{
+		{
+			name: "valid",
+			req: request{
+				Base:  Base{Id: "1"},
+				Owner: item{Name: "foo"},
+				Items: []item{{Name: "foo"}},
+			},
+		},
+		{
+			name: "all failed fields with prefixes",
+			req: request{
+				Owner: item{Name: "f"},
+				Extra: &item{},
+				Items: []item{{Name: "foo"}, {Name: "b"}},
+				Refs:  []*item{nil, {Name: "x"}},
+			},
+			expect: ValidationErrors{
+				{Field: "id", Message: "length must be at least 1"},
+				{Field: "owner.name", Message: "length must be at least 2"},
+				{Field: "extra.name", Message: "length must be at least 2"},
+				{Field: "items[1].name", Message: "length must be at least 2"},
+				{Field: "refs[1].name", Message: "length must be at least 2"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateStruct(&test.req, "json")
+			if len(test.expect) == 0 {
+				assert.Nil(t, err)
+				return
+			}
+
+			assert.Equal(t, test.expect, err)
+		})
+	}
+}
+
+func TestValidateStructKeys(t *testing.T) {
+	type request struct {
+		Id   string `path:"id,minlen=2"`
+		Name string `form:"name,maxlen=1"`
+	}
+
+	err := ValidateStruct(&request{Id: "1", Name: "ab"}, "path", "form")
+	assert.Equal(t, ValidationErrors{
+		{Field: "id", Message: "length must be at least 2"},
+		{Field: "name", Message: "length must be at most 1"},
+	}, err)
+	assert.Equal(t, "id: length must be at least 2; name: length must be at most 1", err.Error())
+}
+
+func TestValidateStructWrongTags(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{
+			name: "bad minlen",
+			v: &struct {
+				Name string `json:"name,minlen=a"`
+			}{},
+		},
+		{
+			name: "bad pattern",
+			v: &struct {
+				Name string `json:"name,pattern=[a"`
+			}{},
+		},
+		{
+			name: "unknown format",
+			v: &struct {
+				Name string `json:"name,format=phone"`
+			}{},
+		},
+		{
+			name: "missing field",
+			v: &struct {
+				Name string `json:"name,eqfield=Other"`
+			}{},
+		},
+		{
+			name: "string options on int",
+			v: &struct {
+				Age int `json:"age,minlen=1"`
+			}{},
+		},
+		{
+			name: "items options on string",
+			v: &struct {
+				Name string `json:"name,minitems=1"`
+			}{},
+		},
+		{
+			name: "not comparable",
+			v: &struct {
+				Name string   `json:"name,eqfield=Tags"`
+				Tags []string `json:"tags"`
+			}{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateStruct(test.v, "json")
+			assert.NotNil(t, err)
+			_, ok := err.(ValidationErrors)
+			assert.False(t, ok)
+		})
+	}
+
+	assert.NotNil(t, ValidateStruct(struct{}{}, "json"))
+	var n int
+	assert.NotNil(t, ValidateStruct(&n, "json"))
+}
+
+func TestValidateTagOptions(t *testing.T) {
+	assert.Nil(t, ValidateTagOptions("name,optional,minlen=3,pattern=^[a-z]+$"))
+	assert.Nil(t, ValidateTagOptions("name,options=a|b,range=[1:2]"))
+	assert.NotNil(t, ValidateTagOptions("name,maxlen=-1"))
+	assert.NotNil(t, ValidateTagOptions("name,format=phone"))
+	assert.NotNil(t, ValidateTagOptions("name,gtfield="))
+}
@@ -0,0 +1,31 @@
+package proc
+
+import "time"
+
+const defaultDrainTimeout = 5 * time.Second
+
+var (
+	drainTimeout  = defaultDrainTimeout
+	shutdownDelay time.Duration
+)
+
+// DrainTimeout returns the max time that the servers drain the in-flight requests on shutdown.
+func DrainTimeout() time.Duration {
+	return drainTimeout
+}
+
+// SetDrainTimeout sets the max time that the servers drain the in-flight requests on shutdown.
+func SetDrainTimeout(timeout time.Duration) {
+	drainTimeout = timeout
+}
+
+// SetShutdownDelay sets the time to wait after marking the process unready and before draining,
+// lets the load balancers and the clients notice the process is going away.
+func SetShutdownDelay(delay time.Duration) {
+	shutdownDelay = delay
+}
+
+// ShutdownDelay returns the time to wait after marking the process unready and before draining.
+func ShutdownDelay() time.Duration {
+	return shutdownDelay
+}
@@ -9,6 +9,11 @@ func AddShutdownListener(fn func()) func() {
 	return fn
 }
 
+// AddUnreadyListener returns fn itself on windows, lets callers call fn on their own.
+func AddUnreadyListener(fn func()) func() {
+	return fn
+}
+
 // AddWrapUpListener returns fn itself on windows, lets callers call fn on their own.
 func AddWrapUpListener(fn func()) func() {
 	return fn
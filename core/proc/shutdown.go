@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/core/threading"
 )
 
 const (
@@ -19,6 +20,7 @@ const (
 )
 
 var (
+	unreadyListeners         = new(listenerManager)
 	wrapUpListeners          = new(listenerManager)
 	shutdownListeners        = new(listenerManager)
 	delayTimeBeforeForceQuit = waitTime
@@ -30,13 +32,21 @@ func AddShutdownListener(fn func()) (waitForCalled func()) {
 	return shutdownListeners.addListener(fn)
 }
 
+// AddUnreadyListener adds fn as an unready listener, which is called first on shutdown,
+// like failing the readiness checks and deregistering from the service discovery.
+// The returned func can be used to wait for fn getting called.
+func AddUnreadyListener(fn func()) (waitForCalled func()) {
+	return unreadyListeners.addListener(fn)
+}
+
 // AddWrapUpListener adds fn as a wrap up listener.
+// The wrap up listeners are called concurrently after the shutdown delay, like draining the servers.
 // The returned func can be used to wait for fn getting called.
 func AddWrapUpListener(fn func()) (waitForCalled func()) {
 	return wrapUpListeners.addListener(fn)
 }
 
-// SetTimeToForceQuit sets the waiting time before force quitting.
+// SetTimeToForceQuit sets the waiting time before force quitting, which starts after the shutdown delay.
 func SetTimeToForceQuit(duration time.Duration) {
 	delayTimeBeforeForceQuit = duration
 }
@@ -45,7 +55,14 @@ func gracefulStop(signals chan os.Signal) {
 	signal.Stop(signals)
 
 	logx.Info("Got signal SIGTERM, shutting down...")
-	wrapUpListeners.notifyListeners()
+	unreadyListeners.notifyListeners()
+	if shutdownDelay > 0 {
+		logx.Infof("Marked unready, waiting %v before draining...", shutdownDelay)
+		time.Sleep(shutdownDelay)
+	}
+
+	// the servers drain concurrently, otherwise the drain timeouts add up.
+	wrapUpListeners.notifyListenersConcurrently()
 
 	time.Sleep(wrapUpTime)
 	shutdownListeners.notifyListeners()
@@ -84,3 +101,14 @@ func (lm *listenerManager) notifyListeners() {
 		listener()
 	}
 }
+
+func (lm *listenerManager) notifyListenersConcurrently() {
+	lm.lock.Lock()
+	defer lm.lock.Unlock()
+
+	group := threading.NewRoutineGroup()
+	for _, listener := range lm.listeners {
+		group.RunSafe(listener)
+	}
+	group.Wait()
+}
@@ -0,0 +1,54 @@
+// +build linux darwin
+
+package proc
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotifyListenersConcurrently(t *testing.T) {
+	var lm listenerManager
+	var calls int32
+	for i := 0; i < 3; i++ {
+		lm.addListener(func() {
+			time.Sleep(time.Millisecond * 100)
+			atomic.AddInt32(&calls, 1)
+		})
+	}
+	waitForCalled := lm.addListener(func() {
+		panic("should be recovered")
+	})
+
+	start := time.Now()
+	lm.notifyListenersConcurrently()
+	waitForCalled()
+	if atomic.LoadInt32(&calls) != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Millisecond*300 {
+		t.Fatalf("expected concurrent calls, took %v", elapsed)
+	}
+}
+
+func TestShutdownPhases(t *testing.T) {
+	defer SetShutdownDelay(0)
+	defer SetDrainTimeout(defaultDrainTimeout)
+
+	SetShutdownDelay(time.Second)
+	SetDrainTimeout(time.Minute)
+	if ShutdownDelay() != time.Second || DrainTimeout() != time.Minute {
+		t.Fatalf("unexpected shutdown delay %v, drain timeout %v", ShutdownDelay(), DrainTimeout())
+	}
+
+	var called bool
+	waitForCalled := AddUnreadyListener(func() {
+		called = true
+	})
+	unreadyListeners.notifyListeners()
+	waitForCalled()
+	if !called {
+		t.Fatal("expected unready listener called")
+	}
+}
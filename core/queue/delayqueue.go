@@ -0,0 +1,452 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/core/proc"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+	"github.com/lukebull/go-zero-extern/core/stringx"
+	"github.com/lukebull/go-zero-extern/core/threading"
+)
+
+const (
+	// KEYS[1]: ready, KEYS[2]: jobs
+	// ARGV[1]: id, ARGV[2]: due time, ARGV[3]: payload
+	scheduleScript = `redis.call("HSET", KEYS[2], ARGV[1], ARGV[3])
+redis.call("ZADD", KEYS[1], ARGV[2], ARGV[1])
+return 1`
+	// KEYS[1]: ready, KEYS[2]: running, KEYS[3]: jobs, KEYS[4]: attempts
+	// ARGV[1]: id
+	cancelScript = `local removed = redis.call("ZREM", KEYS[1], ARGV[1]) + redis.call("ZREM", KEYS[2], ARGV[1])
+redis.call("HDEL", KEYS[3], ARGV[1])
+redis.call("HDEL", KEYS[4], ARGV[1])
+return removed`
+	// the jobs that exceed the visibility timeout are moved back to ready first,
+	// then the due jobs are moved to running with the new visibility deadline.
+	// KEYS[1]: ready, KEYS[2]: running, KEYS[3]: jobs, KEYS[4]: attempts
+	// ARGV[1]: now, ARGV[2]: visibility deadline, ARGV[3]: batch
+	claimScript = `local expired = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", ARGV[1], "LIMIT", 0, ARGV[3])
+for _, id in ipairs(expired) do
+    redis.call("ZREM", KEYS[2], id)
+    redis.call("ZADD", KEYS[1], ARGV[1], id)
+end
+local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[3])
+local result = {}
+for _, id in ipairs(ids) do
+    redis.call("ZREM", KEYS[1], id)
+    local payload = redis.call("HGET", KEYS[3], id)
+    if payload then
+        redis.call("ZADD", KEYS[2], ARGV[2], id)
+        local attempts = redis.call("HINCRBY", KEYS[4], id, 1)
+        table.insert(result, id)
+        table.insert(result, attempts)
+        table.insert(result, payload)
+    end
+end
+return result`
+	// KEYS[1]: running, KEYS[2]: jobs, KEYS[3]: attempts
+	// ARGV[1]: id
+	ackScript = `redis.call("ZREM", KEYS[1], ARGV[1])
+redis.call("HDEL", KEYS[2], ARGV[1])
+redis.call("HDEL", KEYS[3], ARGV[1])
+return 1`
+	// KEYS[1]: ready, KEYS[2]: running, KEYS[3]: jobs, KEYS[4]: attempts
+	// ARGV[1]: id, ARGV[2]: due time, ARGV[3]: attempts delta
+	retryScript = `redis.call("ZREM", KEYS[2], ARGV[1])
+if redis.call("HEXISTS", KEYS[3], ARGV[1]) == 0 then
+    return 0
+end
+redis.call("ZADD", KEYS[1], ARGV[2], ARGV[1])
+if tonumber(ARGV[3]) ~= 0 then
+    redis.call("HINCRBY", KEYS[4], ARGV[1], ARGV[3])
+end
+return 1`
+	// KEYS[1]: running, KEYS[2]: jobs, KEYS[3]: attempts, KEYS[4]: dead
+	// ARGV[1]: id, ARGV[2]: dead letter
+	buryScript = `redis.call("ZREM", KEYS[1], ARGV[1])
+redis.call("HDEL", KEYS[2], ARGV[1])
+redis.call("HDEL", KEYS[3], ARGV[1])
+redis.call("RPUSH", KEYS[4], ARGV[2])
+return 1`
+
+	defaultDelayWorkers           = 8
+	defaultDelayBatch             = 16
+	defaultDelayPollInterval      = time.Second
+	defaultDelayVisibilityTimeout = time.Second * 30
+	defaultDelayRetryBackoff      = time.Second
+	defaultDelayMaxBackoff        = time.Minute * 5
+	consumerRetryInterval         = time.Second
+)
+
+// ErrNoConsumerFactory is an error that indicates the DelayQueue started without a ConsumerFactory.
+var ErrNoConsumerFactory = errors.New("no consumer factory")
+
+type (
+	// A DelayQueueConf is the config of the DelayQueue.
+	DelayQueueConf struct {
+		redis.RedisConf
+		// Name is the prefix of the redis keys of the queue.
+		Name    string
+		Workers int `json:",default=8"`
+		// Batch is the max number of the jobs that claimed at once.
+		Batch        int           `json:",default=16"`
+		PollInterval time.Duration `json:",default=1s"`
+		// VisibilityTimeout is the time that a job is invisible to other workers after claimed,
+		// the job is redelivered if not acked in time, like the worker died.
+		VisibilityTimeout time.Duration `json:",default=30s"`
+		// MaxRetries is the max retries of the failed jobs before moving into the dead letters.
+		MaxRetries int `json:",default=3"`
+		// RetryBackoff is the backoff of the first retry, doubled on each retry, up to MaxBackoff.
+		RetryBackoff time.Duration `json:",default=1s"`
+		MaxBackoff   time.Duration `json:",default=5m"`
+	}
+
+	// A DeadLetter is a job that failed more than MaxRetries times.
+	DeadLetter struct {
+		Id       string `json:"id"`
+		Payload  string `json:"payload"`
+		Attempts int    `json:"attempts"`
+		Error    string `json:"error"`
+		Time     int64  `json:"time"`
+	}
+
+	// A DelayQueue is a distributed delay queue on redis sorted sets.
+	// The jobs are delivered at least once to the consumers at or after their due time.
+	DelayQueue struct {
+		c               DelayQueueConf
+		store           *redis.Redis
+		consumerFactory ConsumerFactory
+		keys            delayKeys
+		quit            chan struct{}
+		stopOnce        sync.Once
+	}
+
+	delayKeys struct {
+		ready    string
+		running  string
+		jobs     string
+		attempts string
+		dead     string
+	}
+
+	delayJob struct {
+		id       string
+		attempts int
+		payload  string
+	}
+)
+
+// NewDelayQueue returns a DelayQueue, consumerFactory can be nil if only used to schedule jobs.
+func NewDelayQueue(c DelayQueueConf, consumerFactory ConsumerFactory) *DelayQueue {
+	c = c.withDefaults()
+	// use hash tag to make the keys in the same slot in redis cluster.
+	prefix := fmt.Sprintf("{%s}:", c.Name)
+
+	return &DelayQueue{
+		c:               c,
+		store:           c.NewRedis(),
+		consumerFactory: consumerFactory,
+		keys: delayKeys{
+			ready:    prefix + "ready",
+			running:  prefix + "running",
+			jobs:     prefix + "jobs",
+			attempts: prefix + "attempts",
+			dead:     prefix + "dead",
+		},
+		quit: make(chan struct{}),
+	}
+}
+
+// Cancel cancels the job with given id, returns false if the job is not found,
+// like already done or moved into the dead letters.
+func (q *DelayQueue) Cancel(id string) (bool, error) {
+	val, err := q.store.Eval(cancelScript, []string{q.keys.ready, q.keys.running, q.keys.jobs,
+		q.keys.attempts}, id)
+	if err != nil {
+		return false, err
+	}
+
+	n, ok := val.(int64)
+	return ok && n > 0, nil
+}
+
+// DeadLetters returns at most count dead letters from the oldest.
+func (q *DelayQueue) DeadLetters(count int) ([]DeadLetter, error) {
+	vals, err := q.store.Lrange(q.keys.dead, 0, count-1)
+	if err != nil {
+		return nil, err
+	}
+
+	letters := make([]DeadLetter, 0, len(vals))
+	for _, val := range vals {
+		var letter DeadLetter
+		if err = json.Unmarshal([]byte(val), &letter); err != nil {
+			return nil, err
+		}
+
+		letters = append(letters, letter)
+	}
+
+	return letters, nil
+}
+
+// Delay schedules payload to be consumed after delay, returns the id of the job.
+func (q *DelayQueue) Delay(payload string, delay time.Duration) (string, error) {
+	return q.Schedule(payload, time.Now().Add(delay))
+}
+
+// Schedule schedules payload to be consumed at the given time, returns the id of the job.
+func (q *DelayQueue) Schedule(payload string, at time.Time) (string, error) {
+	id := stringx.RandId()
+	_, err := q.store.Eval(scheduleScript, []string{q.keys.ready, q.keys.jobs}, id,
+		toMillis(at), payload)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// Start starts the workers to consume the due jobs, blocks until stopped.
+// The DelayQueue is stopped on process shutdown.
+func (q *DelayQueue) Start() {
+	if q.consumerFactory == nil {
+		logx.Error(ErrNoConsumerFactory)
+		return
+	}
+
+	proc.AddShutdownListener(q.Stop)
+
+	jobs := make(chan delayJob)
+	group := threading.NewRoutineGroup()
+	for i := 0; i < q.c.Workers; i++ {
+		group.RunSafe(func() {
+			q.work(jobs)
+		})
+	}
+
+	q.poll(jobs)
+	close(jobs)
+	group.Wait()
+}
+
+// Stop stops the DelayQueue, the jobs that claimed but not consumed are released.
+func (q *DelayQueue) Stop() {
+	q.stopOnce.Do(func() {
+		close(q.quit)
+	})
+}
+
+func (q *DelayQueue) ack(job delayJob) {
+	if _, err := q.store.Eval(ackScript, []string{q.keys.running, q.keys.jobs, q.keys.attempts},
+		job.id); err != nil {
+		logx.Errorf("Error on acking delay job %s: %v", job.id, err)
+	}
+}
+
+func (q *DelayQueue) backoff(attempts int) time.Duration {
+	backoff := q.c.RetryBackoff
+	for i := 1; i < attempts && backoff < q.c.MaxBackoff; i++ {
+		backoff <<= 1
+	}
+	if backoff > q.c.MaxBackoff {
+		backoff = q.c.MaxBackoff
+	}
+
+	return backoff
+}
+
+func (q *DelayQueue) bury(job delayJob, cause error) {
+	letter, err := json.Marshal(DeadLetter{
+		Id:       job.id,
+		Payload:  job.payload,
+		Attempts: job.attempts,
+		Error:    cause.Error(),
+		Time:     time.Now().Unix(),
+	})
+	if err != nil {
+		logx.Error(err)
+		return
+	}
+
+	if _, err = q.store.Eval(buryScript, []string{q.keys.running, q.keys.jobs, q.keys.attempts,
+		q.keys.dead}, job.id, string(letter)); err != nil {
+		logx.Errorf("Error on moving delay job %s into dead letters: %v", job.id, err)
+	}
+}
+
+func (q *DelayQueue) claim() ([]delayJob, error) {
+	now := time.Now()
+	val, err := q.store.Eval(claimScript, []string{q.keys.ready, q.keys.running, q.keys.jobs,
+		q.keys.attempts}, toMillis(now), toMillis(now.Add(q.c.VisibilityTimeout)), q.c.Batch)
+	if err != nil {
+		return nil, err
+	}
+
+	vals, ok := val.([]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	jobs := make([]delayJob, 0, len(vals)/3)
+	for i := 0; i+2 < len(vals); i += 3 {
+		id, _ := vals[i].(string)
+		attempts, _ := vals[i+1].(int64)
+		payload, _ := vals[i+2].(string)
+		jobs = append(jobs, delayJob{
+			id:       id,
+			attempts: int(attempts),
+			payload:  payload,
+		})
+	}
+
+	return jobs, nil
+}
+
+func (q *DelayQueue) consume(consumer Consumer, job delayJob) {
+	if job.attempts > q.c.MaxRetries+1 {
+		// redelivered by visibility timeout, like the workers died on it.
+		q.bury(job, errors.New("visibility timeout exceeded"))
+		return
+	}
+
+	err := consumeSafely(consumer, job.payload)
+	if err == nil {
+		q.ack(job)
+		return
+	}
+
+	logx.Errorf("Error on consuming delay job %s, attempts %d: %v", job.id, job.attempts, err)
+	if job.attempts > q.c.MaxRetries {
+		q.bury(job, err)
+		return
+	}
+
+	q.retry(job, time.Now().Add(q.backoff(job.attempts)), 0)
+}
+
+func (q *DelayQueue) newConsumer() (Consumer, bool) {
+	for {
+		consumer, err := q.consumerFactory()
+		if err == nil {
+			return consumer, true
+		}
+
+		logx.Errorf("Error on creating consumer: %v", err)
+		select {
+		case <-q.quit:
+			return nil, false
+		case <-time.After(consumerRetryInterval):
+		}
+	}
+}
+
+func (q *DelayQueue) poll(jobs chan<- delayJob) {
+	ticker := time.NewTicker(q.c.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.quit:
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			claimed, err := q.claim()
+			if err != nil {
+				logx.Errorf("Error on claiming delay jobs: %v", err)
+				break
+			}
+
+			for i, job := range claimed {
+				select {
+				case jobs <- job:
+				case <-q.quit:
+					q.release(claimed[i:])
+					return
+				}
+			}
+
+			// drain the due jobs without waiting for the next tick.
+			if len(claimed) < q.c.Batch {
+				break
+			}
+		}
+	}
+}
+
+// release puts the claimed jobs back to ready without counting the attempts.
+func (q *DelayQueue) release(jobs []delayJob) {
+	now := time.Now()
+	for _, job := range jobs {
+		q.retry(job, now, -1)
+	}
+}
+
+func (q *DelayQueue) retry(job delayJob, at time.Time, attemptsDelta int) {
+	if _, err := q.store.Eval(retryScript, []string{q.keys.ready, q.keys.running, q.keys.jobs,
+		q.keys.attempts}, job.id, toMillis(at), attemptsDelta); err != nil {
+		logx.Errorf("Error on retrying delay job %s: %v", job.id, err)
+	}
+}
+
+func (q *DelayQueue) work(jobs <-chan delayJob) {
+	consumer, ok := q.newConsumer()
+	if !ok {
+		// drain the jobs, otherwise the poller blocks.
+		var rest []delayJob
+		for job := range jobs {
+			rest = append(rest, job)
+		}
+		q.release(rest)
+		return
+	}
+
+	for job := range jobs {
+		q.consume(consumer, job)
+	}
+}
+
+func (c DelayQueueConf) withDefaults() DelayQueueConf {
+	if c.Workers <= 0 {
+		c.Workers = defaultDelayWorkers
+	}
+	if c.Batch <= 0 {
+		c.Batch = defaultDelayBatch
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = defaultDelayPollInterval
+	}
+	if c.VisibilityTimeout <= 0 {
+		c.VisibilityTimeout = defaultDelayVisibilityTimeout
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = defaultDelayRetryBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = defaultDelayMaxBackoff
+	}
+
+	return c
+}
+
+func consumeSafely(consumer Consumer, payload string) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return consumer.Consume(payload)
+}
+
+func toMillis(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
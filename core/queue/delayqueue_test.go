@@ -0,0 +1,263 @@
+package queue
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+)
+
+type funcConsumer func(message string) error
+
+func (f funcConsumer) Consume(message string) error {
+	return f(message)
+}
+
+func (f funcConsumer) OnEvent(_ interface{}) {
+}
+
+func newDelayQueueConf(t *testing.T) (DelayQueueConf, *miniredis.Miniredis) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return DelayQueueConf{
+		RedisConf: redis.RedisConf{
+			Host: mr.Addr(),
+			Type: redis.NodeType,
+		},
+		Name:              "delay",
+		Workers:           2,
+		Batch:             4,
+		PollInterval:      time.Millisecond * 10,
+		VisibilityTimeout: time.Second,
+		MaxRetries:        2,
+		RetryBackoff:      time.Millisecond * 10,
+		MaxBackoff:        time.Millisecond * 20,
+	}, mr
+}
+
+func runDelayQueue(q *DelayQueue) func() {
+	done := make(chan struct{})
+	go func() {
+		q.Start()
+		close(done)
+	}()
+
+	return func() {
+		q.Stop()
+		<-done
+	}
+}
+
+func newRecordingFactory(recorder *messageRecorder, err error) ConsumerFactory {
+	return func() (Consumer, error) {
+		return funcConsumer(func(message string) error {
+			recorder.record(message)
+			return err
+		}), nil
+	}
+}
+
+func TestDelayQueueSchedule(t *testing.T) {
+	c, mr := newDelayQueueConf(t)
+	defer mr.Close()
+
+	recorder := newMessageRecorder()
+	q := NewDelayQueue(c, newRecordingFactory(recorder, nil))
+	stop := runDelayQueue(q)
+	defer stop()
+
+	start := time.Now()
+	if _, err := q.Delay("later", time.Millisecond*200); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Schedule("now", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder.waitFor(t, "now")
+	if recorder.count("later") != 0 {
+		t.Fatal("job consumed before due")
+	}
+	recorder.waitFor(t, "later")
+	if time.Since(start) < time.Millisecond*200 {
+		t.Fatal("job consumed before due")
+	}
+
+	// acked jobs are removed.
+	store := c.NewRedis()
+	if n, err := store.Hlen(q.keys.jobs); err != nil || n != 0 {
+		t.Fatalf("expected no jobs left, got %d, %v", n, err)
+	}
+}
+
+func TestDelayQueueCancel(t *testing.T) {
+	c, mr := newDelayQueueConf(t)
+	defer mr.Close()
+
+	recorder := newMessageRecorder()
+	q := NewDelayQueue(c, newRecordingFactory(recorder, nil))
+	id, err := q.Delay("canceled", time.Millisecond*100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := q.Cancel(id)
+	if err != nil || !ok {
+		t.Fatalf("expected canceled, got %t, %v", ok, err)
+	}
+	ok, err = q.Cancel(id)
+	if err != nil || ok {
+		t.Fatalf("expected not found, got %t, %v", ok, err)
+	}
+
+	stop := runDelayQueue(q)
+	if _, err = q.Delay("kept", time.Millisecond*150); err != nil {
+		t.Fatal(err)
+	}
+	recorder.waitFor(t, "kept")
+	stop()
+
+	if recorder.count("canceled") != 0 {
+		t.Fatal("canceled job consumed")
+	}
+}
+
+func TestDelayQueueRetryAndDeadLetter(t *testing.T) {
+	c, mr := newDelayQueueConf(t)
+	defer mr.Close()
+
+	recorder := newMessageRecorder()
+	q := NewDelayQueue(c, newRecordingFactory(recorder, errors.New("boom")))
+	stop := runDelayQueue(q)
+	defer stop()
+
+	id, err := q.Delay("failing", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		letters, err := q.DeadLetters(10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(letters) == 1 {
+			letter := letters[0]
+			if letter.Id != id || letter.Payload != "failing" || letter.Error != "boom" ||
+				letter.Attempts != c.MaxRetries+1 {
+				t.Fatalf("unexpected dead letter %+v", letter)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("job not moved into dead letters")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if recorder.count("failing") != c.MaxRetries+1 {
+		t.Fatalf("expected %d attempts, got %d", c.MaxRetries+1, recorder.count("failing"))
+	}
+}
+
+func TestDelayQueuePanicRetried(t *testing.T) {
+	c, mr := newDelayQueueConf(t)
+	defer mr.Close()
+
+	recorder := newMessageRecorder()
+	q := NewDelayQueue(c, func() (Consumer, error) {
+		return funcConsumer(func(message string) error {
+			recorder.record(message)
+			if recorder.count(message) == 1 {
+				panic("panic once")
+			}
+			return nil
+		}), nil
+	})
+	stop := runDelayQueue(q)
+	defer stop()
+
+	if _, err := q.Delay("panic", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second * 5)
+	for recorder.count("panic") < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("panicked job not retried")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestDelayQueueVisibilityTimeout(t *testing.T) {
+	c, mr := newDelayQueueConf(t)
+	defer mr.Close()
+
+	c.VisibilityTimeout = time.Millisecond * 100
+	recorder := newMessageRecorder()
+	q := NewDelayQueue(c, newRecordingFactory(recorder, nil))
+	if _, err := q.Delay("orphan", 0); err != nil {
+		t.Fatal(err)
+	}
+	// a worker claims the job and dies without acking.
+	jobs, err := q.claim()
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("expected 1 job, got %+v, %v", jobs, err)
+	}
+
+	stop := runDelayQueue(q)
+	defer stop()
+	recorder.waitFor(t, "orphan")
+}
+
+func TestDelayQueueReleaseOnStop(t *testing.T) {
+	c, mr := newDelayQueueConf(t)
+	defer mr.Close()
+
+	q := NewDelayQueue(c, nil)
+	if _, err := q.Delay("released", 0); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := q.claim()
+	if err != nil || len(jobs) != 1 || jobs[0].attempts != 1 {
+		t.Fatalf("expected 1 job, got %+v, %v", jobs, err)
+	}
+
+	q.release(jobs)
+	jobs, err = q.claim()
+	if err != nil || len(jobs) != 1 || jobs[0].attempts != 1 {
+		t.Fatalf("expected released job without counting attempts, got %+v, %v", jobs, err)
+	}
+}
+
+func TestDelayQueueBackoff(t *testing.T) {
+	q := NewDelayQueue(DelayQueueConf{
+		RedisConf: redis.RedisConf{
+			Host: "localhost:6379",
+			Type: redis.NodeType,
+		},
+		RetryBackoff: time.Second,
+		MaxBackoff:   time.Second * 5,
+	}, nil)
+
+	expects := []time.Duration{time.Second, time.Second * 2, time.Second * 4, time.Second * 5, time.Second * 5}
+	for i, expect := range expects {
+		if backoff := q.backoff(i + 1); backoff != expect {
+			t.Fatalf("attempts %d: expected %v, got %v", i+1, expect, backoff)
+		}
+	}
+}
+
+func TestDelayQueueStartWithoutFactory(t *testing.T) {
+	c, mr := newDelayQueueConf(t)
+	defer mr.Close()
+
+	// returns immediately without consumer factory.
+	NewDelayQueue(c, nil).Start()
+}
@@ -0,0 +1,241 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+	"github.com/lukebull/go-zero-extern/core/sysx"
+	"github.com/lukebull/go-zero-extern/core/timex"
+)
+
+const (
+	streamValueKey         = "data"
+	streamMessageSeparator = " "
+	streamGroupStart       = "0"
+	defaultStreamBatch     = 16
+	defaultStreamBlock     = time.Second * 5
+	defaultStreamClaimIdle = time.Minute
+	streamRetryInterval    = time.Second
+)
+
+var errBadStreamMessage = errors.New("bad stream message")
+
+type (
+	// A StreamConf is the config of the queues on redis streams.
+	StreamConf struct {
+		redis.RedisConf
+		Stream string
+		Group  string
+		// Consumer is the consumer name in group, defaults to hostname-pid.
+		Consumer string `json:",optional"`
+		// MaxLen trims the stream approximately to MaxLen on pushing, no trimming if 0.
+		MaxLen int64 `json:",optional"`
+		Batch  int64 `json:",default=16"`
+		// Block is the max duration to wait for new messages on each read.
+		Block time.Duration `json:",default=5s"`
+		// ClaimIdle is the idle duration that the pending messages are considered failed
+		// or their consumers dead, and reclaimed to redeliver.
+		ClaimIdle time.Duration `json:",default=1m"`
+	}
+
+	// StreamHandler handles the message of streams, the message is acked only if nil returned.
+	StreamHandler func(message string) error
+
+	// A StreamPusher is a Pusher that pushes messages into redis stream.
+	StreamPusher struct {
+		store  *redis.Redis
+		stream string
+		maxLen int64
+	}
+
+	streamProducer struct {
+		store     *redis.Redis
+		conf      StreamConf
+		buffer    []redis.XMessage
+		lastClaim time.Duration
+	}
+
+	streamConsumer struct {
+		store  *redis.Redis
+		conf   StreamConf
+		handle StreamHandler
+	}
+)
+
+// NewStreamPusher returns a StreamPusher.
+func NewStreamPusher(c StreamConf) *StreamPusher {
+	return &StreamPusher{
+		store:  c.NewRedis(),
+		stream: c.Stream,
+		maxLen: c.MaxLen,
+	}
+}
+
+// Name returns the name of pusher.
+func (p *StreamPusher) Name() string {
+	return p.stream
+}
+
+// Push pushes message into the stream.
+func (p *StreamPusher) Push(message string) error {
+	_, err := p.store.XaddWithMaxLen(p.stream, p.maxLen, map[string]interface{}{
+		streamValueKey: message,
+	})
+	return err
+}
+
+// NewStreamProducerFactory returns a ProducerFactory that reads messages from the stream
+// in the consumer group, the group is created if not exists. The messages pending longer
+// than ClaimIdle are reclaimed, so the messages of the dead consumers are redelivered.
+func NewStreamProducerFactory(c StreamConf) ProducerFactory {
+	c = c.withDefaults()
+	store := c.NewRedis()
+
+	return func() (Producer, error) {
+		if err := store.XgroupCreate(c.Stream, c.Group, streamGroupStart); err != nil {
+			return nil, err
+		}
+
+		return &streamProducer{
+			store:     store,
+			conf:      c,
+			lastClaim: timex.Now(),
+		}, nil
+	}
+}
+
+// NewStreamConsumerFactory returns a ConsumerFactory that handles the messages read by
+// the producers from NewStreamProducerFactory, acks the messages that handled successfully.
+// The messages are delivered at least once.
+func NewStreamConsumerFactory(c StreamConf, handle StreamHandler) ConsumerFactory {
+	c = c.withDefaults()
+	store := c.NewRedis()
+
+	return func() (Consumer, error) {
+		return &streamConsumer{
+			store:  store,
+			conf:   c,
+			handle: handle,
+		}, nil
+	}
+}
+
+func (p *streamProducer) AddListener(_ ProduceListener) {
+}
+
+func (p *streamProducer) Produce() (string, bool) {
+	if len(p.buffer) == 0 {
+		p.fill()
+	}
+	if len(p.buffer) == 0 {
+		return "", false
+	}
+
+	msg := p.buffer[0]
+	p.buffer = p.buffer[1:]
+	val, ok := msg.Values[streamValueKey].(string)
+	if !ok {
+		logx.Errorf("bad message %s in stream %s, acked", msg.ID, p.conf.Stream)
+		if _, err := p.store.Xack(p.conf.Stream, p.conf.Group, msg.ID); err != nil {
+			logx.Error(err)
+		}
+		return "", false
+	}
+
+	return msg.ID + streamMessageSeparator + val, true
+}
+
+func (p *streamProducer) fill() {
+	if timex.Since(p.lastClaim) >= p.conf.ClaimIdle/2 {
+		p.lastClaim = timex.Now()
+		msgs, err := p.claim()
+		if err != nil {
+			logx.Errorf("Error on reclaiming pending messages of stream %s: %v", p.conf.Stream, err)
+		} else if len(msgs) > 0 {
+			p.buffer = msgs
+			return
+		}
+	}
+
+	msgs, err := p.store.Xreadgroup(p.conf.Stream, p.conf.Group, p.conf.Consumer, ">",
+		p.conf.Batch, p.conf.Block)
+	if err != nil {
+		logx.Errorf("Error on reading stream %s: %v", p.conf.Stream, err)
+		time.Sleep(streamRetryInterval)
+		return
+	}
+
+	p.buffer = msgs
+}
+
+func (p *streamProducer) claim() ([]redis.XMessage, error) {
+	pendings, err := p.store.XpendingExt(p.conf.Stream, p.conf.Group, "-", "+", p.conf.Batch)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, pending := range pendings {
+		if pending.Idle >= p.conf.ClaimIdle {
+			ids = append(ids, pending.Id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// xclaim checks the idle time again, so only one consumer claims the message.
+	msgs, err := p.store.Xclaim(p.conf.Stream, p.conf.Group, p.conf.Consumer, p.conf.ClaimIdle, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := msgs[:0]
+	for _, msg := range msgs {
+		// the deleted messages are returned with empty ids.
+		if len(msg.ID) > 0 {
+			claimed = append(claimed, msg)
+		}
+	}
+
+	return claimed, nil
+}
+
+func (c *streamConsumer) Consume(message string) error {
+	fields := strings.SplitN(message, streamMessageSeparator, 2)
+	if len(fields) != 2 {
+		return errBadStreamMessage
+	}
+
+	if err := c.handle(fields[1]); err != nil {
+		return err
+	}
+
+	_, err := c.store.Xack(c.conf.Stream, c.conf.Group, fields[0])
+	return err
+}
+
+func (c *streamConsumer) OnEvent(_ interface{}) {
+}
+
+func (c StreamConf) withDefaults() StreamConf {
+	if len(c.Consumer) == 0 {
+		c.Consumer = fmt.Sprintf("%s-%d", sysx.Hostname(), os.Getpid())
+	}
+	if c.Batch <= 0 {
+		c.Batch = defaultStreamBatch
+	}
+	if c.Block <= 0 {
+		c.Block = defaultStreamBlock
+	}
+	if c.ClaimIdle <= 0 {
+		c.ClaimIdle = defaultStreamClaimIdle
+	}
+
+	return c
+}
@@ -0,0 +1,235 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+)
+
+type messageRecorder struct {
+	lock     sync.Mutex
+	messages map[string]int
+}
+
+func newMessageRecorder() *messageRecorder {
+	return &messageRecorder{
+		messages: make(map[string]int),
+	}
+}
+
+func (r *messageRecorder) record(message string) {
+	r.lock.Lock()
+	r.messages[message]++
+	r.lock.Unlock()
+}
+
+func (r *messageRecorder) count(message string) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.messages[message]
+}
+
+func (r *messageRecorder) waitFor(t *testing.T, messages ...string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		r.lock.Lock()
+		var missing []string
+		for _, message := range messages {
+			if r.messages[message] == 0 {
+				missing = append(missing, message)
+			}
+		}
+		r.lock.Unlock()
+		if len(missing) == 0 {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			sort.Strings(missing)
+			t.Fatalf("messages not consumed: %s", strings.Join(missing, ","))
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func newStreamConf(t *testing.T) (StreamConf, *miniredis.Miniredis) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return StreamConf{
+		RedisConf: redis.RedisConf{
+			Host: mr.Addr(),
+			Type: redis.NodeType,
+		},
+		Stream:    "stream",
+		Group:     "group",
+		Consumer:  "consumer",
+		Batch:     4,
+		Block:     time.Millisecond * 50,
+		ClaimIdle: time.Millisecond * 200,
+	}, mr
+}
+
+func runQueue(q *Queue) func() {
+	done := make(chan struct{})
+	go func() {
+		q.Start()
+		close(done)
+	}()
+
+	return func() {
+		q.Stop()
+		<-done
+	}
+}
+
+func TestStreamQueue(t *testing.T) {
+	c, mr := newStreamConf(t)
+	defer mr.Close()
+
+	pusher := NewStreamPusher(c)
+	if pusher.Name() != c.Stream {
+		t.Fatalf("unexpected name %s", pusher.Name())
+	}
+
+	recorder := newMessageRecorder()
+	q := NewQueue(NewStreamProducerFactory(c), NewStreamConsumerFactory(c, func(message string) error {
+		recorder.record(message)
+		return nil
+	}))
+	q.SetNumProducer(2)
+	q.SetNumConsumer(4)
+	stop := runQueue(q)
+
+	var messages []string
+	for i := 0; i < 20; i++ {
+		message := fmt.Sprintf("message %d", i)
+		messages = append(messages, message)
+		if err := pusher.Push(message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recorder.waitFor(t, messages...)
+	stop()
+
+	pending, err := c.NewRedis().Xpending(c.Stream, c.Group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending.Count != 0 {
+		t.Fatalf("expected all messages acked, got %d pending", pending.Count)
+	}
+	for _, message := range messages {
+		if recorder.count(message) != 1 {
+			t.Fatalf("expected %q consumed once, got %d", message, recorder.count(message))
+		}
+	}
+}
+
+func TestStreamQueueRedeliverFailed(t *testing.T) {
+	c, mr := newStreamConf(t)
+	defer mr.Close()
+
+	recorder := newMessageRecorder()
+	var lock sync.Mutex
+	failed := make(map[string]bool)
+	q := NewQueue(NewStreamProducerFactory(c), NewStreamConsumerFactory(c, func(message string) error {
+		recorder.record(message)
+		lock.Lock()
+		defer lock.Unlock()
+		if !failed[message] {
+			failed[message] = true
+			return errors.New("fail once")
+		}
+		return nil
+	}))
+	q.SetNumProducer(1)
+	q.SetNumConsumer(1)
+	stop := runQueue(q)
+	defer stop()
+
+	if err := NewStreamPusher(c).Push("flaky"); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second * 5)
+	for recorder.count("flaky") < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("failed message not redelivered")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestStreamQueueReclaimDeadConsumer(t *testing.T) {
+	c, mr := newStreamConf(t)
+	defer mr.Close()
+
+	store := c.NewRedis()
+	if err := store.XgroupCreate(c.Stream, c.Group, "0"); err != nil {
+		t.Fatal(err)
+	}
+	pusher := NewStreamPusher(c)
+	for _, message := range []string{"a", "b"} {
+		if err := pusher.Push(message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a consumer reads the messages and dies without acking.
+	msgs, err := store.Xreadgroup(c.Stream, c.Group, "dead", ">", 10, 0)
+	if err != nil || len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %+v, %v", msgs, err)
+	}
+
+	recorder := newMessageRecorder()
+	q := NewQueue(NewStreamProducerFactory(c), NewStreamConsumerFactory(c, func(message string) error {
+		recorder.record(message)
+		return nil
+	}))
+	q.SetNumProducer(1)
+	q.SetNumConsumer(1)
+	stop := runQueue(q)
+	recorder.waitFor(t, "a", "b")
+	stop()
+
+	pending, err := store.Xpending(c.Stream, c.Group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending.Count != 0 {
+		t.Fatalf("expected reclaimed messages acked, got %d pending", pending.Count)
+	}
+}
+
+func TestStreamConsumerBadMessage(t *testing.T) {
+	c, mr := newStreamConf(t)
+	defer mr.Close()
+
+	consumer, err := NewStreamConsumerFactory(c, func(string) error {
+		return nil
+	})()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = consumer.Consume("no-separator"); err != errBadStreamMessage {
+		t.Fatalf("expected errBadStreamMessage, got %v", err)
+	}
+}
+
+func TestStreamConfDefaults(t *testing.T) {
+	c := StreamConf{}.withDefaults()
+	if len(c.Consumer) == 0 || c.Batch != defaultStreamBatch || c.Block != defaultStreamBlock ||
+		c.ClaimIdle != defaultStreamClaimIdle {
+		t.Fatalf("unexpected defaults %+v", c)
+	}
+}
@@ -2,11 +2,15 @@ package service
 
 import (
 	"log"
+	"time"
 
+	"github.com/lukebull/go-zero-extern/core/health"
 	"github.com/lukebull/go-zero-extern/core/load"
 	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/core/proc"
 	"github.com/lukebull/go-zero-extern/core/prometheus"
 	"github.com/lukebull/go-zero-extern/core/stat"
+	"github.com/lukebull/go-zero-extern/core/trace"
 )
 
 const (
@@ -22,14 +26,29 @@ const (
 	ProMode = "pro"
 )
 
-// A ServiceConf is a service config.
-type ServiceConf struct {
-	Name       string
-	Log        logx.LogConf
-	Mode       string            `json:",default=pro,options=dev|test|rt|pre|pro"`
-	MetricsUrl string            `json:",optional"`
-	Prometheus prometheus.Config `json:",optional"`
-}
+type (
+	// A ServiceConf is a service config.
+	ServiceConf struct {
+		Name       string
+		Log        logx.LogConf
+		Mode       string            `json:",default=pro,options=dev|test|rt|pre|pro"`
+		MetricsUrl string            `json:",optional"`
+		Prometheus prometheus.Config `json:",optional"`
+		Telemetry  trace.Config      `json:",optional"`
+		Shutdown   ShutdownConf      `json:",optional"`
+		Health     health.Conf       `json:",optional"`
+	}
+
+	// A ShutdownConf is the graceful shutdown config.
+	// On shutdown, the process is marked unready, then waits for Delay, then drains
+	// the in-flight requests within DrainTimeout, then stops.
+	ShutdownConf struct {
+		// Delay lets the load balancers notice the process is unready, should be longer than
+		// the period of the readiness checks.
+		Delay        time.Duration `json:",optional"`
+		DrainTimeout time.Duration `json:",default=5s"`
+	}
+)
 
 // MustSetUp sets up the service, exits on error.
 func (sc ServiceConf) MustSetUp() {
@@ -48,7 +67,16 @@ func (sc ServiceConf) SetUp() error {
 	}
 
 	sc.initMode()
+	proc.SetShutdownDelay(sc.Shutdown.Delay)
+	if sc.Shutdown.DrainTimeout > 0 {
+		proc.SetDrainTimeout(sc.Shutdown.DrainTimeout)
+	}
 	prometheus.StartAgent(sc.Prometheus)
+	health.StartServer(sc.Health)
+	if len(sc.Telemetry.Name) == 0 {
+		sc.Telemetry.Name = sc.Name
+	}
+	trace.StartAgent(sc.Telemetry)
 	if len(sc.MetricsUrl) > 0 {
 		stat.SetReportWriter(stat.NewRemoteWriter(sc.MetricsUrl))
 	}
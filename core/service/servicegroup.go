@@ -1,10 +1,14 @@
 package service
 
 import (
+	"context"
 	"log"
+	"sync"
+	"time"
 
+	"github.com/lukebull/go-zero-extern/core/health"
+	"github.com/lukebull/go-zero-extern/core/logx"
 	"github.com/lukebull/go-zero-extern/core/proc"
-	"github.com/lukebull/go-zero-extern/core/syncx"
 	"github.com/lukebull/go-zero-extern/core/threading"
 )
 
@@ -19,6 +23,12 @@ type (
 		Stop()
 	}
 
+	// Drainer is the interface wraps the Drain method, which stops accepting new requests,
+	// and waits for the in-flight requests to finish until ctx is done.
+	Drainer interface {
+		Drain(ctx context.Context) error
+	}
+
 	// Service is the interface that groups Start and Stop methods.
 	Service interface {
 		Starter
@@ -28,15 +38,13 @@ type (
 	// A ServiceGroup is a group of services.
 	ServiceGroup struct {
 		services []Service
-		stopOnce func()
+		stopOnce sync.Once
 	}
 )
 
 // NewServiceGroup returns a ServiceGroup.
 func NewServiceGroup() *ServiceGroup {
-	sg := new(ServiceGroup)
-	sg.stopOnce = syncx.Once(sg.doStop)
-	return sg
+	return new(ServiceGroup)
 }
 
 // Add adds service into sg.
@@ -48,17 +56,48 @@ func (sg *ServiceGroup) Add(service Service) {
 // There should not be any logic code after calling this method, because this method is a blocking one.
 // Also, quitting this method will close the logx output.
 func (sg *ServiceGroup) Start() {
+	// on signals, proc marks the process unready and waits for the shutdown delay,
+	// then drains the services in the wrap up phase, and stops them in the shutdown phase.
+	proc.AddWrapUpListener(sg.drain)
 	proc.AddShutdownListener(func() {
 		log.Println("Shutting down...")
-		sg.stopOnce()
+		sg.stopOnce.Do(sg.doStop)
 	})
 
 	sg.doStart()
 }
 
-// Stop stops the ServiceGroup.
+// Stop stops the ServiceGroup in the same phases as the graceful shutdown on signals,
+// marks the process unready, waits for proc.ShutdownDelay, drains the Drainer services
+// within proc.DrainTimeout, then stops all the services.
 func (sg *ServiceGroup) Stop() {
-	sg.stopOnce()
+	sg.stopOnce.Do(func() {
+		health.MarkUnready()
+		time.Sleep(proc.ShutdownDelay())
+		sg.drain()
+		sg.doStop()
+	})
+}
+
+func (sg *ServiceGroup) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), proc.DrainTimeout())
+	defer cancel()
+
+	routineGroup := threading.NewRoutineGroup()
+	for i := range sg.services {
+		drainer, ok := sg.services[i].(Drainer)
+		if !ok {
+			continue
+		}
+
+		routineGroup.RunSafe(func() {
+			if err := drainer.Drain(ctx); err != nil {
+				logx.Errorf("Error on draining service: %v", err)
+			}
+		})
+	}
+
+	routineGroup.Wait()
 }
 
 func (sg *ServiceGroup) doStart() {
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/health"
+	"github.com/lukebull/go-zero-extern/core/proc"
+)
+
+type mockService struct {
+	lock    sync.Mutex
+	events  []string
+	drain   time.Duration
+	stopped chan struct{}
+}
+
+func newMockService(drain time.Duration) *mockService {
+	return &mockService{
+		drain:   drain,
+		stopped: make(chan struct{}),
+	}
+}
+
+func (s *mockService) Start() {
+	s.record("start")
+	<-s.stopped
+}
+
+func (s *mockService) Stop() {
+	s.record("stop")
+	close(s.stopped)
+}
+
+func (s *mockService) Drain(ctx context.Context) error {
+	select {
+	case <-time.After(s.drain):
+		s.record("drained")
+		return nil
+	case <-ctx.Done():
+		s.record("timeout")
+		return ctx.Err()
+	}
+}
+
+func (s *mockService) record(event string) {
+	s.lock.Lock()
+	s.events = append(s.events, event)
+	s.lock.Unlock()
+}
+
+func (s *mockService) Events() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string(nil), s.events...)
+}
+
+func TestServiceGroupStop(t *testing.T) {
+	defer health.MarkReady()
+	defer proc.SetShutdownDelay(0)
+	defer proc.SetDrainTimeout(proc.DrainTimeout())
+	proc.SetShutdownDelay(time.Millisecond * 50)
+	proc.SetDrainTimeout(time.Millisecond * 200)
+
+	fast := newMockService(time.Millisecond * 10)
+	slow := newMockService(time.Minute)
+	var started sync.WaitGroup
+	started.Add(1)
+	group := NewServiceGroup()
+	group.Add(fast)
+	group.Add(slow)
+	group.Add(WithStart(func() {
+		started.Done()
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		group.Start()
+		close(done)
+	}()
+	started.Wait()
+
+	start := time.Now()
+	group.Stop()
+	group.Stop()
+	if health.IsReady() {
+		t.Fatal("expected unready after stop")
+	}
+	if elapsed := time.Since(start); elapsed < time.Millisecond*50 || elapsed > time.Second {
+		t.Fatalf("expected the shutdown delay and the drain timeout, took %v", elapsed)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected Start returned after Stop")
+	}
+
+	assertEvents(t, fast, "drained", "stop")
+	assertEvents(t, slow, "timeout", "stop")
+}
+
+func assertEvents(t *testing.T, s *mockService, expects ...string) {
+	t.Helper()
+
+	// start might be recorded in any order, because Start runs concurrently.
+	var events []string
+	for _, event := range s.Events() {
+		if event != "start" {
+			events = append(events, event)
+		}
+	}
+	if len(events) != len(expects) {
+		t.Fatalf("expected %v, got %v", expects, events)
+	}
+	for i := range expects {
+		if events[i] != expects[i] {
+			t.Fatalf("expected %v, got %v", expects, events)
+		}
+	}
+}
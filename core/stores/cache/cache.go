@@ -13,6 +13,8 @@ import (
 type (
 	// Cache interface is used to define the cache implementation.
 	Cache interface {
+		// Close releases the resources of the cache, like the subscription of the local cache invalidations.
+		Close() error
 		Del(keys ...string) error
 		Get(key string, v interface{}) error
 		IsNotFound(err error) bool
@@ -24,6 +26,7 @@ type (
 
 	cacheCluster struct {
 		dispatcher  *hash.ConsistentHash
+		nodes       []Cache
 		errNotFound error
 	}
 )
@@ -36,21 +39,55 @@ func New(c ClusterConf, barrier syncx.SharedCalls, st *Stat, errNotFound error,
 	}
 
 	if len(c) == 1 {
-		return NewNode(c[0].NewRedis(), barrier, st, errNotFound, opts...)
+		return NewNode(c[0].NewRedis(), barrier, st, errNotFound, nodeOptions(c[0], opts)...)
 	}
 
 	dispatcher := hash.NewConsistentHash()
+	nodes := make([]Cache, 0, len(c))
 	for _, node := range c {
-		cn := NewNode(node.NewRedis(), barrier, st, errNotFound, opts...)
+		cn := NewNode(node.NewRedis(), barrier, st, errNotFound, nodeOptions(node, opts)...)
 		dispatcher.AddWithWeight(cn, node.Weight)
+		nodes = append(nodes, cn)
 	}
 
 	return cacheCluster{
 		dispatcher:  dispatcher,
+		nodes:       nodes,
 		errNotFound: errNotFound,
 	}
 }
 
+// nodeOptions returns the options of the node, the given opts take precedence over the config.
+func nodeOptions(node NodeConf, opts []Option) []Option {
+	var nodeOpts []Option
+	if len(node.Codec) > 0 {
+		codec, err := codecByName(node.Codec)
+		if err != nil {
+			log.Fatal(err)
+		}
+		nodeOpts = append(nodeOpts, WithCodec(codec))
+	}
+	if node.Local.Limit > 0 {
+		nodeOpts = append(nodeOpts, WithLocalCache(node.Local))
+	}
+	if node.Stale.Grace > 0 {
+		nodeOpts = append(nodeOpts, WithStale(node.Stale))
+	}
+
+	return append(nodeOpts, opts...)
+}
+
+func (cc cacheCluster) Close() error {
+	var be errorx.BatchError
+	for _, node := range cc.nodes {
+		if err := node.Close(); err != nil {
+			be.Add(err)
+		}
+	}
+
+	return be.Err()
+}
+
 func (cc cacheCluster) Del(keys ...string) error {
 	switch len(keys) {
 	case 0:
@@ -0,0 +1,276 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/lukebull/go-zero-extern/core/jsonx"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// The versions of the built-in codecs, the version is written as the header byte of the
+// encoded data, except json, which is written without header to be compatible with the
+// values cached before. The versions of the codecs have the highest bit set, because
+// json never starts with such bytes. The custom codecs can use versions 0x83 to 0x8f.
+const (
+	JsonVersion    byte = 0
+	MsgpackVersion byte = 0x81
+	ProtoVersion   byte = 0x82
+
+	versionFlag   byte = 0x80
+	gzipFlag      byte = 0x10
+	snappyFlag    byte = 0x20
+	compressFlags      = gzipFlag | snappyFlag
+)
+
+var (
+	// JsonCodec is the codec that marshals the values as json, which is the default one.
+	JsonCodec Codec = jsonCodec{}
+	// MsgpackCodec is the codec that marshals the values as msgpack.
+	MsgpackCodec Codec = msgpackCodec{}
+	// ProtoCodec is the codec that marshals the values of proto.Message,
+	// the other values are cached as json.
+	ProtoCodec Codec = protoCodec{}
+
+	// ErrNotProtoMessage is an error that indicates the value to be marshaled by ProtoCodec
+	// is not a proto.Message.
+	ErrNotProtoMessage = errors.New("not a proto.Message")
+
+	codecs = map[byte]Codec{
+		versionFlag:    JsonCodec,
+		MsgpackVersion: MsgpackCodec,
+		ProtoVersion:   ProtoCodec,
+	}
+	codecLock sync.RWMutex
+)
+
+type (
+	// A Codec is used to marshal and unmarshal the cached values.
+	Codec interface {
+		// Version returns the header byte that identifies the codec on reading, so that
+		// the values written by the other codecs can be read during migrating codecs.
+		Version() byte
+		Marshal(v interface{}) ([]byte, error)
+		Unmarshal(data []byte, v interface{}) error
+	}
+
+	jsonCodec struct{}
+
+	msgpackCodec struct{}
+
+	protoCodec struct{}
+
+	compressedCodec struct {
+		Codec
+		flag       byte
+		compress   func([]byte) ([]byte, error)
+		decompress func([]byte) ([]byte, error)
+	}
+)
+
+// GzipCodec returns a Codec that compresses the data of c with gzip.
+func GzipCodec(c Codec) Codec {
+	return compressedCodec{
+		Codec:      c,
+		flag:       gzipFlag,
+		compress:   gzipCompress,
+		decompress: gzipDecompress,
+	}
+}
+
+// SnappyCodec returns a Codec that compresses the data of c with snappy.
+func SnappyCodec(c Codec) Codec {
+	return compressedCodec{
+		Codec: c,
+		flag:  snappyFlag,
+		compress: func(data []byte) ([]byte, error) {
+			return snappy.Encode(nil, data), nil
+		},
+		decompress: func(data []byte) ([]byte, error) {
+			return snappy.Decode(nil, data)
+		},
+	}
+}
+
+// RegisterCodec registers c to read the values written by c, the built-in codecs are registered.
+func RegisterCodec(c Codec) {
+	codecLock.Lock()
+	codecs[c.Version()|versionFlag] = c
+	codecLock.Unlock()
+}
+
+func (c jsonCodec) Version() byte {
+	return JsonVersion
+}
+
+func (c jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return jsonx.Marshal(v)
+}
+
+func (c jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return jsonx.Unmarshal(data, v)
+}
+
+func (c msgpackCodec) Version() byte {
+	return MsgpackVersion
+}
+
+func (c msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (c msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+func (c protoCodec) Version() byte {
+	return ProtoVersion
+}
+
+func (c protoCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, ErrNotProtoMessage
+	}
+
+	return proto.Marshal(msg)
+}
+
+func (c protoCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return ErrNotProtoMessage
+	}
+
+	return proto.Unmarshal(data, msg)
+}
+
+func (c compressedCodec) Version() byte {
+	return c.Codec.Version() | versionFlag | c.flag
+}
+
+func (c compressedCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := c.Codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.compress(data)
+}
+
+func (c compressedCodec) Unmarshal(data []byte, v interface{}) error {
+	data, err := c.decompress(data)
+	if err != nil {
+		return err
+	}
+
+	return c.Codec.Unmarshal(data, v)
+}
+
+// codecByName returns the codec by name, like json, msgpack or proto,
+// with the optional compression suffix, like msgpack-gzip or proto-snappy.
+func codecByName(name string) (Codec, error) {
+	fields := strings.SplitN(name, "-", 2)
+
+	var c Codec
+	switch fields[0] {
+	case "", "json":
+		c = JsonCodec
+	case "msgpack":
+		c = MsgpackCodec
+	case "proto":
+		c = ProtoCodec
+	default:
+		return nil, fmt.Errorf("unknown cache codec: %s", name)
+	}
+
+	if len(fields) == 1 {
+		return c, nil
+	}
+
+	switch fields[1] {
+	case "gzip":
+		return GzipCodec(c), nil
+	case "snappy":
+		return SnappyCodec(c), nil
+	default:
+		return nil, fmt.Errorf("unknown cache compression: %s", name)
+	}
+}
+
+// decode unmarshals data into v, with the codec identified by the header byte,
+// the data without header is json.
+func decode(data string, v interface{}) error {
+	if len(data) == 0 || data[0]&versionFlag == 0 {
+		return JsonCodec.Unmarshal([]byte(data), v)
+	}
+
+	version := data[0]
+	codecLock.RLock()
+	c, ok := codecs[version&^compressFlags]
+	codecLock.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown cache codec version: %#x", version)
+	}
+
+	switch version & compressFlags {
+	case 0:
+		return c.Unmarshal([]byte(data[1:]), v)
+	case gzipFlag:
+		return GzipCodec(c).Unmarshal([]byte(data[1:]), v)
+	case snappyFlag:
+		return SnappyCodec(c).Unmarshal([]byte(data[1:]), v)
+	default:
+		return fmt.Errorf("unknown cache codec version: %#x", version)
+	}
+}
+
+// encode marshals v with c, prefixed with the version of c as the header byte, except json.
+// The values that are not proto.Message, like the primary keys of the indexes, are marshaled
+// as json by the proto codecs.
+func encode(c Codec, v interface{}) (string, error) {
+	data, err := c.Marshal(v)
+	if err == ErrNotProtoMessage {
+		return encode(JsonCodec, v)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	version := c.Version()
+	if version == JsonVersion {
+		return string(data), nil
+	}
+
+	return string(append([]byte{version}, data...)), nil
+}
+
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func gzipDecompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+	"github.com/lukebull/go-zero-extern/core/syncx"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type codecRecord struct {
+	Name    string
+	Age     int
+	Created time.Time
+}
+
+func TestCodecs(t *testing.T) {
+	record := codecRecord{
+		Name:    "foo",
+		Age:     10,
+		Created: time.Unix(1600000000, 123456789).UTC(),
+	}
+	for _, name := range []string{"json", "msgpack", "json-gzip", "msgpack-gzip", "json-snappy",
+		"msgpack-snappy"} {
+		t.Run(name, func(t *testing.T) {
+			c, err := codecByName(name)
+			assert.Nil(t, err)
+
+			data, err := encode(c, record)
+			assert.Nil(t, err)
+			if c.Version() == JsonVersion {
+				assert.Equal(t, byte('{'), data[0])
+			} else {
+				assert.Equal(t, c.Version(), data[0])
+			}
+
+			var val codecRecord
+			assert.Nil(t, decode(data, &val))
+			assert.Equal(t, record.Name, val.Name)
+			assert.Equal(t, record.Age, val.Age)
+			assert.True(t, record.Created.Equal(val.Created))
+		})
+	}
+}
+
+func TestProtoCodecs(t *testing.T) {
+	for _, c := range []Codec{ProtoCodec, GzipCodec(ProtoCodec), SnappyCodec(ProtoCodec)} {
+		data, err := encode(c, wrapperspb.String("foo"))
+		assert.Nil(t, err)
+		assert.Equal(t, c.Version(), data[0])
+
+		var msg wrapperspb.StringValue
+		assert.Nil(t, decode(data, &msg))
+		assert.Equal(t, "foo", msg.Value)
+
+		// the values that are not proto.Message are cached as json
+		var primary interface{} = 1
+		data, err = encode(c, &primary)
+		assert.Nil(t, err)
+		assert.Equal(t, "1", data)
+	}
+
+	var val string
+	assert.Equal(t, ErrNotProtoMessage, ProtoCodec.Unmarshal(nil, &val))
+}
+
+func TestCodecByName(t *testing.T) {
+	c, err := codecByName("")
+	assert.Nil(t, err)
+	assert.Equal(t, JsonCodec, c)
+	c, err = codecByName("proto-gzip")
+	assert.Nil(t, err)
+	assert.Equal(t, ProtoVersion|gzipFlag, c.Version())
+
+	_, err = codecByName("xml")
+	assert.NotNil(t, err)
+	_, err = codecByName("json-lz4")
+	assert.NotNil(t, err)
+}
+
+func TestDecodeUnknownVersion(t *testing.T) {
+	var val string
+	assert.NotNil(t, decode(string([]byte{0x8f, 'a'}), &val))
+	assert.NotNil(t, decode(string([]byte{MsgpackVersion | gzipFlag | snappyFlag, 'a'}), &val))
+}
+
+func TestCacheNodeCodecMigration(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+
+	jsonNode := NewNode(redis.New(mr.Addr()), syncx.NewSharedCalls(), NewStat("codec"), errTestNotFound)
+	msgpackNode := NewNode(redis.New(mr.Addr()), syncx.NewSharedCalls(), NewStat("codec"), errTestNotFound,
+		WithCodec(SnappyCodec(MsgpackCodec)))
+
+	var val codecRecord
+	assert.Nil(t, jsonNode.Set("json", codecRecord{Name: "json"}))
+	assert.Nil(t, msgpackNode.Get("json", &val))
+	assert.Equal(t, "json", val.Name)
+
+	assert.Nil(t, msgpackNode.Take(&val, "msgpack", func(v interface{}) error {
+		*v.(*codecRecord) = codecRecord{Name: "msgpack"}
+		return nil
+	}))
+	data, err := mr.Get("msgpack")
+	assert.Nil(t, err)
+	assert.Equal(t, MsgpackVersion|snappyFlag, data[0])
+	val = codecRecord{}
+	assert.Nil(t, jsonNode.Get("msgpack", &val))
+	assert.Equal(t, "msgpack", val.Name)
+}
+
+func TestCacheCodecConf(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+
+	c := New(ClusterConf{
+		{
+			RedisConf: redis.RedisConf{
+				Host: mr.Addr(),
+				Type: redis.NodeType,
+			},
+			Weight: 100,
+			Codec:  "msgpack-gzip",
+		},
+	}, syncx.NewSharedCalls(), NewStat("codec-conf"), errTestNotFound)
+	assert.Equal(t, MsgpackVersion|gzipFlag, c.(cacheNode).codec.Version())
+}
@@ -7,7 +7,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/lukebull/go-zero-extern/core/jsonx"
 	"github.com/lukebull/go-zero-extern/core/logx"
 	"github.com/lukebull/go-zero-extern/core/mathx"
 	"github.com/lukebull/go-zero-extern/core/stat"
@@ -35,6 +34,10 @@ type cacheNode struct {
 	unstableExpiry mathx.Unstable
 	stat           *Stat
 	errNotFound    error
+	stale          StaleConf
+	codec          Codec
+	// local is the in-process tier in front of rds, nil if not enabled.
+	local *localCache
 }
 
 // NewNode returns a cacheNode.
@@ -46,7 +49,7 @@ type cacheNode struct {
 func NewNode(rds *redis.Redis, barrier syncx.SharedCalls, st *Stat,
 	errNotFound error, opts ...Option) Cache {
 	o := newOptions(opts...)
-	return cacheNode{
+	c := cacheNode{
 		rds:            rds,
 		expiry:         o.Expiry,
 		notFoundExpiry: o.NotFoundExpiry,
@@ -56,7 +59,26 @@ func NewNode(rds *redis.Redis, barrier syncx.SharedCalls, st *Stat,
 		unstableExpiry: mathx.NewUnstable(expiryDeviation),
 		stat:           st,
 		errNotFound:    errNotFound,
+		stale:          o.Stale,
+		codec:          o.Codec,
 	}
+	if o.Local.Limit > 0 {
+		local, err := newLocalCache(rds, o.Local)
+		logx.Must(err)
+		c.local = local
+	}
+
+	return c
+}
+
+// Close stops receiving the invalidations of the local cache if enabled,
+// the redis node is shared, so it's not closed.
+func (c cacheNode) Close() error {
+	if c.local != nil {
+		c.local.close()
+	}
+
+	return nil
 }
 
 // Del deletes cached values with keys.
@@ -69,13 +91,16 @@ func (c cacheNode) Del(keys ...string) error {
 		logx.Errorf("failed to clear cache with keys: %q, error: %v", formatKeys(keys), err)
 		c.asyncRetryDelCache(keys...)
 	}
+	if c.local != nil {
+		c.local.invalidate(keys...)
+	}
 
 	return nil
 }
 
 // Get gets the cache with key and fills into v.
 func (c cacheNode) Get(key string, v interface{}) error {
-	err := c.doGetCache(key, v)
+	_, err := c.doGetCache(key, v)
 	if err == errPlaceholder {
 		return c.errNotFound
 	}
@@ -95,12 +120,16 @@ func (c cacheNode) Set(key string, v interface{}) error {
 
 // SetWithExpire sets the cache with key and v, using given expire.
 func (c cacheNode) SetWithExpire(key string, v interface{}, expire time.Duration) error {
-	data, err := jsonx.Marshal(v)
-	if err != nil {
+	if _, err := c.setRedis(key, v, expire, 0); err != nil {
 		return err
 	}
 
-	return c.rds.Setex(key, string(data), int(expire.Seconds()))
+	// the local caches of all instances may hold the previous value.
+	if c.local != nil {
+		c.local.invalidate(key)
+	}
+
+	return nil
 }
 
 // String returns a string that represents the cacheNode.
@@ -111,9 +140,7 @@ func (c cacheNode) String() string {
 // Take takes the result from cache first, if not found,
 // query from DB and set cache using c.expiry, then return the result.
 func (c cacheNode) Take(v interface{}, key string, query func(v interface{}) error) error {
-	return c.doTake(v, key, query, func(v interface{}) error {
-		return c.Set(key, v)
-	})
+	return c.doTake(v, key, c.aroundDuration(c.expiry), query)
 }
 
 // TakeWithExpire takes the result from cache first, if not found,
@@ -121,10 +148,8 @@ func (c cacheNode) Take(v interface{}, key string, query func(v interface{}) err
 func (c cacheNode) TakeWithExpire(v interface{}, key string, query func(v interface{},
 	expire time.Duration) error) error {
 	expire := c.aroundDuration(c.expiry)
-	return c.doTake(v, key, func(v interface{}) error {
+	return c.doTake(v, key, expire, func(v interface{}) error {
 		return query(v, expire)
-	}, func(v interface{}) error {
-		return c.SetWithExpire(key, v, expire)
 	})
 }
 
@@ -139,31 +164,79 @@ func (c cacheNode) asyncRetryDelCache(keys ...string) {
 	}, keys...)
 }
 
-func (c cacheNode) doGetCache(key string, v interface{}) error {
+func (c cacheNode) doGetCache(key string, v interface{}) (freshness, error) {
 	c.stat.IncrementTotal()
+	if c.local != nil {
+		if data, ok := c.local.get(key); ok {
+			c.stat.IncrementLocalHit()
+			c.stat.IncrementHit()
+			return c.processData(key, data, v)
+		}
+
+		c.stat.IncrementLocalMiss()
+	}
+
 	data, err := c.rds.Get(key)
 	if err != nil {
 		c.stat.IncrementMiss()
-		return err
+		return freshness{}, err
 	}
 
 	if len(data) == 0 {
 		c.stat.IncrementMiss()
-		return c.errNotFound
+		return freshness{}, c.errNotFound
 	}
 
 	c.stat.IncrementHit()
-	if data == notFoundPlaceholder {
-		return errPlaceholder
+	if c.local != nil {
+		c.local.set(key, data)
 	}
 
-	return c.processCache(key, data, v)
+	return c.processData(key, data, v)
 }
 
-func (c cacheNode) doTake(v interface{}, key string, query func(v interface{}) error,
-	cacheVal func(v interface{}) error) error {
+// doSetCache sets the cache with the value loaded from db, the local cache is filled without invalidations.
+// delta is how long the query took.
+func (c cacheNode) doSetCache(key string, v interface{}, expire, delta time.Duration) error {
+	data, err := c.setRedis(key, v, expire, delta)
+	if err != nil {
+		return err
+	}
+
+	if c.local != nil {
+		c.local.set(key, data)
+	}
+
+	return nil
+}
+
+// setRedis sets v into redis, with the logical expiry if serving stale enabled, returns the stored data.
+func (c cacheNode) setRedis(key string, v interface{}, expire, delta time.Duration) (string, error) {
+	data, err := encode(c.codec, v)
+	if err != nil {
+		return "", err
+	}
+
+	if c.stale.Grace > 0 {
+		data = encodeFreshness(freshness{
+			expiry: time.Now().Add(expire),
+			delta:  delta,
+		}, data)
+		expire += c.stale.Grace
+	}
+
+	if err = c.rds.Setex(key, data, int(expire.Seconds())); err != nil {
+		return "", err
+	}
+
+	return data, nil
+}
+
+func (c cacheNode) doTake(v interface{}, key string, expire time.Duration,
+	query func(v interface{}) error) error {
 	val, fresh, err := c.barrier.DoEx(key, func() (interface{}, error) {
-		if err := c.doGetCache(key, v); err != nil {
+		fr, err := c.doGetCache(key, v)
+		if err != nil {
 			if err == errPlaceholder {
 				return nil, c.errNotFound
 			} else if err != c.errNotFound {
@@ -173,6 +246,7 @@ func (c cacheNode) doTake(v interface{}, key string, query func(v interface{}) e
 				return nil, err
 			}
 
+			start := time.Now()
 			if err = query(v); err == c.errNotFound {
 				if err = c.setCacheWithNotFound(key); err != nil {
 					logx.Error(err)
@@ -184,12 +258,14 @@ func (c cacheNode) doTake(v interface{}, key string, query func(v interface{}) e
 				return nil, err
 			}
 
-			if err = cacheVal(v); err != nil {
+			if err = c.doSetCache(key, v, expire, time.Since(start)); err != nil {
 				logx.Error(err)
 			}
+		} else if c.shouldRefresh(fr) {
+			c.asyncRefresh(key, v, expire, query)
 		}
 
-		return jsonx.Marshal(v)
+		return encode(c.codec, v)
 	})
 	if err != nil {
 		return err
@@ -202,11 +278,20 @@ func (c cacheNode) doTake(v interface{}, key string, query func(v interface{}) e
 	c.stat.IncrementTotal()
 	c.stat.IncrementHit()
 
-	return jsonx.Unmarshal(val.([]byte), v)
+	return decode(val.(string), v)
+}
+
+func (c cacheNode) processData(key, data string, v interface{}) (freshness, error) {
+	if data == notFoundPlaceholder {
+		return freshness{}, errPlaceholder
+	}
+
+	fr, data := decodeFreshness(data)
+	return fr, c.processCache(key, data, v)
 }
 
 func (c cacheNode) processCache(key, data string, v interface{}) error {
-	err := jsonx.Unmarshal([]byte(data), v)
+	err := decode(data, v)
 	if err == nil {
 		return nil
 	}
@@ -215,6 +300,9 @@ func (c cacheNode) processCache(key, data string, v interface{}) error {
 		c.rds.Addr, key, data, err)
 	logx.Error(report)
 	stat.Report(report)
+	if c.local != nil {
+		c.local.del(key)
+	}
 	if _, e := c.rds.Del(key); e != nil {
 		logx.Errorf("delete invalid cache, node: %s, key: %s, value: %s, error: %v",
 			c.rds.Addr, key, data, e)
@@ -225,5 +313,13 @@ func (c cacheNode) processCache(key, data string, v interface{}) error {
 }
 
 func (c cacheNode) setCacheWithNotFound(key string) error {
-	return c.rds.Setex(key, notFoundPlaceholder, int(c.aroundDuration(c.notFoundExpiry).Seconds()))
+	if err := c.rds.Setex(key, notFoundPlaceholder,
+		int(c.aroundDuration(c.notFoundExpiry).Seconds())); err != nil {
+		return err
+	}
+	if c.local != nil {
+		c.local.set(key, notFoundPlaceholder)
+	}
+
+	return nil
 }
@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+	"github.com/lukebull/go-zero-extern/core/syncx"
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestNotFound = errors.New("not found")
+
+func TestCacheNodeLocalHit(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+
+	st := NewStat("local-hit")
+	c := NewNode(redis.New(mr.Addr()), syncx.NewSharedCalls(), st, errTestNotFound,
+		WithLocalCache(LocalConf{Limit: 10}))
+
+	var val string
+	assert.Nil(t, c.Take(&val, "any", func(v interface{}) error {
+		*v.(*string) = "foo"
+		return nil
+	}))
+	assert.Equal(t, "foo", val)
+
+	// the local tier is in front of redis
+	assert.Nil(t, mr.Set("any", `"bar"`))
+	assert.Nil(t, c.Get("any", &val))
+	assert.Equal(t, "foo", val)
+	assert.Equal(t, uint64(1), st.LocalHit)
+	assert.Equal(t, uint64(1), st.LocalMiss)
+
+	// the not found placeholder is cached locally too
+	mr.FlushAll()
+	assert.Equal(t, errTestNotFound, c.Take(&val, "none", func(v interface{}) error {
+		return errTestNotFound
+	}))
+	assert.Nil(t, mr.Set("none", `"bar"`))
+	assert.Equal(t, errTestNotFound, c.Get("none", &val))
+}
+
+func TestCacheNodeLocalInvalidation(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+
+	conf := LocalConf{
+		Limit:   10,
+		Channel: "test:invalidate",
+	}
+	st := NewStat("local-invalidation")
+	c1 := NewNode(redis.New(mr.Addr()), syncx.NewSharedCalls(), st, errTestNotFound, WithLocalCache(conf))
+	c2 := NewNode(redis.New(mr.Addr()), syncx.NewSharedCalls(), st, errTestNotFound, WithLocalCache(conf))
+	assert.Eventually(t, func() bool {
+		return mr.PubSubNumSub(conf.Channel)[conf.Channel] > 0
+	}, time.Second, time.Millisecond*10)
+
+	var val string
+	assert.Nil(t, c1.Set("any", "foo"))
+	assert.Nil(t, c1.Get("any", &val))
+	assert.Nil(t, c2.Get("any", &val))
+	assert.Equal(t, "foo", val)
+
+	assert.Nil(t, c1.Set("any", "bar"))
+	assert.Eventually(t, func() bool {
+		return c2.Get("any", &val) == nil && val == "bar"
+	}, time.Second, time.Millisecond*10)
+
+	assert.Nil(t, c1.Del("any"))
+	assert.Eventually(t, func() bool {
+		return c2.Get("any", &val) == errTestNotFound
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestCacheLocalConf(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+
+	conf := ClusterConf{
+		{
+			RedisConf: redis.RedisConf{
+				Host: mr.Addr(),
+				Type: redis.NodeType,
+			},
+			Weight: 100,
+			Local: LocalConf{
+				Limit: 10,
+			},
+		},
+	}
+	c := New(conf, syncx.NewSharedCalls(), NewStat("local-conf"), errTestNotFound)
+	node, ok := c.(cacheNode)
+	assert.True(t, ok)
+	assert.NotNil(t, node.local)
+	assert.Equal(t, defaultInvalidateChannel, node.local.channel)
+
+	c = New(conf, syncx.NewSharedCalls(), NewStat("local-conf"), errTestNotFound,
+		WithLocalCache(LocalConf{}))
+	assert.Nil(t, c.(cacheNode).local)
+}
+
+func TestCacheNodeLocalClose(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+
+	conf := LocalConf{
+		Limit:   10,
+		Channel: "test:close",
+	}
+	st := NewStat("local-close")
+	c1 := NewNode(redis.New(mr.Addr()), syncx.NewSharedCalls(), st, errTestNotFound, WithLocalCache(conf))
+	c2 := NewNode(redis.New(mr.Addr()), syncx.NewSharedCalls(), st, errTestNotFound, WithLocalCache(conf))
+	assert.Eventually(t, func() bool {
+		return mr.PubSubNumSub(conf.Channel)[conf.Channel] > 0
+	}, time.Second, time.Millisecond*10)
+
+	key := c1.(cacheNode).local.invalidatorKey()
+	invalidatorLock.Lock()
+	inv := invalidators[key]
+	invalidatorLock.Unlock()
+	assert.NotNil(t, inv)
+
+	// the subscription is kept for the other local caches.
+	assert.Nil(t, c2.Close())
+	inv.lock.RLock()
+	assert.Equal(t, 1, len(inv.caches))
+	inv.lock.RUnlock()
+	assert.Equal(t, 1, mr.PubSubNumSub(conf.Channel)[conf.Channel])
+
+	assert.Nil(t, c1.Close())
+	assert.Nil(t, c1.Close())
+	invalidatorLock.Lock()
+	_, ok := invalidators[key]
+	invalidatorLock.Unlock()
+	assert.False(t, ok)
+	assert.Eventually(t, func() bool {
+		return mr.PubSubNumSub(conf.Channel)[conf.Channel] == 0
+	}, time.Second, time.Millisecond*10)
+
+	// subscribes again on new local caches.
+	c3 := NewNode(redis.New(mr.Addr()), syncx.NewSharedCalls(), st, errTestNotFound, WithLocalCache(conf))
+	assert.Eventually(t, func() bool {
+		return mr.PubSubNumSub(conf.Channel)[conf.Channel] > 0
+	}, time.Second, time.Millisecond*10)
+	assert.Nil(t, c3.Close())
+}
+
+func TestCacheClusterClose(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+
+	node := NodeConf{
+		RedisConf: redis.RedisConf{
+			Host: mr.Addr(),
+			Type: redis.NodeType,
+		},
+		Weight: 100,
+		Local: LocalConf{
+			Limit:   10,
+			Channel: "test:cluster-close",
+		},
+	}
+	c := New(ClusterConf{node, node}, syncx.NewSharedCalls(), NewStat("cluster-close"), errTestNotFound)
+	assert.Eventually(t, func() bool {
+		return mr.PubSubNumSub(node.Local.Channel)[node.Local.Channel] > 0
+	}, time.Second, time.Millisecond*10)
+
+	assert.Nil(t, c.Close())
+	assert.Eventually(t, func() bool {
+		return mr.PubSubNumSub(node.Local.Channel)[node.Local.Channel] == 0
+	}, time.Second, time.Millisecond*10)
+}
@@ -3,8 +3,11 @@ package cache
 import "time"
 
 const (
-	defaultExpiry         = time.Hour * 24 * 7
-	defaultNotFoundExpiry = time.Minute
+	defaultExpiry            = time.Hour * 24 * 7
+	defaultNotFoundExpiry    = time.Minute
+	defaultLocalExpiry       = time.Minute
+	defaultInvalidateChannel = "cache:invalidate"
+	defaultStaleBeta         = 1
 )
 
 type (
@@ -12,6 +15,9 @@ type (
 	Options struct {
 		Expiry         time.Duration
 		NotFoundExpiry time.Duration
+		Local          LocalConf
+		Stale          StaleConf
+		Codec          Codec
 	}
 
 	// Option defines the method to customize an Options.
@@ -31,9 +37,32 @@ func newOptions(opts ...Option) Options {
 		o.NotFoundExpiry = defaultNotFoundExpiry
 	}
 
+	if o.Local.Limit > 0 {
+		if o.Local.Expiry <= 0 {
+			o.Local.Expiry = defaultLocalExpiry
+		}
+		if len(o.Local.Channel) == 0 {
+			o.Local.Channel = defaultInvalidateChannel
+		}
+	}
+
+	if o.Codec == nil {
+		o.Codec = JsonCodec
+	}
+	if o.Stale.Grace > 0 && o.Stale.Beta <= 0 {
+		o.Stale.Beta = defaultStaleBeta
+	}
+
 	return o
 }
 
+// WithCodec returns a func to customize a Options with given codec.
+func WithCodec(c Codec) Option {
+	return func(o *Options) {
+		o.Codec = c
+	}
+}
+
 // WithExpiry returns a func to customize a Options with given expiry.
 func WithExpiry(expiry time.Duration) Option {
 	return func(o *Options) {
@@ -41,6 +70,20 @@ func WithExpiry(expiry time.Duration) Option {
 	}
 }
 
+// WithLocalCache returns a func to customize a Options with the in-process cache in front of redis.
+func WithLocalCache(c LocalConf) Option {
+	return func(o *Options) {
+		o.Local = c
+	}
+}
+
+// WithStale returns a func to customize a Options with serving the stale values.
+func WithStale(c StaleConf) Option {
+	return func(o *Options) {
+		o.Stale = c
+	}
+}
+
 // WithNotFoundExpiry returns a func to customize a Options with given not found expiry.
 func WithNotFoundExpiry(expiry time.Duration) Option {
 	return func(o *Options) {
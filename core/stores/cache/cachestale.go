@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/core/threading"
+)
+
+const (
+	// the values with logical expiry are encoded as ~<expiry in unix ms>:<delta in ms>:<data>,
+	// json values never start with ~, so that the plain values can be read as always fresh.
+	freshnessPrefix    = "~"
+	refreshKeyPrefix   = "refresh:"
+	refreshLockSeconds = 10
+)
+
+// freshness is the logical expiry of a cached value,
+// the zero freshness means the value never needs to be refreshed.
+type freshness struct {
+	expiry time.Time
+	// delta is how long the query took, the longer the earlier to refresh.
+	delta time.Duration
+}
+
+func decodeFreshness(data string) (freshness, string) {
+	if !strings.HasPrefix(data, freshnessPrefix) {
+		return freshness{}, data
+	}
+
+	fields := strings.SplitN(data[len(freshnessPrefix):], ":", 3)
+	if len(fields) != 3 {
+		return freshness{}, data
+	}
+
+	expiry, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return freshness{}, data
+	}
+	delta, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return freshness{}, data
+	}
+
+	return freshness{
+		expiry: time.Unix(0, expiry*int64(time.Millisecond)),
+		delta:  time.Duration(delta) * time.Millisecond,
+	}, fields[2]
+}
+
+func encodeFreshness(fr freshness, data string) string {
+	return freshnessPrefix + strconv.FormatInt(fr.expiry.UnixNano()/int64(time.Millisecond), 10) +
+		":" + strconv.FormatInt(int64(fr.delta/time.Millisecond), 10) + ":" + data
+}
+
+// asyncRefresh refreshes the cached value of key in background,
+// the stale value is kept on query failures until the grace period ends.
+func (c cacheNode) asyncRefresh(key string, v interface{}, expire time.Duration,
+	query func(v interface{}) error) {
+	typ := reflect.TypeOf(v)
+	if typ == nil || typ.Kind() != reflect.Ptr {
+		return
+	}
+
+	threading.GoSafe(func() {
+		// refresh once at a time in process, and across the instances by the lock in redis.
+		_, _ = c.barrier.Do(refreshKeyPrefix+key, func() (interface{}, error) {
+			lockKey := refreshKeyPrefix + key
+			ok, err := c.rds.SetnxEx(lockKey, "1", refreshLockSeconds)
+			if err != nil || !ok {
+				return nil, err
+			}
+			defer func() {
+				if _, err := c.rds.Del(lockKey); err != nil {
+					logx.Error(err)
+				}
+			}()
+
+			c.refresh(key, reflect.New(typ.Elem()).Interface(), expire, query)
+			return nil, nil
+		})
+	})
+}
+
+func (c cacheNode) refresh(key string, v interface{}, expire time.Duration, query func(v interface{}) error) {
+	start := time.Now()
+	err := query(v)
+	switch {
+	case err == c.errNotFound:
+		if err = c.setCacheWithNotFound(key); err != nil {
+			logx.Error(err)
+		}
+	case err != nil:
+		c.stat.IncrementDbFails()
+		logx.Errorf("failed to refresh cache, serving stale, node: %s, key: %s, error: %v",
+			c.rds.Addr, key, err)
+		return
+	default:
+		if err = c.doSetCache(key, v, expire, time.Since(start)); err != nil {
+			logx.Error(err)
+			return
+		}
+	}
+
+	// the other instances may hold the previous value.
+	if c.local != nil {
+		c.local.invalidate(key)
+	}
+}
+
+// shouldRefresh implements the probabilistic early refresh of XFetch,
+// the probability rises as the logical expiry approaches, always true after expiry.
+func (c cacheNode) shouldRefresh(fr freshness) bool {
+	if fr.expiry.IsZero() {
+		return false
+	}
+
+	c.lock.Lock()
+	// 1-Float64() is in (0, 1], to avoid log(0)
+	gap := -float64(fr.delta) * c.stale.Beta * math.Log(1-c.r.Float64())
+	c.lock.Unlock()
+
+	return !time.Now().Add(time.Duration(gap)).Before(fr.expiry)
+}
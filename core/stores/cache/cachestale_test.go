@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+	"github.com/lukebull/go-zero-extern/core/syncx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreshnessCodec(t *testing.T) {
+	fr := freshness{
+		expiry: time.Unix(1600000000, 0),
+		delta:  time.Millisecond * 20,
+	}
+	decoded, data := decodeFreshness(encodeFreshness(fr, `{"a":1}`))
+	assert.Equal(t, `{"a":1}`, data)
+	assert.True(t, fr.expiry.Equal(decoded.expiry))
+	assert.Equal(t, fr.delta, decoded.delta)
+
+	for _, plain := range []string{`"foo"`, `~foo`, `~1:bar:baz`} {
+		decoded, data = decodeFreshness(plain)
+		assert.True(t, decoded.expiry.IsZero())
+		assert.Equal(t, plain, data)
+	}
+}
+
+func TestCacheNodeShouldRefresh(t *testing.T) {
+	c := NewNode(redis.New("localhost:6379"), syncx.NewSharedCalls(), NewStat("should-refresh"),
+		errTestNotFound, WithStale(StaleConf{Grace: time.Minute})).(cacheNode)
+	assert.Equal(t, float64(defaultStaleBeta), c.stale.Beta)
+	assert.False(t, c.shouldRefresh(freshness{}))
+	assert.True(t, c.shouldRefresh(freshness{
+		expiry: time.Now().Add(-time.Second),
+	}))
+	assert.False(t, c.shouldRefresh(freshness{
+		expiry: time.Now().Add(time.Hour),
+	}))
+
+	c.stale.Beta = 1e9
+	assert.True(t, c.shouldRefresh(freshness{
+		expiry: time.Now().Add(time.Hour),
+		delta:  time.Second,
+	}))
+}
+
+func TestCacheNodeStaleWhileRevalidate(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+
+	c := NewNode(redis.New(mr.Addr()), syncx.NewSharedCalls(), NewStat("stale"), errTestNotFound,
+		WithStale(StaleConf{Grace: time.Minute}))
+	assert.Nil(t, mr.Set("any", encodeFreshness(freshness{
+		expiry: time.Now().Add(-time.Second),
+	}, `"old"`)))
+
+	var val string
+	assert.Nil(t, c.Take(&val, "any", func(v interface{}) error {
+		*v.(*string) = "new"
+		return nil
+	}))
+	assert.Equal(t, "old", val)
+
+	assert.Eventually(t, func() bool {
+		return c.Get("any", &val) == nil && val == "new" && !mr.Exists(refreshKeyPrefix+"any")
+	}, time.Second, time.Millisecond*10)
+
+	// the value is kept in redis for the grace period after the logical expiry
+	data, err := mr.Get("any")
+	assert.Nil(t, err)
+	fr, _ := decodeFreshness(data)
+	assert.InDelta(t, float64(time.Until(fr.expiry)+time.Minute), float64(mr.TTL("any")), float64(time.Second*2))
+}
+
+func TestCacheNodeStaleOnError(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+
+	st := NewStat("stale-on-error")
+	c := NewNode(redis.New(mr.Addr()), syncx.NewSharedCalls(), st, errTestNotFound,
+		WithStale(StaleConf{Grace: time.Minute}))
+	stale := encodeFreshness(freshness{
+		expiry: time.Now().Add(-time.Second),
+	}, `"old"`)
+	assert.Nil(t, mr.Set("any", stale))
+
+	errDb := errors.New("db down")
+	var val string
+	assert.Nil(t, c.Take(&val, "any", func(v interface{}) error {
+		return errDb
+	}))
+	assert.Equal(t, "old", val)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadUint64(&st.DbFails) > 0 && !mr.Exists(refreshKeyPrefix+"any")
+	}, time.Second, time.Millisecond*10)
+	data, err := mr.Get("any")
+	assert.Nil(t, err)
+	assert.Equal(t, stale, data)
+
+	// nothing to serve after the grace period
+	mr.FlushAll()
+	assert.Equal(t, errDb, c.Take(&val, "any", func(v interface{}) error {
+		return errDb
+	}))
+}
+
+func TestCacheStaleConf(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+
+	c := New(ClusterConf{
+		{
+			RedisConf: redis.RedisConf{
+				Host: mr.Addr(),
+				Type: redis.NodeType,
+			},
+			Weight: 100,
+			Stale: StaleConf{
+				Grace: time.Minute,
+				Beta:  2,
+			},
+		},
+	}, syncx.NewSharedCalls(), NewStat("stale-conf"), errTestNotFound)
+	assert.Equal(t, StaleConf{Grace: time.Minute, Beta: 2}, c.(cacheNode).stale)
+
+	assert.Nil(t, c.Set("any", "foo"))
+	data, err := mr.Get("any")
+	assert.Nil(t, err)
+	fr, data := decodeFreshness(data)
+	assert.False(t, fr.expiry.IsZero())
+	assert.Equal(t, `"foo"`, data)
+}
@@ -18,6 +18,9 @@ type Stat struct {
 	Hit     uint64
 	Miss    uint64
 	DbFails uint64
+	// LocalHit and LocalMiss count the lookups on the in-process tier, if enabled.
+	LocalHit  uint64
+	LocalMiss uint64
 }
 
 // NewStat returns a Stat.
@@ -50,6 +53,16 @@ func (s *Stat) IncrementDbFails() {
 	atomic.AddUint64(&s.DbFails, 1)
 }
 
+// IncrementLocalHit increments the local tier hit count.
+func (s *Stat) IncrementLocalHit() {
+	atomic.AddUint64(&s.LocalHit, 1)
+}
+
+// IncrementLocalMiss increments the local tier miss count.
+func (s *Stat) IncrementLocalMiss() {
+	atomic.AddUint64(&s.LocalMiss, 1)
+}
+
 func (s *Stat) statLoop() {
 	ticker := time.NewTicker(statInterval)
 	defer ticker.Stop()
@@ -64,6 +77,14 @@ func (s *Stat) statLoop() {
 		percent := 100 * float32(hit) / float32(total)
 		miss := atomic.SwapUint64(&s.Miss, 0)
 		dbf := atomic.SwapUint64(&s.DbFails, 0)
+		localHit := atomic.SwapUint64(&s.LocalHit, 0)
+		localMiss := atomic.SwapUint64(&s.LocalMiss, 0)
+		if localHit+localMiss > 0 {
+			logx.Statf("dbcache(%s) - qpm: %d, hit_ratio: %.1f%%, hit: %d, miss: %d, db_fails: %d, "+
+				"local_hit: %d, local_miss: %d", s.name, total, percent, hit, miss, dbf, localHit, localMiss)
+			continue
+		}
+
 		logx.Statf("dbcache(%s) - qpm: %d, hit_ratio: %.1f%%, hit: %d, miss: %d, db_fails: %d",
 			s.name, total, percent, hit, miss, dbf)
 	}
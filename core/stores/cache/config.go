@@ -1,6 +1,10 @@
 package cache
 
-import "github.com/lukebull/go-zero-extern/core/stores/redis"
+import (
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+)
 
 type (
 	// A ClusterConf is the config of a redis cluster that used as cache.
@@ -10,5 +14,34 @@ type (
 	NodeConf struct {
 		redis.RedisConf
 		Weight int `json:",default=100"`
+		// Local enables the in-process cache in front of the redis node.
+		Local LocalConf `json:",optional"`
+		// Stale enables serving the stale values while refreshing or on query failures.
+		Stale StaleConf `json:",optional"`
+		// Codec is the codec of the cached values, json, msgpack or proto,
+		// with the optional compression suffix, like msgpack-gzip or proto-snappy, defaults to json.
+		Codec string `json:",optional"`
+	}
+
+	// A LocalConf is the config of the in-process cache in front of redis,
+	// the local items are invalidated across the instances by redis pub/sub on deleting.
+	LocalConf struct {
+		// Limit is the max number of the local items of each cache, disabled if 0.
+		// Each model has its own cache, so the memory usage grows with the number of the models.
+		Limit int `json:",optional"`
+		// Expiry bounds the staleness of the local items if the invalidations are lost.
+		Expiry  time.Duration `json:",default=1m"`
+		Channel string        `json:",default=cache:invalidate"`
+	}
+
+	// A StaleConf is the config of serving the stale values, the values are stored with logical expiry,
+	// refreshed early by XFetch, and served stale after expiry while refreshing or on query failures.
+	// The queries of Take are called again in background to refresh, with a new v, so they must
+	// write the results only into their v arguments, and not depend on the canceled contexts.
+	StaleConf struct {
+		// Grace is how long the values are served stale after expiry, disabled if 0.
+		Grace time.Duration `json:",optional"`
+		// Beta tunes the early refresh, the larger the earlier, defaults to 1.
+		Beta float64 `json:",default=1"`
 	}
 )
@@ -0,0 +1,218 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/collection"
+	"github.com/lukebull/go-zero-extern/core/jsonx"
+	"github.com/lukebull/go-zero-extern/core/lang"
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+	"github.com/lukebull/go-zero-extern/core/syncx"
+	"github.com/lukebull/go-zero-extern/core/threading"
+)
+
+const resubscribeInterval = time.Second
+
+var (
+	invalidators    = make(map[string]*invalidator)
+	invalidatorLock sync.Mutex
+)
+
+type (
+	// localCache is the in-process tier in front of a redis node, which keeps the raw cached data.
+	localCache struct {
+		items   *collection.Cache
+		rds     *redis.Redis
+		channel string
+	}
+
+	// invalidator subscribes the invalidations of a channel on a redis node,
+	// and deletes the keys from the local caches of the redis node.
+	invalidator struct {
+		lock   sync.RWMutex
+		caches map[*localCache]lang.PlaceholderType
+		pubsub *redis.PubSub
+		done   *syncx.DoneChan
+	}
+)
+
+func newLocalCache(rds *redis.Redis, c LocalConf) (*localCache, error) {
+	items, err := collection.NewCache(c.Expiry, collection.WithLimit(c.Limit),
+		collection.WithName("dbcache-local("+rds.Addr+")"))
+	if err != nil {
+		return nil, err
+	}
+
+	lc := &localCache{
+		items:   items,
+		rds:     rds,
+		channel: c.Channel,
+	}
+	subscribeInvalidations(lc)
+
+	return lc, nil
+}
+
+// close stops receiving the invalidations, the items are kept until the local cache is released.
+func (lc *localCache) close() {
+	unsubscribeInvalidations(lc)
+}
+
+func (lc *localCache) del(keys ...string) {
+	for _, key := range keys {
+		lc.items.Del(key)
+	}
+}
+
+func (lc *localCache) get(key string) (string, bool) {
+	val, ok := lc.items.Get(key)
+	if !ok {
+		return "", false
+	}
+
+	return val.(string), true
+}
+
+// invalidate deletes the keys from the local caches of all instances.
+func (lc *localCache) invalidate(keys ...string) {
+	lc.del(keys...)
+
+	data, err := jsonx.Marshal(keys)
+	if err != nil {
+		logx.Error(err)
+		return
+	}
+
+	// the other instances keep the stale items until expiry if failed.
+	if _, err = lc.rds.Publish(lc.channel, string(data)); err != nil {
+		logx.Errorf("failed to publish cache invalidation, keys: %q, error: %v", formatKeys(keys), err)
+	}
+}
+
+func (lc *localCache) invalidatorKey() string {
+	return lc.rds.Addr + "/" + lc.channel
+}
+
+func (lc *localCache) set(key, data string) {
+	lc.items.Set(key, data)
+}
+
+func subscribeInvalidations(lc *localCache) {
+	key := lc.invalidatorKey()
+
+	invalidatorLock.Lock()
+	defer invalidatorLock.Unlock()
+
+	inv, ok := invalidators[key]
+	if !ok {
+		inv = &invalidator{
+			caches: make(map[*localCache]lang.PlaceholderType),
+			done:   syncx.NewDoneChan(),
+		}
+		invalidators[key] = inv
+		threading.GoSafe(func() {
+			inv.run(lc.rds, lc.channel)
+		})
+	}
+
+	inv.lock.Lock()
+	inv.caches[lc] = lang.Placeholder
+	inv.lock.Unlock()
+}
+
+// unsubscribeInvalidations removes lc from its invalidator,
+// and stops the invalidator if no local caches left.
+func unsubscribeInvalidations(lc *localCache) {
+	key := lc.invalidatorKey()
+
+	invalidatorLock.Lock()
+	defer invalidatorLock.Unlock()
+
+	inv, ok := invalidators[key]
+	if !ok {
+		return
+	}
+
+	inv.lock.Lock()
+	delete(inv.caches, lc)
+	empty := len(inv.caches) == 0
+	inv.lock.Unlock()
+
+	if empty {
+		delete(invalidators, key)
+		inv.stop()
+	}
+}
+
+func (inv *invalidator) invalidate(payload string) {
+	var keys []string
+	if err := jsonx.UnmarshalFromString(payload, &keys); err != nil {
+		logx.Errorf("bad cache invalidation: %s, error: %v", payload, err)
+		return
+	}
+
+	inv.lock.RLock()
+	defer inv.lock.RUnlock()
+	for lc := range inv.caches {
+		lc.del(keys...)
+	}
+}
+
+func (inv *invalidator) run(rds *redis.Redis, channel string) {
+	for {
+		pubsub, err := rds.Subscribe(channel)
+		if err != nil {
+			logx.Errorf("failed to subscribe cache invalidations on %s, error: %v", rds.Addr, err)
+			select {
+			case <-inv.done.Done():
+				return
+			case <-time.After(resubscribeInterval):
+			}
+			continue
+		}
+
+		if !inv.setPubSub(pubsub) {
+			pubsub.Close()
+			return
+		}
+
+		// the channel is reconnected by the PubSub, the invalidations during reconnecting are lost.
+		// the channel is closed on stopping, because the PubSub is closed.
+		for msg := range pubsub.Channel() {
+			inv.invalidate(msg.Payload)
+		}
+
+		pubsub.Close()
+		select {
+		case <-inv.done.Done():
+			return
+		default:
+		}
+	}
+}
+
+// setPubSub sets the current PubSub to close on stopping, returns false if already stopped.
+func (inv *invalidator) setPubSub(pubsub *redis.PubSub) bool {
+	inv.lock.Lock()
+	defer inv.lock.Unlock()
+
+	select {
+	case <-inv.done.Done():
+		return false
+	default:
+		inv.pubsub = pubsub
+		return true
+	}
+}
+
+func (inv *invalidator) stop() {
+	inv.lock.Lock()
+	defer inv.lock.Unlock()
+
+	inv.done.Close()
+	if inv.pubsub != nil {
+		inv.pubsub.Close()
+	}
+}
@@ -3,6 +3,7 @@ package kv
 import (
 	"errors"
 	"log"
+	"time"
 
 	"github.com/lukebull/go-zero-extern/core/errorx"
 	"github.com/lukebull/go-zero-extern/core/hash"
@@ -58,6 +59,16 @@ type (
 		Srem(key string, values ...interface{}) (int, error)
 		Sscan(key string, cursor uint64, match string, count int64) (keys []string, cur uint64, err error)
 		Ttl(key string) (int, error)
+		Xack(key, group string, ids ...string) (int64, error)
+		Xadd(key string, values map[string]interface{}) (string, error)
+		XaddWithMaxLen(key string, maxLen int64, values map[string]interface{}) (string, error)
+		Xclaim(key, group, consumer string, minIdle time.Duration, ids ...string) ([]redis.XMessage, error)
+		Xdel(key string, ids ...string) (int64, error)
+		XgroupCreate(key, group, start string) error
+		Xlen(key string) (int64, error)
+		Xpending(key, group string) (*redis.XPending, error)
+		XpendingExt(key, group, start, end string, count int64, consumer ...string) ([]redis.XPendingExt, error)
+		Xreadgroup(key, group, consumer, id string, count int64, block time.Duration) ([]redis.XMessage, error)
 		Zadd(key string, score int64, value string) (bool, error)
 		Zadds(key string, ps ...redis.Pair) (int64, error)
 		Zcard(key string) (int, error)
@@ -493,6 +504,99 @@ func (cs clusterStore) Ttl(key string) (int, error) {
 	return node.Ttl(key)
 }
 
+func (cs clusterStore) Xack(key, group string, ids ...string) (int64, error) {
+	node, err := cs.getRedis(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return node.Xack(key, group, ids...)
+}
+
+func (cs clusterStore) Xadd(key string, values map[string]interface{}) (string, error) {
+	node, err := cs.getRedis(key)
+	if err != nil {
+		return "", err
+	}
+
+	return node.Xadd(key, values)
+}
+
+func (cs clusterStore) XaddWithMaxLen(key string, maxLen int64, values map[string]interface{}) (string, error) {
+	node, err := cs.getRedis(key)
+	if err != nil {
+		return "", err
+	}
+
+	return node.XaddWithMaxLen(key, maxLen, values)
+}
+
+func (cs clusterStore) Xclaim(key, group, consumer string, minIdle time.Duration, ids ...string) (
+	[]redis.XMessage, error) {
+	node, err := cs.getRedis(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.Xclaim(key, group, consumer, minIdle, ids...)
+}
+
+func (cs clusterStore) Xdel(key string, ids ...string) (int64, error) {
+	node, err := cs.getRedis(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return node.Xdel(key, ids...)
+}
+
+func (cs clusterStore) XgroupCreate(key, group, start string) error {
+	node, err := cs.getRedis(key)
+	if err != nil {
+		return err
+	}
+
+	return node.XgroupCreate(key, group, start)
+}
+
+func (cs clusterStore) Xlen(key string) (int64, error) {
+	node, err := cs.getRedis(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return node.Xlen(key)
+}
+
+func (cs clusterStore) Xpending(key, group string) (*redis.XPending, error) {
+	node, err := cs.getRedis(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.Xpending(key, group)
+}
+
+func (cs clusterStore) XpendingExt(key, group, start, end string, count int64, consumer ...string) (
+	[]redis.XPendingExt, error) {
+	node, err := cs.getRedis(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.XpendingExt(key, group, start, end, count, consumer...)
+}
+
+func (cs clusterStore) Xreadgroup(key, group, consumer, id string, count int64, block time.Duration) (
+	[]redis.XMessage, error) {
+	node, err := cs.getRedis(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.Xreadgroup(key, group, consumer, id, count, block)
+}
+
 func (cs clusterStore) Zadd(key string, score int64, value string) (bool, error) {
 	node, err := cs.getRedis(key)
 	if err != nil {
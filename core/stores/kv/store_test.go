@@ -0,0 +1,70 @@
+package kv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/lukebull/go-zero-extern/core/stores/cache"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+)
+
+func TestStoreStream(t *testing.T) {
+	var conf KvConf
+	for i := 0; i < 2; i++ {
+		mr, err := miniredis.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer mr.Close()
+
+		conf = append(conf, cache.NodeConf{
+			RedisConf: redis.RedisConf{
+				Host: mr.Addr(),
+				Type: redis.NodeType,
+			},
+			Weight: 100,
+		})
+	}
+
+	store := NewStore(conf)
+	for _, stream := range []string{"s1", "s2", "s3"} {
+		if err := store.XgroupCreate(stream, "group", "0"); err != nil {
+			t.Fatal(err)
+		}
+
+		id, err := store.XaddWithMaxLen(stream, 10, map[string]interface{}{"k": stream})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = store.Xadd(stream, map[string]interface{}{"k": "other"}); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := store.Xlen(stream); err != nil || n != 2 {
+			t.Fatalf("expected 2 messages in %s, got %d, %v", stream, n, err)
+		}
+
+		msgs, err := store.Xreadgroup(stream, "group", "c1", ">", 1, time.Millisecond)
+		if err != nil || len(msgs) != 1 || msgs[0].ID != id || msgs[0].Values["k"] != stream {
+			t.Fatalf("unexpected messages in %s: %+v, %v", stream, msgs, err)
+		}
+
+		pending, err := store.Xpending(stream, "group")
+		if err != nil || pending.Count != 1 {
+			t.Fatalf("expected 1 pending in %s, got %+v, %v", stream, pending, err)
+		}
+		exts, err := store.XpendingExt(stream, "group", "-", "+", 10)
+		if err != nil || len(exts) != 1 || exts[0].Consumer != "c1" {
+			t.Fatalf("unexpected pending in %s: %+v, %v", stream, exts, err)
+		}
+		if msgs, err = store.Xclaim(stream, "group", "c2", 0, id); err != nil || len(msgs) != 1 {
+			t.Fatalf("expected claimed in %s, got %+v, %v", stream, msgs, err)
+		}
+		if n, err := store.Xack(stream, "group", id); err != nil || n != 1 {
+			t.Fatalf("expected acked in %s, got %d, %v", stream, n, err)
+		}
+		if n, err := store.Xdel(stream, id); err != nil || n != 1 {
+			t.Fatalf("expected deleted in %s, got %d, %v", stream, n, err)
+		}
+	}
+}
@@ -9,15 +9,24 @@ var (
 	ErrEmptyType = errors.New("empty redis type")
 	// ErrEmptyKey is an error that indicates no redis key is set.
 	ErrEmptyKey = errors.New("empty redis key")
+	// ErrEmptyMasterName is an error that indicates no master name is set on sentinel type.
+	ErrEmptyMasterName = errors.New("empty redis master name")
 )
 
 type (
 	// A RedisConf is a redis config.
 	RedisConf struct {
+		// Host is the comma separated sentinel addresses on sentinel type.
 		Host string
-		Type string `json:",default=node,options=node|cluster"`
+		Type string `json:",default=node,options=node|cluster|sentinel"`
 		Pass string `json:",optional"`
 		Tls  bool   `json:",default=false,options=true|false"`
+		// MasterName is the name of the master that the sentinels monitor.
+		MasterName string `json:",optional"`
+		// ReadReplicas routes the read-only commands to replicas on sentinel and cluster types.
+		ReadReplicas bool `json:",default=false,options=true|false"`
+		// Probe registers a readiness probe, only for the redis that the process can't serve without.
+		Probe bool `json:",default=false,options=true|false"`
 	}
 
 	// A RedisKeyConf is a redis config with key.
@@ -30,8 +39,14 @@ type (
 // NewRedis returns a Redis.
 func (rc RedisConf) NewRedis() *Redis {
 	var opts []Option
-	if rc.Type == ClusterType {
+	switch rc.Type {
+	case ClusterType:
 		opts = append(opts, Cluster())
+	case SentinelType:
+		opts = append(opts, Sentinel(rc.MasterName))
+	}
+	if rc.ReadReplicas {
+		opts = append(opts, WithReadReplicas())
 	}
 	if len(rc.Pass) > 0 {
 		opts = append(opts, WithPass(rc.Pass))
@@ -39,6 +54,9 @@ func (rc RedisConf) NewRedis() *Redis {
 	if rc.Tls {
 		opts = append(opts, WithTLS())
 	}
+	if rc.Probe {
+		opts = append(opts, WithReadinessProbe())
+	}
 
 	return New(rc.Host, opts...)
 }
@@ -53,6 +71,10 @@ func (rc RedisConf) Validate() error {
 		return ErrEmptyType
 	}
 
+	if rc.Type == SentinelType && len(rc.MasterName) == 0 {
+		return ErrEmptyMasterName
+	}
+
 	return nil
 }
 
@@ -1,14 +1,18 @@
 package redis
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	red "github.com/go-redis/redis"
 	"github.com/lukebull/go-zero-extern/core/breaker"
+	"github.com/lukebull/go-zero-extern/core/health"
 	"github.com/lukebull/go-zero-extern/core/mapping"
+	"github.com/lukebull/go-zero-extern/core/threading"
 )
 
 const (
@@ -16,6 +20,8 @@ const (
 	ClusterType = "cluster"
 	// NodeType means redis node.
 	NodeType = "node"
+	// SentinelType means redis master/replicas behind sentinels.
+	SentinelType = "sentinel"
 	// Nil is an alias of redis.Nil.
 	Nil = red.Nil
 
@@ -23,6 +29,8 @@ const (
 	readWriteTimeout     = 2 * time.Second
 
 	slowThreshold = time.Millisecond * 100
+
+	redisProbePrefix = "redis:"
 )
 
 // ErrNilNode is an error that indicates a nil redis node.
@@ -45,6 +53,10 @@ type (
 		Pass string
 		tls  bool
 		brk  breaker.Breaker
+		// master is the master name monitored by the sentinels.
+		master       string
+		readReplicas bool
+		probe        bool
 	}
 
 	// RedisNode interface represents a redis node.
@@ -71,6 +83,16 @@ type (
 	IntCmd = red.IntCmd
 	// FloatCmd is an alias of redis.FloatCmd.
 	FloatCmd = red.FloatCmd
+
+	// PubSub is an alias of redis.PubSub, a subscription of channels.
+	PubSub = red.PubSub
+
+	// XMessage is an alias of redis.XMessage, a message in redis stream.
+	XMessage = red.XMessage
+	// XPending is an alias of redis.XPending, the summary of the pending messages.
+	XPending = red.XPending
+	// XPendingExt is an alias of redis.XPendingExt, the detail of a pending message.
+	XPendingExt = red.XPendingExt
 )
 
 // New returns a Redis with given options.
@@ -84,6 +106,9 @@ func New(addr string, opts ...Option) *Redis {
 	for _, opt := range opts {
 		opt(r)
 	}
+	if r.probe {
+		health.AddReadinessProbe(redisProbePrefix+r.Addr, r.ping)
+	}
 
 	return r
 }
@@ -935,6 +960,27 @@ func (s *Redis) Ping() (val bool) {
 	return
 }
 
+// ping pings the redis without the breaker to report the errors, returns on ctx done,
+// because the commands can't be canceled by ctx.
+func (s *Redis) ping(ctx context.Context) error {
+	conn, err := getRedis(s)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	threading.GoSafe(func() {
+		done <- conn.Ping().Err()
+	})
+
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Pipelined lets fn to execute pipelined commands.
 func (s *Redis) Pipelined(fn func(Pipeliner) error) (err error) {
 	err = s.brk.DoWithAcceptable(func() error {
@@ -950,6 +996,27 @@ func (s *Redis) Pipelined(fn func(Pipeliner) error) (err error) {
 	return
 }
 
+// Publish is the implementation of redis publish command,
+// it returns the number of the subscribers that received the message.
+func (s *Redis) Publish(channel, message string) (val int, err error) {
+	err = s.brk.DoWithAcceptable(func() error {
+		conn, err := getRedis(s)
+		if err != nil {
+			return err
+		}
+
+		v, err := conn.Publish(channel, message).Result()
+		if err != nil {
+			return err
+		}
+
+		val = int(v)
+		return nil
+	}, acceptable)
+
+	return
+}
+
 // Rpop is the implementation of redis rpop command.
 func (s *Redis) Rpop(key string) (val string, err error) {
 	err = s.brk.DoWithAcceptable(func() error {
@@ -1317,6 +1384,31 @@ func (s *Redis) Sinterstore(destination string, keys ...string) (val int, err er
 	return
 }
 
+// Subscribe subscribes the channels, the messages are received from PubSub.Channel,
+// which reconnects automatically. The caller should close the PubSub after use.
+func (s *Redis) Subscribe(channels ...string) (*PubSub, error) {
+	conn, err := getRedis(s)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, ok := conn.(interface {
+		Subscribe(channels ...string) *red.PubSub
+	})
+	if !ok {
+		return nil, fmt.Errorf("redis type '%s' doesn't support subscribe", s.Type)
+	}
+
+	pubsub := sub.Subscribe(channels...)
+	// waits for the confirmation, so that the messages published after returning are received.
+	if _, err = pubsub.Receive(); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	return pubsub, nil
+}
+
 // Ttl is the implementation of redis ttl command.
 func (s *Redis) Ttl(key string) (val int, err error) {
 	err = s.brk.DoWithAcceptable(func() error {
@@ -1337,6 +1429,201 @@ func (s *Redis) Ttl(key string) (val int, err error) {
 	return
 }
 
+// Xack is the implementation of redis xack command.
+func (s *Redis) Xack(stream, group string, ids ...string) (val int64, err error) {
+	err = s.brk.DoWithAcceptable(func() error {
+		conn, err := getRedis(s)
+		if err != nil {
+			return err
+		}
+
+		val, err = conn.XAck(stream, group, ids...).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// Xadd is the implementation of redis xadd command, returns the id of the added message.
+func (s *Redis) Xadd(stream string, values map[string]interface{}) (string, error) {
+	return s.XaddWithMaxLen(stream, 0, values)
+}
+
+// XaddWithMaxLen is the implementation of redis xadd command with MAXLEN ~ maxLen,
+// the stream is trimmed approximately to maxLen, no trimming if maxLen <= 0.
+func (s *Redis) XaddWithMaxLen(stream string, maxLen int64, values map[string]interface{}) (
+	val string, err error) {
+	err = s.brk.DoWithAcceptable(func() error {
+		conn, err := getRedis(s)
+		if err != nil {
+			return err
+		}
+
+		val, err = conn.XAdd(&red.XAddArgs{
+			Stream:       stream,
+			MaxLenApprox: maxLen,
+			Values:       values,
+		}).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// Xclaim is the implementation of redis xclaim command, claims the pending messages
+// that have been idle for at least minIdle to consumer.
+func (s *Redis) Xclaim(stream, group, consumer string, minIdle time.Duration, ids ...string) (
+	val []XMessage, err error) {
+	err = s.brk.DoWithAcceptable(func() error {
+		conn, err := getRedis(s)
+		if err != nil {
+			return err
+		}
+
+		val, err = conn.XClaim(&red.XClaimArgs{
+			Stream:   stream,
+			Group:    group,
+			Consumer: consumer,
+			MinIdle:  minIdle,
+			Messages: ids,
+		}).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// Xdel is the implementation of redis xdel command.
+func (s *Redis) Xdel(stream string, ids ...string) (val int64, err error) {
+	err = s.brk.DoWithAcceptable(func() error {
+		conn, err := getRedis(s)
+		if err != nil {
+			return err
+		}
+
+		val, err = conn.XDel(stream, ids...).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XgroupCreate creates the consumer group on stream, the stream is created if not exists.
+// start is the id that the group starts from, 0 for the beginning, $ for the new messages.
+// It's not an error if the group already exists.
+func (s *Redis) XgroupCreate(stream, group, start string) error {
+	return s.brk.DoWithAcceptable(func() error {
+		conn, err := getRedis(s)
+		if err != nil {
+			return err
+		}
+
+		err = conn.XGroupCreateMkStream(stream, group, start).Err()
+		if isBusyGroup(err) {
+			return nil
+		}
+
+		return err
+	}, acceptable)
+}
+
+// Xlen is the implementation of redis xlen command.
+func (s *Redis) Xlen(stream string) (val int64, err error) {
+	err = s.brk.DoWithAcceptable(func() error {
+		conn, err := getRedis(s)
+		if err != nil {
+			return err
+		}
+
+		val, err = conn.XLen(stream).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// Xpending is the implementation of redis xpending command, returns the summary
+// of the pending messages of group.
+func (s *Redis) Xpending(stream, group string) (val *XPending, err error) {
+	err = s.brk.DoWithAcceptable(func() error {
+		conn, err := getRedis(s)
+		if err != nil {
+			return err
+		}
+
+		val, err = conn.XPending(stream, group).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// XpendingExt is the implementation of redis xpending command with range,
+// returns at most count pending messages between start and end, - and + for all.
+// If consumer is given, only the messages pending on it are returned.
+func (s *Redis) XpendingExt(stream, group, start, end string, count int64, consumer ...string) (
+	val []XPendingExt, err error) {
+	err = s.brk.DoWithAcceptable(func() error {
+		conn, err := getRedis(s)
+		if err != nil {
+			return err
+		}
+
+		args := &red.XPendingExtArgs{
+			Stream: stream,
+			Group:  group,
+			Start:  start,
+			End:    end,
+			Count:  count,
+		}
+		if len(consumer) > 0 {
+			args.Consumer = consumer[0]
+		}
+		val, err = conn.XPendingExt(args).Result()
+		return err
+	}, acceptable)
+
+	return
+}
+
+// Xreadgroup is the implementation of redis xreadgroup command on one stream.
+// id is > for the messages never delivered, or the id to read the pending messages
+// of consumer after. It blocks at most block if no messages, doesn't block if block <= 0.
+func (s *Redis) Xreadgroup(stream, group, consumer, id string, count int64, block time.Duration) (
+	val []XMessage, err error) {
+	if block <= 0 {
+		// go-redis blocks forever on zero block.
+		block = -1
+	}
+
+	err = s.brk.DoWithAcceptable(func() error {
+		conn, err := getRedis(s)
+		if err != nil {
+			return err
+		}
+
+		streams, err := conn.XReadGroup(&red.XReadGroupArgs{
+			Group:    group,
+			Consumer: consumer,
+			Streams:  []string{stream, id},
+			Count:    count,
+			Block:    block,
+		}).Result()
+		if err == red.Nil {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		for _, item := range streams {
+			val = append(val, item.Messages...)
+		}
+		return nil
+	}, acceptable)
+
+	return
+}
+
 // Zadd is the implementation of redis zadd command.
 func (s *Redis) Zadd(key string, score int64, value string) (val bool, err error) {
 	err = s.brk.DoWithAcceptable(func() error {
@@ -1755,6 +2042,23 @@ func Cluster() Option {
 	}
 }
 
+// Sentinel customizes the given Redis as a sentinel monitored master named masterName,
+// the Addr of the Redis is the comma separated sentinel addresses.
+func Sentinel(masterName string) Option {
+	return func(r *Redis) {
+		r.Type = SentinelType
+		r.master = masterName
+	}
+}
+
+// WithReadReplicas customizes the given Redis to route the read-only commands to replicas.
+// Only works with sentinel and cluster types.
+func WithReadReplicas() Option {
+	return func(r *Redis) {
+		r.readReplicas = true
+	}
+}
+
 // WithPass customizes the given Redis with given password.
 func WithPass(pass string) Option {
 	return func(r *Redis) {
@@ -1762,6 +2066,15 @@ func WithPass(pass string) Option {
 	}
 }
 
+// WithReadinessProbe customizes the given Redis to register a readiness probe, then the process
+// is unready if the redis is unreachable. Don't use it on the redis that the process can serve
+// without, like caches with stale values or rate limiters with local fallbacks.
+func WithReadinessProbe() Option {
+	return func(r *Redis) {
+		r.probe = true
+	}
+}
+
 // WithTLS customizes the given Redis with TLS enabled.
 func WithTLS() Option {
 	return func(r *Redis) {
@@ -1769,6 +2082,10 @@ func WithTLS() Option {
 	}
 }
 
+func isBusyGroup(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP")
+}
+
 func acceptable(err error) bool {
 	return err == nil || err == red.Nil
 }
@@ -1779,11 +2096,27 @@ func getRedis(r *Redis) (RedisNode, error) {
 		return getCluster(r)
 	case NodeType:
 		return getClient(r)
+	case SentinelType:
+		return getSentinel(r)
 	default:
 		return nil, fmt.Errorf("redis type '%s' is not supported", r.Type)
 	}
 }
 
+// resourceKey returns the key to share the clients, the Redis with different routing
+// or master can't share the same clients.
+func resourceKey(r *Redis) string {
+	key := r.Addr
+	if len(r.master) > 0 {
+		key = r.master + "@" + key
+	}
+	if r.readReplicas {
+		key += "#replicas"
+	}
+
+	return key
+}
+
 func toPairs(vals []red.Z) []Pair {
 	pairs := make([]Pair, len(vals))
 	for i, val := range vals {
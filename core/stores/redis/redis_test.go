@@ -0,0 +1,108 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/health"
+)
+
+func TestRedisReadinessProbe(t *testing.T) {
+	mr := runMiniredis(t)
+	store := New(mr.Addr(), WithReadinessProbe())
+	name := redisProbePrefix + store.Addr
+	defer health.RemoveProbe(name)
+
+	report := health.CheckReadiness(context.Background())
+	if check := report.Checks[name]; check.Status != health.StatusUp {
+		t.Fatalf("expected redis probe up, got %+v", check)
+	}
+
+	mr.Close()
+	report = health.CheckReadiness(context.Background())
+	if check := report.Checks[name]; check.Status != health.StatusDown || len(check.Error) == 0 {
+		t.Fatalf("expected redis probe down, got %+v", check)
+	}
+	if report.Status != health.StatusDown {
+		t.Fatalf("expected readiness down, got %s", report.Status)
+	}
+}
+
+func TestRedisReadinessProbeOptIn(t *testing.T) {
+	mr := runMiniredis(t)
+	defer mr.Close()
+
+	store := New(mr.Addr())
+	report := health.CheckReadiness(context.Background())
+	if _, ok := report.Checks[redisProbePrefix+store.Addr]; ok {
+		t.Fatal("expected no redis probe without opt-in")
+	}
+
+	store = RedisConf{Host: mr.Addr(), Type: NodeType, Probe: true}.NewRedis()
+	defer health.RemoveProbe(redisProbePrefix + store.Addr)
+	report = health.CheckReadiness(context.Background())
+	if _, ok := report.Checks[redisProbePrefix+store.Addr]; !ok {
+		t.Fatal("expected redis probe on Probe config")
+	}
+}
+
+func TestRedisReadinessProbeTimeout(t *testing.T) {
+	// accepts the connections but never responds.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	store := New(listener.Addr().String())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	start := time.Now()
+	if err := store.ping(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the probe returns on ctx done, took %v", elapsed)
+	}
+}
+
+func TestRedisPublishSubscribe(t *testing.T) {
+	mr := runMiniredis(t)
+	defer mr.Close()
+
+	store := New(mr.Addr())
+	pubsub, err := store.Subscribe("events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pubsub.Close()
+
+	n, err := store.Publish("events", "hello")
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 subscriber, got %d, %v", n, err)
+	}
+
+	select {
+	case msg := <-pubsub.Channel():
+		if msg.Channel != "events" || msg.Payload != "hello" {
+			t.Fatalf("unexpected message: %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected message received")
+	}
+
+	if n, err = store.Publish("nobody", "hello"); err != nil || n != 0 {
+		t.Fatalf("expected no subscribers, got %d, %v", n, err)
+	}
+}
@@ -39,6 +39,13 @@ func CreateBlockingNode(r *Redis) (ClosableNode, error) {
 			ReadTimeout:  timeout,
 		})
 		return &clusterBridge{client}, nil
+	case SentinelType:
+		opt := newFailoverOptions(r)
+		opt.PoolSize = 1
+		opt.MinIdleConns = 1
+		opt.ReadTimeout = timeout
+		client := red.NewFailoverClient(opt)
+		return &clientBridge{client}, nil
 	default:
 		return nil, fmt.Errorf("unknown redis type: %s", r.Type)
 	}
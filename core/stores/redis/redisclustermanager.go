@@ -11,7 +11,7 @@ import (
 var clusterManager = syncx.NewResourceManager()
 
 func getCluster(r *Redis) (*red.ClusterClient, error) {
-	val, err := clusterManager.GetResource(r.Addr, func() (io.Closer, error) {
+	val, err := clusterManager.GetResource(resourceKey(r), func() (io.Closer, error) {
 		var tlsConfig *tls.Config
 		if r.tls {
 			tlsConfig = &tls.Config{
@@ -24,6 +24,7 @@ func getCluster(r *Redis) (*red.ClusterClient, error) {
 			MaxRetries:   maxRetries,
 			MinIdleConns: idleConns,
 			TLSConfig:    tlsConfig,
+			ReadOnly:     r.readReplicas,
 		})
 		store.WrapProcess(process)
 
@@ -0,0 +1,266 @@
+package redis
+
+import (
+	"crypto/tls"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	red "github.com/go-redis/redis"
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/core/syncx"
+	"github.com/lukebull/go-zero-extern/core/threading"
+	"github.com/lukebull/go-zero-extern/core/timex"
+)
+
+var (
+	replicaRefreshInterval = time.Second * 10
+
+	// readOnlyCommands are the commands that can be routed to replicas.
+	readOnlyCommands = map[string]bool{
+		"bitcount":         true,
+		"bitpos":           true,
+		"exists":           true,
+		"geodist":          true,
+		"geohash":          true,
+		"geopos":           true,
+		"georadius_ro":     true,
+		"get":              true,
+		"getbit":           true,
+		"getrange":         true,
+		"hexists":          true,
+		"hget":             true,
+		"hgetall":          true,
+		"hkeys":            true,
+		"hlen":             true,
+		"hmget":            true,
+		"hscan":            true,
+		"hvals":            true,
+		"lindex":           true,
+		"llen":             true,
+		"lrange":           true,
+		"mget":             true,
+		"pfcount":          true,
+		"pttl":             true,
+		"scan":             true,
+		"scard":            true,
+		"sismember":        true,
+		"smembers":         true,
+		"srandmember":      true,
+		"sscan":            true,
+		"strlen":           true,
+		"ttl":              true,
+		"type":             true,
+		"zcard":            true,
+		"zcount":           true,
+		"zrange":           true,
+		"zrangebylex":      true,
+		"zrangebyscore":    true,
+		"zrank":            true,
+		"zrevrange":        true,
+		"zrevrangebyscore": true,
+		"zrevrank":         true,
+		"zscan":            true,
+		"zscore":           true,
+	}
+)
+
+// A replicaSet routes the read-only commands to the replicas that the sentinels report.
+type replicaSet struct {
+	r          *Redis
+	sentinels  []string
+	lock       sync.RWMutex
+	clients    map[string]*red.Client
+	addrs      []string
+	index      uint64
+	refreshed  time.Duration
+	refreshing *syncx.AtomicBool
+}
+
+func newReplicaSet(r *Redis) *replicaSet {
+	rs := &replicaSet{
+		r:          r,
+		sentinels:  splitSentinels(r.Addr),
+		clients:    make(map[string]*red.Client),
+		refreshing: syncx.NewAtomicBool(),
+	}
+	rs.refresh()
+
+	return rs
+}
+
+func (rs *replicaSet) close() {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	for addr, client := range rs.clients {
+		if err := client.Close(); err != nil {
+			logx.Errorf("Error occurred on close redis replica %s: %s", addr, err)
+		}
+	}
+	rs.clients = make(map[string]*red.Client)
+	rs.addrs = nil
+}
+
+// pick returns a replica in round robin, nil if no replicas available.
+func (rs *replicaSet) pick() *red.Client {
+	rs.lock.RLock()
+	refreshed := rs.refreshed
+	var client *red.Client
+	if len(rs.addrs) > 0 {
+		index := atomic.AddUint64(&rs.index, 1)
+		client = rs.clients[rs.addrs[index%uint64(len(rs.addrs))]]
+	}
+	rs.lock.RUnlock()
+
+	if timex.Since(refreshed) > replicaRefreshInterval && rs.refreshing.CompareAndSwap(false, true) {
+		threading.GoSafe(func() {
+			defer rs.refreshing.Set(false)
+			rs.refresh()
+		})
+	}
+
+	return client
+}
+
+func (rs *replicaSet) refresh() {
+	addrs, err := rs.discover()
+	if err != nil {
+		logx.Errorf("redis sentinel: failed to discover replicas of %s: %s", rs.r.master, err)
+	}
+
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	rs.refreshed = timex.Now()
+	if err != nil {
+		return
+	}
+
+	available := make(map[string]bool, len(addrs))
+	for _, addr := range addrs {
+		available[addr] = true
+		if _, ok := rs.clients[addr]; !ok {
+			rs.clients[addr] = rs.newClient(addr)
+		}
+	}
+	for addr, client := range rs.clients {
+		if available[addr] {
+			continue
+		}
+
+		delete(rs.clients, addr)
+		if err := client.Close(); err != nil {
+			logx.Errorf("Error occurred on close redis replica %s: %s", addr, err)
+		}
+	}
+	rs.addrs = addrs
+}
+
+func (rs *replicaSet) discover() ([]string, error) {
+	var lastErr error
+	for _, sentinel := range rs.sentinels {
+		client := red.NewSentinelClient(&red.Options{
+			Addr:       sentinel,
+			MaxRetries: maxRetries,
+			TLSConfig:  rs.tlsConfig(),
+		})
+		cmd := red.NewSliceCmd("sentinel", "slaves", rs.r.master)
+		err := client.Process(cmd)
+		if closeErr := client.Close(); closeErr != nil {
+			logx.Errorf("Error occurred on close redis sentinel %s: %s", sentinel, closeErr)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		return parseReplicas(cmd.Val()), nil
+	}
+
+	return nil, lastErr
+}
+
+func (rs *replicaSet) newClient(addr string) *red.Client {
+	client := red.NewClient(&red.Options{
+		Addr:         addr,
+		Password:     rs.r.Pass,
+		DB:           defaultDatabase,
+		MaxRetries:   maxRetries,
+		MinIdleConns: idleConns,
+		TLSConfig:    rs.tlsConfig(),
+	})
+	client.WrapProcess(process)
+
+	return client
+}
+
+// route routes the read-only commands to replicas, falls back to master if no replicas
+// available or the replica fails.
+func (rs *replicaSet) route(proc func(red.Cmder) error) func(red.Cmder) error {
+	return func(cmd red.Cmder) error {
+		if !readOnlyCommands[cmd.Name()] {
+			return proc(cmd)
+		}
+
+		client := rs.pick()
+		if client == nil {
+			return proc(cmd)
+		}
+
+		err := client.Process(cmd)
+		if err == nil || err == red.Nil {
+			return err
+		}
+
+		logx.Errorf("redis replica %s failed on %s, fallback to master: %s",
+			client.Options().Addr, cmd.Name(), err)
+		return proc(cmd)
+	}
+}
+
+func (rs *replicaSet) tlsConfig() *tls.Config {
+	if !rs.r.tls {
+		return nil
+	}
+
+	return &tls.Config{
+		InsecureSkipVerify: true,
+	}
+}
+
+// parseReplicas parses the reply of SENTINEL SLAVES, the replicas that are down
+// or disconnected from master are skipped.
+func parseReplicas(vals []interface{}) []string {
+	var addrs []string
+	for _, val := range vals {
+		fields, ok := val.([]interface{})
+		if !ok {
+			continue
+		}
+
+		info := make(map[string]string, len(fields)/2)
+		for i := 0; i+1 < len(fields); i += 2 {
+			key, _ := fields[i].(string)
+			value, _ := fields[i+1].(string)
+			info[key] = value
+		}
+
+		if strings.Contains(info["flags"], "s_down") || strings.Contains(info["flags"], "o_down") ||
+			strings.Contains(info["flags"], "disconnected") {
+			continue
+		}
+		if status, ok := info["master-link-status"]; ok && status != "ok" {
+			continue
+		}
+		if len(info["ip"]) == 0 || len(info["port"]) == 0 {
+			continue
+		}
+
+		addrs = append(addrs, net.JoinHostPort(info["ip"], info["port"]))
+	}
+
+	return addrs
+}
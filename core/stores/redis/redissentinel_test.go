@@ -0,0 +1,424 @@
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+const testMasterName = "mymaster"
+
+type (
+	fakeReplica struct {
+		addr  string
+		flags string
+	}
+
+	// fakeSentinel speaks the subset of the sentinel protocol that go-redis
+	// and the replica discovery use, with miniredis instances as master and replicas.
+	fakeSentinel struct {
+		listener    net.Listener
+		lock        sync.Mutex
+		master      string
+		replicas    []fakeReplica
+		subscribers []net.Conn
+		conns       []net.Conn
+	}
+)
+
+func newFakeSentinel(t *testing.T, master string) *fakeSentinel {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &fakeSentinel{
+		listener: listener,
+		master:   master,
+	}
+	go s.serve()
+
+	return s
+}
+
+func (s *fakeSentinel) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeSentinel) Close() {
+	_ = s.listener.Close()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, conn := range s.conns {
+		_ = conn.Close()
+	}
+}
+
+func (s *fakeSentinel) setReplicas(replicas ...fakeReplica) {
+	s.lock.Lock()
+	s.replicas = replicas
+	s.lock.Unlock()
+}
+
+func (s *fakeSentinel) switchMaster(addr string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	oldHost, oldPort, _ := net.SplitHostPort(s.master)
+	newHost, newPort, _ := net.SplitHostPort(addr)
+	s.master = addr
+	payload := strings.Join([]string{testMasterName, oldHost, oldPort, newHost, newPort}, " ")
+	for _, conn := range s.subscribers {
+		_, _ = io.WriteString(conn, encodeArray("message", "+switch-master", payload))
+	}
+}
+
+func (s *fakeSentinel) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.lock.Lock()
+		s.conns = append(s.conns, conn)
+		s.lock.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSentinel) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	var subscribed bool
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		s.lock.Lock()
+		reply := s.reply(conn, args, &subscribed)
+		s.lock.Unlock()
+		if _, err = io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeSentinel) reply(conn net.Conn, args []string, subscribed *bool) string {
+	switch strings.ToLower(args[0]) {
+	case "ping":
+		if *subscribed {
+			return encodeArray("pong", "")
+		}
+		return "+PONG\r\n"
+	case "subscribe":
+		*subscribed = true
+		s.subscribers = append(s.subscribers, conn)
+		return "*3\r\n" + encodeBulk("subscribe") + encodeBulk(args[1]) + ":1\r\n"
+	case "sentinel":
+		if len(args) < 3 || args[2] != testMasterName {
+			return "*-1\r\n"
+		}
+
+		switch strings.ToLower(args[1]) {
+		case "get-master-addr-by-name":
+			host, port, _ := net.SplitHostPort(s.master)
+			return encodeArray(host, port)
+		case "sentinels":
+			return "*0\r\n"
+		case "slaves", "replicas":
+			reply := fmt.Sprintf("*%d\r\n", len(s.replicas))
+			for _, replica := range s.replicas {
+				host, port, _ := net.SplitHostPort(replica.addr)
+				reply += encodeArray("ip", host, "port", port, "flags", replica.flags,
+					"master-link-status", "ok")
+			}
+			return reply
+		}
+	}
+
+	return "-ERR unknown command\r\n"
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil || line[0] != '*' || n <= 0 {
+		return nil, fmt.Errorf("bad command: %q", line)
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+
+		buf := make([]byte, size+2)
+		if _, err = io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+
+	return args, nil
+}
+
+func encodeBulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func encodeArray(vals ...string) string {
+	reply := fmt.Sprintf("*%d\r\n", len(vals))
+	for _, val := range vals {
+		reply += encodeBulk(val)
+	}
+
+	return reply
+}
+
+func runMiniredis(t *testing.T) *miniredis.Miniredis {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mr
+}
+
+func TestSentinel(t *testing.T) {
+	master := runMiniredis(t)
+	defer master.Close()
+	sentinel := newFakeSentinel(t, master.Addr())
+	defer sentinel.Close()
+
+	store := RedisConf{
+		Host:       "127.0.0.1:1, " + sentinel.Addr(),
+		Type:       SentinelType,
+		MasterName: testMasterName,
+	}.NewRedis()
+	if err := store.Set("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if val, err := master.Get("a"); err != nil || val != "b" {
+		t.Fatalf("expected the value written to master, got %q, %v", val, err)
+	}
+	if val, err := store.Get("a"); err != nil || val != "b" {
+		t.Fatalf("expected b, got %q, %v", val, err)
+	}
+	if !store.Ping() {
+		t.Fatal("ping failed")
+	}
+}
+
+func TestSentinelFailover(t *testing.T) {
+	master := runMiniredis(t)
+	defer master.Close()
+	replica := runMiniredis(t)
+	defer replica.Close()
+	sentinel := newFakeSentinel(t, master.Addr())
+	defer sentinel.Close()
+
+	store := New(sentinel.Addr(), Sentinel(testMasterName))
+	if err := store.Set("a", "master"); err != nil {
+		t.Fatal(err)
+	}
+
+	sentinel.switchMaster(replica.Addr())
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		if err := store.Set("a", "promoted"); err != nil {
+			t.Fatal(err)
+		}
+		if val, _ := replica.Get("a"); val == "promoted" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("writes didn't switch to the new master")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestSentinelReadReplicas(t *testing.T) {
+	old := replicaRefreshInterval
+	replicaRefreshInterval = 0
+	defer func() {
+		replicaRefreshInterval = old
+	}()
+
+	master := runMiniredis(t)
+	defer master.Close()
+	replica := runMiniredis(t)
+	defer replica.Close()
+	sentinel := newFakeSentinel(t, master.Addr())
+	defer sentinel.Close()
+	sentinel.setReplicas(fakeReplica{addr: replica.Addr(), flags: "slave"})
+
+	// the replica holds different values to tell where the reads go.
+	_ = master.Set("a", "master")
+	_ = replica.Set("a", "replica")
+	master.HSet("h", "f", "master")
+	replica.HSet("h", "f", "replica")
+	_, _ = replica.SetAdd("s", "replica")
+
+	store := New(sentinel.Addr(), Sentinel(testMasterName), WithReadReplicas())
+	if val, err := store.Get("a"); err != nil || val != "replica" {
+		t.Fatalf("expected get from replica, got %q, %v", val, err)
+	}
+	if val, err := store.Hget("h", "f"); err != nil || val != "replica" {
+		t.Fatalf("expected hget from replica, got %q, %v", val, err)
+	}
+	if vals, err := store.Smembers("s"); err != nil || len(vals) != 1 || vals[0] != "replica" {
+		t.Fatalf("expected smembers from replica, got %v, %v", vals, err)
+	}
+	if _, err := store.Get("missing"); err != nil {
+		t.Fatalf("missing keys on replicas should not fall back, got %v", err)
+	}
+
+	if err := store.Set("b", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := master.Get("b"); val != "c" {
+		t.Fatal("writes should go to master")
+	}
+	if replica.Exists("b") {
+		t.Fatal("writes should not go to replicas")
+	}
+	if val, err := store.Eval(`return redis.call("GET", KEYS[1])`, []string{"a"}); err != nil || val != "master" {
+		t.Fatalf("scripts should go to master, got %v, %v", val, err)
+	}
+
+	// the replica is down, reads fall back to master.
+	replica.Close()
+	if val, err := store.Get("a"); err != nil || val != "master" {
+		t.Fatalf("expected fallback to master, got %q, %v", val, err)
+	}
+}
+
+func TestSentinelReadReplicasRefresh(t *testing.T) {
+	old := replicaRefreshInterval
+	replicaRefreshInterval = 0
+	defer func() {
+		replicaRefreshInterval = old
+	}()
+
+	master := runMiniredis(t)
+	defer master.Close()
+	replica := runMiniredis(t)
+	defer replica.Close()
+	sentinel := newFakeSentinel(t, master.Addr())
+	defer sentinel.Close()
+	_ = master.Set("a", "master")
+	_ = replica.Set("a", "replica")
+
+	// no replicas yet, reads go to master.
+	store := New(sentinel.Addr(), Sentinel(testMasterName), WithReadReplicas())
+	if val, err := store.Get("a"); err != nil || val != "master" {
+		t.Fatalf("expected get from master, got %q, %v", val, err)
+	}
+
+	sentinel.setReplicas(fakeReplica{addr: replica.Addr(), flags: "slave"})
+	waitForValue(t, store, "a", "replica")
+
+	sentinel.setReplicas(fakeReplica{addr: replica.Addr(), flags: "slave,s_down"})
+	waitForValue(t, store, "a", "master")
+}
+
+func TestSentinelBlockingNode(t *testing.T) {
+	master := runMiniredis(t)
+	defer master.Close()
+	sentinel := newFakeSentinel(t, master.Addr())
+	defer sentinel.Close()
+
+	store := New(sentinel.Addr(), Sentinel(testMasterName))
+	node, err := CreateBlockingNode(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer node.Close()
+
+	if _, err = store.Lpush("list", "value"); err != nil {
+		t.Fatal(err)
+	}
+	if val, err := store.Blpop(node, "list"); err != nil || val != "value" {
+		t.Fatalf("expected value, got %q, %v", val, err)
+	}
+}
+
+func TestSentinelConf(t *testing.T) {
+	conf := RedisConf{
+		Host: "localhost:26379",
+		Type: SentinelType,
+	}
+	if err := conf.Validate(); err != ErrEmptyMasterName {
+		t.Fatalf("expected ErrEmptyMasterName, got %v", err)
+	}
+
+	conf.MasterName = testMasterName
+	conf.ReadReplicas = true
+	if err := conf.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	store := conf.NewRedis()
+	if store.Type != SentinelType || store.master != testMasterName || !store.readReplicas {
+		t.Fatalf("unexpected redis %+v", store)
+	}
+	if resourceKey(store) == resourceKey(New(conf.Host, Sentinel(testMasterName))) {
+		t.Fatal("redis with and without replica reads should not share clients")
+	}
+}
+
+func TestParseReplicas(t *testing.T) {
+	replica := func(ip, port, flags, status string) interface{} {
+		return []interface{}{"ip", ip, "port", port, "flags", flags, "master-link-status", status}
+	}
+
+	addrs := parseReplicas([]interface{}{
+		replica("10.0.0.1", "6379", "slave", "ok"),
+		replica("10.0.0.2", "6379", "slave,s_down", "ok"),
+		replica("10.0.0.3", "6379", "slave,disconnected", "ok"),
+		replica("10.0.0.4", "6379", "slave", "err"),
+		replica("", "6379", "slave", "ok"),
+		"bad",
+		replica("10.0.0.5", "6380", "slave", "ok"),
+	})
+	if strings.Join(addrs, ",") != "10.0.0.1:6379,10.0.0.5:6380" {
+		t.Fatalf("unexpected replicas %v", addrs)
+	}
+}
+
+func waitForValue(t *testing.T, store *Redis, key, expect string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		val, err := store.Get(key)
+		if err == nil && val == expect {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %q, got %q, %v", expect, val, err)
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
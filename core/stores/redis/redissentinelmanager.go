@@ -0,0 +1,78 @@
+package redis
+
+import (
+	"crypto/tls"
+	"io"
+	"strings"
+
+	red "github.com/go-redis/redis"
+	"github.com/lukebull/go-zero-extern/core/syncx"
+)
+
+var sentinelManager = syncx.NewResourceManager()
+
+type sentinelNode struct {
+	*red.Client
+	replicas *replicaSet
+}
+
+func (n *sentinelNode) Close() error {
+	if n.replicas != nil {
+		n.replicas.close()
+	}
+
+	return n.Client.Close()
+}
+
+func getSentinel(r *Redis) (*sentinelNode, error) {
+	val, err := sentinelManager.GetResource(resourceKey(r), func() (io.Closer, error) {
+		store := red.NewFailoverClient(newFailoverOptions(r))
+		store.WrapProcess(process)
+
+		node := &sentinelNode{
+			Client: store,
+		}
+		if r.readReplicas {
+			node.replicas = newReplicaSet(r)
+			// wrapped after process, so that the commands are routed before being processed.
+			store.WrapProcess(node.replicas.route)
+		}
+
+		return node, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return val.(*sentinelNode), nil
+}
+
+func newFailoverOptions(r *Redis) *red.FailoverOptions {
+	var tlsConfig *tls.Config
+	if r.tls {
+		tlsConfig = &tls.Config{
+			InsecureSkipVerify: true,
+		}
+	}
+
+	return &red.FailoverOptions{
+		MasterName:    r.master,
+		SentinelAddrs: splitSentinels(r.Addr),
+		Password:      r.Pass,
+		DB:            defaultDatabase,
+		MaxRetries:    maxRetries,
+		MinIdleConns:  idleConns,
+		TLSConfig:     tlsConfig,
+	}
+}
+
+func splitSentinels(addr string) []string {
+	var addrs []string
+	for _, item := range strings.Split(addr, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			addrs = append(addrs, item)
+		}
+	}
+
+	return addrs
+}
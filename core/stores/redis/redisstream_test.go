@@ -0,0 +1,123 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRedisStream(t *testing.T) {
+	mr := runMiniredis(t)
+	defer mr.Close()
+
+	store := New(mr.Addr())
+	const (
+		stream = "stream"
+		group  = "group"
+	)
+	if err := store.XgroupCreate(stream, group, "0"); err != nil {
+		t.Fatal(err)
+	}
+	// creating an existing group is not an error.
+	if err := store.XgroupCreate(stream, group, "0"); err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, val := range []string{"a", "b", "c"} {
+		id, err := store.Xadd(stream, map[string]interface{}{"v": val})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if n, err := store.Xlen(stream); err != nil || n != 3 {
+		t.Fatalf("expected 3 messages, got %d, %v", n, err)
+	}
+
+	msgs, err := store.Xreadgroup(stream, group, "c1", ">", 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 || msgs[0].ID != ids[0] || msgs[0].Values["v"] != "a" || msgs[1].Values["v"] != "b" {
+		t.Fatalf("unexpected messages %+v", msgs)
+	}
+
+	// no new messages, returns immediately without blocking.
+	if _, err = store.Xreadgroup(stream, group, "c2", ">", 10, 0); err != nil {
+		t.Fatal(err)
+	}
+	msgs, err = store.Xreadgroup(stream, group, "c2", ">", 10, time.Millisecond*10)
+	if err != nil || len(msgs) != 0 {
+		t.Fatalf("expected no messages, got %+v, %v", msgs, err)
+	}
+
+	// c1 rereads its pending messages.
+	msgs, err = store.Xreadgroup(stream, group, "c1", "0", 10, 0)
+	if err != nil || len(msgs) != 2 {
+		t.Fatalf("expected 2 pending messages, got %+v, %v", msgs, err)
+	}
+
+	pending, err := store.Xpending(stream, group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending.Count != 3 || pending.Consumers["c1"] != 2 || pending.Consumers["c2"] != 1 {
+		t.Fatalf("unexpected pending %+v", pending)
+	}
+
+	if n, err := store.Xack(stream, group, ids[0]); err != nil || n != 1 {
+		t.Fatalf("expected 1 acked, got %d, %v", n, err)
+	}
+
+	time.Sleep(time.Millisecond * 20)
+	exts, err := store.XpendingExt(stream, group, "-", "+", 10, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exts) != 1 || exts[0].Id != ids[1] || exts[0].Consumer != "c1" || exts[0].Idle < time.Millisecond*10 {
+		t.Fatalf("unexpected pending messages %+v", exts)
+	}
+
+	msgs, err = store.Xclaim(stream, group, "c2", time.Millisecond*10, ids[1])
+	if err != nil || len(msgs) != 1 || msgs[0].ID != ids[1] {
+		t.Fatalf("expected message claimed, got %+v, %v", msgs, err)
+	}
+	if exts, err = store.XpendingExt(stream, group, "-", "+", 10, "c2"); err != nil || len(exts) != 2 {
+		t.Fatalf("expected 2 messages pending on c2, got %+v, %v", exts, err)
+	}
+
+	if n, err := store.Xdel(stream, ids...); err != nil || n != 3 {
+		t.Fatalf("expected 3 deleted, got %d, %v", n, err)
+	}
+}
+
+func TestRedisStreamMaxLen(t *testing.T) {
+	mr := runMiniredis(t)
+	defer mr.Close()
+
+	store := New(mr.Addr())
+	for i := 0; i < 10; i++ {
+		if _, err := store.XaddWithMaxLen("stream", 5, map[string]interface{}{"i": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n, err := store.Xlen("stream"); err != nil || n > 5 {
+		t.Fatalf("expected the stream trimmed, got %d, %v", n, err)
+	}
+}
+
+func TestRedisStreamBadNode(t *testing.T) {
+	store := New("127.0.0.1:1", func(r *Redis) {
+		r.Type = "bad"
+	})
+	if _, err := store.Xadd("stream", map[string]interface{}{"a": "b"}); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := store.Xreadgroup("stream", "group", "c", ">", 1, 0); err == nil {
+		t.Fatal("expected error")
+	}
+	if err := store.XgroupCreate("stream", "group", "0"); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -1,9 +1,12 @@
 package sqlc
 
 import (
+	"context"
 	"database/sql"
+	"reflect"
 	"time"
 
+	"github.com/lukebull/go-zero-extern/core/contextx"
 	"github.com/lukebull/go-zero-extern/core/stores/cache"
 	"github.com/lukebull/go-zero-extern/core/stores/redis"
 	"github.com/lukebull/go-zero-extern/core/stores/sqlx"
@@ -25,12 +28,20 @@ var (
 type (
 	// ExecFn defines the sql exec method.
 	ExecFn func(conn sqlx.SqlConn) (sql.Result, error)
+	// ExecCtxFn defines the sql exec method with context.
+	ExecCtxFn func(ctx context.Context, conn sqlx.SqlConn) (sql.Result, error)
 	// IndexQueryFn defines the query method that based on unique indexes.
 	IndexQueryFn func(conn sqlx.SqlConn, v interface{}) (interface{}, error)
+	// IndexQueryCtxFn defines the query method that based on unique indexes with context.
+	IndexQueryCtxFn func(ctx context.Context, conn sqlx.SqlConn, v interface{}) (interface{}, error)
 	// PrimaryQueryFn defines the query method that based on primary keys.
 	PrimaryQueryFn func(conn sqlx.SqlConn, v, primary interface{}) error
+	// PrimaryQueryCtxFn defines the query method that based on primary keys with context.
+	PrimaryQueryCtxFn func(ctx context.Context, conn sqlx.SqlConn, v, primary interface{}) error
 	// QueryFn defines the query method.
 	QueryFn func(conn sqlx.SqlConn, v interface{}) error
+	// QueryCtxFn defines the query method with context.
+	QueryCtxFn func(ctx context.Context, conn sqlx.SqlConn, v interface{}) error
 
 	// A CachedConn is a DB connection with cache capability.
 	CachedConn struct {
@@ -55,6 +66,12 @@ func NewConn(db sqlx.SqlConn, c cache.CacheConf, opts ...cache.Option) CachedCon
 	}
 }
 
+// Close releases the resources of the cache, like the subscription of the local cache invalidations.
+// The db is not closed, because it might be shared.
+func (cc CachedConn) Close() error {
+	return cc.cache.Close()
+}
+
 // DelCache deletes cache with keys.
 func (cc CachedConn) DelCache(keys ...string) error {
 	return cc.cache.Del(keys...)
@@ -67,7 +84,14 @@ func (cc CachedConn) GetCache(key string, v interface{}) error {
 
 // Exec runs given exec on given keys, and returns execution result.
 func (cc CachedConn) Exec(exec ExecFn, keys ...string) (sql.Result, error) {
-	res, err := exec(cc.db)
+	return cc.ExecCtx(context.Background(), func(_ context.Context, conn sqlx.SqlConn) (sql.Result, error) {
+		return exec(conn)
+	}, keys...)
+}
+
+// ExecCtx runs given exec on given keys with ctx, and returns execution result.
+func (cc CachedConn) ExecCtx(ctx context.Context, exec ExecCtxFn, keys ...string) (sql.Result, error) {
+	res, err := exec(ctx, cc.db)
 	if err != nil {
 		return nil, err
 	}
@@ -81,30 +105,66 @@ func (cc CachedConn) Exec(exec ExecFn, keys ...string) (sql.Result, error) {
 
 // ExecNoCache runs exec with given sql statement, without affecting cache.
 func (cc CachedConn) ExecNoCache(q string, args ...interface{}) (sql.Result, error) {
-	return cc.db.Exec(q, args...)
+	return cc.ExecNoCacheCtx(context.Background(), q, args...)
+}
+
+// ExecNoCacheCtx runs exec with given sql statement and ctx, without affecting cache.
+func (cc CachedConn) ExecNoCacheCtx(ctx context.Context, q string, args ...interface{}) (sql.Result, error) {
+	return cc.db.ExecCtx(ctx, q, args...)
 }
 
 // QueryRow unmarshals into v with given key and query func.
 func (cc CachedConn) QueryRow(v interface{}, key string, query QueryFn) error {
-	return cc.cache.Take(v, key, func(v interface{}) error {
-		return query(cc.db, v)
+	return cc.QueryRowCtx(context.Background(), v, key, func(_ context.Context, conn sqlx.SqlConn,
+		v interface{}) error {
+		return query(conn, v)
+	})
+}
+
+// QueryRowCtx unmarshals into v with given key, ctx and query func.
+func (cc CachedConn) QueryRowCtx(ctx context.Context, v interface{}, key string, query QueryCtxFn) error {
+	return cc.cache.Take(v, key, func(val interface{}) error {
+		return query(queryContext(ctx, v, val), cc.db, val)
 	})
 }
 
 // QueryRowIndex unmarshals into v with given key.
 func (cc CachedConn) QueryRowIndex(v interface{}, key string, keyer func(primary interface{}) string,
 	indexQuery IndexQueryFn, primaryQuery PrimaryQueryFn) error {
+	return cc.QueryRowIndexCtx(context.Background(), v, key, keyer,
+		func(_ context.Context, conn sqlx.SqlConn, v interface{}) (interface{}, error) {
+			return indexQuery(conn, v)
+		}, func(_ context.Context, conn sqlx.SqlConn, v, primary interface{}) error {
+			return primaryQuery(conn, v, primary)
+		})
+}
+
+// QueryRowIndexCtx unmarshals into v with given key and ctx.
+func (cc CachedConn) QueryRowIndexCtx(ctx context.Context, v interface{}, key string,
+	keyer func(primary interface{}) string, indexQuery IndexQueryCtxFn,
+	primaryQuery PrimaryQueryCtxFn) error {
 	var primaryKey interface{}
 	var found bool
 
-	if err := cc.cache.TakeWithExpire(&primaryKey, key, func(val interface{}, expire time.Duration) (err error) {
-		primaryKey, err = indexQuery(cc.db, v)
+	if err := cc.cache.TakeWithExpire(&primaryKey, key, func(val interface{}, expire time.Duration) error {
+		// val is not &primaryKey on refreshing the stale index in background,
+		// the row is queried into a new value, to keep v of the returned call untouched.
+		row := v
+		refreshing := val != &primaryKey
+		if refreshing {
+			row = reflect.New(reflect.TypeOf(v).Elem()).Interface()
+		}
+
+		primary, err := indexQuery(queryContext(ctx, &primaryKey, val), cc.db, row)
 		if err != nil {
-			return
+			return err
 		}
 
-		found = true
-		return cc.cache.SetWithExpire(keyer(primaryKey), v, expire+cacheSafeGapBetweenIndexAndPrimary)
+		*val.(*interface{}) = primary
+		if !refreshing {
+			found = true
+		}
+		return cc.cache.SetWithExpire(keyer(primary), row, expire+cacheSafeGapBetweenIndexAndPrimary)
 	}); err != nil {
 		return err
 	}
@@ -113,20 +173,34 @@ func (cc CachedConn) QueryRowIndex(v interface{}, key string, keyer func(primary
 		return nil
 	}
 
-	return cc.cache.Take(v, keyer(primaryKey), func(v interface{}) error {
-		return primaryQuery(cc.db, v, primaryKey)
+	primary := primaryKey
+	return cc.cache.Take(v, keyer(primary), func(val interface{}) error {
+		return primaryQuery(queryContext(ctx, v, val), cc.db, val, primary)
 	})
 }
 
 // QueryRowNoCache unmarshals into v with given statement.
 func (cc CachedConn) QueryRowNoCache(v interface{}, q string, args ...interface{}) error {
-	return cc.db.QueryRow(v, q, args...)
+	return cc.QueryRowNoCacheCtx(context.Background(), v, q, args...)
+}
+
+// QueryRowNoCacheCtx unmarshals into v with given statement and ctx.
+func (cc CachedConn) QueryRowNoCacheCtx(ctx context.Context, v interface{}, q string,
+	args ...interface{}) error {
+	return cc.db.QueryRowCtx(ctx, v, q, args...)
 }
 
 // QueryRowsNoCache unmarshals into v with given statement.
 // It doesn't use cache, because it might cause consistency problem.
 func (cc CachedConn) QueryRowsNoCache(v interface{}, q string, args ...interface{}) error {
-	return cc.db.QueryRows(v, q, args...)
+	return cc.QueryRowsNoCacheCtx(context.Background(), v, q, args...)
+}
+
+// QueryRowsNoCacheCtx unmarshals into v with given statement and ctx.
+// It doesn't use cache, because it might cause consistency problem.
+func (cc CachedConn) QueryRowsNoCacheCtx(ctx context.Context, v interface{}, q string,
+	args ...interface{}) error {
+	return cc.db.QueryRowsCtx(ctx, v, q, args...)
 }
 
 // SetCache sets v into cache with given key.
@@ -138,3 +212,20 @@ func (cc CachedConn) SetCache(key string, v interface{}) error {
 func (cc CachedConn) Transact(fn func(sqlx.Session) error) error {
 	return cc.db.Transact(fn)
 }
+
+// TransactCtx runs given fn in transaction mode with ctx.
+func (cc CachedConn) TransactCtx(ctx context.Context, fn func(context.Context, sqlx.Session) error) error {
+	return cc.db.TransactCtx(ctx, fn)
+}
+
+// queryContext returns the context to query into val, which is detached from the cancellation
+// of ctx if val is not v, that means the cache is refreshed in background after the call returned.
+func queryContext(ctx context.Context, v, val interface{}) context.Context {
+	rv := reflect.ValueOf(v)
+	rval := reflect.ValueOf(val)
+	if rv.Kind() == reflect.Ptr && rval.Kind() == reflect.Ptr && rv.Pointer() == rval.Pointer() {
+		return ctx
+	}
+
+	return contextx.ValueOnlyFrom(ctx)
+}
@@ -0,0 +1,120 @@
+package sqlc
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/lukebull/go-zero-extern/core/stores/cache"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+	"github.com/lukebull/go-zero-extern/core/stores/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedConnCodec(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+
+	conn := NewConn(nil, cache.CacheConf{
+		{
+			RedisConf: redis.RedisConf{
+				Host: mr.Addr(),
+				Type: redis.NodeType,
+			},
+			Weight: 100,
+			Codec:  "msgpack",
+		},
+	})
+	type user struct {
+		Id   int64
+		Name string
+	}
+	assert.Nil(t, conn.SetCache("user", user{Id: 1, Name: "foo"}))
+	data, err := mr.Get("user")
+	assert.Nil(t, err)
+	assert.Equal(t, cache.MsgpackVersion, data[0])
+
+	var val user
+	assert.Nil(t, conn.GetCache("user", &val))
+	assert.Equal(t, user{Id: 1, Name: "foo"}, val)
+
+	conn = NewNodeConn(nil, redis.New(mr.Addr()), cache.WithCodec(cache.GzipCodec(cache.JsonCodec)))
+	assert.Nil(t, conn.SetCache("user", user{Id: 2}))
+	data, err = mr.Get("user")
+	assert.Nil(t, err)
+	assert.Equal(t, cache.GzipCodec(cache.JsonCodec).Version(), data[0])
+}
+
+func TestCachedConnQueryRowIndexStale(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+
+	type user struct {
+		Id   int64
+		Name string
+	}
+	var indexQueries, primaryQueries int32
+	var ctxErrs int32
+	conn := NewNodeConn(nil, redis.New(mr.Addr()), cache.WithExpiry(time.Second),
+		cache.WithStale(cache.StaleConf{
+			Grace: time.Minute,
+			Beta:  1,
+		}))
+	keyer := func(primary interface{}) string {
+		assert.NotNil(t, primary)
+		return fmt.Sprintf("user#%v", primary)
+	}
+	query := func(ctx context.Context) (user, error) {
+		var u user
+		err := conn.QueryRowIndexCtx(ctx, &u, "user#name#foo", keyer,
+			func(ctx context.Context, _ sqlx.SqlConn, v interface{}) (interface{}, error) {
+				if ctx.Err() != nil {
+					atomic.AddInt32(&ctxErrs, 1)
+				}
+				atomic.AddInt32(&indexQueries, 1)
+				*v.(*user) = user{Id: 1, Name: "foo"}
+				return int64(1), nil
+			}, func(ctx context.Context, _ sqlx.SqlConn, v, primary interface{}) error {
+				if ctx.Err() != nil {
+					atomic.AddInt32(&ctxErrs, 1)
+				}
+				atomic.AddInt32(&primaryQueries, 1)
+				*v.(*user) = user{Id: 1, Name: "foo"}
+				return nil
+			})
+		return u, err
+	}
+
+	u, err := query(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, user{Id: 1, Name: "foo"}, u)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&indexQueries))
+
+	// the logical expiry passed, served stale and refreshed in background.
+	time.Sleep(time.Millisecond * 1200)
+	ctx, cancel := context.WithCancel(context.Background())
+	u, err = query(ctx)
+	cancel()
+	assert.Nil(t, err)
+	assert.Equal(t, user{Id: 1, Name: "foo"}, u)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&indexQueries) == 2
+	}, time.Second, time.Millisecond*10)
+
+	var primary interface{}
+	assert.Eventually(t, func() bool {
+		return conn.GetCache("user#name#foo", &primary) == nil && primary != nil
+	}, time.Second, time.Millisecond*10)
+
+	u, err = query(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, user{Id: 1, Name: "foo"}, u)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&ctxErrs))
+	// the rows are cached by the index queries.
+	assert.Equal(t, int32(0), atomic.LoadInt32(&primaryQueries))
+}
@@ -0,0 +1,166 @@
+package sqlx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lukebull/go-zero-extern/core/breaker"
+	"github.com/lukebull/go-zero-extern/core/trace"
+	"github.com/lukebull/go-zero-extern/core/trace/tracespec"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockedBreaker struct {
+	breaker.Breaker
+	accepted []bool
+}
+
+func (b *mockedBreaker) DoWithAcceptable(req func() error, acceptable breaker.Acceptable) error {
+	err := req()
+	b.accepted = append(b.accepted, acceptable(err))
+	return err
+}
+
+func newMockedConn(t *testing.T, dsn string) (*commonSqlConn, sqlmock.Sqlmock, *mockedBreaker) {
+	_, mock, err := sqlmock.NewWithDSN(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	brk := &mockedBreaker{Breaker: breaker.NewBreaker()}
+	return &commonSqlConn{
+		driverName: "sqlmock",
+		datasource: dsn,
+		beginTx:    begin,
+		brk:        brk,
+	}, mock, brk
+}
+
+func TestSqlConn_ExecCtxCanceled(t *testing.T) {
+	conn, mock, brk := newMockedConn(t, "exec-canceled")
+	mock.ExpectExec("delete from users").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := conn.ExecCtx(ctx, "delete from users where id = ?", 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(brk.accepted) != 1 || !brk.accepted[0] {
+		t.Fatalf("canceled exec should be accepted by breaker, got %v", brk.accepted)
+	}
+	if err := mock.ExpectationsWereMet(); err == nil {
+		t.Fatal("canceled exec should not reach the database")
+	}
+}
+
+func TestSqlConn_QueryRowCtxCanceled(t *testing.T) {
+	conn, mock, brk := newMockedConn(t, "query-canceled")
+	mock.ExpectQuery("select name from users").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("foo"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var name string
+	err := conn.QueryRowCtx(ctx, &name, "select name from users where id = ?", 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(brk.accepted) != 1 || !brk.accepted[0] {
+		t.Fatalf("canceled query should be accepted by breaker, got %v", brk.accepted)
+	}
+}
+
+func TestSqlConn_QueryRowCtxAbortsRunningQuery(t *testing.T) {
+	conn, mock, _ := newMockedConn(t, "query-running")
+	mock.ExpectQuery("select name from users").
+		WillDelayFor(time.Minute).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("foo"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond*50, cancel)
+	start := time.Now()
+	var name string
+	err := conn.QueryRowCtx(ctx, &name, "select name from users where id = ?", 1)
+	if err == nil {
+		t.Fatal("expected error on canceled query")
+	}
+	if time.Since(start) > time.Second*5 {
+		t.Fatal("canceled query should return promptly")
+	}
+	if len(name) > 0 {
+		t.Fatalf("unexpected result %q", name)
+	}
+}
+
+func TestSqlConn_QueryRowCtx(t *testing.T) {
+	conn, mock, brk := newMockedConn(t, "query-ok")
+	mock.ExpectQuery("select name from users").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("foo"))
+
+	var name string
+	if err := conn.QueryRowCtx(context.Background(), &name, "select name from users where id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+	if name != "foo" {
+		t.Fatalf("expected foo, got %q", name)
+	}
+	if len(brk.accepted) != 1 || !brk.accepted[0] {
+		t.Fatalf("unexpected breaker results %v", brk.accepted)
+	}
+}
+
+type recordingExporter struct {
+	lock  sync.Mutex
+	spans []trace.SpanData
+}
+
+func (e *recordingExporter) Export(_ string, spans []trace.SpanData) error {
+	e.lock.Lock()
+	e.spans = append(e.spans, spans...)
+	e.lock.Unlock()
+	return nil
+}
+
+func (e *recordingExporter) exported() []trace.SpanData {
+	// setting another exporter flushes the spans to the previous one.
+	trace.SetExporter("test", new(recordingExporter))
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.spans
+}
+
+func TestSqlConn_SpanStatus(t *testing.T) {
+	conn, mock, _ := newMockedConn(t, "span-status")
+	mock.ExpectExec("delete from users").WillReturnError(errors.New("bad conn"))
+	mock.ExpectQuery("select name from users").WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	mock.ExpectQuery("select name from users").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("foo"))
+
+	exporter := new(recordingExporter)
+	trace.SetExporter("test", exporter)
+	ctx, span := trace.StartServerSpan(context.Background(), nil, "test", "/users")
+	defer span.Finish()
+
+	_, err := conn.ExecCtx(ctx, "delete from users where id = ?", 1)
+	assert.NotNil(t, err)
+	var name string
+	assert.Equal(t, ErrNotFound, conn.QueryRowCtx(ctx, &name, "select name from users where id = ?", 1))
+	assert.Nil(t, conn.QueryRowCtx(ctx, &name, "select name from users where id = ?", 2))
+
+	spans := exporter.exported()
+	if assert.Len(t, spans, 3) {
+		assert.Equal(t, tracespec.StatusError, spans[0].Status)
+		assert.Equal(t, "bad conn", spans[0].StatusMessage)
+		assert.Equal(t, true, spans[0].Attributes[spanErrorKey])
+		for _, span := range spans[1:] {
+			assert.Equal(t, tracespec.StatusUnset, span.Status)
+			assert.Nil(t, span.Attributes[spanErrorKey])
+		}
+	}
+}
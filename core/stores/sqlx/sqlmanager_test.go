@@ -0,0 +1,55 @@
+package sqlx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lukebull/go-zero-extern/core/health"
+)
+
+func TestProbeName(t *testing.T) {
+	tests := []struct {
+		datasource string
+		expect     string
+	}{
+		{
+			datasource: "user:pass@tcp(localhost:3306)/db?parseTime=true",
+			expect:     "sql:tcp(localhost:3306)/db",
+		},
+		{
+			datasource: "postgres://user:p@ss@localhost:5432/db?sslmode=disable",
+			expect:     "sql:localhost:5432/db",
+		},
+		{
+			datasource: "host=localhost user=user password=pass dbname=db",
+			expect:     "sql:host=localhost user=user dbname=db",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expect, func(t *testing.T) {
+			if name := probeName(test.datasource); name != test.expect {
+				t.Fatalf("expected %q, got %q", test.expect, name)
+			}
+		})
+	}
+}
+
+func TestSqlConnReadinessProbe(t *testing.T) {
+	const dsn = "readiness-probe"
+	if _, _, err := sqlmock.NewWithDSN(dsn); err != nil {
+		t.Fatal(err)
+	}
+	defer health.RemoveProbe(probeName(dsn))
+
+	if _, err := getSqlConn("sqlmock", dsn); err != nil {
+		t.Fatal(err)
+	}
+
+	report := health.CheckReadiness(context.Background())
+	check, ok := report.Checks[probeName(dsn)]
+	if !ok || check.Status != health.StatusUp {
+		t.Fatalf("expected sql probe up, got %+v", report)
+	}
+}
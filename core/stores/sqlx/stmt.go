@@ -1,73 +1,96 @@
 package sqlx
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
 	"github.com/lukebull/go-zero-extern/core/logx"
 	"github.com/lukebull/go-zero-extern/core/timex"
+	"github.com/lukebull/go-zero-extern/core/trace"
+	"github.com/lukebull/go-zero-extern/core/trace/tracespec"
 )
 
-const slowThreshold = time.Millisecond * 500
+const (
+	slowThreshold = time.Millisecond * 500
+	spanName      = "sql"
+	spanErrorKey  = "error"
+)
 
-func exec(conn sessionConn, q string, args ...interface{}) (sql.Result, error) {
+func exec(ctx context.Context, conn sessionConn, q string, args ...interface{}) (result sql.Result, err error) {
 	stmt, err := format(q, args...)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, span := trace.StartClientSpan(ctx, spanName, "exec")
+	defer func() {
+		finishSpan(span, err)
+	}()
+
 	startTime := timex.Now()
-	result, err := conn.Exec(q, args...)
+	result, err = conn.ExecContext(ctx, q, args...)
 	duration := timex.Since(startTime)
 	if duration > slowThreshold {
-		logx.WithDuration(duration).Slowf("[SQL] exec: slowcall - %s", stmt)
+		logx.WithContext(ctx).WithDuration(duration).Slowf("[SQL] exec: slowcall - %s", stmt)
 	} else {
-		logx.WithDuration(duration).Infof("sql exec: %s", stmt)
+		logx.WithContext(ctx).WithDuration(duration).Infof("sql exec: %s", stmt)
 	}
 	if err != nil {
-		logSqlError(stmt, err)
+		logSqlError(ctx, stmt, err)
 	}
 
 	return result, err
 }
 
-func execStmt(conn stmtConn, q string, args ...interface{}) (sql.Result, error) {
+func execStmt(ctx context.Context, conn stmtConn, q string, args ...interface{}) (result sql.Result, err error) {
 	stmt, err := format(q, args...)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, span := trace.StartClientSpan(ctx, spanName, "execStmt")
+	defer func() {
+		finishSpan(span, err)
+	}()
+
 	startTime := timex.Now()
-	result, err := conn.Exec(args...)
+	result, err = conn.ExecContext(ctx, args...)
 	duration := timex.Since(startTime)
 	if duration > slowThreshold {
-		logx.WithDuration(duration).Slowf("[SQL] execStmt: slowcall - %s", stmt)
+		logx.WithContext(ctx).WithDuration(duration).Slowf("[SQL] execStmt: slowcall - %s", stmt)
 	} else {
-		logx.WithDuration(duration).Infof("sql execStmt: %s", stmt)
+		logx.WithContext(ctx).WithDuration(duration).Infof("sql execStmt: %s", stmt)
 	}
 	if err != nil {
-		logSqlError(stmt, err)
+		logSqlError(ctx, stmt, err)
 	}
 
 	return result, err
 }
 
-func query(conn sessionConn, scanner func(*sql.Rows) error, q string, args ...interface{}) error {
+func query(ctx context.Context, conn sessionConn, scanner func(*sql.Rows) error,
+	q string, args ...interface{}) (err error) {
 	stmt, err := format(q, args...)
 	if err != nil {
 		return err
 	}
 
+	ctx, span := trace.StartClientSpan(ctx, spanName, "query")
+	defer func() {
+		finishSpan(span, err)
+	}()
+
 	startTime := timex.Now()
-	rows, err := conn.Query(q, args...)
+	rows, err := conn.QueryContext(ctx, q, args...)
 	duration := timex.Since(startTime)
 	if duration > slowThreshold {
-		logx.WithDuration(duration).Slowf("[SQL] query: slowcall - %s", stmt)
+		logx.WithContext(ctx).WithDuration(duration).Slowf("[SQL] query: slowcall - %s", stmt)
 	} else {
-		logx.WithDuration(duration).Infof("sql query: %s", stmt)
+		logx.WithContext(ctx).WithDuration(duration).Infof("sql query: %s", stmt)
 	}
 	if err != nil {
-		logSqlError(stmt, err)
+		logSqlError(ctx, stmt, err)
 		return err
 	}
 	defer rows.Close()
@@ -75,25 +98,41 @@ func query(conn sessionConn, scanner func(*sql.Rows) error, q string, args ...in
 	return scanner(rows)
 }
 
-func queryStmt(conn stmtConn, scanner func(*sql.Rows) error, q string, args ...interface{}) error {
+func queryStmt(ctx context.Context, conn stmtConn, scanner func(*sql.Rows) error,
+	q string, args ...interface{}) (err error) {
 	stmt, err := format(q, args...)
 	if err != nil {
 		return err
 	}
 
+	ctx, span := trace.StartClientSpan(ctx, spanName, "queryStmt")
+	defer func() {
+		finishSpan(span, err)
+	}()
+
 	startTime := timex.Now()
-	rows, err := conn.Query(args...)
+	rows, err := conn.QueryContext(ctx, args...)
 	duration := timex.Since(startTime)
 	if duration > slowThreshold {
-		logx.WithDuration(duration).Slowf("[SQL] queryStmt: slowcall - %s", stmt)
+		logx.WithContext(ctx).WithDuration(duration).Slowf("[SQL] queryStmt: slowcall - %s", stmt)
 	} else {
-		logx.WithDuration(duration).Infof("sql queryStmt: %s", stmt)
+		logx.WithContext(ctx).WithDuration(duration).Infof("sql queryStmt: %s", stmt)
 	}
 	if err != nil {
-		logSqlError(stmt, err)
+		logSqlError(ctx, stmt, err)
 		return err
 	}
 	defer rows.Close()
 
 	return scanner(rows)
 }
+
+// finishSpan finishes the span, marks it as failed if err is not acceptable,
+// like sql.ErrNoRows, which is an expected result, not a failure.
+func finishSpan(span tracespec.Trace, err error) {
+	if !isAcceptable(err) {
+		span.SetAttribute(spanErrorKey, true)
+		span.SetStatus(tracespec.StatusError, err.Error())
+	}
+	span.Finish()
+}
@@ -0,0 +1,113 @@
+package trace
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/executors"
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/core/syncx"
+)
+
+const (
+	kindOtlp      = "otlp"
+	kindZipkin    = "zipkin"
+	flushInterval = time.Second * 5
+	maxBatchSpans = 512
+)
+
+var (
+	once    sync.Once
+	sampler = syncx.ForAtomicFloat64(1)
+	agent   *exportAgent
+	lock    sync.RWMutex
+)
+
+type exportAgent struct {
+	executor *executors.BulkExecutor
+}
+
+// StartAgent starts an agent that exports the sampled spans to the backend in c.
+func StartAgent(c Config) {
+	once.Do(func() {
+		if len(c.Endpoint) == 0 {
+			return
+		}
+
+		var exporter Exporter
+		switch c.Batcher {
+		case kindZipkin:
+			exporter = NewZipkinExporter(c.Endpoint)
+		default:
+			exporter = NewOtlpExporter(c.Endpoint)
+		}
+
+		SetSampler(c.Sampler)
+		SetExporter(c.Name, exporter)
+		logx.Infof("Starting trace agent, exporting to %s with %s", c.Endpoint, c.Batcher)
+	})
+}
+
+// SetExporter sets the exporter to export the sampled spans in batches,
+// serviceName is reported as the service of the spans.
+func SetExporter(serviceName string, exporter Exporter) {
+	executor := executors.NewBulkExecutor(func(tasks []interface{}) {
+		spans := make([]SpanData, 0, len(tasks))
+		for _, task := range tasks {
+			spans = append(spans, task.(SpanData))
+		}
+
+		if err := exporter.Export(serviceName, spans); err != nil {
+			logx.Errorf("export %d spans error: %v", len(spans), err)
+		}
+	}, executors.WithBulkTasks(maxBatchSpans), executors.WithBulkInterval(flushInterval))
+
+	lock.Lock()
+	prev := agent
+	agent = &exportAgent{
+		executor: executor,
+	}
+	lock.Unlock()
+
+	if prev != nil {
+		prev.executor.Flush()
+	}
+}
+
+// SetSampler sets the ratio of the root spans to sample, in range [0, 1].
+func SetSampler(ratio float64) {
+	sampler.Set(math.Max(0, math.Min(1, ratio)))
+}
+
+func export(span SpanData) {
+	lock.RLock()
+	a := agent
+	lock.RUnlock()
+
+	if a != nil {
+		a.executor.Add(span)
+	}
+}
+
+// shouldSample decides by the trace id, so that the decisions are consistent across services.
+func shouldSample(traceId string) bool {
+	ratio := sampler.Load()
+	if ratio >= 1 {
+		return true
+	}
+	if ratio <= 0 {
+		return false
+	}
+
+	if len(traceId) > spanIdLen {
+		traceId = traceId[len(traceId)-spanIdLen:]
+	}
+	val, err := strconv.ParseUint(traceId, 16, 64)
+	if err != nil {
+		return false
+	}
+
+	return float64(val>>1) < ratio*float64(uint64(1)<<63)
+}
@@ -0,0 +1,54 @@
+package trace
+
+import "testing"
+
+func TestShouldSample(t *testing.T) {
+	defer SetSampler(1)
+
+	ids := make([]string, 20000)
+	for i := range ids {
+		ids[i] = newTraceId()
+	}
+
+	SetSampler(1)
+	for _, id := range ids[:100] {
+		if !shouldSample(id) {
+			t.Fatal("all traces should be sampled with ratio 1")
+		}
+	}
+
+	SetSampler(0)
+	for _, id := range ids[:100] {
+		if shouldSample(id) {
+			t.Fatal("no traces should be sampled with ratio 0")
+		}
+	}
+
+	for _, ratio := range []float64{0.1, 0.5, 0.9} {
+		SetSampler(ratio)
+		var sampled int
+		for _, id := range ids {
+			if shouldSample(id) {
+				sampled++
+			}
+			// the decision only depends on the trace id.
+			if shouldSample(id) != shouldSample(id) {
+				t.Fatal("sampling should be consistent")
+			}
+		}
+
+		actual := float64(sampled) / float64(len(ids))
+		if actual < ratio-0.03 || actual > ratio+0.03 {
+			t.Fatalf("ratio %.2f, sampled %.3f", ratio, actual)
+		}
+	}
+
+	SetSampler(2)
+	if sampler.Load() != 1 {
+		t.Fatal("ratio should be capped to 1")
+	}
+	SetSampler(-1)
+	if sampler.Load() != 0 {
+		t.Fatal("ratio should be floored to 0")
+	}
+}
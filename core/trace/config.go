@@ -0,0 +1,10 @@
+package trace
+
+// A Config is an opentelemetry compatible tracing config.
+type Config struct {
+	Name     string `json:",optional"`
+	Endpoint string `json:",optional"`
+	// Sampler is the ratio of the root spans to sample, the sampled flag of the callers is respected.
+	Sampler float64 `json:",default=1.0,range=[0:1]"`
+	Batcher string  `json:",default=otlp,options=otlp|zipkin"`
+}
@@ -3,4 +3,12 @@ package trace
 const (
 	traceIdKey = "X-Trace-ID"
 	spanIdKey  = "X-Span-ID"
+	// the w3c trace context headers, see https://www.w3.org/TR/trace-context/
+	traceparentKey = "traceparent"
+	tracestateKey  = "tracestate"
+
+	// SpanKindServer means the span is a server span.
+	SpanKindServer = "server"
+	// SpanKindClient means the span is a client span.
+	SpanKindClient = "client"
 )
@@ -0,0 +1,41 @@
+package trace
+
+import (
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/trace/tracespec"
+)
+
+type (
+	// An Event is a timestamped event in a span.
+	Event struct {
+		Name       string
+		Time       time.Time
+		Attributes map[string]interface{}
+	}
+
+	// SpanData is a finished span to export.
+	SpanData struct {
+		TraceId      string
+		SpanId       string
+		ParentSpanId string
+		Name         string
+		// Kind is either SpanKindServer or SpanKindClient.
+		Kind string
+		// RemoteService is the service name that the span was started with,
+		// the callee for client spans.
+		RemoteService string
+		StartTime     time.Time
+		EndTime       time.Time
+		Attributes    map[string]interface{}
+		Events        []Event
+		Status        tracespec.StatusCode
+		StatusMessage string
+	}
+
+	// An Exporter exports the finished spans to a tracing backend.
+	// Export is called in batches from the same goroutine.
+	Exporter interface {
+		Export(serviceName string, spans []SpanData) error
+	}
+)
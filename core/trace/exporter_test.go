@@ -0,0 +1,161 @@
+package trace
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/trace/tracespec"
+)
+
+type collector struct {
+	*httptest.Server
+	bodies chan []byte
+	code   int
+}
+
+func newCollector(t *testing.T, code int) *collector {
+	c := &collector{
+		bodies: make(chan []byte, 1),
+		code:   code,
+	}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		c.bodies <- body
+		w.WriteHeader(c.code)
+	}))
+	t.Cleanup(c.Close)
+
+	return c
+}
+
+func testSpans() []SpanData {
+	start := time.Unix(1600000000, 0)
+	return []SpanData{
+		{
+			TraceId:       "4bf92f3577b34da6a3ce929d0e0e4736",
+			SpanId:        "00f067aa0ba902b7",
+			ParentSpanId:  "53995c3f42cd8ad8",
+			Name:          "/api/users",
+			Kind:          SpanKindClient,
+			RemoteService: "user.rpc",
+			StartTime:     start,
+			EndTime:       start.Add(time.Millisecond * 15),
+			Attributes: map[string]interface{}{
+				"http.status_code": 500,
+				"http.method":      "GET",
+				"retried":          true,
+			},
+			Events: []Event{
+				{Name: "retry", Time: start.Add(time.Millisecond)},
+			},
+			Status:        tracespec.StatusError,
+			StatusMessage: "internal error",
+		},
+	}
+}
+
+func TestOtlpExporter(t *testing.T) {
+	c := newCollector(t, http.StatusOK)
+	if err := NewOtlpExporter(c.URL).Export("api", testSpans()); err != nil {
+		t.Fatal(err)
+	}
+
+	var req otlpRequest
+	if err := json.Unmarshal(<-c.bodies, &req); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.ResourceSpans) != 1 || len(req.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected payload %+v", req)
+	}
+
+	attrs := req.ResourceSpans[0].Resource.Attributes
+	if len(attrs) != 1 || attrs[0].Key != serviceNameKey || *attrs[0].Value.StringValue != "api" {
+		t.Fatalf("unexpected resource attributes %+v", attrs)
+	}
+
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.TraceId != "4bf92f3577b34da6a3ce929d0e0e4736" || span.SpanId != "00f067aa0ba902b7" ||
+		span.ParentSpanId != "53995c3f42cd8ad8" || span.Kind != otlpKindClient {
+		t.Fatalf("unexpected span %+v", span)
+	}
+	if span.StartTimeUnixNano != "1600000000000000000" || span.EndTimeUnixNano != "1600000000015000000" {
+		t.Fatalf("unexpected times %s %s", span.StartTimeUnixNano, span.EndTimeUnixNano)
+	}
+	if span.Status.Code != int(tracespec.StatusError) || span.Status.Message != "internal error" {
+		t.Fatalf("unexpected status %+v", span.Status)
+	}
+	if len(span.Events) != 1 || span.Events[0].Name != "retry" {
+		t.Fatalf("unexpected events %+v", span.Events)
+	}
+
+	values := make(map[string]otlpAnyValue)
+	for _, kv := range span.Attributes {
+		values[kv.Key] = kv.Value
+	}
+	if v := values["http.status_code"]; v.IntValue == nil || *v.IntValue != "500" {
+		t.Fatalf("unexpected int attribute %+v", v)
+	}
+	if v := values["http.method"]; v.StringValue == nil || *v.StringValue != "GET" {
+		t.Fatalf("unexpected string attribute %+v", v)
+	}
+	if v := values["retried"]; v.BoolValue == nil || !*v.BoolValue {
+		t.Fatalf("unexpected bool attribute %+v", v)
+	}
+	if v := values[peerServiceKey]; v.StringValue == nil || *v.StringValue != "user.rpc" {
+		t.Fatalf("unexpected peer service %+v", v)
+	}
+}
+
+func TestZipkinExporter(t *testing.T) {
+	c := newCollector(t, http.StatusAccepted)
+	if err := NewZipkinExporter(c.URL).Export("api", testSpans()); err != nil {
+		t.Fatal(err)
+	}
+
+	var spans []zipkinSpan
+	if err := json.Unmarshal(<-c.bodies, &spans); err != nil {
+		t.Fatal(err)
+	}
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	span := spans[0]
+	if span.TraceId != "4bf92f3577b34da6a3ce929d0e0e4736" || span.Id != "00f067aa0ba902b7" ||
+		span.ParentId != "53995c3f42cd8ad8" || span.Kind != "CLIENT" {
+		t.Fatalf("unexpected span %+v", span)
+	}
+	if span.Timestamp != 1600000000000000 || span.Duration != 15000 {
+		t.Fatalf("unexpected timestamp %d or duration %d", span.Timestamp, span.Duration)
+	}
+	if span.LocalEndpoint.ServiceName != "api" || span.RemoteEndpoint == nil ||
+		span.RemoteEndpoint.ServiceName != "user.rpc" {
+		t.Fatalf("unexpected endpoints %+v %+v", span.LocalEndpoint, span.RemoteEndpoint)
+	}
+	if span.Tags[zipkinErrorTag] != "internal error" || span.Tags["http.status_code"] != "500" {
+		t.Fatalf("unexpected tags %v", span.Tags)
+	}
+	if len(span.Annotations) != 1 || span.Annotations[0].Value != "retry" {
+		t.Fatalf("unexpected annotations %+v", span.Annotations)
+	}
+}
+
+func TestExporterStatusError(t *testing.T) {
+	c := newCollector(t, http.StatusBadRequest)
+	if err := NewZipkinExporter(c.URL).Export("api", testSpans()); err == nil {
+		t.Fatal("expected error on bad status")
+	}
+	<-c.bodies
+}
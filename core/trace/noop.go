@@ -31,3 +31,12 @@ func (s noopSpan) TraceId() string {
 
 func (s noopSpan) Visit(fn func(key, val string) bool) {
 }
+
+func (s noopSpan) SetAttribute(key string, value interface{}) {
+}
+
+func (s noopSpan) AddEvent(name string, attributes map[string]interface{}) {
+}
+
+func (s noopSpan) SetStatus(code tracespec.StatusCode, description string) {
+}
@@ -0,0 +1,203 @@
+package trace
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/trace/tracespec"
+)
+
+const (
+	otlpKindServer     = 2
+	otlpKindClient     = 3
+	serviceNameKey     = "service.name"
+	peerServiceKey     = "peer.service"
+	instrumentationLib = "github.com/lukebull/go-zero-extern/core/trace"
+)
+
+type (
+	otlpExporter struct {
+		endpoint string
+	}
+
+	// the json encoding of OTLP/HTTP, see opentelemetry-proto/opentelemetry/proto/trace/v1
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+
+	otlpSpan struct {
+		TraceId           string         `json:"traceId"`
+		SpanId            string         `json:"spanId"`
+		ParentSpanId      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Events            []otlpEvent    `json:"events,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+
+	otlpEvent struct {
+		TimeUnixNano string         `json:"timeUnixNano"`
+		Name         string         `json:"name"`
+		Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+	}
+
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+
+	otlpAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// NewOtlpExporter returns an Exporter that exports spans with OTLP/HTTP in json encoding,
+// endpoint is like http://localhost:4318/v1/traces.
+func NewOtlpExporter(endpoint string) Exporter {
+	return otlpExporter{
+		endpoint: endpoint,
+	}
+}
+
+func (e otlpExporter) Export(serviceName string, spans []SpanData) error {
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		otlpSpans = append(otlpSpans, toOtlpSpan(span))
+	}
+
+	return postJson(e.endpoint, otlpRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: []otlpKeyValue{toOtlpKeyValue(serviceNameKey, serviceName)},
+				},
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{
+							Name: instrumentationLib,
+						},
+						Spans: otlpSpans,
+					},
+				},
+			},
+		},
+	})
+}
+
+func toOtlpAttributes(attrs map[string]interface{}) []otlpKeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	kvs := make([]otlpKeyValue, 0, len(attrs))
+	for k, v := range attrs {
+		kvs = append(kvs, toOtlpKeyValue(k, v))
+	}
+
+	return kvs
+}
+
+func toOtlpKeyValue(key string, v interface{}) otlpKeyValue {
+	var val otlpAnyValue
+	switch vv := v.(type) {
+	case bool:
+		val.BoolValue = &vv
+	case int:
+		s := strconv.FormatInt(int64(vv), 10)
+		val.IntValue = &s
+	case int32:
+		s := strconv.FormatInt(int64(vv), 10)
+		val.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(vv, 10)
+		val.IntValue = &s
+	case uint32:
+		s := strconv.FormatUint(uint64(vv), 10)
+		val.IntValue = &s
+	case float32:
+		f := float64(vv)
+		val.DoubleValue = &f
+	case float64:
+		val.DoubleValue = &vv
+	default:
+		s := toString(v)
+		val.StringValue = &s
+	}
+
+	return otlpKeyValue{
+		Key:   key,
+		Value: val,
+	}
+}
+
+func toOtlpSpan(span SpanData) otlpSpan {
+	kind := otlpKindServer
+	attrs := toOtlpAttributes(span.Attributes)
+	if span.Kind == SpanKindClient {
+		kind = otlpKindClient
+		attrs = append(attrs, toOtlpKeyValue(peerServiceKey, span.RemoteService))
+	}
+
+	events := make([]otlpEvent, 0, len(span.Events))
+	for _, evt := range span.Events {
+		events = append(events, otlpEvent{
+			TimeUnixNano: unixNano(evt.Time),
+			Name:         evt.Name,
+			Attributes:   toOtlpAttributes(evt.Attributes),
+		})
+	}
+
+	return otlpSpan{
+		TraceId:           span.TraceId,
+		SpanId:            span.SpanId,
+		ParentSpanId:      span.ParentSpanId,
+		Name:              span.Name,
+		Kind:              kind,
+		StartTimeUnixNano: unixNano(span.StartTime),
+		EndTimeUnixNano:   unixNano(span.EndTime),
+		Attributes:        attrs,
+		Events:            events,
+		Status: otlpStatus{
+			Code:    toOtlpStatusCode(span.Status),
+			Message: span.StatusMessage,
+		},
+	}
+}
+
+func toOtlpStatusCode(code tracespec.StatusCode) int {
+	// the same values as otlp status codes.
+	return int(code)
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
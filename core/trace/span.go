@@ -2,56 +2,61 @@ package trace
 
 import (
 	"context"
-	"fmt"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/lukebull/go-zero-extern/core/stringx"
 	"github.com/lukebull/go-zero-extern/core/timex"
 	"github.com/lukebull/go-zero-extern/core/trace/tracespec"
 )
 
 const (
-	initSpanId  = "0"
-	clientFlag  = "client"
-	serverFlag  = "server"
-	spanSepRune = '.'
+	clientFlag = "client"
+	serverFlag = "server"
 )
 
-var spanSep = string([]byte{spanSepRune})
-
 // A Span is a calling span that connects caller and callee.
 type Span struct {
 	ctx           spanContext
+	parentId      string
 	serviceName   string
 	operationName string
 	startTime     time.Time
 	flag          string
-	children      int
+	attributes    map[string]interface{}
+	events        []Event
+	status        tracespec.StatusCode
+	statusMessage string
+	finished      bool
+	lock          sync.Mutex
 }
 
 func newServerSpan(carrier Carrier, serviceName, operationName string) tracespec.Trace {
-	traceId := stringx.TakeWithPriority(func() string {
-		if carrier != nil {
-			return carrier.Get(traceIdKey)
-		}
-		return ""
-	}, stringx.RandId)
-	spanId := stringx.TakeWithPriority(func() string {
-		if carrier != nil {
-			return carrier.Get(spanIdKey)
+	var sc spanContext
+	var parentId string
+	if carrier != nil {
+		if traceId, spanId, sampled, ok := parseTraceparent(carrier.Get(traceparentKey)); ok {
+			sc.traceId = traceId
+			sc.sampled = sampled
+			sc.traceState = carrier.Get(tracestateKey)
+			parentId = spanId
+		} else if traceId := carrier.Get(traceIdKey); isValidTraceId(traceId) {
+			// the caller only sends the legacy headers, the malformed ids are dropped,
+			// otherwise they would break the traceparent headers and the exported spans.
+			sc.traceId = strings.ToLower(traceId)
+			sc.sampled = shouldSample(traceId)
+			parentId = carrier.Get(spanIdKey)
 		}
-		return ""
-	}, func() string {
-		return initSpanId
-	})
+	}
+	if len(sc.traceId) == 0 {
+		sc.traceId = newTraceId()
+		sc.sampled = shouldSample(sc.traceId)
+	}
+	sc.spanId = newSpanId()
 
 	return &Span{
-		ctx: spanContext{
-			traceId: traceId,
-			spanId:  spanId,
-		},
+		ctx:           sc,
+		parentId:      parentId,
 		serviceName:   serviceName,
 		operationName: operationName,
 		startTime:     timex.Time(),
@@ -59,17 +64,44 @@ func newServerSpan(carrier Carrier, serviceName, operationName string) tracespec
 	}
 }
 
-// Finish finishes the calling span.
+// AddEvent adds an event with given name and attributes to the span.
+func (s *Span) AddEvent(name string, attributes map[string]interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.events = append(s.events, Event{
+		Name:       name,
+		Time:       timex.Time(),
+		Attributes: attributes,
+	})
+}
+
+// Finish finishes the calling span, and exports it if sampled.
 func (s *Span) Finish() {
+	s.lock.Lock()
+	if s.finished {
+		s.lock.Unlock()
+		return
+	}
+
+	s.finished = true
+	s.lock.Unlock()
+
+	if s.ctx.sampled {
+		export(s.toSpanData(timex.Time()))
+	}
 }
 
 // Follow follows the tracing service and operation names in context.
 func (s *Span) Follow(ctx context.Context, serviceName, operationName string) (context.Context, tracespec.Trace) {
 	span := &Span{
 		ctx: spanContext{
-			traceId: s.ctx.traceId,
-			spanId:  s.followSpanId(),
+			traceId:    s.ctx.traceId,
+			spanId:     newSpanId(),
+			sampled:    s.ctx.sampled,
+			traceState: s.ctx.traceState,
 		},
+		parentId:      s.parentId,
 		serviceName:   serviceName,
 		operationName: operationName,
 		startTime:     timex.Time(),
@@ -82,9 +114,12 @@ func (s *Span) Follow(ctx context.Context, serviceName, operationName string) (c
 func (s *Span) Fork(ctx context.Context, serviceName, operationName string) (context.Context, tracespec.Trace) {
 	span := &Span{
 		ctx: spanContext{
-			traceId: s.ctx.traceId,
-			spanId:  s.forkSpanId(),
+			traceId:    s.ctx.traceId,
+			spanId:     newSpanId(),
+			sampled:    s.ctx.sampled,
+			traceState: s.ctx.traceState,
 		},
+		parentId:      s.ctx.spanId,
 		serviceName:   serviceName,
 		operationName: operationName,
 		startTime:     timex.Time(),
@@ -93,6 +128,26 @@ func (s *Span) Fork(ctx context.Context, serviceName, operationName string) (con
 	return context.WithValue(ctx, tracespec.TracingKey, span), span
 }
 
+// SetAttribute sets the attribute with key and value to the span.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.attributes == nil {
+		s.attributes = make(map[string]interface{})
+	}
+	s.attributes[key] = value
+}
+
+// SetStatus sets the status of the span.
+func (s *Span) SetStatus(code tracespec.StatusCode, description string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.status = code
+	s.statusMessage = description
+}
+
 // SpanId returns the span id.
 func (s *Span) SpanId() string {
 	return s.ctx.SpanId()
@@ -108,29 +163,58 @@ func (s *Span) Visit(fn func(key, val string) bool) {
 	s.ctx.Visit(fn)
 }
 
-func (s *Span) forkSpanId() string {
-	s.children++
-	return fmt.Sprintf("%s.%d", s.ctx.spanId, s.children)
-}
+func (s *Span) toSpanData(endTime time.Time) SpanData {
+	s.lock.Lock()
+	defer s.lock.Unlock()
 
-func (s *Span) followSpanId() string {
-	fields := strings.FieldsFunc(s.ctx.spanId, func(r rune) bool {
-		return r == spanSepRune
-	})
-	if len(fields) == 0 {
-		return s.ctx.spanId
+	kind := SpanKindServer
+	if s.flag == clientFlag {
+		kind = SpanKindClient
 	}
 
-	last := fields[len(fields)-1]
-	val, err := strconv.Atoi(last)
-	if err != nil {
-		return s.ctx.spanId
+	// the legacy span ids like 0.1 can't be exported.
+	parentId := s.parentId
+	if len(parentId) != spanIdLen || !isHex(parentId) {
+		parentId = ""
 	}
 
-	last = strconv.Itoa(val + 1)
-	fields[len(fields)-1] = last
+	// copy the attributes and events, because they are marshaled in the exporting goroutine.
+	var attributes map[string]interface{}
+	if len(s.attributes) > 0 {
+		attributes = make(map[string]interface{}, len(s.attributes))
+		for k, v := range s.attributes {
+			attributes[k] = v
+		}
+	}
+	var events []Event
+	if len(s.events) > 0 {
+		events = make([]Event, len(s.events))
+		copy(events, s.events)
+	}
+
+	return SpanData{
+		TraceId:       normalizeTraceId(s.ctx.traceId),
+		SpanId:        s.ctx.spanId,
+		ParentSpanId:  parentId,
+		Name:          s.operationName,
+		Kind:          kind,
+		RemoteService: s.serviceName,
+		StartTime:     s.startTime,
+		EndTime:       endTime,
+		Attributes:    attributes,
+		Events:        events,
+		Status:        s.status,
+		StatusMessage: s.statusMessage,
+	}
+}
+
+// SpanFromContext returns the span in ctx, or a noop span if not exists.
+func SpanFromContext(ctx context.Context) tracespec.Trace {
+	if span, ok := ctx.Value(tracespec.TracingKey).(tracespec.Trace); ok {
+		return span
+	}
 
-	return strings.Join(fields, spanSep)
+	return emptyNoopSpan
 }
 
 // StartClientSpan starts the client span with given context, service and operation names.
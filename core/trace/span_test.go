@@ -0,0 +1,173 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lukebull/go-zero-extern/core/trace/tracespec"
+)
+
+type recordingExporter struct {
+	lock  sync.Mutex
+	spans []SpanData
+}
+
+func (e *recordingExporter) Export(_ string, spans []SpanData) error {
+	// marshal like the real exporters do, to detect races on the span data.
+	if _, err := json.Marshal(spans); err != nil {
+		return err
+	}
+
+	e.lock.Lock()
+	e.spans = append(e.spans, spans...)
+	e.lock.Unlock()
+	return nil
+}
+
+func (e *recordingExporter) exported() []SpanData {
+	lock.RLock()
+	a := agent
+	lock.RUnlock()
+	a.executor.Flush()
+	a.executor.Wait()
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.spans
+}
+
+func useRecordingExporter(t *testing.T) *recordingExporter {
+	exporter := new(recordingExporter)
+	SetExporter("test", exporter)
+	t.Cleanup(func() {
+		lock.Lock()
+		agent = nil
+		lock.Unlock()
+	})
+
+	return exporter
+}
+
+func visitHeaders(span tracespec.Trace) http.Header {
+	header := make(http.Header)
+	span.Visit(func(key, val string) bool {
+		header.Set(key, val)
+		return true
+	})
+
+	return header
+}
+
+func TestServerSpanFromTraceparent(t *testing.T) {
+	const (
+		traceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanId  = "00f067aa0ba902b7"
+	)
+
+	for _, sampled := range []bool{true, false} {
+		header := make(http.Header)
+		header.Set(traceparentKey, formatTraceparent(traceId, spanId, sampled))
+		header.Set(tracestateKey, "vendor=value")
+		ctx, span := StartServerSpan(context.Background(), httpCarrier(header), "service", "/api")
+		if span.TraceId() != traceId {
+			t.Fatalf("expected trace id %s, got %s", traceId, span.TraceId())
+		}
+
+		_, client := StartClientSpan(ctx, "callee", "method")
+		out := visitHeaders(client)
+		tid, parent, s, ok := parseTraceparent(out.Get(traceparentKey))
+		if !ok || tid != traceId || s != sampled {
+			t.Fatalf("unexpected traceparent %s", out.Get(traceparentKey))
+		}
+		if parent != client.SpanId() {
+			t.Fatalf("expected parent %s, got %s", client.SpanId(), parent)
+		}
+		if out.Get(tracestateKey) != "vendor=value" {
+			t.Fatalf("tracestate should be propagated, got %q", out.Get(tracestateKey))
+		}
+		if out.Get(traceIdKey) != traceId {
+			t.Fatalf("legacy trace id should be propagated, got %q", out.Get(traceIdKey))
+		}
+	}
+}
+
+func TestServerSpanNotSampledNotExported(t *testing.T) {
+	exporter := useRecordingExporter(t)
+	header := make(http.Header)
+	header.Set(traceparentKey, formatTraceparent(newTraceId(), newSpanId(), false))
+	_, span := StartServerSpan(context.Background(), httpCarrier(header), "service", "/api")
+	span.Finish()
+
+	if spans := exporter.exported(); len(spans) != 0 {
+		t.Fatalf("not sampled spans should not be exported, got %d", len(spans))
+	}
+}
+
+func TestServerSpanFromLegacyHeaders(t *testing.T) {
+	header := make(http.Header)
+	header.Set(traceIdKey, "463AC35C9F6413AD")
+	header.Set(spanIdKey, "0.1")
+	_, span := StartServerSpan(context.Background(), httpCarrier(header), "service", "/api")
+	if span.TraceId() != "463ac35c9f6413ad" {
+		t.Fatalf("legacy trace id should be adopted, got %s", span.TraceId())
+	}
+
+	tid, _, _, ok := parseTraceparent(visitHeaders(span).Get(traceparentKey))
+	if !ok || tid != strings.Repeat("0", 16)+"463ac35c9f6413ad" {
+		t.Fatalf("unexpected traceparent %s", visitHeaders(span).Get(traceparentKey))
+	}
+}
+
+func TestServerSpanFromMalformedLegacyHeaders(t *testing.T) {
+	for _, id := range []string{"abc-not-hex", strings.Repeat("a", 33), strings.Repeat("0", 32)} {
+		header := make(http.Header)
+		header.Set(traceIdKey, id)
+		_, span := StartServerSpan(context.Background(), httpCarrier(header), "service", "/api")
+		if span.TraceId() == id || len(span.TraceId()) != traceIdLen {
+			t.Fatalf("malformed trace id %q should be replaced, got %s", id, span.TraceId())
+		}
+		if _, _, _, ok := parseTraceparent(visitHeaders(span).Get(traceparentKey)); !ok {
+			t.Fatalf("malformed traceparent %s", visitHeaders(span).Get(traceparentKey))
+		}
+	}
+}
+
+func TestSpanExported(t *testing.T) {
+	exporter := useRecordingExporter(t)
+	ctx, span := StartServerSpan(context.Background(), nil, "service", "/api")
+	span.SetAttribute("http.method", "GET")
+	_, client := StartClientSpan(ctx, "callee", "method")
+	client.AddEvent("retry", map[string]interface{}{"attempt": 1})
+	client.SetStatus(tracespec.StatusError, "failed")
+	client.Finish()
+	span.Finish()
+	// modifying after finishing must not race with exporting.
+	span.SetAttribute("late", true)
+	client.AddEvent("late", nil)
+
+	spans := exporter.exported()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	cs, ss := spans[0], spans[1]
+	if cs.Kind != SpanKindClient || ss.Kind != SpanKindServer {
+		t.Fatalf("unexpected kinds %s %s", cs.Kind, ss.Kind)
+	}
+	if cs.TraceId != ss.TraceId || cs.ParentSpanId != ss.SpanId {
+		t.Fatalf("client span should be the child of server span")
+	}
+	if cs.Status != tracespec.StatusError || cs.StatusMessage != "failed" || len(cs.Events) != 1 {
+		t.Fatalf("unexpected client span %+v", cs)
+	}
+	if ss.Attributes["http.method"] != "GET" {
+		t.Fatalf("unexpected attributes %v", ss.Attributes)
+	}
+	if _, ok := ss.Attributes["late"]; ok {
+		t.Fatal("attributes set after finishing should not be exported")
+	}
+}
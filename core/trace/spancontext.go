@@ -1,8 +1,10 @@
 package trace
 
 type spanContext struct {
-	traceId string
-	spanId  string
+	traceId    string
+	spanId     string
+	sampled    bool
+	traceState string
 }
 
 func (sc spanContext) TraceId() string {
@@ -14,6 +16,15 @@ func (sc spanContext) SpanId() string {
 }
 
 func (sc spanContext) Visit(fn func(key, val string) bool) {
-	fn(traceIdKey, sc.traceId)
+	if !fn(traceparentKey, formatTraceparent(sc.traceId, sc.spanId, sc.sampled)) {
+		return
+	}
+	if len(sc.traceState) > 0 && !fn(tracestateKey, sc.traceState) {
+		return
+	}
+	// keep the legacy headers for the services that don't understand traceparent.
+	if !fn(traceIdKey, sc.traceId) {
+		return
+	}
 	fn(spanIdKey, sc.spanId)
 }
@@ -0,0 +1,13 @@
+package tracespec
+
+const (
+	// StatusUnset means the status is not set.
+	StatusUnset StatusCode = iota
+	// StatusOk means the operation completed successfully.
+	StatusOk
+	// StatusError means the operation failed.
+	StatusError
+)
+
+// StatusCode is the status of a span, compatible with OpenTelemetry.
+type StatusCode int
@@ -8,4 +8,7 @@ type Trace interface {
 	Finish()
 	Fork(ctx context.Context, serviceName, operationName string) (context.Context, Trace)
 	Follow(ctx context.Context, serviceName, operationName string) (context.Context, Trace)
+	SetAttribute(key string, value interface{})
+	AddEvent(name string, attributes map[string]interface{})
+	SetStatus(code StatusCode, description string)
 }
@@ -0,0 +1,105 @@
+package trace
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+const (
+	traceparentVersion = "00"
+	sampledFlag        = "01"
+	notSampledFlag     = "00"
+	traceIdLen         = 32
+	spanIdLen          = 16
+	traceparentParts   = 4
+)
+
+var (
+	invalidTraceId = strings.Repeat("0", traceIdLen)
+	invalidSpanId  = strings.Repeat("0", spanIdLen)
+)
+
+func formatTraceparent(traceId, spanId string, sampled bool) string {
+	flag := notSampledFlag
+	if sampled {
+		flag = sampledFlag
+	}
+
+	return fmt.Sprintf("%s-%s-%s-%s", traceparentVersion, normalizeTraceId(traceId), spanId, flag)
+}
+
+func isHex(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isValidTraceId checks if the trace id can be carried in traceparent after normalizing.
+func isValidTraceId(traceId string) bool {
+	return len(traceId) <= traceIdLen && isHex(traceId) && strings.Trim(traceId, "0") != ""
+}
+
+func newId(n int) string {
+	b := make([]byte, n)
+	if _, err := crand.Read(b); err != nil {
+		rand.Read(b)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+func newSpanId() string {
+	return newId(spanIdLen / 2)
+}
+
+func newTraceId() string {
+	return newId(traceIdLen / 2)
+}
+
+// normalizeTraceId pads the legacy 16 chars trace ids to 32 chars, the way jaeger does.
+func normalizeTraceId(traceId string) string {
+	if len(traceId) < traceIdLen && isHex(traceId) {
+		return strings.Repeat("0", traceIdLen-len(traceId)) + traceId
+	}
+
+	return traceId
+}
+
+func parseTraceparent(val string) (traceId, spanId string, sampled, ok bool) {
+	parts := strings.Split(strings.TrimSpace(val), "-")
+	if len(parts) < traceparentParts {
+		return
+	}
+
+	version, traceId, spanId, flags := parts[0], parts[1], parts[2], parts[3]
+	if len(version) != 2 || version == "ff" || !isHex(version) {
+		return
+	}
+	// only version 00 is fixed to 4 parts, later versions might append more.
+	if version == traceparentVersion && len(parts) != traceparentParts {
+		return
+	}
+	if len(traceId) != traceIdLen || !isHex(traceId) || traceId == invalidTraceId {
+		return
+	}
+	if len(spanId) != spanIdLen || !isHex(spanId) || spanId == invalidSpanId {
+		return
+	}
+	if len(flags) != 2 || !isHex(flags) {
+		return
+	}
+
+	b, _ := hex.DecodeString(flags)
+	return strings.ToLower(traceId), strings.ToLower(spanId), b[0]&1 == 1, true
+}
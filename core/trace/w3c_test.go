@@ -0,0 +1,92 @@
+package trace
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	const (
+		traceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanId  = "00f067aa0ba902b7"
+	)
+
+	tests := []struct {
+		name    string
+		val     string
+		ok      bool
+		sampled bool
+	}{
+		{name: "sampled", val: "00-" + traceId + "-" + spanId + "-01", ok: true, sampled: true},
+		{name: "not sampled", val: "00-" + traceId + "-" + spanId + "-00", ok: true},
+		{name: "other flags", val: "00-" + traceId + "-" + spanId + "-03", ok: true, sampled: true},
+		{name: "upper case", val: "00-" + strings.ToUpper(traceId) + "-" + spanId + "-01", ok: true, sampled: true},
+		{name: "future version", val: "01-" + traceId + "-" + spanId + "-01-extra", ok: true, sampled: true},
+		{name: "empty", val: ""},
+		{name: "version ff", val: "ff-" + traceId + "-" + spanId + "-01"},
+		{name: "bad version", val: "0x-" + traceId + "-" + spanId + "-01"},
+		{name: "extra parts on 00", val: "00-" + traceId + "-" + spanId + "-01-extra"},
+		{name: "short trace id", val: "00-" + traceId[1:] + "-" + spanId + "-01"},
+		{name: "zero trace id", val: "00-" + strings.Repeat("0", 32) + "-" + spanId + "-01"},
+		{name: "not hex trace id", val: "00-" + strings.Repeat("z", 32) + "-" + spanId + "-01"},
+		{name: "short span id", val: "00-" + traceId + "-" + spanId[1:] + "-01"},
+		{name: "zero span id", val: "00-" + traceId + "-" + strings.Repeat("0", 16) + "-01"},
+		{name: "bad flags", val: "00-" + traceId + "-" + spanId + "-1"},
+		{name: "missing parts", val: "00-" + traceId + "-" + spanId},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tid, sid, sampled, ok := parseTraceparent(test.val)
+			if ok != test.ok {
+				t.Fatalf("expected ok %t, got %t", test.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if tid != traceId || sid != spanId {
+				t.Fatalf("unexpected ids %s %s", tid, sid)
+			}
+			if sampled != test.sampled {
+				t.Fatalf("expected sampled %t, got %t", test.sampled, sampled)
+			}
+		})
+	}
+}
+
+func TestFormatTraceparent(t *testing.T) {
+	traceId, spanId := newTraceId(), newSpanId()
+	for _, sampled := range []bool{true, false} {
+		tid, sid, s, ok := parseTraceparent(formatTraceparent(traceId, spanId, sampled))
+		if !ok || tid != traceId || sid != spanId || s != sampled {
+			t.Fatalf("round trip failed: %s %s %t %t", tid, sid, s, ok)
+		}
+	}
+
+	// the legacy short trace ids are padded.
+	val := formatTraceparent("abc", spanId, true)
+	if val != "00-"+strings.Repeat("0", 29)+"abc-"+spanId+"-01" {
+		t.Fatalf("unexpected traceparent %s", val)
+	}
+}
+
+func TestIsValidTraceId(t *testing.T) {
+	tests := map[string]bool{
+		"":                                  false,
+		"abc":                               true,
+		"463ac35c9f6413ad":                  true,
+		"4bf92f3577b34da6a3ce929d0e0e4736":  true,
+		"4BF92F3577B34DA6A3CE929D0E0E4736":  true,
+		"4bf92f3577b34da6a3ce929d0e0e47361": false,
+		"abc-not-hex":                       false,
+		"00000000000000000000000000000000":  false,
+		"1.1":                               false,
+	}
+
+	for id, expect := range tests {
+		if isValidTraceId(id) != expect {
+			t.Fatalf("isValidTraceId(%q) should be %t", id, expect)
+		}
+	}
+}
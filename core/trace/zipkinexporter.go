@@ -0,0 +1,109 @@
+package trace
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/trace/tracespec"
+)
+
+const (
+	zipkinErrorTag     = "error"
+	zipkinStatusMsgTag = "otel.status_description"
+)
+
+type (
+	zipkinExporter struct {
+		endpoint string
+	}
+
+	// the json model of zipkin v2 api, see https://zipkin.io/zipkin-api/
+	zipkinSpan struct {
+		TraceId        string             `json:"traceId"`
+		Id             string             `json:"id"`
+		ParentId       string             `json:"parentId,omitempty"`
+		Name           string             `json:"name"`
+		Kind           string             `json:"kind"`
+		Timestamp      int64              `json:"timestamp"`
+		Duration       int64              `json:"duration"`
+		LocalEndpoint  zipkinEndpoint     `json:"localEndpoint"`
+		RemoteEndpoint *zipkinEndpoint    `json:"remoteEndpoint,omitempty"`
+		Annotations    []zipkinAnnotation `json:"annotations,omitempty"`
+		Tags           map[string]string  `json:"tags,omitempty"`
+	}
+
+	zipkinEndpoint struct {
+		ServiceName string `json:"serviceName"`
+	}
+
+	zipkinAnnotation struct {
+		Timestamp int64  `json:"timestamp"`
+		Value     string `json:"value"`
+	}
+)
+
+// NewZipkinExporter returns an Exporter that exports spans with zipkin v2 json api,
+// endpoint is like http://localhost:9411/api/v2/spans.
+func NewZipkinExporter(endpoint string) Exporter {
+	return zipkinExporter{
+		endpoint: endpoint,
+	}
+}
+
+func (e zipkinExporter) Export(serviceName string, spans []SpanData) error {
+	zipkinSpans := make([]zipkinSpan, 0, len(spans))
+	for _, span := range spans {
+		zipkinSpans = append(zipkinSpans, toZipkinSpan(serviceName, span))
+	}
+
+	return postJson(e.endpoint, zipkinSpans)
+}
+
+func toZipkinSpan(serviceName string, span SpanData) zipkinSpan {
+	zs := zipkinSpan{
+		TraceId:   span.TraceId,
+		Id:        span.SpanId,
+		ParentId:  span.ParentSpanId,
+		Name:      span.Name,
+		Kind:      strings.ToUpper(span.Kind),
+		Timestamp: toMicroseconds(span.StartTime),
+		Duration:  int64(span.EndTime.Sub(span.StartTime) / time.Microsecond),
+		LocalEndpoint: zipkinEndpoint{
+			ServiceName: serviceName,
+		},
+	}
+	if span.Kind == SpanKindClient && len(span.RemoteService) > 0 {
+		zs.RemoteEndpoint = &zipkinEndpoint{
+			ServiceName: span.RemoteService,
+		}
+	}
+
+	tags := make(map[string]string, len(span.Attributes)+1)
+	for k, v := range span.Attributes {
+		tags[k] = toString(v)
+	}
+	if span.Status == tracespec.StatusError {
+		tags[zipkinErrorTag] = span.StatusMessage
+		if len(span.StatusMessage) == 0 {
+			tags[zipkinErrorTag] = "true"
+		}
+	} else if len(span.StatusMessage) > 0 {
+		tags[zipkinStatusMsgTag] = span.StatusMessage
+	}
+	if len(tags) > 0 {
+		zs.Tags = tags
+	}
+
+	for _, evt := range span.Events {
+		zs.Annotations = append(zs.Annotations, zipkinAnnotation{
+			Timestamp: toMicroseconds(evt.Time),
+			Value:     evt.Name,
+		})
+	}
+
+	return zs
+}
+
+func toMicroseconds(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Microsecond)
+}
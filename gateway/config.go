@@ -0,0 +1,46 @@
+package gateway
+
+import (
+	"time"
+
+	"github.com/lukebull/go-zero-extern/rest"
+	"github.com/lukebull/go-zero-extern/zrpc"
+)
+
+type (
+	// A GatewayConf is a gateway config that proxies the http requests to the rpc servers.
+	GatewayConf struct {
+		rest.RestConf
+		Upstreams []Upstream
+		// Timeout is the timeout of the rpc calls and loading the descriptors by server reflection.
+		Timeout time.Duration `json:",default=5s"`
+	}
+
+	// An Upstream is a rpc server that the http requests are proxied to.
+	Upstream struct {
+		// Name is used in the logs, defaults to the endpoints or the target of Grpc.
+		Name string `json:",optional"`
+		Grpc zrpc.RpcClientConf
+		// ProtoSets are the files generated by protoc --include_imports --descriptor_set_out,
+		// the descriptors are loaded by server reflection if not set.
+		ProtoSets []string `json:",optional"`
+		// ApiFile maps the routes in the .api file to the rpc methods with the same names as the handlers.
+		ApiFile string `json:",optional"`
+		// Mappings are the routes mapped to the rpc methods,
+		// besides the routes from the google.api.http annotations.
+		Mappings []RouteMapping `json:",optional"`
+	}
+
+	// A RouteMapping maps a http route to a rpc method.
+	RouteMapping struct {
+		// Method is the http method, like GET.
+		Method string
+		// Path is the http path, like /users/:id.
+		Path string
+		// RpcPath is the rpc method, like user.User/GetUser.
+		RpcPath string
+		// Body is the field of the request message that the json body binds to,
+		// * means the whole request message.
+		Body string `json:",default=*"`
+	}
+)
@@ -0,0 +1,168 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"google.golang.org/grpc"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const reflectionService = "grpc.reflection.v1alpha.ServerReflection"
+
+var errNoServices = errors.New("no rpc services found")
+
+// A descriptorSource holds the descriptors of the services of an upstream.
+type descriptorSource struct {
+	services []protoreflect.ServiceDescriptor
+}
+
+// loadProtoSets loads the descriptors from the protoset files, the services in the files are all proxied.
+func loadProtoSets(files []string) (*descriptorSource, error) {
+	fdps := make(map[string]*descriptorpb.FileDescriptorProto)
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var set descriptorpb.FileDescriptorSet
+		if err = proto.Unmarshal(content, &set); err != nil {
+			return nil, fmt.Errorf("bad protoset file %s: %v", file, err)
+		}
+		for _, fdp := range set.File {
+			fdps[fdp.GetName()] = fdp
+		}
+	}
+
+	return newDescriptorSource(fdps, nil)
+}
+
+// loadByReflection loads the descriptors of the services listed by the server reflection of conn.
+func loadByReflection(ctx context.Context, conn grpc.ClientConnInterface) (*descriptorSource, error) {
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.CloseSend()
+
+	resp, err := reflectionRoundTrip(stream, &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		if svc.GetName() != reflectionService {
+			names = append(names, svc.GetName())
+		}
+	}
+
+	// the server sends the dependencies that are not sent before along with the files.
+	fdps := make(map[string]*descriptorpb.FileDescriptorProto)
+	for _, name := range names {
+		resp, err := reflectionRoundTrip(stream, &rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{
+				FileContainingSymbol: name,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, content := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			var fdp descriptorpb.FileDescriptorProto
+			if err = proto.Unmarshal(content, &fdp); err != nil {
+				return nil, err
+			}
+			fdps[fdp.GetName()] = &fdp
+		}
+	}
+
+	return newDescriptorSource(fdps, names)
+}
+
+// newDescriptorSource returns a descriptorSource with the services of names, all services if names is nil.
+func newDescriptorSource(fdps map[string]*descriptorpb.FileDescriptorProto, names []string) (
+	*descriptorSource, error) {
+	var set descriptorpb.FileDescriptorSet
+	for _, fdp := range fdps {
+		set.File = append(set.File, fdp)
+	}
+
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, err
+	}
+
+	source := new(descriptorSource)
+	if names == nil {
+		files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+			for i := 0; i < fd.Services().Len(); i++ {
+				source.services = append(source.services, fd.Services().Get(i))
+			}
+			return true
+		})
+	} else {
+		for _, name := range names {
+			desc, err := files.FindDescriptorByName(protoreflect.FullName(name))
+			if err != nil {
+				return nil, err
+			}
+
+			svc, ok := desc.(protoreflect.ServiceDescriptor)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a rpc service", name)
+			}
+			source.services = append(source.services, svc)
+		}
+	}
+	if len(source.services) == 0 {
+		return nil, errNoServices
+	}
+
+	return source, nil
+}
+
+// findMethod finds the method of rpcPath, like user.User/GetUser.
+func (s *descriptorSource) findMethod(rpcPath string) (protoreflect.MethodDescriptor, error) {
+	for _, svc := range s.services {
+		for i := 0; i < svc.Methods().Len(); i++ {
+			method := svc.Methods().Get(i)
+			if methodPath(method) == rpcPath {
+				return method, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("rpc method %s not found", rpcPath)
+}
+
+func methodPath(method protoreflect.MethodDescriptor) string {
+	return fmt.Sprintf("%s/%s", method.Parent().FullName(), method.Name())
+}
+
+func reflectionRoundTrip(stream rpb.ServerReflection_ServerReflectionInfoClient,
+	req *rpb.ServerReflectionRequest) (*rpb.ServerReflectionResponse, error) {
+	if err := stream.Send(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if errResp := resp.GetErrorResponse(); errResp != nil {
+		return nil, fmt.Errorf("server reflection: %s", errResp.GetErrorMessage())
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,258 @@
+package gateway
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/rest/httpx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	metadataHeaderPrefix = "Grpc-Metadata-"
+	forwardedForKey      = "x-forwarded-for"
+	maxBodyLen           = 8 << 20 // 8MB
+)
+
+var (
+	marshaler = protojson.MarshalOptions{
+		EmitUnpopulated: true,
+	}
+	unmarshaler = protojson.UnmarshalOptions{
+		DiscardUnknown: true,
+	}
+)
+
+type errorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// newHandler returns a http.HandlerFunc that invokes the rpc method of r on conn.
+func newHandler(conn grpc.ClientConnInterface, r rule, timeout time.Duration) (http.HandlerFunc, error) {
+	if r.rpc.IsStreamingClient() || r.rpc.IsStreamingServer() {
+		return nil, fmt.Errorf("streaming rpc method %s is not supported", methodPath(r.rpc))
+	}
+
+	input := r.rpc.Input()
+	if r.body != bodyAll && len(r.body) > 0 && findField(input, r.body) == nil {
+		return nil, fmt.Errorf("body field %s not found in %s", r.body, input.FullName())
+	}
+	for _, name := range routeVars(r.path) {
+		if _, _, err := resolveField(dynamicpb.NewMessage(input), name); err != nil {
+			return nil, fmt.Errorf("path variable of %s: %v", r.path, err)
+		}
+	}
+
+	fullMethod := "/" + methodPath(r.rpc)
+	return func(w http.ResponseWriter, req *http.Request) {
+		in, err := buildRequest(req, input, r.body)
+		if err != nil {
+			httpx.WriteJson(w, http.StatusBadRequest, errorResponse{
+				Code:    int(codes.InvalidArgument),
+				Message: err.Error(),
+			})
+			return
+		}
+
+		ctx := req.Context()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		var header metadata.MD
+		out := dynamicpb.NewMessage(r.rpc.Output())
+		err = conn.Invoke(metadata.NewOutgoingContext(ctx, outgoingMetadata(req)), fullMethod, in, out,
+			grpc.Header(&header))
+		for k, vals := range header {
+			for _, val := range vals {
+				w.Header().Add(metadataHeaderPrefix+k, val)
+			}
+		}
+		if err != nil {
+			writeError(w, req, err)
+			return
+		}
+
+		content, err := marshaler.Marshal(out)
+		if err != nil {
+			writeError(w, req, err)
+			return
+		}
+
+		w.Header().Set(httpx.ContentType, httpx.ApplicationJson)
+		w.WriteHeader(http.StatusOK)
+		if _, err = w.Write(content); err != nil {
+			logx.WithContext(req.Context()).Error(err)
+		}
+	}, nil
+}
+
+// buildRequest builds the request message from the json body, the query and the path variables,
+// the later ones take precedence.
+func buildRequest(r *http.Request, input protoreflect.MessageDescriptor, body string) (
+	*dynamicpb.Message, error) {
+	msg := dynamicpb.NewMessage(input)
+	if len(body) > 0 && r.Body != nil {
+		content, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodyLen))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(strings.TrimSpace(string(content))) > 0 {
+			if body != bodyAll {
+				fd := findField(input, body)
+				content = []byte(fmt.Sprintf(`{%q:%s}`, fd.JSONName(), content))
+			}
+			if err = unmarshaler.Unmarshal(content, msg); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// the query parameters that match no fields are ignored, like the ones for the proxies.
+	for key, vals := range r.URL.Query() {
+		if err := setField(msg, key, vals); err != nil && err != errFieldNotFound {
+			return nil, err
+		}
+	}
+
+	for key, val := range httpx.GetPathVars(r) {
+		if err := setField(msg, key, []string{val}); err != nil {
+			return nil, err
+		}
+	}
+
+	return msg, nil
+}
+
+func outgoingMetadata(r *http.Request) metadata.MD {
+	md := metadata.MD{}
+	for key, vals := range r.Header {
+		if strings.HasPrefix(key, metadataHeaderPrefix) {
+			md.Append(strings.TrimPrefix(key, metadataHeaderPrefix), vals...)
+		}
+	}
+	md.Set(forwardedForKey, httpx.GetRemoteAddr(r))
+
+	return md
+}
+
+// routeVars returns the variables in the route path, like id of /users/:id.
+func routeVars(routePath string) []string {
+	var vars []string
+	for _, segment := range strings.Split(routePath, "/") {
+		if strings.HasPrefix(segment, ":") {
+			vars = append(vars, segment[1:])
+		}
+	}
+
+	return vars
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		st = status.FromContextError(err)
+	}
+
+	code := httpStatus(st.Code())
+	if code >= http.StatusInternalServerError {
+		logx.WithContext(r.Context()).Errorf("[gateway] %s - %s", r.RequestURI, st.Message())
+	}
+
+	httpx.WriteJson(w, code, errorResponse{
+		Code:    int(st.Code()),
+		Message: st.Message(),
+	})
+}
+
+// httpStatus returns the http status code of the grpc status code.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		// the nginx convention of client closed request.
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func parseValue(fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(s)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(s, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(s, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BytesKind:
+		v, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			v, err = base64.URLEncoding.DecodeString(s)
+		}
+		return protoreflect.ValueOfBytes(v), err
+	case protoreflect.EnumKind:
+		if v := fd.Enum().Values().ByName(protoreflect.Name(s)); v != nil {
+			return protoreflect.ValueOfEnum(v.Number()), nil
+		}
+		v, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), err
+	default:
+		return protoreflect.Value{}, fmt.Errorf("field %s of kind %s can't be set from string",
+			fd.FullName(), fd.Kind())
+	}
+}
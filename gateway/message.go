@@ -0,0 +1,86 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var errFieldNotFound = errors.New("field not found")
+
+// findField finds the field by the proto name or the json name.
+func findField(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if fd := md.Fields().ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+
+	return md.Fields().ByJSONName(name)
+}
+
+// resolveField resolves the field of the dotted path in msg, like user.id,
+// the parent messages are created if not set.
+func resolveField(msg protoreflect.Message, fieldPath string) (
+	protoreflect.Message, protoreflect.FieldDescriptor, error) {
+	names := strings.Split(fieldPath, ".")
+	fds := make([]protoreflect.FieldDescriptor, len(names))
+	md := msg.Descriptor()
+	for i, name := range names {
+		fd := findField(md, name)
+		if fd == nil {
+			return nil, nil, errFieldNotFound
+		}
+		if i < len(names)-1 {
+			if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+				return nil, nil, fmt.Errorf("field %s is not a message", fd.FullName())
+			}
+			md = fd.Message()
+		}
+		fds[i] = fd
+	}
+
+	// the parents are created after the whole path is resolved, to leave msg untouched on errors.
+	last := len(fds) - 1
+	for _, fd := range fds[:last] {
+		msg = msg.Mutable(fd).Message()
+	}
+
+	return msg, fds[last], nil
+}
+
+// setField sets the field of the dotted path in msg from the string values,
+// all values are appended to the repeated fields, the last value is used for the others.
+func setField(msg protoreflect.Message, fieldPath string, vals []string) error {
+	if len(vals) == 0 {
+		return nil
+	}
+
+	parent, fd, err := resolveField(msg, fieldPath)
+	if err != nil {
+		return err
+	}
+
+	if fd.IsMap() {
+		return fmt.Errorf("map field %s can't be set from string", fd.FullName())
+	}
+	if fd.IsList() {
+		list := parent.Mutable(fd).List()
+		for _, val := range vals {
+			v, err := parseValue(fd, val)
+			if err != nil {
+				return fmt.Errorf("bad value of %s: %v", fieldPath, err)
+			}
+			list.Append(v)
+		}
+		return nil
+	}
+
+	v, err := parseValue(fd, vals[len(vals)-1])
+	if err != nil {
+		return fmt.Errorf("bad value of %s: %v", fieldPath, err)
+	}
+
+	parent.Set(fd, v)
+	return nil
+}
@@ -0,0 +1,158 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/lukebull/go-zero-extern/tools/goctl/api/parser"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	bodyAll          = "*"
+	prefixAnnotation = "prefix"
+)
+
+// pathVarPattern matches the variables in the path templates, like {id} and {name=shelves/*}.
+var pathVarPattern = regexp.MustCompile(`\{([^}=]+)(=[^}]*)?}`)
+
+// A rule maps a http route to a rpc method.
+type rule struct {
+	method string
+	path   string
+	body   string
+	rpc    protoreflect.MethodDescriptor
+}
+
+// annotationRules returns the rules from the google.api.http annotations of the methods in source.
+func annotationRules(source *descriptorSource) []rule {
+	var rules []rule
+	for _, svc := range source.services {
+		for i := 0; i < svc.Methods().Len(); i++ {
+			method := svc.Methods().Get(i)
+			opts, ok := method.Options().(*descriptorpb.MethodOptions)
+			if !ok || opts == nil {
+				continue
+			}
+
+			httpRule, ok := proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule)
+			if !ok || httpRule == nil {
+				continue
+			}
+
+			for _, binding := range append([]*annotations.HttpRule{httpRule}, httpRule.AdditionalBindings...) {
+				if r, ok := annotationRule(method, binding); ok {
+					rules = append(rules, r)
+				}
+			}
+		}
+	}
+
+	return rules
+}
+
+// apiRules returns the rules of the routes in the .api file,
+// each route is mapped to the rpc method with the same name as its handler.
+func apiRules(source *descriptorSource, file string) ([]rule, error) {
+	api, err := parser.Parse(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []rule
+	for _, group := range api.Service.Groups {
+		prefix := group.GetAnnotation(prefixAnnotation)
+		for _, route := range group.Routes {
+			method, err := findMethodByName(source, route.Handler)
+			if err != nil {
+				return nil, err
+			}
+
+			rules = append(rules, rule{
+				method: strings.ToUpper(route.Method),
+				path:   path.Join("/", prefix, route.Path),
+				body:   bodyAll,
+				rpc:    method,
+			})
+		}
+	}
+
+	return rules, nil
+}
+
+// mappingRules returns the rules of the route mappings in config.
+func mappingRules(source *descriptorSource, mappings []RouteMapping) ([]rule, error) {
+	rules := make([]rule, 0, len(mappings))
+	for _, mapping := range mappings {
+		method, err := source.findMethod(strings.TrimPrefix(mapping.RpcPath, "/"))
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule{
+			method: strings.ToUpper(mapping.Method),
+			path:   mapping.Path,
+			body:   mapping.Body,
+			rpc:    method,
+		})
+	}
+
+	return rules, nil
+}
+
+func annotationRule(method protoreflect.MethodDescriptor, httpRule *annotations.HttpRule) (rule, bool) {
+	r := rule{
+		body: httpRule.Body,
+		rpc:  method,
+	}
+	var template string
+	switch pattern := httpRule.Pattern.(type) {
+	case *annotations.HttpRule_Get:
+		r.method, template = http.MethodGet, pattern.Get
+	case *annotations.HttpRule_Put:
+		r.method, template = http.MethodPut, pattern.Put
+	case *annotations.HttpRule_Post:
+		r.method, template = http.MethodPost, pattern.Post
+	case *annotations.HttpRule_Delete:
+		r.method, template = http.MethodDelete, pattern.Delete
+	case *annotations.HttpRule_Patch:
+		r.method, template = http.MethodPatch, pattern.Patch
+	case *annotations.HttpRule_Custom:
+		r.method, template = strings.ToUpper(pattern.Custom.GetKind()), pattern.Custom.GetPath()
+	default:
+		return rule{}, false
+	}
+
+	// the path templates like /v1/{name=shelves/*} are routed as /v1/:name,
+	// so that the variables match single segments.
+	r.path = pathVarPattern.ReplaceAllString(template, ":$1")
+	return r, true
+}
+
+func findMethodByName(source *descriptorSource, name string) (protoreflect.MethodDescriptor, error) {
+	var found protoreflect.MethodDescriptor
+	for _, svc := range source.services {
+		for i := 0; i < svc.Methods().Len(); i++ {
+			method := svc.Methods().Get(i)
+			if !strings.EqualFold(string(method.Name()), name) {
+				continue
+			}
+			if found != nil {
+				return nil, fmt.Errorf("ambiguous rpc methods %s and %s for handler %s",
+					methodPath(found), methodPath(method), name)
+			}
+			found = method
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no rpc method for handler %s", name)
+	}
+
+	return found, nil
+}
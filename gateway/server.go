@@ -0,0 +1,128 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/rest"
+	"github.com/lukebull/go-zero-extern/zrpc"
+	"google.golang.org/grpc"
+)
+
+// A Server is a gateway server that proxies the http requests to the rpc servers.
+type Server struct {
+	*rest.Server
+	upstreams []Upstream
+	timeout   time.Duration
+}
+
+// MustNewServer returns a Server, exits on any error.
+func MustNewServer(c GatewayConf, opts ...rest.RunOption) *Server {
+	return &Server{
+		Server:    rest.MustNewServer(c.RestConf, opts...),
+		upstreams: c.Upstreams,
+		timeout:   c.Timeout,
+	}
+}
+
+// Start starts the gateway server, the routes are loaded from the upstreams before serving.
+func (s *Server) Start() {
+	logx.Must(s.build())
+	s.Server.Start()
+}
+
+// Stop stops the gateway server.
+func (s *Server) Stop() {
+	s.Server.Stop()
+}
+
+func (s *Server) build() error {
+	for _, up := range s.upstreams {
+		cli, err := zrpc.NewClient(up.Grpc)
+		if err != nil {
+			return fmt.Errorf("upstream %s: %v", upstreamName(up), err)
+		}
+
+		routes, err := buildRoutes(up, cli.Conn(), s.timeout)
+		if err != nil {
+			return fmt.Errorf("upstream %s: %v", upstreamName(up), err)
+		}
+
+		s.Server.AddRoutes(routes)
+	}
+
+	return nil
+}
+
+// buildRoutes returns the routes of the upstream, from the google.api.http annotations,
+// the routes in the .api file and the route mappings.
+func buildRoutes(up Upstream, conn grpc.ClientConnInterface, timeout time.Duration) ([]rest.Route, error) {
+	source, err := loadSource(up, conn, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := annotationRules(source)
+	if len(up.ApiFile) > 0 {
+		apiRules, err := apiRules(source, up.ApiFile)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, apiRules...)
+	}
+
+	mappings, err := mappingRules(source, up.Mappings)
+	if err != nil {
+		return nil, err
+	}
+	rules = append(rules, mappings...)
+
+	routes := make([]rest.Route, 0, len(rules))
+	for _, r := range rules {
+		handler, err := newHandler(conn, r, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		logx.Infof("gateway route %s %s -> %s", r.method, r.path, methodPath(r.rpc))
+		routes = append(routes, rest.Route{
+			Method:  r.method,
+			Path:    r.path,
+			Handler: handler,
+		})
+	}
+
+	return routes, nil
+}
+
+func loadSource(up Upstream, conn grpc.ClientConnInterface, timeout time.Duration) (*descriptorSource, error) {
+	if len(up.ProtoSets) > 0 {
+		return loadProtoSets(up.ProtoSets)
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return loadByReflection(ctx, conn)
+}
+
+func upstreamName(up Upstream) string {
+	if len(up.Name) > 0 {
+		return up.Name
+	}
+	if len(up.Grpc.Target) > 0 {
+		return up.Grpc.Target
+	}
+	if len(up.Grpc.Endpoints) > 0 {
+		return strings.Join(up.Grpc.Endpoints, ",")
+	}
+
+	return up.Grpc.Etcd.Key
+}
@@ -0,0 +1,407 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/rest/router"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const testApi = `syntax = "v1"
+
+@server(
+	prefix: /api
+)
+service users {
+	@handler getUser
+	get /users/:id
+}
+`
+
+func TestBuildRoutesWithProtoSets(t *testing.T) {
+	fdp, conn := startTestServer(t)
+	protoSet := writeProtoSet(t, fdp)
+
+	handle := buildTestRouter(t, Upstream{
+		ProtoSets: []string{protoSet},
+		Mappings: []RouteMapping{
+			{Method: "post", Path: "/fail/:id", RpcPath: "/gwtest.Users/Fail", Body: bodyAll},
+		},
+	}, conn)
+
+	r := httptest.NewRequest(http.MethodGet,
+		"/v1/users/42?name=foo&tags=a&tags=b&filter.limit=3&active=true&status=ACTIVE&unknown=1", nil)
+	r.Header.Set("Grpc-Metadata-App", "gw")
+	w := serve(handle, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gw", w.Header().Get("Grpc-Metadata-X-App"))
+	assert.JSONEq(t, `{"id":"42","name":"foo","tags":["a","b"],"filter":{"limit":3},"active":true,"status":"ACTIVE"}`,
+		w.Body.String())
+
+	w = serve(handle, httptest.NewRequest(http.MethodGet, "/v1/names/bob", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "bob", decode(t, w)["name"])
+
+	w = serve(handle, httptest.NewRequest(http.MethodPost, "/v1/users?name=override",
+		strings.NewReader(`{"id":7,"name":"body","extra":1}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "7", decode(t, w)["id"])
+	assert.Equal(t, "body", decode(t, w)["name"])
+
+	w = serve(handle, httptest.NewRequest(http.MethodPost, "/fail/5", strings.NewReader(`{"name":"x"}`)))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, float64(codes.NotFound), decode(t, w)["code"])
+
+	w = serve(handle, httptest.NewRequest(http.MethodPost, "/fail/8", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	for _, target := range []string{"/v1/users/abc", "/v1/users/1?active=maybe", "/v1/users/1?filter.limit=x"} {
+		w = serve(handle, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+	}
+	w = serve(handle, httptest.NewRequest(http.MethodPost, "/v1/users", strings.NewReader(`{"id":`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBuildRoutesByReflection(t *testing.T) {
+	_, conn := startTestServer(t)
+	apiFile := filepath.Join(t.TempDir(), "users.api")
+	assert.Nil(t, ioutil.WriteFile(apiFile, []byte(testApi), os.ModePerm))
+
+	handle := buildTestRouter(t, Upstream{
+		ApiFile: apiFile,
+	}, conn)
+
+	w := serve(handle, httptest.NewRequest(http.MethodGet, "/api/users/3?name=api", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "3", decode(t, w)["id"])
+	assert.Equal(t, "api", decode(t, w)["name"])
+
+	w = serve(handle, httptest.NewRequest(http.MethodGet, "/v1/users/4", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestBuildRoutesErrors(t *testing.T) {
+	fdp, conn := startTestServer(t)
+	protoSet := writeProtoSet(t, fdp)
+
+	tests := []struct {
+		name     string
+		mappings []RouteMapping
+	}{
+		{
+			name:     "unknown method",
+			mappings: []RouteMapping{{Method: "GET", Path: "/x", RpcPath: "gwtest.Users/Nothing"}},
+		},
+		{
+			name:     "streaming",
+			mappings: []RouteMapping{{Method: "GET", Path: "/x", RpcPath: "gwtest.Users/Watch"}},
+		},
+		{
+			name:     "bad path variable",
+			mappings: []RouteMapping{{Method: "GET", Path: "/x/:nothing", RpcPath: "gwtest.Users/Fail"}},
+		},
+		{
+			name:     "bad body",
+			mappings: []RouteMapping{{Method: "POST", Path: "/x", RpcPath: "gwtest.Users/Fail", Body: "nothing"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := buildRoutes(Upstream{
+				ProtoSets: []string{protoSet},
+				Mappings:  test.mappings,
+			}, conn, time.Second)
+			assert.NotNil(t, err)
+		})
+	}
+
+	_, err := buildRoutes(Upstream{ProtoSets: []string{"not-exist"}}, conn, time.Second)
+	assert.NotNil(t, err)
+}
+
+func TestHttpStatus(t *testing.T) {
+	assert.Equal(t, http.StatusOK, httpStatus(codes.OK))
+	assert.Equal(t, 499, httpStatus(codes.Canceled))
+	assert.Equal(t, http.StatusBadRequest, httpStatus(codes.InvalidArgument))
+	assert.Equal(t, http.StatusGatewayTimeout, httpStatus(codes.DeadlineExceeded))
+	assert.Equal(t, http.StatusConflict, httpStatus(codes.AlreadyExists))
+	assert.Equal(t, http.StatusForbidden, httpStatus(codes.PermissionDenied))
+	assert.Equal(t, http.StatusUnauthorized, httpStatus(codes.Unauthenticated))
+	assert.Equal(t, http.StatusNotImplemented, httpStatus(codes.Unimplemented))
+	assert.Equal(t, http.StatusServiceUnavailable, httpStatus(codes.Unavailable))
+	assert.Equal(t, http.StatusInternalServerError, httpStatus(codes.DataLoss))
+}
+
+func buildTestRouter(t *testing.T, up Upstream, conn grpc.ClientConnInterface) http.Handler {
+	routes, err := buildRoutes(up, conn, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rt := router.NewRouter()
+	for _, route := range routes {
+		if err = rt.Handle(route.Method, route.Path, route.Handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return rt
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	var m map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+func serve(handle http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, r)
+	return w
+}
+
+// startTestServer starts an echo rpc server of the descriptor built in code, with server reflection.
+func startTestServer(t *testing.T) (*descriptorpb.FileDescriptorProto, *grpc.ClientConn) {
+	fdp := testFileDescriptor()
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := fd.Services().Get(0)
+	echo := svc.Methods().ByName("GetUser").Input()
+	unary := func(name string, handle func(ctx context.Context, in *dynamicpb.Message) (interface{}, error)) grpc.MethodDesc {
+		input := svc.Methods().ByName(protoreflect.Name(name)).Input()
+		return grpc.MethodDesc{
+			MethodName: name,
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error,
+				_ grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := dynamicpb.NewMessage(input)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("app")) > 0 {
+					_ = grpc.SetHeader(ctx, metadata.Pairs("x-app", md.Get("app")[0]))
+				}
+
+				return handle(ctx, in)
+			},
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	content, err := proto.Marshal(fdp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = zw.Write(content)
+	_ = zw.Close()
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: string(svc.FullName()),
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			unary("GetUser", func(ctx context.Context, in *dynamicpb.Message) (interface{}, error) {
+				return in, nil
+			}),
+			unary("CreateUser", func(ctx context.Context, in *dynamicpb.Message) (interface{}, error) {
+				return in.Get(in.Descriptor().Fields().ByName("user")).Message().Interface(), nil
+			}),
+			unary("Fail", func(ctx context.Context, in *dynamicpb.Message) (interface{}, error) {
+				code := in.Get(echo.Fields().ByName("id")).Int()
+				return nil, status.Error(codes.Code(code), "failed")
+			}),
+		},
+		Streams: []grpc.StreamDesc{
+			{
+				StreamName:    "Watch",
+				ServerStreams: true,
+				Handler: func(srv interface{}, stream grpc.ServerStream) error {
+					return nil
+				},
+			},
+		},
+		Metadata: buf.Bytes(),
+	}, struct{}{})
+	reflection.Register(server)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = server.Serve(lis)
+	}()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+		server.Stop()
+	})
+
+	return fdp, conn
+}
+
+func testFileDescriptor() *descriptorpb.FileDescriptorProto {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type,
+		label descriptorpb.FieldDescriptorProto_Label, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    label.Enum(),
+			JsonName: proto.String(name),
+		}
+		if len(typeName) > 0 {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	httpOptions := func(rule *annotations.HttpRule) *descriptorpb.MethodOptions {
+		opts := &descriptorpb.MethodOptions{}
+		proto.SetExtension(opts, annotations.E_Http, rule)
+		return opts
+	}
+
+	return &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("gwtest/users.proto"),
+		Package:    proto.String("gwtest"),
+		Dependency: []string{"google/api/annotations.proto"},
+		Syntax:     proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{
+			{
+				Name: proto.String("Status"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+					{Name: proto.String("ACTIVE"), Number: proto.Int32(1)},
+				},
+			},
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Filter"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("limit", 1, descriptorpb.FieldDescriptorProto_TYPE_INT32, optional, ""),
+				},
+			},
+			{
+				Name: proto.String("User"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional, ""),
+					field("name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+					field("tags", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING,
+						descriptorpb.FieldDescriptorProto_LABEL_REPEATED, ""),
+					field("filter", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, optional, ".gwtest.Filter"),
+					field("active", 5, descriptorpb.FieldDescriptorProto_TYPE_BOOL, optional, ""),
+					field("status", 6, descriptorpb.FieldDescriptorProto_TYPE_ENUM, optional, ".gwtest.Status"),
+				},
+			},
+			{
+				Name: proto.String("CreateUserRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("user", 1, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, optional, ".gwtest.User"),
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("Users"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:       proto.String("GetUser"),
+						InputType:  proto.String(".gwtest.User"),
+						OutputType: proto.String(".gwtest.User"),
+						Options: httpOptions(&annotations.HttpRule{
+							Pattern: &annotations.HttpRule_Get{Get: "/v1/users/{id}"},
+							AdditionalBindings: []*annotations.HttpRule{
+								{Pattern: &annotations.HttpRule_Get{Get: "/v1/names/{name=names/*}"}},
+							},
+						}),
+					},
+					{
+						Name:       proto.String("CreateUser"),
+						InputType:  proto.String(".gwtest.CreateUserRequest"),
+						OutputType: proto.String(".gwtest.User"),
+						Options: httpOptions(&annotations.HttpRule{
+							Pattern: &annotations.HttpRule_Post{Post: "/v1/users"},
+							Body:    "user",
+						}),
+					},
+					{
+						Name:       proto.String("Fail"),
+						InputType:  proto.String(".gwtest.User"),
+						OutputType: proto.String(".gwtest.User"),
+					},
+					{
+						Name:            proto.String("Watch"),
+						InputType:       proto.String(".gwtest.User"),
+						OutputType:      proto.String(".gwtest.User"),
+						ServerStreaming: proto.Bool(true),
+					},
+				},
+			},
+		},
+	}
+}
+
+func writeProtoSet(t *testing.T, fdp *descriptorpb.FileDescriptorProto) string {
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, name := range []string{
+		"google/protobuf/descriptor.proto",
+		"google/api/http.proto",
+		"google/api/annotations.proto",
+	} {
+		fd, err := protoregistry.GlobalFiles.FindFileByPath(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+	}
+	set.File = append(set.File, fdp)
+
+	content, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "users.protoset")
+	if err = ioutil.WriteFile(file, content, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/lukebull/go-zero-extern/core/service"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+	"github.com/lukebull/go-zero-extern/rest/ws"
 )
 
 type (
@@ -13,6 +15,51 @@ type (
 		KeyFile     string
 	}
 
+	// A JwtPublicKeyConf is a public key config to verify the asymmetric signed jwt tokens.
+	JwtPublicKeyConf struct {
+		Kid     string `json:",optional"`
+		KeyFile string
+	}
+
+	// A JwtClaimsConf is the config to validate the registered claims of jwt tokens.
+	JwtClaimsConf struct {
+		Issuer   string `json:",optional"`
+		Audience string `json:",optional"`
+		// Leeway is the allowed clock skew on validating exp, iat and nbf.
+		Leeway time.Duration `json:",optional"`
+	}
+
+	// A CorsConf is the config of cross-origin resource sharing, disabled if AllowOrigins is empty.
+	CorsConf struct {
+		// AllowOrigins are the allowed origins, * means all origins,
+		// and wildcards like https://*.example.com are supported.
+		AllowOrigins []string `json:",optional"`
+		// AllowMethods defaults to GET, HEAD, POST, PATCH, PUT and DELETE.
+		AllowMethods []string `json:",optional"`
+		// AllowHeaders defaults to the headers requested by the preflight requests.
+		AllowHeaders     []string      `json:",optional"`
+		ExposeHeaders    []string      `json:",optional"`
+		AllowCredentials bool          `json:",optional"`
+		MaxAge           time.Duration `json:",optional"`
+	}
+
+	// A RateLimitConf is the config of rate limiting, disabled if Quota is 0.
+	// The requests are limited per route, and per client ip or per jwt claim in the routes.
+	RateLimitConf struct {
+		// Period is the period in seconds that Quota requests are allowed.
+		Period int `json:",default=1"`
+		Quota  int `json:",optional"`
+		// KeyBy is how to share the quota in a route, the requests from the clients
+		// without the jwt claim are limited by ip.
+		KeyBy string `json:",default=ip,options=route|ip|claim"`
+		// Claim is the jwt claim to limit by, like uid, required if KeyBy is claim.
+		Claim string `json:",optional"`
+		// Redis shares the quotas across the instances, the requests are limited in process
+		// if not set or unreachable.
+		Redis     redis.RedisConf `json:",optional"`
+		KeyPrefix string          `json:",default=ratelimit:"`
+	}
+
 	// A SignatureConf is a signature config.
 	SignatureConf struct {
 		Strict      bool          `json:",default=false"`
@@ -40,5 +87,11 @@ type (
 		Timeout      int64         `json:",default=3000"`
 		CpuThreshold int64         `json:",default=900,range=[0:1000]"`
 		Signature    SignatureConf `json:",optional"`
+		Cors         CorsConf      `json:",optional"`
+		WebSocket    ws.Conf       `json:",optional"`
+		RateLimit    RateLimitConf `json:",optional"`
+		// HealthPath serves the readiness for the load balancers, like /health,
+		// which responds 503 once the server is shutting down.
+		HealthPath string `json:",optional"`
 	}
 )
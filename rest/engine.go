@@ -1,26 +1,46 @@
 package rest
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/justinas/alice"
 	"github.com/lukebull/go-zero-extern/core/codec"
+	"github.com/lukebull/go-zero-extern/core/health"
+	"github.com/lukebull/go-zero-extern/core/limit"
 	"github.com/lukebull/go-zero-extern/core/load"
+	"github.com/lukebull/go-zero-extern/core/search"
 	"github.com/lukebull/go-zero-extern/core/stat"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
 	"github.com/lukebull/go-zero-extern/rest/handler"
 	"github.com/lukebull/go-zero-extern/rest/httpx"
 	"github.com/lukebull/go-zero-extern/rest/internal"
 	"github.com/lukebull/go-zero-extern/rest/router"
+	"github.com/lukebull/go-zero-extern/rest/token"
+	"github.com/lukebull/go-zero-extern/rest/ws"
 )
 
-// use 1000m to represent 100%
-const topCpuUsage = 1000
+const (
+	// use 1000m to represent 100%
+	topCpuUsage       = 1000
+	corsRequestMethod = "Access-Control-Request-Method"
+	rateLimitByRoute  = "route"
+	rateLimitByClaim  = "claim"
+)
 
-// ErrSignatureConfig is an error that indicates bad config for signature.
-var ErrSignatureConfig = errors.New("bad config for Signature")
+var (
+	// ErrSignatureConfig is an error that indicates bad config for signature.
+	ErrSignatureConfig = errors.New("bad config for Signature")
+	// ErrRateLimitConfig is an error that indicates bad config for rate limit.
+	ErrRateLimitConfig = errors.New("bad config for RateLimit")
+)
 
 type engine struct {
 	conf                 RestConf
@@ -30,6 +50,16 @@ type engine struct {
 	middlewares          []Middleware
 	shedder              load.Shedder
 	priorityShedder      load.Shedder
+	// preflights are the CORS preflight handlers by the requested method and path.
+	preflights map[string]*search.Tree
+	// apis are the bound routes by method, the static files don't shadow them.
+	apis    map[string]*search.Tree
+	statics []staticRoute
+	hub     *ws.Hub
+	// quotaLimiter is shared by the routes limited by RestConf.RateLimit.
+	quotaLimiter *limit.QuotaLimiter
+	lock         sync.Mutex
+	server       *internal.Server
 }
 
 func newEngine(c RestConf) *engine {
@@ -49,6 +79,22 @@ func (s *engine) AddRoutes(r featuredRoutes) {
 	s.routes = append(s.routes, r)
 }
 
+func (s *engine) AddStatic(r staticRoute) {
+	s.statics = append(s.statics, r)
+}
+
+// Drain stops accepting new requests and waits for the in-flight requests to finish until ctx is done.
+func (s *engine) Drain(ctx context.Context) error {
+	s.lock.Lock()
+	server := s.server
+	s.lock.Unlock()
+	if server == nil {
+		return nil
+	}
+
+	return server.Drain(ctx)
+}
+
 func (s *engine) SetUnauthorizedCallback(callback handler.UnauthorizedCallback) {
 	s.unauthorizedCallback = callback
 }
@@ -66,37 +112,48 @@ func (s *engine) StartWithRouter(router httpx.Router) error {
 		return err
 	}
 
+	handle := s.wrapRouter(router)
+	setServer := func(srv *internal.Server) {
+		s.lock.Lock()
+		s.server = srv
+		s.lock.Unlock()
+	}
+	opts := []internal.StartOption{setServer}
+	if s.hub != nil {
+		// the websocket connections are hijacked, close them before the server returns.
+		opts = append(opts, internal.WithDrainHook(s.hub.Close))
+	}
 	if len(s.conf.CertFile) == 0 && len(s.conf.KeyFile) == 0 {
-		return internal.StartHttp(s.conf.Host, s.conf.Port, router)
+		return internal.StartHttp(s.conf.Host, s.conf.Port, handle, opts...)
 	}
 
-	return internal.StartHttps(s.conf.Host, s.conf.Port, s.conf.CertFile, s.conf.KeyFile, router)
+	return internal.StartHttps(s.conf.Host, s.conf.Port, s.conf.CertFile, s.conf.KeyFile, handle, opts...)
 }
 
-func (s *engine) appendAuthHandler(fr featuredRoutes, chain alice.Chain,
-	verifier func(alice.Chain) alice.Chain) alice.Chain {
-	if fr.jwt.enabled {
-		if len(fr.jwt.prevSecret) == 0 {
-			chain = chain.Append(handler.Authorize(fr.jwt.secret,
-				handler.WithUnauthorizedCallback(s.unauthorizedCallback)))
-		} else {
-			chain = chain.Append(handler.Authorize(fr.jwt.secret,
-				handler.WithPrevSecret(fr.jwt.prevSecret),
-				handler.WithUnauthorizedCallback(s.unauthorizedCallback)))
-		}
+func (s *engine) bindFeaturedRoutes(router httpx.Router, fr featuredRoutes, metrics *stat.Metrics) error {
+	verifier, err := s.signatureVerifier(fr.signature)
+	if err != nil {
+		return err
 	}
 
-	return verifier(chain)
-}
+	authorizer, err := s.jwtAuthorizer(fr.jwt)
+	if err != nil {
+		return err
+	}
 
-func (s *engine) bindFeaturedRoutes(router httpx.Router, fr featuredRoutes, metrics *stat.Metrics) error {
-	verifier, err := s.signatureVerifier(fr.signature)
+	limiter, err := s.rateLimiter(fr)
 	if err != nil {
 		return err
 	}
 
 	for _, route := range fr.routes {
-		if err := s.bindRoute(fr, router, metrics, route, verifier); err != nil {
+		if err := s.bindRoute(fr, router, metrics, route, authorizer, verifier, limiter); err != nil {
+			return err
+		}
+	}
+
+	for _, socket := range fr.sockets {
+		if err := s.bindWebSocket(fr, router, socket, authorizer, verifier); err != nil {
 			return err
 		}
 	}
@@ -105,32 +162,101 @@ func (s *engine) bindFeaturedRoutes(router httpx.Router, fr featuredRoutes, metr
 }
 
 func (s *engine) bindRoute(fr featuredRoutes, router httpx.Router, metrics *stat.Metrics,
-	route Route, verifier func(chain alice.Chain) alice.Chain) error {
+	route Route, authorizer, verifier func(chain alice.Chain) alice.Chain,
+	limiter func(chain alice.Chain, route Route) alice.Chain) error {
+	if len(fr.prefix) > 0 {
+		route.Path = path.Join(fr.prefix, route.Path)
+	}
+
 	chain := alice.New(
 		handler.TracingHandler,
 		s.getLogHandler(),
+	)
+	cors, corsEnabled := s.getCorsPolicy(fr)
+	if corsEnabled {
+		// before the other handlers, so that the rejected responses have CORS headers too.
+		chain = chain.Append(handler.CorsHandler(cors))
+	}
+	chain = chain.Append(
 		handler.PrometheusHandler(route.Path),
 		handler.MaxConns(s.conf.MaxConns),
 		handler.BreakerHandler(route.Method, route.Path, metrics),
 		handler.SheddingHandler(s.getShedder(fr.priority), metrics),
-		handler.TimeoutHandler(time.Duration(s.conf.Timeout)*time.Millisecond),
+		handler.TimeoutHandler(s.getTimeout(fr)),
 		handler.RecoverHandler,
 		handler.MetricHandler(metrics),
 		handler.MaxBytesHandler(s.conf.MaxBytes),
 		handler.GunzipHandler,
 	)
-	chain = s.appendAuthHandler(fr, chain, verifier)
+	// after the authorizer, so that the requests can be limited by the jwt claims.
+	chain = limiter(verifier(authorizer(chain)), route)
 
 	for _, middleware := range s.middlewares {
 		chain = chain.Append(convertMiddleware(middleware))
 	}
 	handle := chain.ThenFunc(route.Handler)
 
-	return router.Handle(route.Method, route.Path, handle)
+	if err := router.Handle(route.Method, route.Path, handle); err != nil {
+		return err
+	}
+	if err := s.addApi(route.Method, route.Path); err != nil {
+		return err
+	}
+
+	if !corsEnabled {
+		return nil
+	}
+
+	tree, ok := s.preflights[route.Method]
+	if !ok {
+		tree = search.NewTree()
+		s.preflights[route.Method] = tree
+	}
+
+	return tree.Add(path.Clean(route.Path), handler.CorsPreflightHandler(cors))
+}
+
+func (s *engine) bindWebSocket(fr featuredRoutes, router httpx.Router, socket WebSocketRoute,
+	authorizer, verifier func(chain alice.Chain) alice.Chain) error {
+	socketPath := socket.Path
+	if len(fr.prefix) > 0 {
+		socketPath = path.Join(fr.prefix, socketPath)
+	}
+
+	// the handlers that assume request/response lifetimes are not used, like timeout,
+	// max conns, breaker, shedding, max bytes and gunzip. The connections are limited by the hub.
+	chain := alice.New(
+		handler.TracingHandler,
+		handler.RecoverHandler,
+	)
+	chain = verifier(authorizer(chain))
+
+	for _, middleware := range s.middlewares {
+		chain = chain.Append(convertMiddleware(middleware))
+	}
+	handle := chain.Then(s.getHub().Handler(socketPath, socket.Handler))
+
+	if err := router.Handle(http.MethodGet, socketPath, handle); err != nil {
+		return err
+	}
+
+	return s.addApi(http.MethodGet, socketPath)
+}
+
+func (s *engine) addApi(method, reqPath string) error {
+	tree, ok := s.apis[method]
+	if !ok {
+		tree = search.NewTree()
+		s.apis[method] = tree
+	}
+
+	return tree.Add(path.Clean(reqPath), true)
 }
 
 func (s *engine) bindRoutes(router httpx.Router) error {
 	metrics := s.createMetrics()
+	s.preflights = make(map[string]*search.Tree)
+	s.apis = make(map[string]*search.Tree)
 
 	for _, fr := range s.routes {
 		if err := s.bindFeaturedRoutes(router, fr, metrics); err != nil {
@@ -153,6 +279,33 @@ func (s *engine) createMetrics() *stat.Metrics {
 	return metrics
 }
 
+func (s *engine) getCorsPolicy(fr featuredRoutes) (handler.CorsPolicy, bool) {
+	c := s.conf.Cors
+	if fr.cors != nil {
+		c = *fr.cors
+	}
+	if len(c.AllowOrigins) == 0 {
+		return handler.CorsPolicy{}, false
+	}
+
+	return handler.CorsPolicy{
+		AllowOrigins:     c.AllowOrigins,
+		AllowMethods:     c.AllowMethods,
+		AllowHeaders:     c.AllowHeaders,
+		ExposeHeaders:    c.ExposeHeaders,
+		AllowCredentials: c.AllowCredentials,
+		MaxAge:           c.MaxAge,
+	}, true
+}
+
+func (s *engine) getHub() *ws.Hub {
+	if s.hub == nil {
+		s.hub = ws.NewHub(s.conf.WebSocket)
+	}
+
+	return s.hub
+}
+
 func (s *engine) getLogHandler() func(http.Handler) http.Handler {
 	if s.conf.Verbose {
 		return handler.DetailedLogHandler
@@ -169,6 +322,216 @@ func (s *engine) getShedder(priority bool) load.Shedder {
 	return s.shedder
 }
 
+// getTimeout returns the timeout of the featured routes, falls back to RestConf.Timeout if not set.
+// The streaming routes have no timeout.
+func (s *engine) getTimeout(fr featuredRoutes) time.Duration {
+	if fr.streaming {
+		return 0
+	}
+	if fr.timeout > 0 {
+		return fr.timeout
+	}
+
+	return time.Duration(s.conf.Timeout) * time.Millisecond
+}
+
+// handleHealth serves the readiness on RestConf.HealthPath, before the other handlers,
+// so that the health checks are not logged, limited or shed.
+func (s *engine) handleHealth(next http.Handler) http.Handler {
+	if len(s.conf.HealthPath) == 0 {
+		return next
+	}
+
+	healthPath := path.Clean(s.conf.HealthPath)
+	readiness := health.ReadinessHandler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path.Clean(r.URL.Path) == healthPath &&
+			(r.Method == http.MethodGet || r.Method == http.MethodHead) {
+			readiness.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handlePreflights answers the CORS preflight requests before routing, otherwise the router
+// rejects them as not allowed methods.
+func (s *engine) handlePreflights(next http.Handler) http.Handler {
+	if len(s.preflights) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if handler.IsCorsPreflight(r) {
+			method := strings.ToUpper(r.Header.Get(corsRequestMethod))
+			if tree, ok := s.preflights[method]; ok {
+				if result, ok := tree.Search(path.Clean(r.URL.Path)); ok {
+					result.Item.(http.Handler).ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleStatics serves the static files on the GET and HEAD requests that match no routes,
+// the longest prefix takes precedence if the static prefixes overlap.
+func (s *engine) handleStatics(next http.Handler) http.Handler {
+	if len(s.statics) == 0 {
+		return next
+	}
+
+	statics := make([]staticRoute, len(s.statics))
+	for i, static := range s.statics {
+		chain := alice.New(
+			handler.TracingHandler,
+			s.getLogHandler(),
+			handler.PrometheusHandler(static.prefix),
+			handler.RecoverHandler,
+		)
+		for _, middleware := range s.middlewares {
+			chain = chain.Append(convertMiddleware(middleware))
+		}
+		statics[i] = staticRoute{
+			prefix:  static.prefix,
+			handler: chain.Then(static.handler),
+		}
+	}
+	sort.SliceStable(statics, func(i, j int) bool {
+		return len(statics[i].prefix) > len(statics[j].prefix)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			reqPath := path.Clean(r.URL.Path)
+			if !s.hasApi(reqPath) {
+				for _, static := range statics {
+					if static.match(reqPath) {
+						static.handler.ServeHTTP(w, r)
+						return
+					}
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// hasApi checks if any route matches reqPath, by any method, so that the router
+// responds with not allowed methods.
+func (s *engine) hasApi(reqPath string) bool {
+	for _, tree := range s.apis {
+		if _, ok := tree.Search(reqPath); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *engine) jwtAuthorizer(jwt jwtSetting) (func(chain alice.Chain) alice.Chain, error) {
+	if !jwt.enabled {
+		return func(chain alice.Chain) alice.Chain {
+			return chain
+		}, nil
+	}
+
+	opts := []handler.AuthorizeOption{
+		handler.WithUnauthorizedCallback(s.unauthorizedCallback),
+	}
+	if jwt.claims != nil {
+		opts = append(opts, handler.WithClaimsValidator(token.ClaimsValidator{
+			Issuer:   jwt.claims.Issuer,
+			Audience: jwt.claims.Audience,
+			Leeway:   jwt.claims.Leeway,
+		}))
+	}
+
+	// the asymmetric keys take precedence over the secret.
+	if len(jwt.jwksUrl) == 0 && len(jwt.publicKeys) == 0 {
+		if len(jwt.prevSecret) > 0 {
+			opts = append(opts, handler.WithPrevSecret(jwt.prevSecret))
+		}
+
+		return func(chain alice.Chain) alice.Chain {
+			return chain.Append(handler.Authorize(jwt.secret, opts...))
+		}, nil
+	}
+
+	var keyOpts []token.KeySetOption
+	if len(jwt.jwksUrl) > 0 {
+		keyOpts = append(keyOpts, token.WithJwksUrl(jwt.jwksUrl))
+	}
+	for _, key := range jwt.publicKeys {
+		publicKey, err := token.LoadPublicKey(key.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		keyOpts = append(keyOpts, token.WithPublicKey(key.Kid, publicKey))
+	}
+
+	keys, err := token.NewKeySet(keyOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(chain alice.Chain) alice.Chain {
+		return chain.Append(handler.AuthorizeWithKeySet(keys, opts...))
+	}, nil
+}
+
+// rateLimiter returns a func that limits the requests to the route in fr,
+// RestConf.RateLimit is used if not overridden by WithRateLimit.
+func (s *engine) rateLimiter(fr featuredRoutes) (func(chain alice.Chain, route Route) alice.Chain, error) {
+	c := s.conf.RateLimit
+	if fr.rateLimit != nil {
+		c = *fr.rateLimit
+	}
+	if c.Quota <= 0 {
+		return func(chain alice.Chain, _ Route) alice.Chain {
+			return chain
+		}, nil
+	}
+	if c.Period <= 0 || (c.KeyBy == rateLimitByClaim && len(c.Claim) == 0) {
+		return nil, ErrRateLimitConfig
+	}
+
+	var limiter *limit.QuotaLimiter
+	if fr.rateLimit == nil && s.quotaLimiter != nil {
+		limiter = s.quotaLimiter
+	} else {
+		var store *redis.Redis
+		if len(c.Redis.Host) > 0 {
+			store = c.Redis.NewRedis()
+		}
+		limiter = limit.NewQuotaLimiter(c.Period, c.Quota, store, c.KeyPrefix)
+		if fr.rateLimit == nil {
+			s.quotaLimiter = limiter
+		}
+	}
+
+	return func(chain alice.Chain, route Route) alice.Chain {
+		routeKey := route.Method + " " + route.Path
+		return chain.Append(handler.RateLimitHandler(limiter, func(r *http.Request) string {
+			switch c.KeyBy {
+			case rateLimitByRoute:
+				return routeKey
+			case rateLimitByClaim:
+				if val := r.Context().Value(c.Claim); val != nil {
+					return fmt.Sprintf("%s:%s:%v", routeKey, c.Claim, val)
+				}
+			}
+
+			return routeKey + ":" + httpx.GetRemoteAddr(r)
+		}))
+	}, nil
+}
+
 func (s *engine) signatureVerifier(signature signatureSetting) (func(chain alice.Chain) alice.Chain, error) {
 	if !signature.enabled {
 		return func(chain alice.Chain) alice.Chain {
@@ -213,6 +576,11 @@ func (s *engine) use(middleware Middleware) {
 	s.middlewares = append(s.middlewares, middleware)
 }
 
+// wrapRouter wraps the router with the handlers that run before routing.
+func (s *engine) wrapRouter(router http.Handler) http.Handler {
+	return s.handleHealth(s.handlePreflights(s.handleStatics(router)))
+}
+
 func convertMiddleware(ware Middleware) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return ware(next.ServeHTTP)
@@ -0,0 +1,659 @@
+package rest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gorilla/websocket"
+	"github.com/lukebull/go-zero-extern/core/health"
+	"github.com/lukebull/go-zero-extern/rest/fileserver"
+	"github.com/lukebull/go-zero-extern/rest/httpx"
+	"github.com/lukebull/go-zero-extern/rest/router"
+	"github.com/lukebull/go-zero-extern/rest/ws"
+)
+
+func serveEngine(t *testing.T, ngin *engine, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	return serveEngineRequest(t, ngin, httptest.NewRequest(method, path, nil))
+}
+
+func serveEngineRequest(t *testing.T, ngin *engine, r *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	rt := router.NewRouter()
+	if err := ngin.bindRoutes(rt); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	ngin.wrapRouter(rt).ServeHTTP(w, r)
+	return w
+}
+
+func newTestEngine(routes ...featuredRoutes) *engine {
+	ngin := newEngine(RestConf{
+		Host:     "localhost",
+		Port:     8888,
+		MaxConns: 10,
+		MaxBytes: 1024,
+		Timeout:  1000,
+	})
+	for _, r := range routes {
+		ngin.AddRoutes(r)
+	}
+
+	return ngin
+}
+
+func newFeaturedRoutes(rs []Route, opts ...RouteOption) featuredRoutes {
+	r := featuredRoutes{
+		routes: rs,
+	}
+	for _, opt := range opts {
+		opt(&r)
+	}
+
+	return r
+}
+
+func TestEngineWithPrefix(t *testing.T) {
+	okHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	ngin := newTestEngine(
+		newFeaturedRoutes([]Route{
+			{Method: http.MethodGet, Path: "/users", Handler: okHandler},
+			{Method: http.MethodGet, Path: "/", Handler: okHandler},
+		}, WithPrefix("/api/v1/")),
+		newFeaturedRoutes([]Route{
+			{Method: http.MethodGet, Path: "/ping", Handler: okHandler},
+		}),
+	)
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{path: "/api/v1/users", code: http.StatusOK},
+		{path: "/api/v1", code: http.StatusOK},
+		{path: "/users", code: http.StatusNotFound},
+		{path: "/ping", code: http.StatusOK},
+		{path: "/api/v1/ping", code: http.StatusNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			if w := serveEngine(t, ngin, http.MethodGet, test.path); w.Code != test.code {
+				t.Fatalf("expected %d, got %d", test.code, w.Code)
+			}
+		})
+	}
+}
+
+func TestWithPrefixInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic on prefix without leading slash")
+		}
+	}()
+
+	newFeaturedRoutes(nil, WithPrefix("api"))
+}
+
+func TestEngineWithTimeout(t *testing.T) {
+	slowHandler := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Millisecond * 200):
+			w.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+		}
+	}
+	ngin := newTestEngine(
+		newFeaturedRoutes([]Route{
+			{Method: http.MethodGet, Path: "/short", Handler: slowHandler},
+		}, WithTimeout(time.Millisecond*20)),
+		newFeaturedRoutes([]Route{
+			{Method: http.MethodGet, Path: "/default", Handler: slowHandler},
+		}),
+	)
+
+	if w := serveEngine(t, ngin, http.MethodGet, "/short"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected timeout, got %d", w.Code)
+	}
+	if w := serveEngine(t, ngin, http.MethodGet, "/default"); w.Code != http.StatusOK {
+		t.Fatalf("expected ok within the global timeout, got %d", w.Code)
+	}
+}
+
+func TestEngineGetTimeout(t *testing.T) {
+	ngin := newTestEngine()
+	if timeout := ngin.getTimeout(newFeaturedRoutes(nil)); timeout != time.Second {
+		t.Fatalf("expected global timeout, got %v", timeout)
+	}
+	if timeout := ngin.getTimeout(newFeaturedRoutes(nil, WithTimeout(time.Minute))); timeout != time.Minute {
+		t.Fatalf("expected route timeout, got %v", timeout)
+	}
+	if timeout := ngin.getTimeout(newFeaturedRoutes(nil, WithTimeout(time.Minute),
+		WithStreaming())); timeout != 0 {
+		t.Fatalf("expected no timeout on streaming routes, got %v", timeout)
+	}
+}
+
+func TestEngineWithJwks(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"keys":[{"kid":"k1","kty":"EC","crv":"P-256","x":"%s","y":"%s"}]}`,
+			base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+			base64.RawURLEncoding.EncodeToString(key.Y.Bytes()))
+	}))
+	defer jwks.Close()
+
+	ngin := newTestEngine(newFeaturedRoutes([]Route{
+		{
+			Method: http.MethodGet,
+			Path:   "/me",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, r.Context().Value("uid"))
+			},
+		},
+	}, WithJwks(jwks.URL), WithJwtClaims(JwtClaimsConf{
+		Issuer:   "https://issuer",
+		Audience: "api",
+	})))
+
+	sign := func(claims jwt.MapClaims) *http.Request {
+		tok := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		tok.Header["kid"] = "k1"
+		signed, err := tok.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := httptest.NewRequest(http.MethodGet, "/me", nil)
+		r.Header.Set("Authorization", "Bearer "+signed)
+		return r
+	}
+
+	w := serveEngineRequest(t, ngin, sign(jwt.MapClaims{
+		"uid": "42",
+		"iss": "https://issuer",
+		"aud": []string{"api"},
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}))
+	if w.Code != http.StatusOK || w.Body.String() != "42" {
+		t.Fatalf("expected claims injected, got %d %q", w.Code, w.Body.String())
+	}
+
+	for _, claims := range []jwt.MapClaims{
+		{"uid": "42", "iss": "https://evil", "aud": "api"},
+		{"uid": "42", "iss": "https://issuer", "aud": "other"},
+		{"uid": "42", "iss": "https://issuer", "aud": "api", "nbf": time.Now().Add(time.Hour).Unix()},
+	} {
+		if w = serveEngineRequest(t, ngin, sign(claims)); w.Code != http.StatusUnauthorized {
+			t.Fatalf("expected unauthorized on %v, got %d", claims, w.Code)
+		}
+	}
+
+	if w = serveEngine(t, ngin, http.MethodGet, "/me"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorized without token, got %d", w.Code)
+	}
+}
+
+func TestEngineWithJwtPublicKeysBadFile(t *testing.T) {
+	ngin := newTestEngine(newFeaturedRoutes([]Route{
+		{Method: http.MethodGet, Path: "/", Handler: func(w http.ResponseWriter, r *http.Request) {}},
+	}, WithJwtPublicKeys(JwtPublicKeyConf{Kid: "k1", KeyFile: "not-exist.pem"})))
+
+	if err := ngin.bindRoutes(router.NewRouter()); err == nil {
+		t.Fatal("expected error on bad public key file")
+	}
+}
+
+func TestEngineWithCors(t *testing.T) {
+	okHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	ngin := newTestEngine(
+		newFeaturedRoutes([]Route{
+			{Method: http.MethodGet, Path: "/users/:id", Handler: okHandler},
+			{Method: http.MethodPost, Path: "/users/:id", Handler: okHandler},
+		}, WithJwt("mysecret12345678")),
+		newFeaturedRoutes([]Route{
+			{Method: http.MethodGet, Path: "/internal", Handler: okHandler},
+		}, WithCors(CorsConf{})),
+	)
+	ngin.conf.Cors = CorsConf{
+		AllowOrigins:     []string{"https://*.example.com"},
+		AllowMethods:     []string{"GET", "POST"},
+		ExposeHeaders:    []string{"X-Trace"},
+		AllowCredentials: true,
+		MaxAge:           time.Minute,
+	}
+
+	preflight := func(path, origin, method string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodOptions, path, nil)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", method)
+		r.Header.Set("Access-Control-Request-Headers", "Authorization")
+		return serveEngineRequest(t, ngin, r)
+	}
+
+	w := preflight("/users/1", "https://app.example.com", http.MethodPost)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected preflight answered, got %d", w.Code)
+	}
+	for key, val := range map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Methods":     "GET, POST",
+		"Access-Control-Allow-Headers":     "Authorization",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Max-Age":           "60",
+	} {
+		if w.Header().Get(key) != val {
+			t.Fatalf("expected %s: %s, got %q", key, val, w.Header().Get(key))
+		}
+	}
+
+	w = preflight("/users/1", "https://evil.com", http.MethodGet)
+	if w.Code != http.StatusNoContent || len(w.Header().Get("Access-Control-Allow-Origin")) > 0 {
+		t.Fatalf("expected origin rejected, got %d %v", w.Code, w.Header())
+	}
+
+	// CORS disabled on /internal, and no route on DELETE, answered by router without CORS headers.
+	for _, w = range []*httptest.ResponseRecorder{
+		preflight("/internal", "https://app.example.com", http.MethodGet),
+		preflight("/users/1", "https://app.example.com", http.MethodDelete),
+	} {
+		if w.Code != http.StatusNoContent || len(w.Header().Get("Allow")) == 0 ||
+			len(w.Header().Get("Access-Control-Allow-Origin")) > 0 {
+			t.Fatalf("unexpected response %d %v", w.Code, w.Header())
+		}
+	}
+	if w = preflight("/unknown", "https://app.example.com", http.MethodGet); w.Code != http.StatusNotFound {
+		t.Fatalf("expected not found, got %d", w.Code)
+	}
+
+	// the actual requests rejected by authorization still have CORS headers.
+	r := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	w = serveEngineRequest(t, ngin, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorized, got %d", w.Code)
+	}
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		w.Header().Get("Access-Control-Expose-Headers") != "X-Trace" ||
+		w.Header().Get("Vary") != "Origin" {
+		t.Fatalf("unexpected headers %v", w.Header())
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/internal", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	if w = serveEngineRequest(t, ngin, r); len(w.Header().Get("Access-Control-Allow-Origin")) > 0 {
+		t.Fatalf("unexpected CORS headers %v", w.Header())
+	}
+}
+
+func TestEngineWithStreaming(t *testing.T) {
+	sseHandler := func(w http.ResponseWriter, r *http.Request) {
+		stream, err := httpx.SSE(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer stream.Close()
+
+		for i := 0; i < 3; i++ {
+			if err = stream.Send(httpx.Event{Data: fmt.Sprint(i)}); err != nil {
+				return
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+	}
+	ngin := newTestEngine(
+		newFeaturedRoutes([]Route{
+			{Method: http.MethodGet, Path: "/stream", Handler: sseHandler},
+		}, WithStreaming()),
+		newFeaturedRoutes([]Route{
+			{Method: http.MethodGet, Path: "/buffered", Handler: sseHandler},
+		}, WithTimeout(time.Millisecond*20)),
+	)
+
+	w := serveEngine(t, ngin, http.MethodGet, "/stream")
+	if w.Code != http.StatusOK || w.Body.String() != "data: 0\n\ndata: 1\n\ndata: 2\n\n" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+
+	// the timeout handler buffers the responses, so streaming is not supported.
+	w = serveEngine(t, ngin, http.MethodGet, "/buffered")
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected streaming unsupported, got %d", w.Code)
+	}
+}
+
+func TestEngineWithWebSocket(t *testing.T) {
+	const secret = "websocket-secret"
+	ngin := newTestEngine()
+	ngin.AddRoutes(featuredRoutes{
+		sockets: []WebSocketRoute{
+			{
+				Path: "/echo",
+				Handler: func(conn *ws.Conn) {
+					messageType, data, err := conn.Read()
+					if err != nil {
+						return
+					}
+					uid := conn.Request().Context().Value("uid")
+					_ = conn.Write(messageType, []byte(fmt.Sprintf("%v:%s", uid, data)))
+				},
+			},
+		},
+		prefix: "/api",
+		jwt: jwtSetting{
+			enabled: true,
+			secret:  secret,
+		},
+	})
+
+	rt := router.NewRouter()
+	if err := ngin.bindRoutes(rt); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(rt)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/echo"
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"uid": "42",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+signed)
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err = conn.WriteMessage(websocket.TextMessage, []byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+	_, data, err := conn.ReadMessage()
+	if err != nil || string(data) != "42:hi" {
+		t.Fatalf("unexpected reply %q %v", data, err)
+	}
+}
+
+func TestEngineDrainClosesWebSockets(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := lis.Addr().(*net.TCPAddr).Port
+	lis.Close()
+
+	ngin := newEngine(RestConf{
+		Host:    "127.0.0.1",
+		Port:    port,
+		Timeout: 1000,
+	})
+	ngin.AddRoutes(featuredRoutes{
+		sockets: []WebSocketRoute{
+			{
+				Path: "/echo",
+				Handler: func(conn *ws.Conn) {
+					<-conn.Done()
+				},
+			},
+		},
+	})
+	errs := make(chan error, 1)
+	go func() {
+		errs <- ngin.Start()
+	}()
+
+	url := fmt.Sprintf("ws://127.0.0.1:%d/echo", port)
+	var conn *websocket.Conn
+	for i := 0; i < 100; i++ {
+		if conn, _, err = websocket.DefaultDialer.Dial(url, nil); err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err = ngin.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Fatalf("expected going away on draining, got %v", err)
+	}
+	if err = <-errs; err != http.ErrServerClosed {
+		t.Fatalf("expected server closed, got %v", err)
+	}
+}
+
+func TestEngineWithStatics(t *testing.T) {
+	server := &Server{ngin: newTestEngine()}
+	WithFileServer("/", fstest.MapFS{
+		"index.html":    {Data: []byte("spa")},
+		"users/a.txt":   {Data: []byte("a")},
+		"admin/app.css": {Data: []byte("body{}")},
+	}, fileserver.WithIndexFallback())(server)
+	server.AddStatic("/docs", t.TempDir())
+	server.AddRoutes([]Route{
+		{
+			Method: http.MethodGet,
+			Path:   "/users/:id",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "api")
+			},
+		},
+		{
+			Method: http.MethodPost,
+			Path:   "/admin/login",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+		},
+	})
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{method: http.MethodGet, path: "/", code: http.StatusOK, body: "spa"},
+		{method: http.MethodGet, path: "/admin/app.css", code: http.StatusOK, body: "body{}"},
+		{method: http.MethodGet, path: "/admin/dashboard", code: http.StatusOK, body: "spa"},
+		// the routes take precedence over the static files.
+		{method: http.MethodGet, path: "/users/a.txt", code: http.StatusOK, body: "api"},
+		{method: http.MethodGet, path: "/admin/login", code: http.StatusMethodNotAllowed},
+		{method: http.MethodPost, path: "/admin/app.css", code: http.StatusNotFound},
+		// the longest prefix takes precedence.
+		{method: http.MethodGet, path: "/docs/missing", code: http.StatusNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.method+test.path, func(t *testing.T) {
+			r := httptest.NewRequest(test.method, test.path, nil)
+			r.Header.Set("Accept", "text/html")
+			w := serveEngineRequest(t, server.ngin, r)
+			if w.Code != test.code {
+				t.Fatalf("expected %d, got %d", test.code, w.Code)
+			}
+			if len(test.body) > 0 && w.Body.String() != test.body {
+				t.Fatalf("expected %q, got %q", test.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWithFileServerInvalidPrefix(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic on prefix without leading slash")
+		}
+	}()
+
+	WithFileServer("static", fstest.MapFS{})(&Server{ngin: newTestEngine()})
+}
+
+func TestEngineWithHealthPath(t *testing.T) {
+	defer health.MarkReady()
+
+	ngin := newTestEngine(newFeaturedRoutes([]Route{
+		{
+			Method: http.MethodGet,
+			Path:   "/ping",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+		},
+	}))
+	ngin.conf.HealthPath = "/health"
+
+	if w := serveEngine(t, ngin, http.MethodGet, "/health"); w.Code != http.StatusOK {
+		t.Fatalf("expected ready, got %d", w.Code)
+	}
+	if w := serveEngine(t, ngin, http.MethodPost, "/health"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected not found on post, got %d", w.Code)
+	}
+
+	health.MarkUnready()
+	if w := serveEngine(t, ngin, http.MethodGet, "/health"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected unready, got %d", w.Code)
+	}
+	// the in-flight and the new requests are still served while draining.
+	if w := serveEngine(t, ngin, http.MethodGet, "/ping"); w.Code != http.StatusOK {
+		t.Fatalf("expected ok, got %d", w.Code)
+	}
+}
+
+func TestEngineDrainNotStarted(t *testing.T) {
+	if err := newTestEngine().Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEngineWithRateLimit(t *testing.T) {
+	const secret = "rate-limit-secret-key"
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	ngin := newTestEngine(
+		newFeaturedRoutes([]Route{
+			{Method: http.MethodGet, Path: "/ip", Handler: ok},
+			{Method: http.MethodGet, Path: "/other", Handler: ok},
+		}),
+		newFeaturedRoutes([]Route{
+			{Method: http.MethodGet, Path: "/me", Handler: ok},
+		}, WithJwt(secret), WithRateLimit(RateLimitConf{
+			Period: 60,
+			Quota:  1,
+			KeyBy:  rateLimitByClaim,
+			Claim:  "uid",
+		})),
+		newFeaturedRoutes([]Route{
+			{Method: http.MethodGet, Path: "/free", Handler: ok},
+		}, WithRateLimit(RateLimitConf{})),
+	)
+	ngin.conf.RateLimit = RateLimitConf{
+		Period: 60,
+		Quota:  2,
+		KeyBy:  "ip",
+	}
+	rt := router.NewRouter()
+	if err := ngin.bindRoutes(rt); err != nil {
+		t.Fatal(err)
+	}
+	handle := ngin.wrapRouter(rt)
+	serve := func(reqPath, addr, uid string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, reqPath, nil)
+		r.RemoteAddr = addr
+		if len(uid) > 0 {
+			signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"uid": uid,
+			}).SignedString([]byte(secret))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.Header.Set("Authorization", "Bearer "+signed)
+		}
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := 2; i > 0; i-- {
+		w := serve("/ip", "1.1.1.1:1", "")
+		if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "2" ||
+			w.Header().Get("X-RateLimit-Remaining") != strconv.Itoa(i-1) {
+			t.Fatalf("expected allowed with quota headers, got %d %v", w.Code, w.Header())
+		}
+	}
+	w := serve("/ip", "1.1.1.1:1", "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" ||
+		w.Header().Get("X-RateLimit-Reset") != "60" {
+		t.Fatalf("expected too many requests, got %d %v", w.Code, w.Header())
+	}
+	if w = serve("/ip", "2.2.2.2:1", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected other clients allowed, got %d", w.Code)
+	}
+	if w = serve("/other", "1.1.1.1:1", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected other routes allowed, got %d", w.Code)
+	}
+
+	if w = serve("/me", "1.1.1.1:1", "42"); w.Code != http.StatusOK {
+		t.Fatalf("expected allowed, got %d", w.Code)
+	}
+	if w = serve("/me", "2.2.2.2:1", "42"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected limited by claim across addresses, got %d", w.Code)
+	}
+	if w = serve("/me", "1.1.1.1:1", "43"); w.Code != http.StatusOK {
+		t.Fatalf("expected other claims allowed, got %d", w.Code)
+	}
+
+	for i := 0; i < 3; i++ {
+		if w = serve("/free", "1.1.1.1:1", ""); w.Code != http.StatusOK ||
+			len(w.Header().Get("X-RateLimit-Limit")) > 0 {
+			t.Fatalf("expected not limited, got %d %v", w.Code, w.Header())
+		}
+	}
+}
+
+func TestEngineWithRateLimitBadConfig(t *testing.T) {
+	ngin := newTestEngine(newFeaturedRoutes([]Route{
+		{Method: http.MethodGet, Path: "/", Handler: func(w http.ResponseWriter, r *http.Request) {}},
+	}, WithRateLimit(RateLimitConf{
+		Period: 1,
+		Quota:  1,
+		KeyBy:  rateLimitByClaim,
+	})))
+	if err := ngin.bindRoutes(router.NewRouter()); err != ErrRateLimitConfig {
+		t.Fatalf("expected bad config, got %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package fileserver
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	indexFile      = "index.html"
+	gzipExt        = ".gz"
+	gzipEncoding   = "gzip"
+	htmlType       = "text/html"
+	noCache        = "no-cache"
+	allowedMethods = "GET, HEAD"
+)
+
+type (
+	// Option defines the method to customize the file server.
+	Option func(h *fileHandler)
+
+	fileHandler struct {
+		prefix        string
+		fsys          fs.FS
+		maxAge        time.Duration
+		indexFallback bool
+		lock          sync.RWMutex
+		etags         map[string]etagEntry
+	}
+
+	etagEntry struct {
+		size int64
+		etag string
+	}
+)
+
+// NewHandler returns a http.Handler that serves the files in fsys under prefix.
+// Use os.DirFS to serve a directory, or fs.Sub to serve a sub directory of an embed.FS.
+// The files are served with ETag and Last-Modified, and the gzip precompressed files,
+// like app.js.gz for app.js, are served to the clients accepting gzip.
+// The index.html files are always revalidated by the clients.
+func NewHandler(prefix string, fsys fs.FS, opts ...Option) http.Handler {
+	h := &fileHandler{
+		prefix: path.Clean("/" + prefix),
+		fsys:   fsys,
+		etags:  make(map[string]etagEntry),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// WithIndexFallback returns an Option that serves the root index.html on the html requests
+// to the files that don't exist, which is used by the single page apps with client side routes.
+func WithIndexFallback() Option {
+	return func(h *fileHandler) {
+		h.indexFallback = true
+	}
+}
+
+// WithMaxAge returns an Option that lets the clients cache the files other than index.html
+// for the given duration, which is used for the assets with hashed names.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(h *fileHandler) {
+		h.maxAge = maxAge
+	}
+}
+
+func (h *fileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", allowedMethods)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	name, ok := h.resolve(r.URL.Path)
+	if ok && h.serveFile(w, r, name) {
+		return
+	}
+
+	// only the requests to the client side routes fall back to index.html, not the missing assets.
+	if h.indexFallback && path.Ext(name) == "" && acceptsHtml(r) && h.serveFile(w, r, indexFile) {
+		return
+	}
+
+	http.NotFound(w, r)
+}
+
+// etag returns the etag of the file content, which is made of the modification time and the size.
+// The files in embed.FS have no modification time, so the etags are the content hashes,
+// which are computed once, because such files never change.
+func (h *fileHandler) etag(name string, content io.Reader, info fs.FileInfo) (string, error) {
+	if !info.ModTime().IsZero() {
+		return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()), nil
+	}
+
+	h.lock.RLock()
+	entry, ok := h.etags[name]
+	h.lock.RUnlock()
+	if ok && entry.size == info.Size() {
+		return entry.etag, nil
+	}
+
+	digest := md5.New()
+	if _, err := io.Copy(digest, content); err != nil {
+		return "", err
+	}
+
+	etag := fmt.Sprintf(`"%x"`, digest.Sum(nil))
+	h.lock.Lock()
+	h.etags[name] = etagEntry{
+		size: info.Size(),
+		etag: etag,
+	}
+	h.lock.Unlock()
+
+	return etag, nil
+}
+
+// open opens the file to serve, the directories are treated as not exist.
+func (h *fileHandler) open(name string) (fs.File, fs.FileInfo, error) {
+	file, err := h.fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, nil, fs.ErrNotExist
+	}
+
+	return file, info, nil
+}
+
+// resolve returns the file name in fsys of the request path.
+func (h *fileHandler) resolve(reqPath string) (string, bool) {
+	reqPath = path.Clean("/" + reqPath)
+	if h.prefix != "/" {
+		if reqPath != h.prefix && !strings.HasPrefix(reqPath, h.prefix+"/") {
+			return "", false
+		}
+		reqPath = strings.TrimPrefix(reqPath, h.prefix)
+	}
+
+	name := strings.TrimPrefix(reqPath, "/")
+	if len(name) == 0 {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		return "", false
+	}
+
+	return name, true
+}
+
+func (h *fileHandler) serveFile(w http.ResponseWriter, r *http.Request, name string) bool {
+	if info, err := fs.Stat(h.fsys, name); err != nil {
+		return false
+	} else if info.IsDir() {
+		name = path.Join(name, indexFile)
+	}
+
+	fileName := name
+	header := w.Header()
+	if h.hasGzipFile(name) {
+		header.Add("Vary", "Accept-Encoding")
+		// the ranges of the compressed content make no sense to the clients.
+		if acceptsGzip(r) && len(r.Header.Get("Range")) == 0 {
+			fileName = name + gzipExt
+			header.Set("Content-Encoding", gzipEncoding)
+			if ctype := mime.TypeByExtension(path.Ext(name)); len(ctype) > 0 {
+				header.Set("Content-Type", ctype)
+			}
+		}
+	}
+
+	file, info, err := h.open(fileName)
+	if err != nil {
+		header.Del("Content-Encoding")
+		header.Del("Content-Type")
+		return false
+	}
+	defer file.Close()
+
+	content, etag, err := h.content(fileName, file, info)
+	if err != nil {
+		header.Del("Content-Encoding")
+		header.Del("Content-Type")
+		return false
+	}
+	header.Set("Etag", etag)
+
+	if path.Base(name) == indexFile {
+		header.Set("Cache-Control", noCache)
+	} else if h.maxAge > 0 {
+		header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(h.maxAge/time.Second)))
+	}
+
+	// the original name is used to detect the content type.
+	http.ServeContent(w, r, name, info.ModTime(), content)
+	return true
+}
+
+// content returns the content to serve and the etag of file, the seekable files,
+// like the ones in os.DirFS and embed.FS, are served without reading into memory.
+func (h *fileHandler) content(name string, file fs.File, info fs.FileInfo) (io.ReadSeeker, string, error) {
+	rs, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, "", err
+		}
+
+		rs = bytes.NewReader(data)
+	}
+
+	etag, err := h.etag(name, rs, info)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// the content might be read by hashing.
+	if _, err = rs.Seek(0, io.SeekStart); err != nil {
+		return nil, "", err
+	}
+
+	return rs, etag, nil
+}
+
+func (h *fileHandler) hasGzipFile(name string) bool {
+	info, err := fs.Stat(h.fsys, name+gzipExt)
+	return err == nil && !info.IsDir()
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		encoding = strings.TrimSpace(strings.Split(encoding, ";")[0])
+		if encoding == gzipEncoding {
+			return true
+		}
+	}
+
+	return false
+}
+
+func acceptsHtml(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), htmlType)
+}
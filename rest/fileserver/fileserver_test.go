@@ -0,0 +1,339 @@
+package fileserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func newTestFS(t *testing.T) fstest.MapFS {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte("console.log('app')")); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return fstest.MapFS{
+		"index.html":         {Data: []byte("<html>index</html>")},
+		"assets/app.js":      {Data: []byte("console.log('app')")},
+		"assets/app.js.gz":   {Data: buf.Bytes()},
+		"assets/style.css":   {Data: []byte("body{}")},
+		"docs/index.html":    {Data: []byte("<html>docs</html>")},
+		"empty/.placeholder": {Data: []byte("")},
+	}
+}
+
+func serve(h http.Handler, method, target string, header map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestFileServer(t *testing.T) {
+	h := NewHandler("/static", newTestFS(t), WithMaxAge(time.Hour))
+
+	tests := []struct {
+		name        string
+		target      string
+		code        int
+		body        string
+		contentType string
+		cache       string
+	}{
+		{
+			name:        "asset",
+			target:      "/static/assets/style.css",
+			code:        http.StatusOK,
+			body:        "body{}",
+			contentType: "text/css; charset=utf-8",
+			cache:       "public, max-age=3600",
+		},
+		{
+			name:        "root index",
+			target:      "/static",
+			code:        http.StatusOK,
+			body:        "<html>index</html>",
+			contentType: "text/html; charset=utf-8",
+			cache:       noCache,
+		},
+		{
+			name:        "dir index",
+			target:      "/static/docs/",
+			code:        http.StatusOK,
+			body:        "<html>docs</html>",
+			contentType: "text/html; charset=utf-8",
+			cache:       noCache,
+		},
+		{
+			name:   "dir without index",
+			target: "/static/empty",
+			code:   http.StatusNotFound,
+		},
+		{
+			name:   "missing",
+			target: "/static/assets/missing.js",
+			code:   http.StatusNotFound,
+		},
+		{
+			name:   "out of prefix",
+			target: "/assets/style.css",
+			code:   http.StatusNotFound,
+		},
+		{
+			name:   "traversal",
+			target: "/static/../../etc/passwd",
+			code:   http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := serve(h, http.MethodGet, test.target, nil)
+			if w.Code != test.code {
+				t.Fatalf("expected %d, got %d", test.code, w.Code)
+			}
+			if test.code != http.StatusOK {
+				return
+			}
+
+			if w.Body.String() != test.body {
+				t.Fatalf("expected %q, got %q", test.body, w.Body.String())
+			}
+			if ct := w.Header().Get("Content-Type"); ct != test.contentType {
+				t.Fatalf("expected content type %q, got %q", test.contentType, ct)
+			}
+			if cache := w.Header().Get("Cache-Control"); cache != test.cache {
+				t.Fatalf("expected cache control %q, got %q", test.cache, cache)
+			}
+			if len(w.Header().Get("Etag")) == 0 {
+				t.Fatal("expected etag")
+			}
+		})
+	}
+}
+
+func TestFileServerMethodNotAllowed(t *testing.T) {
+	w := serve(NewHandler("/", newTestFS(t)), http.MethodPost, "/index.html", nil)
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != allowedMethods {
+		t.Fatalf("unexpected response %d %v", w.Code, w.Header())
+	}
+
+	w = serve(NewHandler("/", newTestFS(t)), http.MethodHead, "/index.html", nil)
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Fatalf("unexpected head response %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestFileServerEtag(t *testing.T) {
+	h := NewHandler("/", newTestFS(t))
+	w := serve(h, http.MethodGet, "/assets/style.css", nil)
+	etag := w.Header().Get("Etag")
+	if len(etag) == 0 {
+		t.Fatal("expected etag")
+	}
+
+	w = serve(h, http.MethodGet, "/assets/style.css", map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("expected not modified, got %d", w.Code)
+	}
+
+	w = serve(h, http.MethodGet, "/assets/style.css", map[string]string{"If-None-Match": `"stale"`})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected ok on stale etag, got %d", w.Code)
+	}
+}
+
+func TestFileServerLastModified(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.css")
+	if err := os.WriteFile(file, []byte("body{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler("/", os.DirFS(dir))
+	w := serve(h, http.MethodGet, "/app.css", nil)
+	if w.Header().Get("Last-Modified") != modTime.UTC().Format(http.TimeFormat) {
+		t.Fatalf("unexpected last modified %q", w.Header().Get("Last-Modified"))
+	}
+
+	w = serve(h, http.MethodGet, "/app.css", map[string]string{
+		"If-Modified-Since": modTime.UTC().Format(http.TimeFormat),
+	})
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("expected not modified, got %d", w.Code)
+	}
+
+	// the etag changes with the content.
+	etag := w.Header().Get("Etag")
+	if err := os.WriteFile(file, []byte("body{color:red}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w = serve(h, http.MethodGet, "/app.css", map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusOK || w.Body.String() != "body{color:red}" {
+		t.Fatalf("expected the new content, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestFileServerGzip(t *testing.T) {
+	h := NewHandler("/", newTestFS(t))
+
+	w := serve(h, http.MethodGet, "/assets/app.js", map[string]string{"Accept-Encoding": "br, gzip;q=0.8"})
+	if w.Header().Get("Content-Encoding") != gzipEncoding || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("expected gzip content, got %v", w.Header())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/javascript" &&
+		ct != "text/javascript; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil || string(content) != "console.log('app')" {
+		t.Fatalf("unexpected content %q %v", content, err)
+	}
+	gzipEtag := w.Header().Get("Etag")
+
+	w = serve(h, http.MethodGet, "/assets/app.js", nil)
+	if len(w.Header().Get("Content-Encoding")) > 0 || w.Body.String() != "console.log('app')" {
+		t.Fatalf("expected identity content, got %v %q", w.Header(), w.Body.String())
+	}
+	if w.Header().Get("Vary") != "Accept-Encoding" || w.Header().Get("Etag") == gzipEtag {
+		t.Fatalf("expected vary and different etags, got %v", w.Header())
+	}
+
+	w = serve(h, http.MethodGet, "/assets/app.js", map[string]string{
+		"Accept-Encoding": "gzip",
+		"Range":           "bytes=0-6",
+	})
+	if w.Code != http.StatusPartialContent || w.Body.String() != "console" {
+		t.Fatalf("expected identity range, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestFileServerIndexFallback(t *testing.T) {
+	h := NewHandler("/", newTestFS(t), WithIndexFallback())
+	html := map[string]string{"Accept": "text/html,application/xhtml+xml"}
+
+	w := serve(h, http.MethodGet, "/users/42", html)
+	if w.Code != http.StatusOK || w.Body.String() != "<html>index</html>" ||
+		w.Header().Get("Cache-Control") != noCache {
+		t.Fatalf("expected index fallback, got %d %q", w.Code, w.Body.String())
+	}
+
+	// missing assets and non-html requests are not found.
+	if w = serve(h, http.MethodGet, "/assets/missing.js", html); w.Code != http.StatusNotFound {
+		t.Fatalf("expected not found on missing asset, got %d", w.Code)
+	}
+	if w = serve(h, http.MethodGet, "/users/42", map[string]string{"Accept": "application/json"}); w.Code != http.StatusNotFound {
+		t.Fatalf("expected not found on json request, got %d", w.Code)
+	}
+	if w = serve(NewHandler("/", newTestFS(t)), http.MethodGet, "/users/42", html); w.Code != http.StatusNotFound {
+		t.Fatalf("expected not found without fallback, got %d", w.Code)
+	}
+}
+
+type (
+	countingFS struct {
+		fs.FS
+		read int64
+	}
+
+	countingFile struct {
+		fs.File
+		read *int64
+	}
+)
+
+func (c *countingFS) Open(name string) (fs.File, error) {
+	file, err := c.FS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return countingFile{
+		File: file,
+		read: &c.read,
+	}, nil
+}
+
+func (c countingFile) Read(p []byte) (int, error) {
+	n, err := c.File.Read(p)
+	atomic.AddInt64(c.read, int64(n))
+	return n, err
+}
+
+func (c countingFile) Seek(offset int64, whence int) (int64, error) {
+	return c.File.(io.Seeker).Seek(offset, whence)
+}
+
+func TestFileServerStreamsFiles(t *testing.T) {
+	const size = 1 << 20
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "large.bin"), make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := &countingFS{FS: os.DirFS(dir)}
+	h := NewHandler("/", fsys)
+	w := serve(h, http.MethodGet, "/large.bin", map[string]string{"Range": "bytes=0-9"})
+	if w.Code != http.StatusPartialContent || w.Body.Len() != 10 {
+		t.Fatalf("expected partial content, got %d %d", w.Code, w.Body.Len())
+	}
+	if read := atomic.LoadInt64(&fsys.read); read > 4096 {
+		t.Fatalf("expected only the range read, got %d bytes", read)
+	}
+
+	etag := w.Header().Get("Etag")
+	atomic.StoreInt64(&fsys.read, 0)
+	w = serve(h, http.MethodGet, "/large.bin", map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusNotModified || atomic.LoadInt64(&fsys.read) != 0 {
+		t.Fatalf("expected not modified without reading, got %d %d", w.Code, fsys.read)
+	}
+}
+
+func TestFileServerHashesEmbeddedFilesOnce(t *testing.T) {
+	data := []byte("console.log('app')")
+	// the files in embed.FS have no modification time like fstest.MapFS.
+	fsys := &countingFS{FS: fstest.MapFS{"app.js": {Data: data}}}
+	h := NewHandler("/", fsys)
+
+	w := serve(h, http.MethodGet, "/app.js", nil)
+	if w.Body.String() != string(data) {
+		t.Fatalf("unexpected content %q", w.Body.String())
+	}
+	if read := atomic.LoadInt64(&fsys.read); read != int64(len(data))*2 {
+		t.Fatalf("expected hashing and serving, got %d bytes read", read)
+	}
+
+	atomic.StoreInt64(&fsys.read, 0)
+	etag := w.Header().Get("Etag")
+	w = serve(h, http.MethodGet, "/app.js", nil)
+	if w.Header().Get("Etag") != etag || w.Body.String() != string(data) {
+		t.Fatalf("unexpected response %q %q", w.Header().Get("Etag"), w.Body.String())
+	}
+	if read := atomic.LoadInt64(&fsys.read); read != int64(len(data)) {
+		t.Fatalf("expected the etag cached, got %d bytes read", read)
+	}
+}
@@ -34,6 +34,7 @@ type (
 	AuthorizeOptions struct {
 		PrevSecret string
 		Callback   UnauthorizedCallback
+		Validator  *token.ClaimsValidator
 	}
 
 	// UnauthorizedCallback defines the method of unauthorized callback.
@@ -50,9 +51,69 @@ func Authorize(secret string, opts ...AuthorizeOption) func(http.Handler) http.H
 	}
 
 	parser := token.NewTokenParser()
+	return authorize(func(r *http.Request) (*jwt.Token, error) {
+		tok, err := parser.ParseToken(r, secret, authOpts.PrevSecret)
+		if err != nil {
+			return nil, err
+		}
+
+		if authOpts.Validator != nil {
+			if claims, ok := tok.Claims.(jwt.MapClaims); ok {
+				if err = authOpts.Validator.Validate(claims); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		return tok, nil
+	}, authOpts)
+}
+
+// AuthorizeWithKeySet returns an authorize middleware that verifies the asymmetric signed tokens,
+// like RS256 and ES256, with the public keys in keys.
+func AuthorizeWithKeySet(keys *token.KeySet, opts ...AuthorizeOption) func(http.Handler) http.Handler {
+	var authOpts AuthorizeOptions
+	for _, opt := range opts {
+		opt(&authOpts)
+	}
+
+	var validator token.ClaimsValidator
+	if authOpts.Validator != nil {
+		validator = *authOpts.Validator
+	}
+
+	parser := token.NewTokenParser()
+	return authorize(func(r *http.Request) (*jwt.Token, error) {
+		return parser.ParseTokenWithKeySet(r, keys, validator)
+	}, authOpts)
+}
+
+// WithClaimsValidator returns an AuthorizeOption with setting the validator of registered claims.
+func WithClaimsValidator(validator token.ClaimsValidator) AuthorizeOption {
+	return func(opts *AuthorizeOptions) {
+		opts.Validator = &validator
+	}
+}
+
+// WithPrevSecret returns an AuthorizeOption with setting previous secret.
+func WithPrevSecret(secret string) AuthorizeOption {
+	return func(opts *AuthorizeOptions) {
+		opts.PrevSecret = secret
+	}
+}
+
+// WithUnauthorizedCallback returns an AuthorizeOption with setting unauthorized callback.
+func WithUnauthorizedCallback(callback UnauthorizedCallback) AuthorizeOption {
+	return func(opts *AuthorizeOptions) {
+		opts.Callback = callback
+	}
+}
+
+func authorize(parse func(r *http.Request) (*jwt.Token, error),
+	authOpts AuthorizeOptions) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			tok, err := parser.ParseToken(r, secret, authOpts.PrevSecret)
+			tok, err := parse(r)
 			if err != nil {
 				unauthorized(w, r, err, authOpts.Callback)
 				return
@@ -84,24 +145,10 @@ func Authorize(secret string, opts ...AuthorizeOption) func(http.Handler) http.H
 	}
 }
 
-// WithPrevSecret returns an AuthorizeOption with setting previous secret.
-func WithPrevSecret(secret string) AuthorizeOption {
-	return func(opts *AuthorizeOptions) {
-		opts.PrevSecret = secret
-	}
-}
-
-// WithUnauthorizedCallback returns an AuthorizeOption with setting unauthorized callback.
-func WithUnauthorizedCallback(callback UnauthorizedCallback) AuthorizeOption {
-	return func(opts *AuthorizeOptions) {
-		opts.Callback = callback
-	}
-}
-
 func detailAuthLog(r *http.Request, reason string) {
 	// discard dump error, only for debug purpose
 	details, _ := httputil.DumpRequest(r, true)
-	logx.Errorf("authorize failed: %s\n=> %+v", reason, string(details))
+	logx.WithContext(r.Context()).Errorf("authorize failed: %s\n=> %+v", reason, string(details))
 }
 
 func unauthorized(w http.ResponseWriter, r *http.Request, err error, callback UnauthorizedCallback) {
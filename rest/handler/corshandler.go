@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	corsAllowCredentials = "Access-Control-Allow-Credentials"
+	corsAllowHeaders     = "Access-Control-Allow-Headers"
+	corsAllowMethods     = "Access-Control-Allow-Methods"
+	corsAllowOrigin      = "Access-Control-Allow-Origin"
+	corsExposeHeaders    = "Access-Control-Expose-Headers"
+	corsMaxAge           = "Access-Control-Max-Age"
+	corsRequestHeaders   = "Access-Control-Request-Headers"
+	corsRequestMethod    = "Access-Control-Request-Method"
+	corsOrigin           = "Origin"
+	corsVary             = "Vary"
+	corsAllOrigins       = "*"
+	corsSeparator        = ", "
+)
+
+var defaultCorsMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPatch,
+	http.MethodPut,
+	http.MethodDelete,
+}
+
+type (
+	// A CorsPolicy is the policy of cross-origin resource sharing.
+	CorsPolicy struct {
+		// AllowOrigins are the allowed origins, * means all origins,
+		// and wildcards like https://*.example.com are supported.
+		AllowOrigins []string
+		// AllowMethods defaults to GET, HEAD, POST, PATCH, PUT and DELETE if empty.
+		AllowMethods []string
+		// AllowHeaders are the allowed request headers, the requested headers are allowed if empty.
+		AllowHeaders     []string
+		ExposeHeaders    []string
+		AllowCredentials bool
+		MaxAge           time.Duration
+	}
+
+	corsPolicy struct {
+		CorsPolicy
+		allOrigins bool
+		origins    map[string]bool
+		wildcards  []originWildcard
+		methods    string
+		headers    string
+		expose     string
+		maxAge     string
+	}
+
+	originWildcard struct {
+		prefix string
+		suffix string
+	}
+)
+
+// CorsHandler returns a middleware that adds the CORS headers into the responses of
+// the requests from the allowed origins, and answers the preflight requests.
+func CorsHandler(policy CorsPolicy) func(http.Handler) http.Handler {
+	cp := newCorsPolicy(policy)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if IsCorsPreflight(r) {
+				cp.preflight(w, r)
+				return
+			}
+
+			cp.addHeaders(w, r)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CorsPreflightHandler returns a handler that answers the CORS preflight requests with policy.
+func CorsPreflightHandler(policy CorsPolicy) http.Handler {
+	cp := newCorsPolicy(policy)
+
+	return http.HandlerFunc(cp.preflight)
+}
+
+// IsCorsPreflight checks if r is a CORS preflight request.
+func IsCorsPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && len(r.Header.Get(corsOrigin)) > 0 &&
+		len(r.Header.Get(corsRequestMethod)) > 0
+}
+
+func newCorsPolicy(policy CorsPolicy) *corsPolicy {
+	cp := &corsPolicy{
+		CorsPolicy: policy,
+		origins:    make(map[string]bool),
+	}
+
+	for _, origin := range policy.AllowOrigins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		if origin == corsAllOrigins {
+			cp.allOrigins = true
+		} else if index := strings.IndexByte(origin, '*'); index >= 0 {
+			cp.wildcards = append(cp.wildcards, originWildcard{
+				prefix: origin[:index],
+				suffix: origin[index+1:],
+			})
+		} else if len(origin) > 0 {
+			cp.origins[origin] = true
+		}
+	}
+
+	methods := policy.AllowMethods
+	if len(methods) == 0 {
+		methods = defaultCorsMethods
+	}
+	upperMethods := make([]string, len(methods))
+	for i, method := range methods {
+		upperMethods[i] = strings.ToUpper(method)
+	}
+	cp.AllowMethods = upperMethods
+	cp.methods = strings.Join(upperMethods, corsSeparator)
+	cp.headers = strings.Join(policy.AllowHeaders, corsSeparator)
+	cp.expose = strings.Join(policy.ExposeHeaders, corsSeparator)
+	if policy.MaxAge > 0 {
+		cp.maxAge = strconv.FormatInt(int64(policy.MaxAge/time.Second), 10)
+	}
+
+	return cp
+}
+
+func (cp *corsPolicy) addHeaders(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	// the responses vary on origin unless all origins are allowed without credentials.
+	if !cp.allOrigins || cp.AllowCredentials {
+		header.Add(corsVary, corsOrigin)
+	}
+
+	origin := r.Header.Get(corsOrigin)
+	if len(origin) == 0 || !cp.isOriginAllowed(origin) {
+		return
+	}
+
+	cp.setOrigin(header, origin)
+	if len(cp.expose) > 0 {
+		header.Set(corsExposeHeaders, cp.expose)
+	}
+}
+
+func (cp *corsPolicy) isMethodAllowed(method string) bool {
+	method = strings.ToUpper(method)
+	for _, allowed := range cp.AllowMethods {
+		if allowed == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cp *corsPolicy) isOriginAllowed(origin string) bool {
+	if cp.allOrigins {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if cp.origins[origin] {
+		return true
+	}
+
+	for _, wildcard := range cp.wildcards {
+		if len(origin) > len(wildcard.prefix)+len(wildcard.suffix) &&
+			strings.HasPrefix(origin, wildcard.prefix) && strings.HasSuffix(origin, wildcard.suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cp *corsPolicy) preflight(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	header.Add(corsVary, corsOrigin)
+	header.Add(corsVary, corsRequestMethod)
+	header.Add(corsVary, corsRequestHeaders)
+
+	origin := r.Header.Get(corsOrigin)
+	if !cp.isOriginAllowed(origin) || !cp.isMethodAllowed(r.Header.Get(corsRequestMethod)) {
+		// no CORS headers, the browsers reject the actual requests.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	cp.setOrigin(header, origin)
+	header.Set(corsAllowMethods, cp.methods)
+	if len(cp.headers) > 0 {
+		header.Set(corsAllowHeaders, cp.headers)
+	} else if headers := r.Header.Get(corsRequestHeaders); len(headers) > 0 {
+		header.Set(corsAllowHeaders, headers)
+	}
+	if len(cp.maxAge) > 0 {
+		header.Set(corsMaxAge, cp.maxAge)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cp *corsPolicy) setOrigin(header http.Header, origin string) {
+	// * is not allowed with credentials, so the origin is echoed.
+	if cp.allOrigins && !cp.AllowCredentials {
+		header.Set(corsAllowOrigin, corsAllOrigins)
+	} else {
+		header.Set(corsAllowOrigin, origin)
+	}
+
+	if cp.AllowCredentials {
+		header.Set(corsAllowCredentials, "true")
+	}
+}
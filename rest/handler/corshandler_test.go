@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCorsPolicyOrigins(t *testing.T) {
+	cp := newCorsPolicy(CorsPolicy{
+		AllowOrigins: []string{"https://a.com", "https://*.b.com", "http://localhost:*"},
+	})
+
+	tests := []struct {
+		origin string
+		allow  bool
+	}{
+		{origin: "https://a.com", allow: true},
+		{origin: "HTTPS://A.COM", allow: true},
+		{origin: "https://x.b.com", allow: true},
+		{origin: "https://x.y.b.com", allow: true},
+		{origin: "https://.b.com", allow: false},
+		{origin: "https://b.com", allow: false},
+		{origin: "https://evilb.com", allow: false},
+		{origin: "http://localhost:3000", allow: true},
+		{origin: "https://a.com.evil.com", allow: false},
+	}
+	for _, test := range tests {
+		if cp.isOriginAllowed(test.origin) != test.allow {
+			t.Fatalf("origin %s: expected %t", test.origin, test.allow)
+		}
+	}
+}
+
+func TestCorsHandlerAllOrigins(t *testing.T) {
+	handle := CorsHandler(CorsPolicy{
+		AllowOrigins: []string{"*"},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Origin", "https://any.com")
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, r)
+	if w.Header().Get(corsAllowOrigin) != "*" || len(w.Header().Get(corsVary)) > 0 {
+		t.Fatalf("unexpected headers %v", w.Header())
+	}
+
+	// answers the preflight requests without calling next.
+	r = httptest.NewRequest(http.MethodOptions, "/", nil)
+	r.Header.Set("Origin", "https://any.com")
+	r.Header.Set(corsRequestMethod, "put")
+	w = httptest.NewRecorder()
+	handle.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent || w.Header().Get(corsAllowMethods) != "GET, HEAD, POST, PATCH, PUT, DELETE" {
+		t.Fatalf("unexpected preflight response %d %v", w.Code, w.Header())
+	}
+}
+
+func TestIsCorsPreflight(t *testing.T) {
+	r := httptest.NewRequest(http.MethodOptions, "/", nil)
+	if IsCorsPreflight(r) {
+		t.Fatal("expected not preflight without origin")
+	}
+
+	r.Header.Set("Origin", "https://a.com")
+	r.Header.Set(corsRequestMethod, http.MethodGet)
+	if !IsCorsPreflight(r) {
+		t.Fatal("expected preflight")
+	}
+}
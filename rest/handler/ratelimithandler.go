@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/limit"
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/rest/httpx"
+)
+
+const (
+	rateLimitLimit     = "X-RateLimit-Limit"
+	rateLimitRemaining = "X-RateLimit-Remaining"
+	rateLimitReset     = "X-RateLimit-Reset"
+	retryAfter         = "Retry-After"
+)
+
+// RateLimitHandler returns a middleware that limits the requests by the keys from keyFunc,
+// responds 429 with Retry-After if over quota. The X-RateLimit-* headers are set on all responses.
+func RateLimitHandler(limiter *limit.QuotaLimiter, keyFunc func(r *http.Request) string) func(
+	http.Handler) http.Handler {
+	if limiter == nil {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			quota := limiter.Take(keyFunc(r))
+			reset := strconv.FormatInt(ceilSeconds(quota.Reset), 10)
+			header := w.Header()
+			header.Set(rateLimitLimit, strconv.Itoa(quota.Limit))
+			header.Set(rateLimitRemaining, strconv.Itoa(quota.Remaining))
+			header.Set(rateLimitReset, reset)
+
+			if quota.State == limit.OverQuota {
+				logx.WithContext(r.Context()).Errorf("[http] rate limited, %s - %s - %s",
+					r.RequestURI, httpx.GetRemoteAddr(r), r.UserAgent())
+				header.Set(retryAfter, reset)
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/lukebull/go-zero-extern/core/limit"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+)
+
+func TestRateLimitHandler(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limiter := limit.NewQuotaLimiter(5, 1, redis.New(mr.Addr()), "rl:")
+	handle := RateLimitHandler(limiter, func(r *http.Request) string {
+		return r.Header.Get("X-Key")
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serve := func(key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Key", key)
+		w := httptest.NewRecorder()
+		handle.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("a")
+	if w.Code != http.StatusOK || w.Header().Get(rateLimitRemaining) != "0" ||
+		w.Header().Get(rateLimitReset) != "5" || len(w.Header().Get(retryAfter)) > 0 {
+		t.Fatalf("expected allowed, got %d %v", w.Code, w.Header())
+	}
+	if w = serve("a"); w.Code != http.StatusTooManyRequests || w.Header().Get(retryAfter) != "5" {
+		t.Fatalf("expected too many requests, got %d %v", w.Code, w.Header())
+	}
+	if !mr.Exists("rl:a") {
+		t.Fatal("expected quota in redis")
+	}
+
+	// limited in process if redis is unreachable.
+	mr.Close()
+	if w = serve("b"); w.Code != http.StatusOK {
+		t.Fatalf("expected allowed in process, got %d", w.Code)
+	}
+	if w = serve("b"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected too many requests in process, got %d", w.Code)
+	}
+}
+
+func TestRateLimitHandlerNilLimiter(t *testing.T) {
+	handle := RateLimitHandler(nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	w := httptest.NewRecorder()
+	handle.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK || len(w.Header().Get(rateLimitLimit)) > 0 {
+		t.Fatalf("expected not limited, got %d %v", w.Code, w.Header())
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/lukebull/go-zero-extern/core/logx"
 	"github.com/lukebull/go-zero-extern/core/sysx"
 	"github.com/lukebull/go-zero-extern/core/trace"
+	"github.com/lukebull/go-zero-extern/core/trace/tracespec"
+	"github.com/lukebull/go-zero-extern/rest/internal/security"
 )
 
 // TracingHandler returns a middleware that traces the request.
@@ -19,8 +21,16 @@ func TracingHandler(next http.Handler) http.Handler {
 
 		ctx, span := trace.StartServerSpan(r.Context(), carrier, sysx.Hostname(), r.RequestURI)
 		defer span.Finish()
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.target", r.RequestURI)
 		r = r.WithContext(ctx)
 
-		next.ServeHTTP(w, r)
+		cw := &security.WithCodeResponseWriter{Writer: w, Code: http.StatusOK}
+		next.ServeHTTP(cw, r)
+
+		span.SetAttribute("http.status_code", cw.Code)
+		if cw.Code >= http.StatusInternalServerError {
+			span.SetStatus(tracespec.StatusError, http.StatusText(cw.Code))
+		}
 	})
 }
@@ -13,6 +13,7 @@ const (
 	formKey           = "form"
 	pathKey           = "path"
 	headerKey         = "header"
+	jsonKey           = "json"
 	emptyJson         = "{}"
 	maxMemory         = 32 << 20 // 32MB
 	maxBodyLen        = 8 << 20  // 8MB
@@ -40,7 +41,11 @@ func Parse(r *http.Request, v interface{}) error {
 		return err
 	}
 
-	return ParseJsonBody(r, v)
+	if err := ParseJsonBody(r, v); err != nil {
+		return err
+	}
+
+	return mapping.ValidateStruct(v, pathKey, formKey, headerKey, jsonKey)
 }
 
 // ParseHeaders parses the headers request.
@@ -127,6 +132,11 @@ func ParsePath(r *http.Request, v interface{}) error {
 	return pathUnmarshaler.Unmarshal(m, v)
 }
 
+// GetPathVars returns the symbols reside in url path, like name of http://localhost/bag/:name.
+func GetPathVars(r *http.Request) map[string]string {
+	return context.Vars(r)
+}
+
 func withJsonBody(r *http.Request) bool {
 	return r.ContentLength > 0 && strings.Contains(r.Header.Get(ContentType), ApplicationJson)
 }
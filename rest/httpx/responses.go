@@ -2,12 +2,18 @@ package httpx
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"sync"
 
 	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/core/mapping"
 )
 
+type validationErrorsBody struct {
+	Errors mapping.ValidationErrors `json:"errors"`
+}
+
 var (
 	errorHandler func(error) (int, interface{})
 	lock         sync.RWMutex
@@ -20,7 +26,14 @@ func Error(w http.ResponseWriter, err error) {
 	lock.RUnlock()
 
 	if handler == nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		var ve mapping.ValidationErrors
+		if errors.As(err, &ve) {
+			WriteJson(w, http.StatusBadRequest, validationErrorsBody{
+				Errors: ve,
+			})
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
 		return
 	}
 
@@ -0,0 +1,43 @@
+package httpx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorValidationErrors(t *testing.T) {
+	type request struct {
+		Name  string   `json:"name,minlen=3"`
+		Email string   `json:"email,format=email"`
+		Tags  []string `json:"tags,minitems=1"`
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/",
+		strings.NewReader(`{"name":"fo","email":"foo","tags":[]}`))
+	r.Header.Set(ContentType, ApplicationJson)
+	var req request
+	err := Parse(r, &req)
+	assert.NotNil(t, err)
+
+	w := httptest.NewRecorder()
+	Error(w, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ApplicationJson, w.Header().Get(ContentType))
+	assert.JSONEq(t, `{"errors":[
+		{"field":"name","message":"length must be at least 3"},
+		{"field":"email","message":"must be a valid email"},
+		{"field":"tags","message":"must have at least 1 items"}
+	]}`, w.Body.String())
+}
+
+func TestErrorPlain(t *testing.T) {
+	w := httptest.NewRecorder()
+	Error(w, errors.New("foo"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "foo", strings.TrimSpace(w.Body.String()))
+}
@@ -0,0 +1,209 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/threading"
+)
+
+const (
+	// EventStream means text/event-stream.
+	EventStream = "text/event-stream"
+	// LastEventId means Last-Event-ID, the header that browsers send on reconnecting.
+	LastEventId = "Last-Event-ID"
+
+	heartbeatComment = ": heartbeat\n\n"
+)
+
+var (
+	// ErrStreamingUnsupported is an error that indicates the response writer can't be flushed,
+	// like the routes are not set with rest.WithStreaming, which are buffered by timeout handler.
+	ErrStreamingUnsupported = errors.New("streaming unsupported")
+	// ErrStreamClosed is an error that indicates the stream is closed or the client is gone.
+	ErrStreamClosed = errors.New("event stream closed")
+)
+
+type (
+	// An Event is a server-sent event.
+	Event struct {
+		Id    string
+		Event string
+		Data  string
+		// Retry tells the client how long to wait before reconnecting.
+		Retry time.Duration
+	}
+
+	// SSEOption defines the method to customize an SSEStream.
+	SSEOption func(s *SSEStream)
+
+	// An SSEStream writes server-sent events into the response.
+	SSEStream struct {
+		w         http.ResponseWriter
+		flusher   http.Flusher
+		r         *http.Request
+		retry     time.Duration
+		heartbeat time.Duration
+		lock      sync.Mutex
+		closed    bool
+		quit      chan struct{}
+	}
+)
+
+// SSE starts a server-sent events stream on w. The stream must be closed before the handler returns.
+func SSE(w http.ResponseWriter, r *http.Request, opts ...SSEOption) (*SSEStream, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, ErrStreamingUnsupported
+	}
+
+	s := &SSEStream{
+		w:       w,
+		flusher: flusher,
+		r:       r,
+		quit:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	header := w.Header()
+	header.Set(ContentType, EventStream)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// disables the response buffering of nginx.
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if s.retry > 0 {
+		if err := s.write(fmt.Sprintf("retry: %d\n\n", s.retry/time.Millisecond)); err != nil {
+			return nil, err
+		}
+	} else {
+		flusher.Flush()
+	}
+
+	if s.heartbeat > 0 {
+		threading.GoSafe(s.keepAlive)
+	}
+
+	return s, nil
+}
+
+// Close closes the stream, no more events can be sent.
+func (s *SSEStream) Close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.quit)
+	}
+}
+
+// Done returns a channel that is closed when the client is disconnected.
+func (s *SSEStream) Done() <-chan struct{} {
+	return s.r.Context().Done()
+}
+
+// LastEventId returns the id of the last event that the client received before reconnecting.
+func (s *SSEStream) LastEventId() string {
+	return s.r.Header.Get(LastEventId)
+}
+
+// Send sends the event to the client.
+func (s *SSEStream) Send(event Event) error {
+	var builder strings.Builder
+	if len(event.Id) > 0 {
+		fmt.Fprintf(&builder, "id: %s\n", singleLine(event.Id))
+	}
+	if len(event.Event) > 0 {
+		fmt.Fprintf(&builder, "event: %s\n", singleLine(event.Event))
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(&builder, "retry: %d\n", event.Retry/time.Millisecond)
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(event.Data, "\r\n", "\n"), "\n") {
+		fmt.Fprintf(&builder, "data: %s\n", line)
+	}
+	builder.WriteByte('\n')
+
+	return s.write(builder.String())
+}
+
+// SendJson sends v as json in the event data with the given event name, which can be empty.
+func (s *SSEStream) SendJson(event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.Send(Event{
+		Event: event,
+		Data:  string(data),
+	})
+}
+
+func (s *SSEStream) keepAlive() {
+	ticker := time.NewTicker(s.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-s.r.Context().Done():
+			return
+		case <-ticker.C:
+			if err := s.write(heartbeatComment); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *SSEStream) write(content string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return ErrStreamClosed
+	}
+
+	select {
+	case <-s.r.Context().Done():
+		return ErrStreamClosed
+	default:
+	}
+
+	if _, err := s.w.Write([]byte(content)); err != nil {
+		return err
+	}
+
+	s.flusher.Flush()
+	return nil
+}
+
+// WithHeartbeat returns an SSEOption that sends heartbeat comments in interval,
+// which keeps the connections alive through proxies.
+func WithHeartbeat(interval time.Duration) SSEOption {
+	return func(s *SSEStream) {
+		s.heartbeat = interval
+	}
+}
+
+// WithRetry returns an SSEOption that tells the client how long to wait before reconnecting.
+func WithRetry(retry time.Duration) SSEOption {
+	return func(s *SSEStream) {
+		s.retry = retry
+	}
+}
+
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,132 @@
+package httpx
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type plainWriter struct {
+	http.ResponseWriter
+}
+
+func readLines(t *testing.T, scanner *bufio.Scanner, n int) []string {
+	t.Helper()
+	var lines []string
+	for len(lines) < n && scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) < n {
+		t.Fatalf("expected %d lines, got %q, %v", n, lines, scanner.Err())
+	}
+
+	return lines
+}
+
+func TestSSE(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		stream, err := SSE(w, r, WithRetry(time.Second*3), WithHeartbeat(time.Millisecond*20))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer stream.Close()
+
+		if err = stream.Send(Event{
+			Id:    "1\n",
+			Event: "greet",
+			Data:  "hello\nworld",
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		if err = stream.SendJson("", map[string]string{"last": stream.LastEventId()}); err != nil {
+			t.Error(err)
+			return
+		}
+
+		select {
+		case <-stream.Done():
+		case <-time.After(time.Second * 5):
+			t.Error("client disconnect not detected")
+			return
+		}
+
+		if err = stream.Send(Event{Data: "gone"}); err != ErrStreamClosed {
+			t.Errorf("expected stream closed, got %v", err)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(LastEventId, "0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.Header.Get(ContentType) != EventStream {
+		t.Fatalf("unexpected content type %s", resp.Header.Get(ContentType))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	expect := []string{
+		"retry: 3000",
+		"",
+		"id: 1",
+		"event: greet",
+		"data: hello",
+		"data: world",
+		"",
+		`data: {"last":"0"}`,
+		"",
+	}
+	if lines := readLines(t, scanner, len(expect)); strings.Join(lines, "\n") != strings.Join(expect, "\n") {
+		t.Fatalf("unexpected events:\n%s", strings.Join(lines, "\n"))
+	}
+	if lines := readLines(t, scanner, 2); lines[0] != ": heartbeat" {
+		t.Fatalf("expected heartbeat, got %q", lines)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("handler not finished after client disconnected")
+	}
+}
+
+func TestSSEUnsupported(t *testing.T) {
+	w := plainWriter{ResponseWriter: httptest.NewRecorder()}
+	if _, err := SSE(w, httptest.NewRequest(http.MethodGet, "/", nil)); err != ErrStreamingUnsupported {
+		t.Fatalf("expected unsupported, got %v", err)
+	}
+}
+
+func TestSSEClose(t *testing.T) {
+	w := httptest.NewRecorder()
+	stream, err := SSE(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream.Close()
+	stream.Close()
+	if err = stream.Send(Event{Data: "closed"}); err != ErrStreamClosed {
+		t.Fatalf("expected stream closed, got %v", err)
+	}
+	if err = stream.SendJson("", make(chan int)); err == nil {
+		t.Fatal("expected marshal error")
+	}
+}
@@ -5,37 +5,95 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/lukebull/go-zero-extern/core/health"
+	"github.com/lukebull/go-zero-extern/core/logx"
 	"github.com/lukebull/go-zero-extern/core/proc"
+	"github.com/lukebull/go-zero-extern/core/syncx"
+)
+
+type (
+	// StartOption defines the method to customize the Server.
+	StartOption func(srv *Server)
+
+	// A Server is a http server that drains the in-flight requests on shutdown.
+	Server struct {
+		*http.Server
+		drained    *syncx.DoneChan
+		drainHooks []func()
+	}
 )
 
 // StartHttp starts a http server.
-func StartHttp(host string, port int, handler http.Handler) error {
+func StartHttp(host string, port int, handler http.Handler, opts ...StartOption) error {
 	return start(host, port, handler, func(srv *http.Server) error {
 		return srv.ListenAndServe()
-	})
+	}, opts...)
 }
 
 // StartHttps starts a https server.
-func StartHttps(host string, port int, certFile, keyFile string, handler http.Handler) error {
+func StartHttps(host string, port int, certFile, keyFile string, handler http.Handler,
+	opts ...StartOption) error {
 	return start(host, port, handler, func(srv *http.Server) error {
 		// certFile and keyFile are set in buildHttpsServer
 		return srv.ListenAndServeTLS(certFile, keyFile)
-	})
+	}, opts...)
+}
+
+// WithDrainHook returns a StartOption that calls fn on draining before the listener stops,
+// like closing the hijacked connections, which are not tracked by the http server.
+func WithDrainHook(fn func()) StartOption {
+	return func(srv *Server) {
+		srv.drainHooks = append(srv.drainHooks, fn)
+	}
+}
+
+// Drain stops accepting new requests and waits for the in-flight requests to finish,
+// the connections are closed if ctx is done before that.
+func (s *Server) Drain(ctx context.Context) error {
+	defer s.drained.Close()
+
+	for _, hook := range s.drainHooks {
+		hook()
+	}
+
+	err := s.Shutdown(ctx)
+	if err == nil {
+		return nil
+	}
+
+	logx.Errorf("Error on draining http server %s, closing the connections: %v", s.Addr, err)
+	if closeErr := s.Close(); closeErr != nil {
+		logx.Error(closeErr)
+	}
+
+	return err
 }
 
-func start(host string, port int, handler http.Handler, run func(srv *http.Server) error) (err error) {
-	server := &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", host, port),
-		Handler: handler,
+func start(host string, port int, handler http.Handler, run func(srv *http.Server) error,
+	opts ...StartOption) (err error) {
+	server := &Server{
+		Server: &http.Server{
+			Addr:    fmt.Sprintf("%s:%d", host, port),
+			Handler: handler,
+		},
+		drained: syncx.NewDoneChan(),
 	}
-	waitForCalled := proc.AddWrapUpListener(func() {
-		server.Shutdown(context.Background())
+	for _, opt := range opts {
+		opt(server)
+	}
+
+	proc.AddUnreadyListener(health.MarkUnready)
+	proc.AddWrapUpListener(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), proc.DrainTimeout())
+		defer cancel()
+		_ = server.Drain(ctx)
 	})
 	defer func() {
+		// returns after the in-flight requests are drained.
 		if err == http.ErrServerClosed {
-			waitForCalled()
+			<-server.drained.Done()
 		}
 	}()
 
-	return run(server)
+	return run(server.Server)
 }
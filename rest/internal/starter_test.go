@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func freePort(t *testing.T) int {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+
+	return lis.Addr().(*net.TCPAddr).Port
+}
+
+func startTestServer(t *testing.T, handler http.Handler) (*Server, string, chan error) {
+	port := freePort(t)
+	servers := make(chan *Server, 1)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- StartHttp("127.0.0.1", port, handler, func(srv *Server) {
+			servers <- srv
+		})
+	}()
+
+	server := <-servers
+	addr := server.Addr
+	for i := 0; i < 100; i++ {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	return server, "http://" + addr, errs
+}
+
+func TestDrainWaitsInFlightRequests(t *testing.T) {
+	entered := make(chan struct{})
+	server, url, errs := startTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		time.Sleep(time.Millisecond * 100)
+		_, _ = w.Write([]byte("done"))
+	}))
+
+	bodies := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			bodies <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		bodies <- string(body)
+	}()
+	<-entered
+
+	if err := server.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if body := <-bodies; body != "done" {
+		t.Fatalf("expected the in-flight request finished, got %q", body)
+	}
+	if err := <-errs; err != http.ErrServerClosed {
+		t.Fatalf("expected server closed, got %v", err)
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	entered := make(chan struct{})
+	server, url, errs := startTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-r.Context().Done()
+	}))
+
+	go func() {
+		if resp, err := http.Get(url); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if err := server.Drain(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if err := <-errs; err != http.ErrServerClosed {
+		t.Fatalf("expected server closed, got %v", err)
+	}
+}
+
+func TestDrainHooksBeforeListenerStops(t *testing.T) {
+	port := freePort(t)
+	servers := make(chan *Server, 1)
+	errs := make(chan error, 1)
+	hooked := make(chan error, 1)
+	go func() {
+		errs <- StartHttp("127.0.0.1", port, http.NotFoundHandler(), func(srv *Server) {
+			servers <- srv
+		}, WithDrainHook(func() {
+			// the listener still accepts the connections in the hooks.
+			conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+			if err == nil {
+				conn.Close()
+			}
+			hooked <- err
+		}))
+	}()
+
+	server := <-servers
+	for i := 0; i < 100; i++ {
+		conn, err := net.Dial("tcp", server.Addr)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if err := server.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-hooked; err != nil {
+		t.Fatalf("expected the listener alive in hooks, got %v", err)
+	}
+	if err := <-errs; err != http.ErrServerClosed {
+		t.Fatalf("expected server closed, got %v", err)
+	}
+}
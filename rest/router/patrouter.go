@@ -76,6 +76,11 @@ func (pr *patRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	if pr.notAllowed != nil {
 		pr.notAllowed.ServeHTTP(w, r)
+	} else if r.Method == http.MethodOptions {
+		// answers the OPTIONS requests with the allowed methods, like the preflight requests
+		// on the routes without CORS, which are rejected by browsers without CORS headers.
+		w.Header().Set(allowHeader, allows+allowMethodSeparator+http.MethodOptions)
+		w.WriteHeader(http.StatusNoContent)
 	} else {
 		w.Header().Set(allowHeader, allows)
 		w.WriteHeader(http.StatusMethodNotAllowed)
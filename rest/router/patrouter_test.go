@@ -0,0 +1,37 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPatRouterMethodNotAllowed(t *testing.T) {
+	rt := NewRouter()
+	if err := rt.Handle(http.MethodGet, "/users/:id", http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/1", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get(allowHeader) != http.MethodGet {
+		t.Fatalf("unexpected response %d %v", w.Code, w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/users/1", nil))
+	if w.Code != http.StatusNoContent || w.Header().Get(allowHeader) != "GET, OPTIONS" {
+		t.Fatalf("unexpected response %d %v", w.Code, w.Header())
+	}
+
+	rt.SetNotAllowedHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	w = httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/users/1", nil))
+	if w.Code != http.StatusTeapot {
+		t.Fatalf("expected not allowed handler called, got %d", w.Code)
+	}
+}
@@ -1,10 +1,17 @@
 package rest
 
 import (
+	"context"
+	"io/fs"
 	"log"
 	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/rest/fileserver"
 	"github.com/lukebull/go-zero-extern/rest/handler"
 	"github.com/lukebull/go-zero-extern/rest/httpx"
 	"github.com/lukebull/go-zero-extern/rest/router"
@@ -76,9 +83,39 @@ func (e *Server) AddRoute(r Route, opts ...RouteOption) {
 	e.AddRoutes([]Route{r}, opts...)
 }
 
+// AddWebSocketRoutes adds given websocket routes into the Server.
+// The routes are not limited by MaxConns and Timeout, but by RestConf.WebSocket.
+func (e *Server) AddWebSocketRoutes(rs []WebSocketRoute, opts ...RouteOption) {
+	r := featuredRoutes{
+		sockets: rs,
+	}
+	for _, opt := range opts {
+		opt(&r)
+	}
+	e.ngin.AddRoutes(r)
+}
+
+// AddWebSocketRoute adds given websocket route into the Server.
+func (e *Server) AddWebSocketRoute(r WebSocketRoute, opts ...RouteOption) {
+	e.AddWebSocketRoutes([]WebSocketRoute{r}, opts...)
+}
+
+// AddStatic serves the files in dir under prefix, like /static.
+// The routes take precedence over the static files if they match the same paths.
+func (e *Server) AddStatic(prefix, dir string, opts ...fileserver.Option) {
+	e.addStatic(prefix, os.DirFS(dir), opts...)
+}
+
+// Drain stops accepting new requests and waits for the in-flight requests to finish until ctx is done.
+// It's called by service.ServiceGroup on stopping.
+func (e *Server) Drain(ctx context.Context) error {
+	return e.ngin.Drain(ctx)
+}
+
 // Start starts the Server.
-// Graceful shutdown is enabled by default.
-// Use proc.SetTimeToForceQuit to customize the graceful shutdown period.
+// Graceful shutdown is enabled by default, the server is marked unready first,
+// then drains after RestConf.Shutdown.Delay. Use RestConf.Shutdown.DrainTimeout
+// and proc.SetTimeToForceQuit to customize the graceful shutdown period.
 func (e *Server) Start() {
 	handleError(e.opts.start(e.ngin))
 }
@@ -93,6 +130,15 @@ func (e *Server) Use(middleware Middleware) {
 	e.ngin.use(middleware)
 }
 
+func (e *Server) addStatic(prefix string, fsys fs.FS, opts ...fileserver.Option) {
+	validatePrefix(prefix)
+	prefix = path.Clean(prefix)
+	e.ngin.AddStatic(staticRoute{
+		prefix:  prefix,
+		handler: fileserver.NewHandler(prefix, fsys, opts...),
+	})
+}
+
 // ToMiddleware converts the given handler to a Middleware.
 func ToMiddleware(handler func(next http.Handler) http.Handler) Middleware {
 	return func(handle http.HandlerFunc) http.HandlerFunc {
@@ -109,6 +155,39 @@ func WithJwt(secret string) RouteOption {
 	}
 }
 
+// WithCors returns a RouteOption that overrides RestConf.Cors on the routes,
+// an empty AllowOrigins disables CORS on the routes.
+func WithCors(c CorsConf) RouteOption {
+	return func(r *featuredRoutes) {
+		r.cors = &c
+	}
+}
+
+// WithJwks returns a func to enable jwt authentication with the asymmetric signed tokens,
+// which are verified by the public keys loaded from the JWKS url.
+func WithJwks(url string) RouteOption {
+	return func(r *featuredRoutes) {
+		r.jwt.enabled = true
+		r.jwt.jwksUrl = url
+	}
+}
+
+// WithJwtClaims returns a func to validate the iss and aud of the jwt tokens.
+func WithJwtClaims(claims JwtClaimsConf) RouteOption {
+	return func(r *featuredRoutes) {
+		r.jwt.claims = &claims
+	}
+}
+
+// WithJwtPublicKeys returns a func to enable jwt authentication with the asymmetric signed tokens,
+// which are verified by the given public keys.
+func WithJwtPublicKeys(keys ...JwtPublicKeyConf) RouteOption {
+	return func(r *featuredRoutes) {
+		r.jwt.enabled = true
+		r.jwt.publicKeys = append(r.jwt.publicKeys, keys...)
+	}
+}
+
 // WithJwtTransition returns a func to enable jwt authentication as well as jwt secret transition.
 // Which means old and new jwt secrets work together for a period.
 func WithJwtTransition(secret, prevSecret string) RouteOption {
@@ -122,6 +201,15 @@ func WithJwtTransition(secret, prevSecret string) RouteOption {
 	}
 }
 
+// WithFileServer returns a RunOption that serves the files in fsys under prefix,
+// use fs.Sub to serve a sub directory of an embed.FS, and fileserver.WithIndexFallback for single page apps.
+// The routes take precedence over the static files if they match the same paths.
+func WithFileServer(prefix string, fsys fs.FS, opts ...fileserver.Option) RunOption {
+	return func(server *Server) {
+		server.addStatic(prefix, fsys, opts...)
+	}
+}
+
 // WithMiddlewares adds given middlewares to given routes.
 func WithMiddlewares(ms []Middleware, rs ...Route) []Route {
 	for i := len(ms) - 1; i >= 0; i-- {
@@ -160,6 +248,14 @@ func WithNotAllowedHandler(handler http.Handler) RunOption {
 	return WithRouter(rt)
 }
 
+// WithPrefix returns a RouteOption that mounts the routes under the given prefix, like /api/v1.
+func WithPrefix(prefix string) RouteOption {
+	return func(r *featuredRoutes) {
+		validatePrefix(prefix)
+		r.prefix = path.Clean(prefix)
+	}
+}
+
 // WithPriority returns a RunOption with priority.
 func WithPriority() RouteOption {
 	return func(r *featuredRoutes) {
@@ -167,6 +263,14 @@ func WithPriority() RouteOption {
 	}
 }
 
+// WithRateLimit returns a RouteOption that overrides RestConf.RateLimit on the routes,
+// a zero Quota disables rate limiting on the routes.
+func WithRateLimit(c RateLimitConf) RouteOption {
+	return func(r *featuredRoutes) {
+		r.rateLimit = &c
+	}
+}
+
 // WithRouter returns a RunOption that make server run with given router.
 func WithRouter(router httpx.Router) RunOption {
 	return func(server *Server) {
@@ -186,6 +290,21 @@ func WithSignature(signature SignatureConf) RouteOption {
 	}
 }
 
+// WithStreaming returns a RouteOption that exempts the routes from the timeout handler,
+// which buffers the responses, so that the routes can stream the responses, like httpx.SSE.
+func WithStreaming() RouteOption {
+	return func(r *featuredRoutes) {
+		r.streaming = true
+	}
+}
+
+// WithTimeout returns a RouteOption that overrides RestConf.Timeout on the routes.
+func WithTimeout(timeout time.Duration) RouteOption {
+	return func(r *featuredRoutes) {
+		r.timeout = timeout
+	}
+}
+
 // WithUnauthorizedCallback returns a RunOption that with given unauthorized callback set.
 func WithUnauthorizedCallback(callback handler.UnauthorizedCallback) RunOption {
 	return func(engine *Server) {
@@ -210,6 +329,12 @@ func handleError(err error) {
 	panic(err)
 }
 
+func validatePrefix(prefix string) {
+	if !strings.HasPrefix(prefix, "/") {
+		panic("prefix should start with /")
+	}
+}
+
 func validateSecret(secret string) {
 	if len(secret) < 8 {
 		panic("secret's length can't be less than 8")
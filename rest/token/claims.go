@@ -0,0 +1,87 @@
+package token
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+var (
+	// ErrTokenExpired is an error that indicates the token is expired.
+	ErrTokenExpired = errors.New("token is expired")
+	// ErrTokenNotValidYet is an error that indicates the token is used before nbf or iat.
+	ErrTokenNotValidYet = errors.New("token is not valid yet")
+	// ErrInvalidIssuer is an error that indicates the iss of the token is not expected.
+	ErrInvalidIssuer = errors.New("invalid token issuer")
+	// ErrInvalidAudience is an error that indicates the aud of the token is not expected.
+	ErrInvalidAudience = errors.New("invalid token audience")
+)
+
+// A ClaimsValidator validates the registered claims of the tokens.
+// The iss and aud are not validated if Issuer and Audience are empty.
+type ClaimsValidator struct {
+	Issuer   string
+	Audience string
+	// Leeway is the allowed clock skew on validating exp, iat and nbf.
+	Leeway time.Duration
+}
+
+// Validate validates the given claims.
+func (v ClaimsValidator) Validate(claims jwt.MapClaims) error {
+	now := time.Now()
+	if exp, ok := numericClaim(claims, "exp"); ok && !now.Before(time.Unix(exp, 0).Add(v.Leeway)) {
+		return ErrTokenExpired
+	}
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(v.Leeway).Before(time.Unix(nbf, 0)) {
+		return ErrTokenNotValidYet
+	}
+	if iat, ok := numericClaim(claims, "iat"); ok && now.Add(v.Leeway).Before(time.Unix(iat, 0)) {
+		return ErrTokenNotValidYet
+	}
+
+	if len(v.Issuer) > 0 {
+		if iss, _ := claims["iss"].(string); iss != v.Issuer {
+			return ErrInvalidIssuer
+		}
+	}
+
+	if len(v.Audience) > 0 && !hasAudience(claims["aud"], v.Audience) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
+
+// hasAudience checks the aud claim, which is either a string or an array of strings.
+func hasAudience(aud interface{}, audience string) bool {
+	switch val := aud.(type) {
+	case string:
+		return val == audience
+	case []interface{}:
+		for _, item := range val {
+			if s, ok := item.(string); ok && s == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func numericClaim(claims jwt.MapClaims, key string) (int64, bool) {
+	switch val := claims[key].(type) {
+	case float64:
+		return int64(val), true
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n, true
+		}
+		if f, err := val.Float64(); err == nil {
+			return int64(f), true
+		}
+	}
+
+	return 0, false
+}
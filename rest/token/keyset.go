@@ -0,0 +1,351 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/core/syncx"
+	"github.com/lukebull/go-zero-extern/core/timex"
+)
+
+const (
+	defaultRefreshInterval = time.Minute * 5
+	// minRefreshInterval limits the refreshes on unknown kids, like forged tokens.
+	minRefreshInterval = time.Second * 10
+	jwksFetchTimeout   = time.Second * 10
+	jwksSharedKey      = "jwks"
+	keyUseSignature    = "sig"
+)
+
+var (
+	// ErrKeyNotFound is an error that indicates no public key found for the kid of the token.
+	ErrKeyNotFound = errors.New("public key not found")
+	// ErrUnsupportedKey is an error that indicates the public key is neither RSA nor ECDSA.
+	ErrUnsupportedKey = errors.New("unsupported public key")
+
+	// asymmetricMethods are the allowed signing methods on verifying with public keys,
+	// the HMAC methods are not allowed, otherwise the public keys can be used as HMAC secrets.
+	asymmetricMethods = []string{
+		jwt.SigningMethodRS256.Alg(),
+		jwt.SigningMethodRS384.Alg(),
+		jwt.SigningMethodRS512.Alg(),
+		jwt.SigningMethodPS256.Alg(),
+		jwt.SigningMethodPS384.Alg(),
+		jwt.SigningMethodPS512.Alg(),
+		jwt.SigningMethodES256.Alg(),
+		jwt.SigningMethodES384.Alg(),
+		jwt.SigningMethodES512.Alg(),
+	}
+)
+
+type (
+	// KeySetOption defines the method to customize a KeySet.
+	KeySetOption func(ks *KeySet)
+
+	// A KeySet is a set of public keys to verify the asymmetric signed tokens by kid.
+	// The keys can be static or loaded from a JWKS url, which are refreshed periodically.
+	KeySet struct {
+		url             string
+		client          *http.Client
+		refreshInterval time.Duration
+		static          map[string]interface{}
+		lock            sync.RWMutex
+		keys            map[string]interface{}
+		refreshed       time.Duration
+		barrier         syncx.SharedCalls
+	}
+
+	jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	jwk struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+)
+
+// NewKeySet returns a KeySet, the keys from JWKS url are loaded at once if set.
+func NewKeySet(opts ...KeySetOption) (*KeySet, error) {
+	ks := &KeySet{
+		client: &http.Client{
+			Timeout: jwksFetchTimeout,
+		},
+		refreshInterval: defaultRefreshInterval,
+		static:          make(map[string]interface{}),
+		keys:            make(map[string]interface{}),
+		barrier:         syncx.NewSharedCalls(),
+	}
+	for _, opt := range opts {
+		opt(ks)
+	}
+
+	for kid, key := range ks.static {
+		if !isAsymmetricKey(key) {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, kid)
+		}
+	}
+
+	if len(ks.url) > 0 {
+		if err := ks.refresh(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ks, nil
+}
+
+// Key returns the public key of the given kid. If kid is empty and only one key
+// in the set, the key is returned.
+func (ks *KeySet) Key(kid string) (interface{}, error) {
+	if len(ks.url) > 0 && ks.sinceRefreshed() > ks.refreshInterval {
+		ks.tryRefresh()
+	}
+
+	if key, ok := ks.lookup(kid); ok {
+		return key, nil
+	}
+
+	// the keys might be rotated, refresh if not refreshed recently.
+	if len(ks.url) > 0 && ks.sinceRefreshed() > minRefreshInterval {
+		ks.tryRefresh()
+		if key, ok := ks.lookup(kid); ok {
+			return key, nil
+		}
+	}
+
+	return nil, ErrKeyNotFound
+}
+
+func (ks *KeySet) fetch() (map[string]interface{}, error) {
+	resp, err := ks.client.Get(ks.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch jwks from %s, status: %s", ks.url, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var set jwks
+	if err = json.Unmarshal(body, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		if len(k.Use) > 0 && k.Use != keyUseSignature {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			logx.Errorf("jwks: skipped key %q from %s: %v", k.Kid, ks.url, err)
+			continue
+		}
+
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	return ks.Key(kid)
+}
+
+func (ks *KeySet) lookup(kid string) (interface{}, bool) {
+	if key, ok := ks.static[kid]; ok {
+		return key, true
+	}
+
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+
+	if key, ok := ks.keys[kid]; ok {
+		return key, true
+	}
+
+	if len(kid) > 0 || len(ks.static)+len(ks.keys) != 1 {
+		return nil, false
+	}
+
+	for _, key := range ks.static {
+		return key, true
+	}
+	for _, key := range ks.keys {
+		return key, true
+	}
+
+	return nil, false
+}
+
+func (ks *KeySet) refresh() error {
+	_, err := ks.barrier.Do(jwksSharedKey, func() (interface{}, error) {
+		keys, err := ks.fetch()
+
+		ks.lock.Lock()
+		defer ks.lock.Unlock()
+		ks.refreshed = timex.Now()
+		if err != nil {
+			return nil, err
+		}
+
+		ks.keys = keys
+		return nil, nil
+	})
+	return err
+}
+
+func (ks *KeySet) sinceRefreshed() time.Duration {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+	return timex.Since(ks.refreshed)
+}
+
+// tryRefresh refreshes the keys, the previous keys are kept on errors.
+func (ks *KeySet) tryRefresh() {
+	if err := ks.refresh(); err != nil {
+		logx.Errorf("jwks: failed to refresh keys from %s: %v", ks.url, err)
+	}
+}
+
+// LoadPublicKey loads the RSA or ECDSA public key from the given PEM file.
+func LoadPublicKey(file string) (interface{}, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(content); err == nil {
+		return key, nil
+	}
+
+	if key, err := jwt.ParseECPublicKeyFromPEM(content); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, file)
+}
+
+// WithJwksUrl returns a KeySetOption to load the keys from the JWKS url.
+func WithJwksUrl(url string) KeySetOption {
+	return func(ks *KeySet) {
+		ks.url = url
+	}
+}
+
+// WithPublicKey returns a KeySetOption to add a static RSA or ECDSA public key with kid.
+func WithPublicKey(kid string, key interface{}) KeySetOption {
+	return func(ks *KeySet) {
+		ks.static[kid] = key
+	}
+}
+
+// WithRefreshInterval returns a KeySetOption to customize the refresh interval of JWKS.
+func WithRefreshInterval(interval time.Duration) KeySetOption {
+	return func(ks *KeySet) {
+		ks.refreshInterval = interval
+	}
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{
+			N: n,
+			E: int(e.Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point not on curve")
+		}
+
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     x,
+			Y:     y,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	if len(s) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
+
+func isAsymmetricKey(key interface{}) bool {
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return true
+	default:
+		return false
+	}
+}
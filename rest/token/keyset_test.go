@@ -0,0 +1,348 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+type jwksServer struct {
+	*httptest.Server
+	lock  sync.Mutex
+	keys  []jwk
+	hits  int32
+	fails bool
+}
+
+func newJwksServer() *jwksServer {
+	s := new(jwksServer)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.hits, 1)
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if s.fails {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(jwks{Keys: s.keys})
+	}))
+
+	return s
+}
+
+func (s *jwksServer) setKeys(keys ...jwk) {
+	s.lock.Lock()
+	s.keys = keys
+	s.lock.Unlock()
+}
+
+func (s *jwksServer) setFails(fails bool) {
+	s.lock.Lock()
+	s.fails = fails
+	s.lock.Unlock()
+}
+
+func encodeBigInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+func rsaJwk(kid string, key *rsa.PrivateKey) jwk {
+	return jwk{
+		Kid: kid,
+		Kty: "RSA",
+		Use: "sig",
+		N:   encodeBigInt(key.N),
+		E:   encodeBigInt(big.NewInt(int64(key.E))),
+	}
+}
+
+func ecJwk(kid string, key *ecdsa.PrivateKey) jwk {
+	return jwk{
+		Kid: kid,
+		Kty: "EC",
+		Crv: "P-256",
+		X:   encodeBigInt(key.X),
+		Y:   encodeBigInt(key.Y),
+	}
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{},
+	claims jwt.MapClaims) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(method, claims)
+	if len(kid) > 0 {
+		tok.Header["kid"] = kid
+	}
+	signed, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func newTokenRequest(tok string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+tok)
+	return r
+}
+
+func mustRsaKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func mustEcKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestKeySetJwks(t *testing.T) {
+	rsaKey := mustRsaKey(t)
+	ecKey := mustEcKey(t)
+	server := newJwksServer()
+	defer server.Close()
+	server.setKeys(rsaJwk("rsa", rsaKey), ecJwk("ec", ecKey), jwk{Kid: "enc", Kty: "RSA", Use: "enc"},
+		jwk{Kid: "bad", Kty: "oct"})
+
+	keys, err := NewKeySet(WithJwksUrl(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser := NewTokenParser()
+	claims := jwt.MapClaims{
+		"uid": "1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for _, tok := range []string{
+		signToken(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims),
+		signToken(t, jwt.SigningMethodES256, "ec", ecKey, claims),
+	} {
+		parsed, err := parser.ParseTokenWithKeySet(newTokenRequest(tok), keys, ClaimsValidator{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.Claims.(jwt.MapClaims)["uid"] != "1" {
+			t.Fatalf("unexpected claims %v", parsed.Claims)
+		}
+	}
+
+	for _, kid := range []string{"enc", "bad", "unknown"} {
+		if _, err = keys.Key(kid); err != ErrKeyNotFound {
+			t.Fatalf("expected key %s not found, got %v", kid, err)
+		}
+	}
+}
+
+func TestKeySetRejectsHmacAndWrongKey(t *testing.T) {
+	rsaKey := mustRsaKey(t)
+	keys, err := NewKeySet(WithPublicKey("rsa", &rsaKey.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser := NewTokenParser()
+	publicPem, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// signs with the public key as HMAC secret.
+	forged := signToken(t, jwt.SigningMethodHS256, "rsa", publicPem, jwt.MapClaims{})
+	if _, err = parser.ParseTokenWithKeySet(newTokenRequest(forged), keys, ClaimsValidator{}); err == nil {
+		t.Fatal("expected HMAC tokens rejected")
+	}
+
+	other := signToken(t, jwt.SigningMethodRS256, "rsa", mustRsaKey(t), jwt.MapClaims{})
+	if _, err = parser.ParseTokenWithKeySet(newTokenRequest(other), keys, ClaimsValidator{}); err == nil {
+		t.Fatal("expected tokens signed by other key rejected")
+	}
+
+	// without kid, the only key is used.
+	noKid := signToken(t, jwt.SigningMethodRS256, "", rsaKey, jwt.MapClaims{})
+	if _, err = parser.ParseTokenWithKeySet(newTokenRequest(noKid), keys, ClaimsValidator{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeySetRefresh(t *testing.T) {
+	oldKey := mustRsaKey(t)
+	newKey := mustRsaKey(t)
+	server := newJwksServer()
+	defer server.Close()
+	server.setKeys(rsaJwk("old", oldKey))
+
+	keys, err := NewKeySet(WithJwksUrl(server.URL), WithRefreshInterval(time.Millisecond*50))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = keys.Key("old"); err != nil {
+		t.Fatal(err)
+	}
+
+	// unknown kids don't trigger refreshes within minRefreshInterval.
+	server.setKeys(rsaJwk("new", newKey))
+	hits := atomic.LoadInt32(&server.hits)
+	if _, err = keys.Key("new"); err != ErrKeyNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if atomic.LoadInt32(&server.hits) != hits {
+		t.Fatal("unexpected refresh on unknown kid")
+	}
+
+	time.Sleep(time.Millisecond * 60)
+	if _, err = keys.Key("new"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = keys.Key("old"); err != ErrKeyNotFound {
+		t.Fatalf("expected rotated key removed, got %v", err)
+	}
+
+	// the keys are kept on refresh failures.
+	server.setFails(true)
+	time.Sleep(time.Millisecond * 60)
+	if _, err = keys.Key("new"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewKeySetErrors(t *testing.T) {
+	server := newJwksServer()
+	server.setFails(true)
+	defer server.Close()
+
+	if _, err := NewKeySet(WithJwksUrl(server.URL)); err == nil {
+		t.Fatal("expected error on unavailable jwks")
+	}
+	if _, err := NewKeySet(WithPublicKey("hmac", []byte("secret"))); err == nil {
+		t.Fatal("expected error on symmetric key")
+	}
+}
+
+func TestLoadPublicKey(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := mustRsaKey(t)
+	ecKey := mustEcKey(t)
+
+	for name, key := range map[string]interface{}{
+		"rsa.pem": &rsaKey.PublicKey,
+		"ec.pem":  &ecKey.PublicKey,
+	} {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(dir, name)
+		if err = ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{
+			Type:  "PUBLIC KEY",
+			Bytes: der,
+		}), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := LoadPublicKey(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !isAsymmetricKey(loaded) {
+			t.Fatalf("unexpected key type %T", loaded)
+		}
+	}
+
+	bad := filepath.Join(dir, "bad.pem")
+	if err := ioutil.WriteFile(bad, []byte("bad"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPublicKey(bad); err == nil {
+		t.Fatal("expected error on bad key file")
+	}
+}
+
+func TestClaimsValidator(t *testing.T) {
+	now := time.Now()
+	validator := ClaimsValidator{
+		Issuer:   "https://issuer",
+		Audience: "api",
+		Leeway:   time.Minute,
+	}
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss": "https://issuer",
+			"aud": "api",
+			"exp": json.Number("9999999999"),
+			"nbf": float64(now.Unix()),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(claims jwt.MapClaims)
+		expect error
+	}{
+		{name: "valid", modify: func(claims jwt.MapClaims) {}},
+		{name: "audience array", modify: func(claims jwt.MapClaims) {
+			claims["aud"] = []interface{}{"other", "api"}
+		}},
+		{name: "expired within leeway", modify: func(claims jwt.MapClaims) {
+			claims["exp"] = float64(now.Add(-time.Second * 30).Unix())
+		}},
+		{name: "expired", modify: func(claims jwt.MapClaims) {
+			claims["exp"] = float64(now.Add(-time.Minute * 2).Unix())
+		}, expect: ErrTokenExpired},
+		{name: "not before", modify: func(claims jwt.MapClaims) {
+			claims["nbf"] = json.Number(jsonInt(now.Add(time.Minute * 2).Unix()))
+		}, expect: ErrTokenNotValidYet},
+		{name: "issued in future", modify: func(claims jwt.MapClaims) {
+			claims["iat"] = float64(now.Add(time.Minute * 2).Unix())
+		}, expect: ErrTokenNotValidYet},
+		{name: "wrong issuer", modify: func(claims jwt.MapClaims) {
+			claims["iss"] = "https://evil"
+		}, expect: ErrInvalidIssuer},
+		{name: "missing audience", modify: func(claims jwt.MapClaims) {
+			delete(claims, "aud")
+		}, expect: ErrInvalidAudience},
+		{name: "wrong audience array", modify: func(claims jwt.MapClaims) {
+			claims["aud"] = []interface{}{"other"}
+		}, expect: ErrInvalidAudience},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims := valid()
+			test.modify(claims)
+			if err := validator.Validate(claims); err != test.expect {
+				t.Fatalf("expected %v, got %v", test.expect, err)
+			}
+		})
+	}
+
+	if err := (ClaimsValidator{}).Validate(jwt.MapClaims{"iss": "any"}); err != nil {
+		t.Fatalf("expected iss and aud not validated if not set, got %v", err)
+	}
+}
+
+func jsonInt(n int64) string {
+	return big.NewInt(n).String()
+}
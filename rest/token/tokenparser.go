@@ -1,6 +1,7 @@
 package token
 
 import (
+	"errors"
 	"net/http"
 	"sync"
 	"sync/atomic"
@@ -78,6 +79,34 @@ func (tp *TokenParser) ParseToken(r *http.Request, secret, prevSecret string) (*
 	return token, nil
 }
 
+// ParseTokenWithKeySet parses token from given r, verifies the signature with the public key
+// of the kid in keys. Only the asymmetric signing methods are allowed, and the registered claims
+// are validated by validator.
+func (tp *TokenParser) ParseTokenWithKeySet(r *http.Request, keys *KeySet,
+	validator ClaimsValidator) (*jwt.Token, error) {
+	parser := newParser()
+	parser.ValidMethods = asymmetricMethods
+	// validated by validator with leeway
+	parser.SkipClaimsValidation = true
+
+	token, err := request.ParseFromRequest(r, request.AuthorizationHeaderExtractor, keys.keyFunc,
+		request.WithParser(parser))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("unexpected claims type")
+	}
+
+	if err = validator.Validate(claims); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
 func (tp *TokenParser) doParseToken(r *http.Request, secret string) (*jwt.Token, error) {
 	return request.ParseFromRequest(r, request.AuthorizationHeaderExtractor,
 		func(token *jwt.Token) (interface{}, error) {
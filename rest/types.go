@@ -1,6 +1,12 @@
 package rest
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/rest/ws"
+)
 
 type (
 	// Middleware defines the middleware method.
@@ -13,6 +19,12 @@ type (
 		Handler http.HandlerFunc
 	}
 
+	// A WebSocketRoute is a websocket route, which is upgraded on GET requests.
+	WebSocketRoute struct {
+		Path    string
+		Handler ws.Handler
+	}
+
 	// RouteOption defines the method to customize a featured route.
 	RouteOption func(r *featuredRoutes)
 
@@ -20,6 +32,9 @@ type (
 		enabled    bool
 		secret     string
 		prevSecret string
+		jwksUrl    string
+		publicKeys []JwtPublicKeyConf
+		claims     *JwtClaimsConf
 	}
 
 	signatureSetting struct {
@@ -29,8 +44,23 @@ type (
 
 	featuredRoutes struct {
 		priority  bool
+		streaming bool
+		prefix    string
+		timeout   time.Duration
+		cors      *CorsConf
+		rateLimit *RateLimitConf
 		jwt       jwtSetting
 		signature signatureSetting
 		routes    []Route
+		sockets   []WebSocketRoute
+	}
+
+	staticRoute struct {
+		prefix  string
+		handler http.Handler
 	}
 )
+
+func (r staticRoute) match(reqPath string) bool {
+	return r.prefix == "/" || reqPath == r.prefix || strings.HasPrefix(reqPath, r.prefix+"/")
+}
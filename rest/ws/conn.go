@@ -0,0 +1,161 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/lukebull/go-zero-extern/core/threading"
+)
+
+const (
+	// TextMessage denotes a text data message.
+	TextMessage = websocket.TextMessage
+	// BinaryMessage denotes a binary data message.
+	BinaryMessage = websocket.BinaryMessage
+)
+
+type (
+	// A Handler handles the websocket connection, the connection is closed after the handler returns.
+	// The handler should keep reading the messages, otherwise the pongs are not processed
+	// and the connection is closed on read timeout.
+	Handler func(conn *Conn)
+
+	// A Conn is a websocket connection with read/write deadlines and ping/pong keepalive.
+	Conn struct {
+		conn      *websocket.Conn
+		r         *http.Request
+		path      string
+		conf      Conf
+		writeLock sync.Mutex
+		closeOnce sync.Once
+		done      chan struct{}
+	}
+)
+
+func newConn(conn *websocket.Conn, r *http.Request, path string, c Conf) *Conn {
+	wc := &Conn{
+		conn: conn,
+		r:    r,
+		path: path,
+		conf: c,
+		done: make(chan struct{}),
+	}
+
+	conn.SetReadLimit(c.MaxMessageSize)
+	wc.extendReadDeadline()
+	conn.SetPongHandler(func(string) error {
+		wc.extendReadDeadline()
+		return nil
+	})
+	threading.GoSafe(wc.keepAlive)
+
+	return wc
+}
+
+// Close closes the connection with a normal closure.
+func (c *Conn) Close() error {
+	return c.CloseWithReason(websocket.CloseNormalClosure, "")
+}
+
+// CloseWithReason sends a close message with code and reason, then closes the connection.
+func (c *Conn) CloseWithReason(code int, reason string) error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		// the close message is best effort, the peer might be gone.
+		_ = c.writeControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+		err = c.conn.Close()
+	})
+
+	return err
+}
+
+// Done returns a channel that is closed when the connection is closed.
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Read reads a message, returns the message type, like TextMessage or BinaryMessage.
+func (c *Conn) Read() (int, []byte, error) {
+	messageType, data, err := c.conn.ReadMessage()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	c.extendReadDeadline()
+	reportMessage(c.path, directionIn)
+	return messageType, data, nil
+}
+
+// ReadJson reads a message and unmarshals it into v.
+func (c *Conn) ReadJson(v interface{}) error {
+	_, data, err := c.Read()
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// Request returns the http request that upgraded to the connection.
+func (c *Conn) Request() *http.Request {
+	return c.r
+}
+
+// Write writes a message with the given message type, it's safe to call concurrently.
+func (c *Conn) Write(messageType int, data []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.conf.WriteTimeout)); err != nil {
+		return err
+	}
+
+	if err := c.conn.WriteMessage(messageType, data); err != nil {
+		return err
+	}
+
+	reportMessage(c.path, directionOut)
+	return nil
+}
+
+// WriteJson writes v as json in a text message.
+func (c *Conn) WriteJson(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return c.Write(TextMessage, data)
+}
+
+func (c *Conn) extendReadDeadline() {
+	_ = c.conn.SetReadDeadline(time.Now().Add(c.conf.ReadTimeout))
+}
+
+func (c *Conn) keepAlive() {
+	ticker := time.NewTicker(c.conf.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.writeControl(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *Conn) writeControl(messageType int, data []byte) error {
+	// WriteControl is safe to call concurrently with the other write methods,
+	// the lock is used to keep the order with messages.
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	return c.conn.WriteControl(messageType, data, time.Now().Add(c.conf.WriteTimeout))
+}
@@ -0,0 +1,221 @@
+package ws
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/lukebull/go-zero-extern/core/lang"
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/core/proc"
+	"github.com/lukebull/go-zero-extern/core/syncx"
+	"github.com/lukebull/go-zero-extern/core/timex"
+	"github.com/lukebull/go-zero-extern/rest/httpx"
+)
+
+const (
+	defaultReadTimeout    = time.Minute
+	defaultWriteTimeout   = time.Second * 10
+	defaultMaxMessageSize = 64 << 10
+	allOrigins            = "*"
+	shutdownReason        = "server shutting down"
+)
+
+type (
+	// A Conf is the websocket config.
+	Conf struct {
+		// MaxConns is the max concurrent websocket connections, no limit if 0.
+		MaxConns int `json:",default=10000"`
+		// ReadTimeout closes the connections that have no messages or pongs in time.
+		ReadTimeout  time.Duration `json:",default=60s"`
+		WriteTimeout time.Duration `json:",default=10s"`
+		// PingInterval should be less than ReadTimeout, defaults to ReadTimeout*9/10.
+		PingInterval   time.Duration `json:",optional"`
+		MaxMessageSize int64         `json:",default=65536"`
+		// AllowOrigins are the allowed origins, * means all origins, same origin only if empty.
+		AllowOrigins []string `json:",optional"`
+	}
+
+	// A Hub upgrades the requests to websocket connections, and tracks the connections
+	// to limit the concurrent connections and close them on shutdown.
+	Hub struct {
+		conf     Conf
+		upgrader websocket.Upgrader
+		limit    *syncx.Limit
+		lock     sync.Mutex
+		conns    map[*Conn]lang.PlaceholderType
+		closed   bool
+	}
+)
+
+// NewHub returns a Hub, the connections are closed with going away on draining the rest server
+// that serves the hub, or on process shutdown if the hub is served by the other servers.
+func NewHub(c Conf) *Hub {
+	c = c.withDefaults()
+	h := &Hub{
+		conf: c,
+		upgrader: websocket.Upgrader{
+			HandshakeTimeout: c.WriteTimeout,
+			CheckOrigin:      newOriginChecker(c.AllowOrigins),
+		},
+		conns: make(map[*Conn]lang.PlaceholderType),
+	}
+	if c.MaxConns > 0 {
+		limit := syncx.NewLimit(c.MaxConns)
+		h.limit = &limit
+	}
+	proc.AddShutdownListener(h.Close)
+
+	return h
+}
+
+// Close closes all the connections with going away, and rejects the new connections.
+func (h *Hub) Close() {
+	h.lock.Lock()
+	h.closed = true
+	conns := make([]*Conn, 0, len(h.conns))
+	for conn := range h.conns {
+		conns = append(conns, conn)
+	}
+	h.lock.Unlock()
+
+	for _, conn := range conns {
+		if err := conn.CloseWithReason(websocket.CloseGoingAway, shutdownReason); err != nil {
+			logx.Errorf("Error on closing websocket connection: %v", err)
+		}
+	}
+}
+
+// Count returns the number of the live connections.
+func (h *Hub) Count() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return len(h.conns)
+}
+
+// Handler returns a http.Handler that upgrades the requests and calls handle with the connections.
+// The path is used as the label of metrics.
+func (h *Hub) Handler(path string, handle Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.limit != nil {
+			if !h.limit.TryBorrow() {
+				logx.Errorf("concurrent websocket connections over %d, rejected with code %d",
+					h.conf.MaxConns, http.StatusServiceUnavailable)
+				reportRejected(path)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			defer func() {
+				if err := h.limit.Return(); err != nil {
+					logx.Error(err)
+				}
+			}()
+		}
+
+		if h.isClosed() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		// the upgrader writes the error responses.
+		conn, err := h.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		start := timex.Now()
+		wc := newConn(conn, r, path, h.conf)
+		if !h.add(wc) {
+			// closed during upgrading.
+			_ = wc.CloseWithReason(websocket.CloseGoingAway, shutdownReason)
+			return
+		}
+		defer func() {
+			h.remove(wc)
+			_ = wc.Close()
+			logx.WithContext(r.Context()).Infof("[WS] %s - %s - %s", r.RequestURI,
+				httpx.GetRemoteAddr(r), timex.ReprOfDuration(timex.Since(start)))
+		}()
+
+		handle(wc)
+	})
+}
+
+func (h *Hub) add(conn *Conn) bool {
+	h.lock.Lock()
+	if h.closed {
+		h.lock.Unlock()
+		return false
+	}
+	h.conns[conn] = lang.Placeholder
+	h.lock.Unlock()
+	reportConns(conn.path, 1)
+
+	return true
+}
+
+func (h *Hub) isClosed() bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.closed
+}
+
+func (h *Hub) remove(conn *Conn) {
+	h.lock.Lock()
+	delete(h.conns, conn)
+	h.lock.Unlock()
+	reportConns(conn.path, -1)
+}
+
+func (c Conf) withDefaults() Conf {
+	if c.ReadTimeout <= 0 {
+		c.ReadTimeout = defaultReadTimeout
+	}
+	if c.WriteTimeout <= 0 {
+		c.WriteTimeout = defaultWriteTimeout
+	}
+	if c.PingInterval <= 0 || c.PingInterval >= c.ReadTimeout {
+		c.PingInterval = c.ReadTimeout * 9 / 10
+	}
+	if c.MaxMessageSize <= 0 {
+		c.MaxMessageSize = defaultMaxMessageSize
+	}
+
+	return c
+}
+
+func newOriginChecker(origins []string) func(r *http.Request) bool {
+	if len(origins) == 0 {
+		// nil means same origin check by the upgrader.
+		return nil
+	}
+
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		if origin == allOrigins {
+			return func(r *http.Request) bool {
+				return true
+			}
+		}
+
+		allowed[strings.ToLower(origin)] = true
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if len(origin) == 0 {
+			return true
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+
+		return allowed[strings.ToLower(u.Scheme+"://"+u.Host)]
+	}
+}
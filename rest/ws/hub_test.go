@@ -0,0 +1,221 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func newTestServer(h *Hub, handle Handler) (*httptest.Server, string) {
+	server := httptest.NewServer(h.Handler("/ws", handle))
+	return server, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func echo(conn *Conn) {
+	for {
+		messageType, data, err := conn.Read()
+		if err != nil {
+			return
+		}
+
+		if err = conn.Write(messageType, data); err != nil {
+			return
+		}
+	}
+}
+
+func waitFor(t *testing.T, fn func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second * 5)
+	for !fn() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestHubEcho(t *testing.T) {
+	h := NewHub(Conf{})
+	server, url := newTestServer(h, func(conn *Conn) {
+		var msg map[string]string
+		if err := conn.ReadJson(&msg); err != nil {
+			return
+		}
+		msg["reply"] = "pong"
+		if err := conn.WriteJson(msg); err != nil {
+			return
+		}
+		echo(conn)
+	})
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err = client.WriteJSON(map[string]string{"msg": "ping"}); err != nil {
+		t.Fatal(err)
+	}
+	var reply map[string]string
+	if err = client.ReadJSON(&reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply["msg"] != "ping" || reply["reply"] != "pong" {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+
+	if err = client.WriteMessage(websocket.BinaryMessage, []byte{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	messageType, data, err := client.ReadMessage()
+	if err != nil || messageType != websocket.BinaryMessage || len(data) != 2 {
+		t.Fatalf("unexpected message %d %v %v", messageType, data, err)
+	}
+	if h.Count() != 1 {
+		t.Fatalf("expected 1 connection, got %d", h.Count())
+	}
+
+	client.Close()
+	waitFor(t, func() bool {
+		return h.Count() == 0
+	})
+}
+
+func TestHubMaxConns(t *testing.T) {
+	h := NewHub(Conf{MaxConns: 1})
+	server, url := newTestServer(h, echo)
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected rejected, got %v", err)
+	}
+
+	client.Close()
+	waitFor(t, func() bool {
+		return h.Count() == 0
+	})
+	client, _, err = websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("expected accepted after the connection closed, got %v", err)
+	}
+	client.Close()
+}
+
+func TestHubClose(t *testing.T) {
+	h := NewHub(Conf{})
+	server, url := newTestServer(h, echo)
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	waitFor(t, func() bool {
+		return h.Count() == 1
+	})
+
+	h.Close()
+	_, _, err = client.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Fatalf("expected going away, got %v", err)
+	}
+	waitFor(t, func() bool {
+		return h.Count() == 0
+	})
+
+	// the new connections are rejected after closing.
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected service unavailable after closing, got %v", err)
+	}
+}
+
+func TestHubKeepAlive(t *testing.T) {
+	h := NewHub(Conf{
+		ReadTimeout:  time.Millisecond * 200,
+		PingInterval: time.Millisecond * 50,
+	})
+	server, url := newTestServer(h, echo)
+	defer server.Close()
+
+	// the client answers the pings on reading, so the connection is kept alive.
+	client, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pings := make(chan struct{}, 10)
+	client.SetPingHandler(func(data string) error {
+		pings <- struct{}{}
+		return client.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	go func() {
+		for {
+			if _, _, err := client.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	time.Sleep(time.Millisecond * 500)
+	if h.Count() != 1 || len(pings) == 0 {
+		t.Fatalf("expected connection kept alive by pings, got %d connections, %d pings", h.Count(), len(pings))
+	}
+	client.Close()
+
+	// the idle client doesn't read, so no pongs, closed on read timeout.
+	idle, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idle.Close()
+	waitFor(t, func() bool {
+		return h.Count() == 0
+	})
+}
+
+func TestHubCheckOrigin(t *testing.T) {
+	h := NewHub(Conf{AllowOrigins: []string{"https://app.example.com"}})
+	server, url := newTestServer(h, echo)
+	defer server.Close()
+
+	header := http.Header{}
+	header.Set("Origin", "https://evil.com")
+	if _, _, err := websocket.DefaultDialer.Dial(url, header); err == nil {
+		t.Fatal("expected origin rejected")
+	}
+
+	header.Set("Origin", "https://APP.example.com")
+	client, _, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Close()
+
+	if !newOriginChecker([]string{"*"})(httptest.NewRequest(http.MethodGet, "/", nil)) {
+		t.Fatal("expected all origins allowed")
+	}
+	if newOriginChecker(nil) != nil {
+		t.Fatal("expected same origin check by default")
+	}
+}
+
+func TestConfWithDefaults(t *testing.T) {
+	c := Conf{ReadTimeout: time.Second * 10, PingInterval: time.Minute}.withDefaults()
+	if c.PingInterval != time.Second*9 || c.WriteTimeout != defaultWriteTimeout ||
+		c.MaxMessageSize != defaultMaxMessageSize {
+		t.Fatalf("unexpected conf %+v", c)
+	}
+}
@@ -0,0 +1,56 @@
+package ws
+
+import (
+	"github.com/lukebull/go-zero-extern/core/metric"
+	"github.com/lukebull/go-zero-extern/core/prometheus"
+)
+
+const (
+	serverNamespace = "http_server"
+	directionIn     = "in"
+	directionOut    = "out"
+)
+
+var (
+	metricConns = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: serverNamespace,
+		Subsystem: "websocket",
+		Name:      "connections",
+		Help:      "http server websocket connections.",
+		Labels:    []string{"path"},
+	})
+
+	metricRejected = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: serverNamespace,
+		Subsystem: "websocket",
+		Name:      "rejected_total",
+		Help:      "http server websocket connections rejected by max connections.",
+		Labels:    []string{"path"},
+	})
+
+	metricMessages = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: serverNamespace,
+		Subsystem: "websocket",
+		Name:      "messages_total",
+		Help:      "http server websocket messages count.",
+		Labels:    []string{"path", "direction"},
+	})
+)
+
+func reportConns(path string, delta float64) {
+	if prometheus.Enabled() {
+		metricConns.Add(delta, path)
+	}
+}
+
+func reportMessage(path, direction string) {
+	if prometheus.Enabled() {
+		metricMessages.Inc(path, direction)
+	}
+}
+
+func reportRejected(path string) {
+	if prometheus.Enabled() {
+		metricRejected.Inc(path)
+	}
+}
@@ -39,6 +39,37 @@ func {{.HandlerName}}(ctx *svc.ServiceContext) http.HandlerFunc {
 }
 `
 
+const sseHandlerTemplate = `package handler
+
+import (
+	"net/http"
+
+	{{.ImportPackages}}
+)
+
+func {{.HandlerName}}(ctx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		{{if .HasRequest}}var req types.{{.RequestType}}
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.Error(w, err)
+			return
+		}
+
+		{{end}}stream, err := httpx.SSE(w, r)
+		if err != nil {
+			httpx.Error(w, err)
+			return
+		}
+		defer stream.Close()
+
+		l := logic.New{{.LogicType}}(r.Context(), ctx)
+		if err := l.{{.Call}}({{if .HasRequest}}req, {{end}}stream); err != nil {
+			logx.WithContext(r.Context()).Error(err)
+		}
+	}
+}
+`
+
 type handlerInfo struct {
 	ImportPackages string
 	HandlerName    string
@@ -56,7 +87,7 @@ func genHandler(dir, rootPkg string, cfg *config.Config, group spec.Group, route
 	}
 
 	return doGenToFile(dir, handler, cfg, group, route, handlerInfo{
-		ImportPackages: genHandlerImports(group, route, rootPkg),
+		ImportPackages: genHandlerImports(group, route, rootPkg, isSSE(group)),
 		HandlerName:    handler,
 		RequestType:    util.Title(route.RequestTypeName()),
 		LogicType:      strings.Title(getLogicName(route)),
@@ -73,14 +104,19 @@ func doGenToFile(dir, handler string, cfg *config.Config, group spec.Group,
 		return err
 	}
 
+	templateFile, builtinTemplate := handlerTemplateFile, handlerTemplate
+	if isSSE(group) {
+		templateFile, builtinTemplate = sseHandlerTemplateFile, sseHandlerTemplate
+	}
+
 	return genFile(fileGenConfig{
 		dir:             dir,
 		subdir:          getHandlerFolderPath(group, route),
 		filename:        filename + ".go",
 		templateName:    "handlerTemplate",
 		category:        category,
-		templateFile:    handlerTemplateFile,
-		builtinTemplate: handlerTemplate,
+		templateFile:    templateFile,
+		builtinTemplate: builtinTemplate,
 		data:            handleObj,
 	})
 }
@@ -97,7 +133,7 @@ func genHandlers(dir, rootPkg string, cfg *config.Config, api *spec.ApiSpec) err
 	return nil
 }
 
-func genHandlerImports(group spec.Group, route spec.Route, parentPkg string) string {
+func genHandlerImports(group spec.Group, route spec.Route, parentPkg string, sse bool) string {
 	var imports []string
 	imports = append(imports, fmt.Sprintf("\"%s\"",
 		util.JoinPackages(parentPkg, getLogicFolderPath(group, route))))
@@ -105,6 +141,9 @@ func genHandlerImports(group spec.Group, route spec.Route, parentPkg string) str
 	if len(route.RequestTypeName()) > 0 {
 		imports = append(imports, fmt.Sprintf("\"%s\"\n", util.JoinPackages(parentPkg, typesDir)))
 	}
+	if sse {
+		imports = append(imports, fmt.Sprintf("\"%s/core/logx\"", vars.ProjectOpenSourceURL))
+	}
 	imports = append(imports, fmt.Sprintf("\"%s/rest/httpx\"", vars.ProjectOpenSourceURL))
 
 	return strings.Join(imports, "\n\t")
@@ -140,6 +179,11 @@ func getHandlerName(route spec.Route) string {
 	return handler + "Handler"
 }
 
+// isSSE checks if the routes of group are server-sent events, which are declared by sse: true.
+func isSSE(group spec.Group) bool {
+	return group.GetAnnotation(sseProperty) == "true"
+}
+
 func getLogicName(route spec.Route) string {
 	handler, err := getHandlerBaseName(route)
 	if err != nil {
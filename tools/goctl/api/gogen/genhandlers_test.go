@@ -0,0 +1,85 @@
+package gogen
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apiparser "github.com/lukebull/go-zero-extern/tools/goctl/api/parser"
+	"github.com/lukebull/go-zero-extern/tools/goctl/config"
+)
+
+const sseApi = `syntax = "v1"
+
+type Request {
+	Topic string ` + "`path:\"topic\"`" + `
+}
+
+type Message {
+	Text string ` + "`json:\"text\"`" + `
+}
+
+@server(
+	sse: true
+	group: events
+)
+service greet-api {
+	@handler Subscribe
+	get /events/:topic(Request) returns(Message)
+}
+`
+
+func TestGenSSEHandler(t *testing.T) {
+	api, err := apiparser.ParseContent(sseApi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.NewConfig(config.DefaultFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err = genHandlers(dir, "greet", cfg, api); err != nil {
+		t.Fatal(err)
+	}
+	if err = genLogic(dir, "greet", cfg, api); err != nil {
+		t.Fatal(err)
+	}
+	if err = genRoutes(dir, "greet", cfg, api); err != nil {
+		t.Fatal(err)
+	}
+
+	expects := map[string][]string{
+		filepath.Join(handlerDir, "events", "subscribehandler.go"): {
+			"stream, err := httpx.SSE(w, r)",
+			"defer stream.Close()",
+			"l.Subscribe(req, stream)",
+		},
+		filepath.Join(logicDir, "events", "subscribelogic.go"): {
+			"func (l *SubscribeLogic) Subscribe(req types.Request, stream *httpx.SSEStream) error {",
+		},
+		filepath.Join(handlerDir, "routes.go"): {
+			"rest.WithStreaming(),",
+		},
+	}
+	for file, contents := range expects {
+		data, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		code := string(data)
+		if _, err = parser.ParseFile(token.NewFileSet(), file, code, 0); err != nil {
+			t.Fatalf("invalid code in %s: %v\n%s", file, err, code)
+		}
+		for _, content := range contents {
+			if !strings.Contains(code, content) {
+				t.Fatalf("expected %q in %s:\n%s", content, file, code)
+			}
+		}
+	}
+}
@@ -58,11 +58,16 @@ func genLogicByRoute(dir, rootPkg string, cfg *config.Config, group spec.Group,
 		return err
 	}
 
-	imports := genLogicImports(route, rootPkg)
+	sse := isSSE(group)
+	imports := genLogicImports(route, rootPkg, sse)
 	var responseString string
 	var returnString string
 	var requestString string
-	if len(route.ResponseTypeName()) > 0 {
+	if sse {
+		// the responses are sent as events by stream.
+		responseString = "error"
+		returnString = "return nil"
+	} else if len(route.ResponseTypeName()) > 0 {
 		resp := responseGoTypeName(route, typesPacket)
 		responseString = "(" + resp + ", error)"
 		if strings.HasPrefix(resp, "*") {
@@ -77,6 +82,12 @@ func genLogicByRoute(dir, rootPkg string, cfg *config.Config, group spec.Group,
 	if len(route.RequestTypeName()) > 0 {
 		requestString = "req " + requestGoTypeName(route, typesPacket)
 	}
+	if sse {
+		if len(requestString) > 0 {
+			requestString += ", "
+		}
+		requestString += "stream *httpx.SSEStream"
+	}
 
 	return genFile(fileGenConfig{
 		dir:             dir,
@@ -110,7 +121,7 @@ func getLogicFolderPath(group spec.Group, route spec.Route) string {
 	return path.Join(logicDir, folder)
 }
 
-func genLogicImports(route spec.Route, parentPkg string) string {
+func genLogicImports(route spec.Route, parentPkg string, sse bool) string {
 	var imports []string
 	imports = append(imports, `"context"`+"\n")
 	imports = append(imports, fmt.Sprintf("\"%s\"", ctlutil.JoinPackages(parentPkg, contextDir)))
@@ -118,5 +129,8 @@ func genLogicImports(route spec.Route, parentPkg string) string {
 		imports = append(imports, fmt.Sprintf("\"%s\"\n", ctlutil.JoinPackages(parentPkg, typesDir)))
 	}
 	imports = append(imports, fmt.Sprintf("\"%s/core/logx\"", vars.ProjectOpenSourceURL))
+	if sse {
+		imports = append(imports, fmt.Sprintf("\"%s/rest/httpx\"", vars.ProjectOpenSourceURL))
+	}
 	return strings.Join(imports, "\n\t")
 }
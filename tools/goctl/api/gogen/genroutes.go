@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/lukebull/go-zero-extern/core/collection"
 	"github.com/lukebull/go-zero-extern/tools/goctl/api/spec"
@@ -17,12 +18,14 @@ import (
 )
 
 const (
-	routesFilename = "routes"
-	routesTemplate = `// Code generated by goctl. DO NOT EDIT.
+	routesFilename   = "routes"
+	timeoutThreshold = time.Millisecond
+	routesTemplate   = `// Code generated by goctl. DO NOT EDIT.
 package handler
 
 import (
-	"net/http"
+	"net/http"{{if .hasTimeout}}
+	"time"{{end}}
 
 	{{.importPackages}}
 )
@@ -33,7 +36,7 @@ func RegisterHandlers(engine *rest.Server, serverCtx *svc.ServiceContext) {
 `
 	routesAdditionTemplate = `
 	engine.AddRoutes(
-		{{.routes}} {{.jwt}}{{.signature}}
+		{{.routes}} {{.jwt}}{{.signature}}{{.prefix}}{{.timeout}}{{.streaming}}
 	)
 `
 )
@@ -52,8 +55,11 @@ type (
 		routes           []route
 		jwtEnabled       bool
 		signatureEnabled bool
+		sseEnabled       bool
 		authName         string
 		middlewares      []string
+		prefix           string
+		timeout          string
 	}
 	route struct {
 		method  string
@@ -69,6 +75,7 @@ func genRoutes(dir, rootPkg string, cfg *config.Config, api *spec.ApiSpec) error
 		return err
 	}
 
+	var hasTimeout bool
 	gt := template.Must(template.New("groupTemplate").Parse(routesAdditionTemplate))
 	for _, g := range groups {
 		var gbuilder strings.Builder
@@ -91,6 +98,29 @@ func genRoutes(dir, rootPkg string, cfg *config.Config, api *spec.ApiSpec) error
 		if g.signatureEnabled {
 			signature = "\n rest.WithSignature(serverCtx.Config.Signature),"
 		}
+		var streaming string
+		if g.sseEnabled {
+			streaming = "\n rest.WithStreaming(),"
+		}
+		var prefix string
+		if len(g.prefix) > 0 {
+			prefix = fmt.Sprintf("\n rest.WithPrefix(%q),", g.prefix)
+		}
+		var timeout string
+		if len(g.timeout) > 0 {
+			duration, err := time.ParseDuration(g.timeout)
+			if err != nil {
+				return err
+			}
+
+			// like timeout: 1, which means 1ns, not 1s.
+			if duration < timeoutThreshold {
+				return fmt.Errorf("timeout should not be less than %v, got %v", timeoutThreshold, duration)
+			}
+
+			timeout = fmt.Sprintf("\n rest.WithTimeout(%d * time.Millisecond),", duration/time.Millisecond)
+			hasTimeout = true
+		}
 
 		var routes string
 		if len(g.middlewares) > 0 {
@@ -111,6 +141,9 @@ func genRoutes(dir, rootPkg string, cfg *config.Config, api *spec.ApiSpec) error
 			"routes":    routes,
 			"jwt":       jwt,
 			"signature": signature,
+			"prefix":    prefix,
+			"timeout":   timeout,
+			"streaming": streaming,
 		}); err != nil {
 			return err
 		}
@@ -133,7 +166,8 @@ func genRoutes(dir, rootPkg string, cfg *config.Config, api *spec.ApiSpec) error
 		category:        "",
 		templateFile:    "",
 		builtinTemplate: routesTemplate,
-		data: map[string]string{
+		data: map[string]interface{}{
+			"hasTimeout":      hasTimeout,
 			"importPackages":  genRouteImports(rootPkg, api),
 			"routesAdditions": strings.TrimSpace(builder.String()),
 		},
@@ -201,6 +235,9 @@ func getRoutes(api *spec.ApiSpec) ([]group, error) {
 				groupedRoutes.middlewares = append(groupedRoutes.middlewares, item)
 			}
 		}
+		groupedRoutes.sseEnabled = isSSE(g)
+		groupedRoutes.prefix = g.GetAnnotation("prefix")
+		groupedRoutes.timeout = g.GetAnnotation("timeout")
 		routes = append(routes, groupedRoutes)
 	}
 
@@ -0,0 +1,98 @@
+package gogen
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lukebull/go-zero-extern/tools/goctl/api/parser"
+	"github.com/lukebull/go-zero-extern/tools/goctl/config"
+)
+
+const routesApi = `syntax = "v1"
+
+type Request {
+	Name string ` + "`path:\"name\"`" + `
+}
+
+@server(
+	prefix: /api/v1
+	timeout: 3s
+	group: user
+)
+service greet-api {
+	@handler GetUser
+	get /users/:name(Request)
+}
+
+service greet-api {
+	@handler Ping
+	get /ping
+}
+`
+
+func genTestRoutes(t *testing.T, content string) (string, error) {
+	t.Helper()
+	api, err := parser.ParseContent(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.NewConfig(config.DefaultFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err = genRoutes(dir, "greet", cfg, api); err != nil {
+		return "", err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, handlerDir, "routes.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data), nil
+}
+
+func TestGenRoutesWithPrefixAndTimeout(t *testing.T) {
+	code, err := genTestRoutes(t, routesApi)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expect := range []string{
+		`"time"`,
+		`rest.WithPrefix("/api/v1"),`,
+		`rest.WithTimeout(3000*time.Millisecond),`,
+	} {
+		if !strings.Contains(code, expect) {
+			t.Fatalf("expected %s in generated routes:\n%s", expect, code)
+		}
+	}
+	if strings.Count(code, "rest.WithPrefix") != 1 || strings.Count(code, "rest.WithTimeout") != 1 {
+		t.Fatalf("expected prefix and timeout only on the first group:\n%s", code)
+	}
+}
+
+func TestGenRoutesWithoutTimeout(t *testing.T) {
+	code, err := genTestRoutes(t, strings.Replace(routesApi, "\ttimeout: 3s\n", "", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(code, `"time"`) || strings.Contains(code, "rest.WithTimeout") {
+		t.Fatalf("unexpected timeout in generated routes:\n%s", code)
+	}
+}
+
+func TestGenRoutesBadTimeout(t *testing.T) {
+	for _, timeout := range []string{"3", "abc"} {
+		_, err := genTestRoutes(t, strings.Replace(routesApi, "timeout: 3s", "timeout: "+timeout, 1))
+		if err == nil {
+			t.Fatalf("expected error on timeout %s", timeout)
+		}
+	}
+}
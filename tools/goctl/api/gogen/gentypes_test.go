@@ -0,0 +1,47 @@
+package gogen
+
+import (
+	"strings"
+	"testing"
+
+	apiparser "github.com/lukebull/go-zero-extern/tools/goctl/api/parser"
+)
+
+const validationApi = `syntax = "v1"
+
+type Request {
+	Name  string ` + "`json:\"name,minlen=3,maxlen=20\"`" + `
+	Email string ` + "`json:\"email,optional,format=email\"`" + `
+	Tags  []string ` + "`json:\"tags,minitems=1\"`" + `
+	Min   int ` + "`form:\"min\"`" + `
+	Max   int ` + "`form:\"max,gtfield=Min\"`" + `
+}
+
+service greet-api {
+	@handler Greet
+	post /greet(Request)
+}
+`
+
+func TestBuildTypesWithValidationTags(t *testing.T) {
+	api, err := apiparser.ParseContent(validationApi)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := BuildTypes(api.Types)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tag := range []string{
+		"`json:\"name,minlen=3,maxlen=20\"`",
+		"`json:\"email,optional,format=email\"`",
+		"`json:\"tags,minitems=1\"`",
+		"`form:\"max,gtfield=Min\"`",
+	} {
+		if !strings.Contains(code, tag) {
+			t.Errorf("expected tag %s in:\n%s", tag, code)
+		}
+	}
+}
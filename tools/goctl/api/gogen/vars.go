@@ -10,4 +10,5 @@ const (
 	middlewareDir = interval + "middleware"
 	typesDir      = interval + typesPacket
 	groupProperty = "group"
+	sseProperty   = "sse"
 )
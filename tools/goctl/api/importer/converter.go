@@ -0,0 +1,403 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	swaggerRefPrefix = "#/definitions/"
+	openApiRefPrefix = "#/components/schemas/"
+	interfaceType    = "interface{}"
+	applicationJson  = "application/json"
+)
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+type (
+	apiType struct {
+		name   string
+		doc    string
+		fields []apiField
+	}
+
+	apiField struct {
+		name     string
+		typ      string
+		tag      string
+		comment  string
+		embedded bool
+	}
+
+	apiRoute struct {
+		method   string
+		path     string
+		handler  string
+		doc      string
+		request  string
+		response string
+	}
+
+	apiGroup struct {
+		jwt    string
+		group  string
+		routes []apiRoute
+	}
+
+	converter struct {
+		doc       *object
+		swagger   bool
+		schemas   *object
+		security  *object
+		types     []*apiType
+		typeNames map[string]bool
+		// refTypes maps the names of the schemas to the generated type names.
+		refTypes map[string]string
+		handlers map[string]bool
+		groups   []*apiGroup
+		prefix   string
+		warnings []string
+	}
+)
+
+func newConverter(doc *object) (*converter, error) {
+	c := &converter{
+		doc:       doc,
+		typeNames: make(map[string]bool),
+		refTypes:  make(map[string]string),
+		handlers:  make(map[string]bool),
+	}
+
+	switch {
+	case strings.HasPrefix(doc.string("swagger"), "2."):
+		c.swagger = true
+		c.schemas = doc.object("definitions")
+		c.security = doc.object("securityDefinitions")
+		if basePath := strings.TrimRight(doc.string("basePath"), "/"); len(basePath) > 0 {
+			c.prefix = basePath
+		}
+	case strings.HasPrefix(doc.string("openapi"), "3."):
+		components := doc.object("components")
+		c.schemas = components.object("schemas")
+		c.security = components.object("securitySchemes")
+		if servers := doc.slice("servers"); len(servers) > 0 {
+			if server, ok := servers[0].(*object); ok {
+				c.prefix = serverPrefix(server.string("url"))
+			}
+		}
+	default:
+		return nil, errors.New("only swagger 2.0 and openapi 3.x are supported")
+	}
+
+	if c.schemas == nil {
+		c.schemas = newObject()
+	}
+
+	return c, nil
+}
+
+func (c *converter) convert() error {
+	// reserve the names of the schemas first, so that the generated
+	// request and response types never take them.
+	for _, name := range c.schemas.keys {
+		if schema, ok := c.schemas.get(name).(*object); ok && isObjectSchema(c.resolve(schema)) {
+			c.refTypes[name] = c.uniqueTypeName(identifier(name))
+		}
+	}
+
+	for _, name := range c.schemas.keys {
+		typeName, ok := c.refTypes[name]
+		if !ok {
+			continue
+		}
+
+		if err := c.addType(typeName, c.resolve(c.schemas.object(name))); err != nil {
+			return fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+
+	paths := c.doc.object("paths")
+	if paths == nil {
+		return nil
+	}
+
+	for _, path := range paths.keys {
+		item := paths.object(path)
+		for _, method := range methods {
+			op := item.object(method)
+			if op == nil {
+				continue
+			}
+
+			if err := c.addRoute(path, method, item, op); err != nil {
+				return fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *converter) addType(name string, schema *object) error {
+	tp := &apiType{
+		name: name,
+		doc:  singleLine(schema.string("description")),
+	}
+	// register the type before the fields, so that the recursive references
+	// see that it's already generated.
+	c.types = append(c.types, tp)
+
+	fields, err := c.buildFields(name, schema, "json")
+	if err != nil {
+		return err
+	}
+
+	tp.fields = fields
+	return nil
+}
+
+func (c *converter) buildFields(typeName string, schema *object, tagKey string) ([]apiField, error) {
+	var fields []apiField
+	names := make(map[string]bool)
+	for _, sub := range schema.slice("allOf") {
+		item, ok := sub.(*object)
+		if !ok {
+			continue
+		}
+
+		if ref := item.string("$ref"); len(ref) > 0 && tagKey == "json" {
+			if name, err := c.refType(ref); err == nil && !names[name] {
+				names[name] = true
+				fields = append(fields, apiField{
+					name:     name,
+					typ:      name,
+					embedded: true,
+				})
+				continue
+			}
+		}
+
+		subFields, err := c.buildFields(typeName, c.resolve(item), tagKey)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, field := range subFields {
+			if !names[field.name] {
+				names[field.name] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	required := make(map[string]bool)
+	for _, name := range schema.strings("required") {
+		required[name] = true
+	}
+
+	properties := schema.object("properties")
+	if properties == nil {
+		return fields, nil
+	}
+
+	for _, key := range properties.keys {
+		prop, ok := properties.get(key).(*object)
+		if !ok {
+			continue
+		}
+
+		name := identifier(key)
+		for i := 1; names[name]; i++ {
+			name = identifier(key) + strconv.Itoa(i)
+		}
+		names[name] = true
+
+		typ, err := c.goType(prop, typeName+name)
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", key, err)
+		}
+
+		fields = append(fields, apiField{
+			name:    name,
+			typ:     typ,
+			tag:     buildTag(tagKey, key, c.resolve(prop), required[key]),
+			comment: singleLine(prop.string("description")),
+		})
+	}
+
+	return fields, nil
+}
+
+// goType returns the go type of the schema, inline objects are generated as types named by hint.
+func (c *converter) goType(schema *object, hint string) (string, error) {
+	if ref := schema.string("$ref"); len(ref) > 0 {
+		if name, err := c.refType(ref); err == nil {
+			return name, nil
+		} else if target := c.lookup(ref); target != nil {
+			return c.goType(target, identifier(refName(ref)))
+		} else {
+			return "", err
+		}
+	}
+
+	if schema.has("oneOf") || schema.has("anyOf") {
+		return interfaceType, nil
+	}
+
+	switch schema.string("type") {
+	case "string":
+		return "string", nil
+	case "integer":
+		if schema.string("format") == "int32" {
+			return "int32", nil
+		}
+		return "int64", nil
+	case "number":
+		if schema.string("format") == "float" {
+			return "float32", nil
+		}
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		items := schema.object("items")
+		if items == nil {
+			return "[]" + interfaceType, nil
+		}
+
+		typ, err := c.goType(items, hint+"Item")
+		if err != nil {
+			return "", err
+		}
+
+		return "[]" + typ, nil
+	case "object", "":
+		if isObjectSchema(schema) {
+			name := c.uniqueTypeName(hint)
+			if err := c.addType(name, schema); err != nil {
+				return "", err
+			}
+
+			return name, nil
+		}
+
+		switch additional := schema.get("additionalProperties").(type) {
+		case *object:
+			typ, err := c.goType(additional, hint+"Value")
+			if err != nil {
+				return "", err
+			}
+
+			return "map[string]" + typ, nil
+		case bool:
+			if additional {
+				return "map[string]" + interfaceType, nil
+			}
+		}
+
+		if schema.string("type") == "object" {
+			return "map[string]" + interfaceType, nil
+		}
+		if schema.has("enum") {
+			return "string", nil
+		}
+
+		return interfaceType, nil
+	default:
+		return "", fmt.Errorf("unsupported type %q", schema.string("type"))
+	}
+}
+
+// refType returns the type name of the referenced object schema.
+func (c *converter) refType(ref string) (string, error) {
+	if c.lookup(ref) == nil {
+		return "", fmt.Errorf("unresolved $ref %q", ref)
+	}
+
+	name, ok := c.refTypes[refName(ref)]
+	if !ok {
+		return "", fmt.Errorf("$ref %q is not an object", ref)
+	}
+
+	return name, nil
+}
+
+func (c *converter) lookup(ref string) *object {
+	prefix := openApiRefPrefix
+	if c.swagger {
+		prefix = swaggerRefPrefix
+	}
+	if !strings.HasPrefix(ref, prefix) {
+		return nil
+	}
+
+	return c.schemas.object(refName(ref))
+}
+
+// resolve follows the $ref chain of schema, returns schema if it's not a reference.
+func (c *converter) resolve(schema *object) *object {
+	seen := make(map[string]bool)
+	for schema != nil {
+		ref := schema.string("$ref")
+		if len(ref) == 0 || seen[ref] {
+			return schema
+		}
+
+		seen[ref] = true
+		target := c.lookup(ref)
+		if target == nil {
+			return schema
+		}
+
+		schema = target
+	}
+
+	return schema
+}
+
+func (c *converter) uniqueTypeName(name string) string {
+	if len(name) == 0 {
+		name = "Type"
+	}
+
+	result := name
+	for i := 1; c.typeNames[result]; i++ {
+		result = name + strconv.Itoa(i)
+	}
+	c.typeNames[result] = true
+
+	return result
+}
+
+func (c *converter) warn(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+func isObjectSchema(schema *object) bool {
+	if schema == nil {
+		return false
+	}
+
+	return schema.has("properties") || schema.has("allOf")
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+func serverPrefix(server string) string {
+	u, err := url.Parse(server)
+	if err != nil {
+		return ""
+	}
+
+	path := strings.TrimRight(u.Path, "/")
+	if strings.ContainsAny(path, "{}") {
+		return ""
+	}
+
+	return path
+}
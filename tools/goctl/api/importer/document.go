@@ -0,0 +1,228 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+// object is a json/yaml object that keeps the order of the keys,
+// so that the generated types and routes follow the order in the spec.
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newObject() *object {
+	return &object{
+		values: make(map[string]interface{}),
+	}
+}
+
+func (o *object) get(key string) interface{} {
+	if o == nil {
+		return nil
+	}
+
+	return o.values[key]
+}
+
+func (o *object) has(key string) bool {
+	if o == nil {
+		return false
+	}
+
+	_, ok := o.values[key]
+	return ok
+}
+
+func (o *object) object(key string) *object {
+	val, _ := o.get(key).(*object)
+	return val
+}
+
+func (o *object) set(key string, val interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = val
+}
+
+func (o *object) slice(key string) []interface{} {
+	val, _ := o.get(key).([]interface{})
+	return val
+}
+
+func (o *object) string(key string) string {
+	switch val := o.get(key).(type) {
+	case string:
+		return val
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func (o *object) strings(key string) []string {
+	var result []string
+	for _, item := range o.slice(key) {
+		if val, ok := item.(string); ok {
+			result = append(result, val)
+		}
+	}
+
+	return result
+}
+
+func loadDocument(content []byte) (*object, error) {
+	content = bytes.TrimSpace(content)
+	if len(content) == 0 {
+		return nil, errors.New("empty spec")
+	}
+
+	var val interface{}
+	var err error
+	if content[0] == '{' {
+		val, err = decodeJson(json.NewDecoder(bytes.NewReader(content)))
+	} else {
+		var ms yaml.MapSlice
+		if err = yaml.Unmarshal(content, &ms); err == nil {
+			val, err = fromYaml(ms)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	doc, ok := val.(*object)
+	if !ok {
+		return nil, errors.New("spec is not an object")
+	}
+
+	return doc, nil
+}
+
+func decodeJson(decoder *json.Decoder) (interface{}, error) {
+	decoder.UseNumber()
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeJsonValue(decoder, tok)
+}
+
+func decodeJsonValue(decoder *json.Decoder, tok json.Token) (interface{}, error) {
+	switch val := tok.(type) {
+	case json.Delim:
+		switch val {
+		case '{':
+			obj := newObject()
+			for decoder.More() {
+				keyTok, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+
+				key, ok := keyTok.(string)
+				if !ok {
+					return nil, fmt.Errorf("unexpected object key %v", keyTok)
+				}
+
+				value, err := decodeJson(decoder)
+				if err != nil {
+					return nil, err
+				}
+
+				obj.set(key, value)
+			}
+			if _, err := decoder.Token(); err != nil {
+				return nil, err
+			}
+
+			return obj, nil
+		case '[':
+			list := make([]interface{}, 0)
+			for decoder.More() {
+				value, err := decodeJson(decoder)
+				if err != nil {
+					return nil, err
+				}
+
+				list = append(list, value)
+			}
+			if _, err := decoder.Token(); err != nil {
+				return nil, err
+			}
+
+			return list, nil
+		default:
+			return nil, fmt.Errorf("unexpected delimiter %v", val)
+		}
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i, nil
+		}
+
+		return val.Float64()
+	default:
+		return val, nil
+	}
+}
+
+func fromYaml(val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case yaml.MapSlice:
+		obj := newObject()
+		for _, item := range v {
+			key, err := yamlKey(item.Key)
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := fromYaml(item.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			obj.set(key, value)
+		}
+
+		return obj, nil
+	case []interface{}:
+		list := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			value, err := fromYaml(item)
+			if err != nil {
+				return nil, err
+			}
+
+			list = append(list, value)
+		}
+
+		return list, nil
+	case int:
+		return int64(v), nil
+	default:
+		return v, nil
+	}
+}
+
+func yamlKey(key interface{}) (string, error) {
+	switch k := key.(type) {
+	case string:
+		return k, nil
+	case int:
+		// response codes are parsed as ints
+		return strconv.Itoa(k), nil
+	case bool:
+		return strconv.FormatBool(k), nil
+	default:
+		return "", fmt.Errorf("unsupported key %v", key)
+	}
+}
@@ -0,0 +1,216 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/lukebull/go-zero-extern/tools/goctl/api/format"
+	"github.com/lukebull/go-zero-extern/tools/goctl/api/parser"
+	"github.com/lukebull/go-zero-extern/tools/goctl/util"
+	"github.com/urfave/cli"
+)
+
+const defaultServiceName = "imported-api"
+
+var serviceNameRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ImportCommand converts the Swagger 2 or OpenAPI 3 spec into an api file.
+func ImportCommand(c *cli.Context) error {
+	return Generate(c.String("spec"), c.String("dir"), c.String("o"))
+}
+
+// Generate converts the spec file into an api file in dir.
+func Generate(specFile, dir, filename string) error {
+	if len(specFile) == 0 {
+		return errors.New("missing -spec")
+	}
+
+	if len(dir) == 0 {
+		return errors.New("missing -dir")
+	}
+
+	content, err := ioutil.ReadFile(specFile)
+	if err != nil {
+		return err
+	}
+
+	api, warnings, err := Import(content)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range warnings {
+		fmt.Println(aurora.Yellow("warning: " + warning))
+	}
+
+	if len(filename) == 0 {
+		base := filepath.Base(specFile)
+		filename = strings.TrimSuffix(base, filepath.Ext(base)) + ".api"
+	}
+
+	if err = util.MkdirIfNotExist(dir); err != nil {
+		return err
+	}
+
+	file := filepath.Join(dir, filename)
+	if util.FileExists(file) {
+		return fmt.Errorf("%s already exists", file)
+	}
+
+	if err = ioutil.WriteFile(file, []byte(api), os.ModePerm); err != nil {
+		return err
+	}
+
+	if err = format.ApiFormatByPath(file); err != nil {
+		return err
+	}
+
+	fmt.Println(aurora.Green("Done."))
+	return nil
+}
+
+// Import converts the Swagger 2 or OpenAPI 3 spec, json or yaml, into the content of an api file.
+// The returned warnings describe the parts of the spec that can't be expressed in api files.
+func Import(content []byte) (string, []string, error) {
+	doc, err := loadDocument(content)
+	if err != nil {
+		return "", nil, err
+	}
+
+	c, err := newConverter(doc)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err = c.convert(); err != nil {
+		return "", nil, err
+	}
+
+	api := c.write()
+	if _, err = parser.ParseContent(api); err != nil {
+		return "", nil, fmt.Errorf("invalid generated api: %w", err)
+	}
+
+	return api, c.warnings, nil
+}
+
+func (c *converter) write() string {
+	var builder strings.Builder
+	builder.WriteString("syntax = \"v1\"\n\n")
+	c.writeInfo(&builder)
+
+	for _, tp := range c.types {
+		if len(tp.doc) > 0 {
+			fmt.Fprintf(&builder, "// %s\n", tp.doc)
+		}
+		fmt.Fprintf(&builder, "type %s {\n", tp.name)
+		for _, field := range tp.fields {
+			builder.WriteString("\t")
+			if field.embedded {
+				builder.WriteString(field.typ)
+			} else {
+				fmt.Fprintf(&builder, "%s %s `%s`", field.name, field.typ, field.tag)
+			}
+			if len(field.comment) > 0 {
+				fmt.Fprintf(&builder, " // %s", field.comment)
+			}
+			builder.WriteString("\n")
+		}
+		builder.WriteString("}\n\n")
+	}
+
+	name := c.serviceName()
+	for _, group := range c.groups {
+		c.writeServer(&builder, group)
+		fmt.Fprintf(&builder, "service %s {\n", name)
+		for i, route := range group.routes {
+			if i > 0 {
+				builder.WriteString("\n")
+			}
+			if len(route.doc) > 0 {
+				fmt.Fprintf(&builder, "\t@doc \"%s\"\n", route.doc)
+			}
+			fmt.Fprintf(&builder, "\t@handler %s\n", route.handler)
+			fmt.Fprintf(&builder, "\t%s %s", route.method, route.path)
+			if len(route.request) > 0 {
+				fmt.Fprintf(&builder, " (%s)", route.request)
+			}
+			if len(route.response) > 0 {
+				fmt.Fprintf(&builder, " returns (%s)", route.response)
+			}
+			builder.WriteString("\n")
+		}
+		builder.WriteString("}\n\n")
+	}
+
+	return strings.TrimRight(builder.String(), "\n") + "\n"
+}
+
+func (c *converter) writeInfo(builder *strings.Builder) {
+	info := c.doc.object("info")
+	contact := info.object("contact")
+	var lines []string
+	for _, item := range []struct {
+		key   string
+		value string
+	}{
+		{"title", info.string("title")},
+		{"desc", info.string("description")},
+		{"author", contact.string("name")},
+		{"email", contact.string("email")},
+		{"version", info.string("version")},
+	} {
+		if value := singleLine(item.value); len(value) > 0 {
+			lines = append(lines, fmt.Sprintf("\t%s: \"%s\"\n", item.key, value))
+		}
+	}
+
+	if len(lines) == 0 {
+		return
+	}
+
+	builder.WriteString("info(\n")
+	builder.WriteString(strings.Join(lines, ""))
+	builder.WriteString(")\n\n")
+}
+
+func (c *converter) writeServer(builder *strings.Builder, group *apiGroup) {
+	var lines []string
+	if len(group.jwt) > 0 {
+		lines = append(lines, "\tjwt: "+group.jwt+"\n")
+	}
+	if len(group.group) > 0 {
+		lines = append(lines, "\tgroup: "+group.group+"\n")
+	}
+	if len(c.prefix) > 0 {
+		lines = append(lines, "\tprefix: "+c.prefix+"\n")
+	}
+
+	if len(lines) == 0 {
+		return
+	}
+
+	builder.WriteString("@server(\n")
+	builder.WriteString(strings.Join(lines, ""))
+	builder.WriteString(")\n")
+}
+
+func (c *converter) serviceName() string {
+	title := strings.ToLower(c.doc.object("info").string("title"))
+	name := strings.Trim(serviceNameRegex.ReplaceAllString(title, "-"), "-")
+	if len(name) == 0 {
+		return defaultServiceName
+	}
+
+	if !strings.HasSuffix(name, "api") {
+		name += "-api"
+	}
+
+	return name
+}
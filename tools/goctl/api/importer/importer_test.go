@@ -0,0 +1,278 @@
+package importer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lukebull/go-zero-extern/tools/goctl/api/openapigen"
+	"github.com/lukebull/go-zero-extern/tools/goctl/api/parser"
+	"github.com/lukebull/go-zero-extern/tools/goctl/api/spec"
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+func TestImportSwagger(t *testing.T) {
+	api, doc := importFile(t, "testdata/petstore.yaml")
+
+	if doc.Info.Title != "Pet Store" || doc.Info.Version != "1.0.0" || doc.Info.Contact.Email != "dev@example.com" {
+		t.Fatalf("unexpected info %+v", doc.Info)
+	}
+	if api.Service.Name != "pet-store-api" {
+		t.Fatalf("unexpected service name %s", api.Service.Name)
+	}
+
+	list := doc.Paths["/v1/pets"].Get
+	if list == nil || list.OperationId != "listPets" || list.Summary != "List pets" || len(list.Security) > 0 {
+		t.Fatalf("unexpected list operation %+v", list)
+	}
+	limit := findParam(t, list, "query", "limit")
+	if limit.Required || limit.Schema.Format != "int32" || limit.Schema.Default != int64(20) ||
+		*limit.Schema.Minimum != 1 || *limit.Schema.Maximum != 100 {
+		t.Fatalf("unexpected limit parameter %+v", limit.Schema)
+	}
+	if status := findParam(t, list, "query", "status"); len(status.Schema.Enum) != 2 {
+		t.Fatalf("unexpected status parameter %+v", status.Schema)
+	}
+	resp := list.Responses["200"].Content["application/json"].Schema
+	if resp.Type != "array" || resp.Items.Ref != schemaRefPrefix+"Pet" {
+		t.Fatalf("unexpected list response %+v", resp)
+	}
+
+	create := doc.Paths["/v1/pets"].Post
+	if create == nil || create.OperationId != "createPet" {
+		t.Fatalf("unexpected create operation %+v", create)
+	}
+	if _, ok := create.Security[0]["ApiKey"]; !ok {
+		t.Fatalf("unexpected security %v", create.Security)
+	}
+	if create.RequestBody.Content["application/json"].Schema.Ref != schemaRefPrefix+"NewPet" {
+		t.Fatal("the referenced body should be used as the request")
+	}
+
+	update := doc.Paths["/v1/pets/{petId}"].Put
+	if update == nil {
+		t.Fatalf("update operation not found in %v", doc.Paths)
+	}
+	if id := findParam(t, update, "path", "petId"); !id.Required || id.Schema.Format != "int64" {
+		t.Fatalf("path item parameters should be inherited, got %+v", id)
+	}
+	findParam(t, update, "header", "X-Request-Id")
+	if update.Responses["200"].Content["application/json"].Schema.Ref != schemaRefPrefix+"UpdatePetResp" {
+		t.Fatal("inline responses should be generated as types")
+	}
+	if del := doc.Paths["/v1/pets/{petId}"].Delete; del == nil || len(del.Responses["200"].Content) > 0 {
+		t.Fatalf("unexpected delete operation %+v", del)
+	}
+
+	newPet := doc.Components.Schemas["NewPet"]
+	if strings.Join(newPet.Required, ",") != "name" {
+		t.Fatalf("unexpected required %v", newPet.Required)
+	}
+	if name := newPet.Properties["name"]; *name.MinLength != 1 || *name.MaxLength != 64 ||
+		name.Description != "the name of the pet" {
+		t.Fatalf("unexpected name schema %+v", name)
+	}
+	if tag := newPet.Properties["tag"]; tag.Pattern != "^[a-z]+$" {
+		t.Fatalf("unexpected tag schema %+v", tag)
+	}
+
+	pet := doc.Components.Schemas["Pet"]
+	if pet.Description != "a pet in the store" {
+		t.Fatalf("unexpected description %q", pet.Description)
+	}
+	// allOf with a reference is embedded
+	if _, ok := pet.Properties["name"]; !ok {
+		t.Fatalf("allOf should be flattened, got %v", pet.Properties)
+	}
+	if owner := pet.Properties["owner"]; owner.Ref != schemaRefPrefix+"PetOwner" {
+		t.Fatalf("unexpected owner schema %+v", owner)
+	}
+	if labels := pet.Properties["labels"]; labels.AdditionalProperties.Type != "string" {
+		t.Fatalf("unexpected labels schema %+v", labels)
+	}
+	if weight := pet.Properties["weight"]; *weight.Minimum != 0 || !weight.ExclusiveMinimum || weight.Maximum != nil {
+		t.Fatalf("unexpected weight schema %+v", weight)
+	}
+}
+
+func TestImportOpenApi(t *testing.T) {
+	content, err := ioutil.ReadFile("testdata/users.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, warnings, err := Import(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "cookie") || !strings.Contains(warnings[1], "text/plain") {
+		t.Fatalf("unexpected warnings %v", warnings)
+	}
+
+	api, doc := importFile(t, "testdata/users.json")
+	if api.Service.Name != "user-api" {
+		t.Fatalf("unexpected service name %s", api.Service.Name)
+	}
+
+	get := doc.Paths["/api/v2/users/{user_id}"].Get
+	if get == nil || get.Summary != "Get the 'user'" || len(get.Tags) != 1 || get.Tags[0] != "user" {
+		t.Fatalf("unexpected get operation %+v", get)
+	}
+	if _, ok := get.Security[0]["BearerAuth"]; !ok {
+		t.Fatalf("global security should be applied, got %v", get.Security)
+	}
+	if id := findParam(t, get, "path", "user_id"); id.Schema.Type != "integer" {
+		t.Fatalf("referenced parameters should be resolved, got %+v", id.Schema)
+	}
+	if fields := findParam(t, get, "query", "fields"); fields.Schema.Type != "array" || *fields.Schema.MaxItems != 10 {
+		t.Fatalf("unexpected fields parameter %+v", fields.Schema)
+	}
+	if len(get.Parameters) != 2 {
+		t.Fatalf("cookie parameters should be ignored, got %v", get.Parameters)
+	}
+	if get.Responses["200"].Content["application/json"].Schema.Ref != schemaRefPrefix+"User" {
+		t.Fatal("referenced responses should be resolved")
+	}
+
+	create := doc.Paths["/api/v2/users"].Post
+	body := doc.Components.Schemas["CreateUserReq"]
+	if create == nil || body == nil || strings.Join(body.Required, ",") != "name,role" {
+		t.Fatalf("unexpected create body %+v", body)
+	}
+	if role := body.Properties["role"]; role.Type != "string" || len(role.Enum) != 2 {
+		t.Fatalf("enum references should be inlined, got %+v", role)
+	}
+	if age := body.Properties["age"]; *age.Minimum != 0 || *age.Maximum != 200 || !age.ExclusiveMaximum {
+		t.Fatalf("unexpected age schema %+v", age)
+	}
+	if create.Responses["200"].Content["application/json"].Schema.Ref != schemaRefPrefix+"User" {
+		t.Fatal("201 response should be used")
+	}
+
+	login := doc.Paths["/api/v2/login"].Post
+	if login == nil || len(login.Security) > 0 || login.RequestBody != nil {
+		t.Fatalf("unexpected login operation %+v", login)
+	}
+	findParam(t, login, "query", "username")
+
+	user := doc.Components.Schemas["User"]
+	if friends := user.Properties["friends"]; friends.Items.Ref != schemaRefPrefix+"User" {
+		t.Fatalf("unexpected friends schema %+v", friends)
+	}
+	if created := user.Properties["created-at"]; created.Format != "date-time" {
+		t.Fatalf("unexpected created-at schema %+v", created)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	tests := map[string]string{
+		"empty":       "",
+		"not object":  "[1, 2]",
+		"bad version": `{"swagger": "1.2", "paths": {}}`,
+		"bad json":    `{"openapi": "3.0.0",`,
+		"dangling ref": `{
+  "openapi": "3.0.0",
+  "info": {"title": "x", "version": "1"},
+  "paths": {
+    "/foo": {
+      "get": {
+        "responses": {
+          "200": {
+            "description": "ok",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Missing"}}}
+          }
+        }
+      }
+    }
+  }
+}`,
+	}
+
+	for name, content := range tests {
+		content := content
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := Import([]byte(content)); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "importer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err = Generate("testdata/petstore.yaml", dir, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(dir, "petstore.api")
+	if _, err = parser.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = Generate("testdata/petstore.yaml", dir, ""); err == nil {
+		t.Fatal("existing files should not be overwritten")
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"user_name":    "UserName",
+		"created-at":   "CreatedAt",
+		"X-Request-Id": "XRequestId",
+		"userId":       "UserId",
+		"1st":          "X1st",
+		"a.b c":        "ABC",
+		"":             "",
+	}
+
+	for input, expect := range tests {
+		if actual := identifier(input); actual != expect {
+			t.Errorf("identifier(%q): expected %q, got %q", input, expect, actual)
+		}
+	}
+}
+
+// importFile imports the spec, and builds the OpenAPI document back from the api file.
+func importFile(t *testing.T, file string) (*spec.ApiSpec, *openapigen.Document) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, _, err := Import(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api, err := parser.ParseContent(result)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, result)
+	}
+
+	doc, err := openapigen.BuildDocument(api)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, result)
+	}
+
+	return api, doc
+}
+
+func findParam(t *testing.T, op *openapigen.Operation, in, name string) *openapigen.Parameter {
+	t.Helper()
+	for _, param := range op.Parameters {
+		if param.In == in && param.Name == name {
+			return param
+		}
+	}
+
+	t.Fatalf("%s parameter %s not found", in, name)
+	return nil
+}
@@ -0,0 +1,393 @@
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lukebull/go-zero-extern/core/stringx"
+)
+
+const (
+	formUrlencoded = "application/x-www-form-urlencoded"
+	multipartForm  = "multipart/form-data"
+)
+
+var paramTagKeys = map[string]string{
+	"path":     "path",
+	"query":    "form",
+	"header":   "header",
+	"formData": "form",
+}
+
+type body struct {
+	schema *object
+	tagKey string
+}
+
+func (c *converter) addRoute(path, method string, item, op *object) error {
+	handler := c.handlerName(method, path, op.string("operationId"))
+	route := apiRoute{
+		method:  method,
+		path:    routePath(path),
+		handler: handler,
+		doc:     singleLine(strings.TrimSpace(stringx.TakeOne(op.string("summary"), op.string("description")))),
+	}
+
+	request, err := c.buildRequest(handler, item, op)
+	if err != nil {
+		return err
+	}
+	route.request = request
+
+	response, err := c.buildResponse(handler, op)
+	if err != nil {
+		return err
+	}
+	route.response = response
+
+	var group string
+	if tags := op.strings("tags"); len(tags) > 0 {
+		group = lowerFirst(identifier(tags[0]))
+	}
+	c.addToGroup(c.jwtOf(op), group, route)
+
+	return nil
+}
+
+func (c *converter) addToGroup(jwt, group string, route apiRoute) {
+	for _, g := range c.groups {
+		if g.jwt == jwt && g.group == group {
+			g.routes = append(g.routes, route)
+			return
+		}
+	}
+
+	c.groups = append(c.groups, &apiGroup{
+		jwt:    jwt,
+		group:  group,
+		routes: []apiRoute{route},
+	})
+}
+
+func (c *converter) buildRequest(handler string, item, op *object) (string, error) {
+	params, bd := c.collectParams(item, op)
+
+	if bd == nil && len(params) == 0 {
+		return "", nil
+	}
+
+	// use the referenced type directly if the body is all of the request.
+	if bd != nil && len(params) == 0 && bd.tagKey == "json" {
+		if ref := bd.schema.string("$ref"); len(ref) > 0 {
+			if name, err := c.refType(ref); err == nil {
+				return name, nil
+			}
+		}
+	}
+
+	name := c.uniqueTypeName(identifier(handler) + "Req")
+	tp := &apiType{name: name}
+	c.types = append(c.types, tp)
+
+	names := make(map[string]bool)
+	for _, param := range params {
+		field, err := c.paramField(name, param)
+		if err != nil {
+			return "", err
+		}
+
+		for i := 1; names[field.name]; i++ {
+			field.name = identifier(param.string("name")) + strconv.Itoa(i)
+		}
+		names[field.name] = true
+		tp.fields = append(tp.fields, field)
+	}
+
+	if bd != nil {
+		fields, err := c.bodyFields(name, bd)
+		if err != nil {
+			return "", err
+		}
+
+		for _, field := range fields {
+			if names[field.name] {
+				c.warn("%s: body field %s conflicts with a parameter, ignored", handler, field.name)
+				continue
+			}
+
+			names[field.name] = true
+			tp.fields = append(tp.fields, field)
+		}
+	}
+
+	return name, nil
+}
+
+func (c *converter) collectParams(item, op *object) ([]*object, *body) {
+	var params []*object
+	index := make(map[string]int)
+	var bd *body
+	for _, list := range [][]interface{}{item.slice("parameters"), op.slice("parameters")} {
+		for _, val := range list {
+			param, ok := val.(*object)
+			if !ok {
+				continue
+			}
+
+			param = c.resolvePointer(param)
+			in := param.string("in")
+			if in == "body" {
+				bd = &body{
+					schema: param.object("schema"),
+					tagKey: "json",
+				}
+				continue
+			}
+
+			if _, ok := paramTagKeys[in]; !ok {
+				c.warn("%s parameter %s is not supported, ignored", in, param.string("name"))
+				continue
+			}
+
+			// operation parameters override the path item ones.
+			key := in + ":" + param.string("name")
+			if i, ok := index[key]; ok {
+				params[i] = param
+			} else {
+				index[key] = len(params)
+				params = append(params, param)
+			}
+		}
+	}
+
+	if requestBody := op.object("requestBody"); requestBody != nil {
+		content := c.resolvePointer(requestBody).object("content")
+		if bd = c.bodyOf(content); bd == nil && content != nil {
+			c.warn("request body of %s is not supported, ignored", strings.Join(content.keys, ","))
+		}
+	}
+
+	if bd != nil && bd.schema == nil {
+		bd = nil
+	}
+
+	return params, bd
+}
+
+func (c *converter) bodyOf(content *object) *body {
+	if content == nil {
+		return nil
+	}
+
+	if media := content.object(applicationJson); media != nil {
+		return &body{schema: media.object("schema"), tagKey: "json"}
+	}
+
+	for _, key := range content.keys {
+		if strings.HasSuffix(key, "+json") || strings.HasSuffix(key, "/json") {
+			return &body{schema: content.object(key).object("schema"), tagKey: "json"}
+		}
+	}
+
+	for _, key := range []string{formUrlencoded, multipartForm} {
+		if media := content.object(key); media != nil {
+			return &body{schema: media.object("schema"), tagKey: "form"}
+		}
+	}
+
+	return nil
+}
+
+func (c *converter) bodyFields(typeName string, bd *body) ([]apiField, error) {
+	schema := bd.schema
+	if ref := schema.string("$ref"); len(ref) > 0 && bd.tagKey == "json" {
+		if name, err := c.refType(ref); err == nil {
+			return []apiField{
+				{
+					name:     name,
+					typ:      name,
+					embedded: true,
+				},
+			}, nil
+		}
+	}
+
+	resolved := c.resolve(schema)
+	if !isObjectSchema(resolved) {
+		c.warn("%s: only object request bodies are supported, body ignored", typeName)
+		return nil, nil
+	}
+
+	return c.buildFields(typeName, resolved, bd.tagKey)
+}
+
+func (c *converter) paramField(typeName string, param *object) (apiField, error) {
+	name := param.string("name")
+	in := param.string("in")
+	// swagger 2 declares the types on the parameters, openapi 3 in the schemas.
+	schema := param.object("schema")
+	if schema == nil {
+		schema = param
+	}
+
+	fieldName := identifier(name)
+	typ, err := c.goType(schema, typeName+fieldName)
+	if err != nil {
+		return apiField{}, fmt.Errorf("parameter %s: %w", name, err)
+	}
+
+	required := in == "path" || param.get("required") == true
+	return apiField{
+		name:    fieldName,
+		typ:     typ,
+		tag:     buildTag(paramTagKeys[in], name, c.resolve(schema), required),
+		comment: singleLine(param.string("description")),
+	}, nil
+}
+
+func (c *converter) buildResponse(handler string, op *object) (string, error) {
+	responses := op.object("responses")
+	if responses == nil {
+		return "", nil
+	}
+
+	var codes []string
+	for _, code := range responses.keys {
+		if len(code) == 3 && code[0] == '2' {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	if len(codes) == 0 {
+		return "", nil
+	}
+
+	resp, ok := responses.get(codes[0]).(*object)
+	if !ok {
+		return "", nil
+	}
+
+	resp = c.resolvePointer(resp)
+	schema := resp.object("schema")
+	if !c.swagger {
+		schema = nil
+		content := resp.object("content")
+		if bd := c.bodyOf(content); bd != nil && bd.tagKey == "json" {
+			schema = bd.schema
+		} else if content != nil {
+			c.warn("%s: response of %s is not supported, ignored", handler, strings.Join(content.keys, ","))
+		}
+	}
+	if schema == nil {
+		return "", nil
+	}
+
+	typ, err := c.goType(schema, identifier(handler)+"Resp")
+	if err != nil {
+		return "", fmt.Errorf("response: %w", err)
+	}
+
+	if !c.typeNames[strings.TrimPrefix(typ, "[]")] {
+		c.warn("%s: response type %s is not supported, ignored", handler, typ)
+		return "", nil
+	}
+
+	return typ, nil
+}
+
+// jwtOf returns the security scheme name that the operation requires, empty if not required.
+func (c *converter) jwtOf(op *object) string {
+	requirements := c.doc.slice("security")
+	if op.has("security") {
+		requirements = op.slice("security")
+	}
+
+	var jwt string
+	for _, val := range requirements {
+		requirement, ok := val.(*object)
+		if !ok {
+			continue
+		}
+
+		// an empty requirement means that the authentication is optional.
+		if len(requirement.keys) == 0 {
+			return ""
+		}
+
+		for _, name := range requirement.keys {
+			if len(jwt) == 0 && isTokenScheme(c.security.object(name)) {
+				jwt = identifier(name)
+			}
+		}
+	}
+
+	return jwt
+}
+
+func (c *converter) handlerName(method, path, operationId string) string {
+	name := lowerFirst(identifier(operationId))
+	if len(name) == 0 {
+		name = method + identifier(strings.NewReplacer("{", "", "}", "").Replace(path))
+	}
+
+	result := name
+	for i := 1; c.handlers[result]; i++ {
+		result = name + strconv.Itoa(i)
+	}
+	c.handlers[result] = true
+
+	return result
+}
+
+// resolvePointer follows the local $ref of parameters, request bodies and responses.
+func (c *converter) resolvePointer(obj *object) *object {
+	for i := 0; i < 10 && obj != nil; i++ {
+		ref := obj.string("$ref")
+		if !strings.HasPrefix(ref, "#/") {
+			return obj
+		}
+
+		target := c.doc
+		for _, segment := range strings.Split(ref[2:], "/") {
+			segment = strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)
+			target = target.object(segment)
+		}
+		if target == nil {
+			c.warn("unresolved $ref %q", ref)
+			return obj
+		}
+
+		obj = target
+	}
+
+	return obj
+}
+
+func isTokenScheme(scheme *object) bool {
+	if scheme == nil {
+		return false
+	}
+
+	switch scheme.string("type") {
+	case "http":
+		return strings.EqualFold(scheme.string("scheme"), "bearer")
+	case "apiKey", "oauth2", "openIdConnect":
+		return true
+	default:
+		return false
+	}
+}
+
+// routePath converts /users/{id} into /users/:id.
+func routePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[i] = ":" + segment[1:len(segment)-1]
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
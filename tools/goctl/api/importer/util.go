@@ -0,0 +1,194 @@
+package importer
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var formats = map[string]string{
+	"email":     "email",
+	"uri":       "url",
+	"url":       "url",
+	"uuid":      "uuid",
+	"ipv4":      "ipv4",
+	"ipv6":      "ipv6",
+	"date":      "date",
+	"date-time": "datetime",
+}
+
+// identifier converts s into an exported go identifier, like user_name to UserName.
+func identifier(s string) string {
+	var builder strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) || r > unicode.MaxASCII {
+			upper = true
+			continue
+		}
+
+		if builder.Len() == 0 && unicode.IsDigit(r) {
+			builder.WriteByte('X')
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
+
+func lowerFirst(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// singleLine makes s fit in a line comment or a quoted string.
+func singleLine(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.ReplaceAll(s, `"`, "'")
+}
+
+// buildTag builds the struct tag of the field, with the validation options that can be expressed.
+func buildTag(key, name string, schema *object, required bool) string {
+	opts := []string{name}
+	if !required {
+		opts = append(opts, "optional")
+	}
+
+	if val, ok := scalar(schema.get("default")); ok {
+		opts = append(opts, "default="+val)
+	}
+
+	if enum := schema.slice("enum"); len(enum) > 0 {
+		var vals []string
+		for _, item := range enum {
+			val, ok := scalar(item)
+			if !ok || len(val) == 0 || strings.Contains(val, "|") {
+				vals = nil
+				break
+			}
+			vals = append(vals, val)
+		}
+		if len(vals) > 0 {
+			opts = append(opts, "options="+strings.Join(vals, "|"))
+		}
+	}
+
+	if rng := numberRange(schema); len(rng) > 0 {
+		opts = append(opts, "range="+rng)
+	}
+
+	for _, item := range []struct {
+		key    string
+		option string
+	}{
+		{"minLength", "minlen"},
+		{"maxLength", "maxlen"},
+		{"minItems", "minitems"},
+		{"maxItems", "maxitems"},
+	} {
+		if val, ok := schema.get(item.key).(int64); ok {
+			opts = append(opts, fmt.Sprintf("%s=%d", item.option, val))
+		}
+	}
+
+	if format, ok := formats[schema.string("format")]; ok && schema.string("type") == "string" {
+		opts = append(opts, "format="+format)
+	}
+
+	if pattern := schema.string("pattern"); len(pattern) > 0 && isSafe(pattern) {
+		if _, err := regexp.Compile(pattern); err == nil {
+			opts = append(opts, "pattern="+pattern)
+		}
+	}
+
+	return fmt.Sprintf(`%s:"%s"`, key, strings.Join(opts, ","))
+}
+
+func numberRange(schema *object) string {
+	min, hasMin := number(schema.get("minimum"))
+	max, hasMax := number(schema.get("maximum"))
+	left, right := "[", "]"
+	// exclusiveMinimum and exclusiveMaximum are booleans before openapi 3.1, numbers since.
+	switch val := schema.get("exclusiveMinimum").(type) {
+	case bool:
+		if val {
+			left = "("
+		}
+	default:
+		if n, ok := number(val); ok {
+			min, hasMin, left = n, true, "("
+		}
+	}
+	switch val := schema.get("exclusiveMaximum").(type) {
+	case bool:
+		if val {
+			right = ")"
+		}
+	default:
+		if n, ok := number(val); ok {
+			max, hasMax, right = n, true, ")"
+		}
+	}
+
+	if !hasMin && !hasMax {
+		return ""
+	}
+
+	var lower, upper string
+	if hasMin {
+		lower = formatNumber(min)
+	}
+	if hasMax {
+		upper = formatNumber(max)
+	}
+
+	return fmt.Sprintf("%s%s:%s%s", left, lower, upper, right)
+}
+
+func number(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+	default:
+		return 0, false
+	}
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func scalar(val interface{}) (string, bool) {
+	var s string
+	switch v := val.(type) {
+	case string:
+		s = v
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float64:
+		s = formatNumber(v)
+	case bool:
+		s = strconv.FormatBool(v)
+	default:
+		return "", false
+	}
+
+	return s, isSafe(s)
+}
+
+// isSafe checks if s can be written as an option value in the struct tag.
+func isSafe(s string) bool {
+	return !strings.ContainsAny(s, ",\"`\\ \t\r\n")
+}
@@ -0,0 +1,668 @@
+package openapigen
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lukebull/go-zero-extern/core/stringx"
+	"github.com/lukebull/go-zero-extern/tools/goctl/api/spec"
+)
+
+const (
+	jsonTagKey   = "json"
+	formTagKey   = "form"
+	pathTagKey   = "path"
+	headerTagKey = "header"
+
+	inPath   = "path"
+	inQuery  = "query"
+	inHeader = "header"
+
+	jwtProperty    = "jwt"
+	groupProperty  = "group"
+	prefixProperty = "prefix"
+
+	applicationJson    = "application/json"
+	schemaRefPrefix    = "#/components/schemas/"
+	defaultApiTitle    = "api"
+	defaultApiVersion  = "1.0"
+	optionalOption     = "optional"
+	omitEmptyOption    = "omitempty"
+	defaultOption      = "default"
+	optionsOption      = "options"
+	rangeOption        = "range"
+	minLenOption       = "minlen"
+	maxLenOption       = "maxlen"
+	patternOption      = "pattern"
+	formatOption       = "format"
+	minItemsOption     = "minitems"
+	maxItemsOption     = "maxitems"
+	optionSeparator    = "|"
+	rangeSeparator     = ":"
+	equalToken         = "="
+	ignoredPropertyTag = "-"
+)
+
+var formats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uuid":     "uuid",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"date":     "date",
+	"datetime": "date-time",
+}
+
+type (
+	builder struct {
+		api   *spec.ApiSpec
+		types map[string]spec.DefineStruct
+		doc   *Document
+	}
+
+	field struct {
+		name     string
+		in       string
+		member   spec.Member
+		options  []string
+		required bool
+	}
+)
+
+// BuildDocument converts the api spec into an OpenAPI 3 document.
+func BuildDocument(api *spec.ApiSpec) (*Document, error) {
+	b := &builder{
+		api:   api,
+		types: make(map[string]spec.DefineStruct),
+		doc: &Document{
+			OpenApi: openApiVersion,
+			Info:    buildInfo(api),
+			Paths:   make(map[string]*PathItem),
+			Components: Components{
+				Schemas: make(map[string]*Schema),
+			},
+		},
+	}
+	for _, tp := range api.Types {
+		if ds, ok := tp.(spec.DefineStruct); ok {
+			b.types[ds.Name()] = ds
+		}
+	}
+
+	if err := b.buildSchemas(); err != nil {
+		return nil, err
+	}
+
+	if err := b.buildPaths(); err != nil {
+		return nil, err
+	}
+
+	return b.doc, nil
+}
+
+func (b *builder) addOperation(method, path string, op *Operation) error {
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = new(PathItem)
+		b.doc.Paths[path] = item
+	}
+
+	var target **Operation
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		target = &item.Get
+	case http.MethodPut:
+		target = &item.Put
+	case http.MethodPost:
+		target = &item.Post
+	case http.MethodDelete:
+		target = &item.Delete
+	case http.MethodOptions:
+		target = &item.Options
+	case http.MethodHead:
+		target = &item.Head
+	case http.MethodPatch:
+		target = &item.Patch
+	default:
+		return fmt.Errorf("unsupported method %q on %s", method, path)
+	}
+
+	if *target != nil {
+		return fmt.Errorf("duplicate route %s %s", method, path)
+	}
+
+	*target = op
+	return nil
+}
+
+func (b *builder) buildOperation(group spec.Group, route spec.Route) (*Operation, error) {
+	op := &Operation{
+		Summary:     unquote(route.JoinedDoc()),
+		Description: strings.TrimSpace(strings.Join(route.HandlerDoc, " ")),
+		OperationId: route.Handler,
+		Responses: map[string]*Response{
+			strconv.Itoa(http.StatusOK): {
+				Description: http.StatusText(http.StatusOK),
+			},
+		},
+	}
+	if desc, ok := route.AtDoc.Properties["description"]; ok {
+		op.Description = unquote(desc)
+	}
+	tag := stringx.TakeOne(route.GetAnnotation(groupProperty), group.GetAnnotation(groupProperty))
+	if len(tag) > 0 {
+		op.Tags = []string{tag}
+	}
+
+	jwt := stringx.TakeOne(route.GetAnnotation(jwtProperty), group.GetAnnotation(jwtProperty))
+	if len(jwt) > 0 {
+		b.addSecurityScheme(jwt)
+		op.Security = []map[string][]string{
+			{jwt: {}},
+		}
+	}
+
+	if err := b.fillRequest(op, route); err != nil {
+		return nil, err
+	}
+
+	if route.ResponseType != nil && len(route.ResponseType.Name()) > 0 {
+		schema, err := b.typeSchema(route.ResponseType)
+		if err != nil {
+			return nil, err
+		}
+
+		op.Responses[strconv.Itoa(http.StatusOK)].Content = map[string]*MediaType{
+			applicationJson: {Schema: schema},
+		}
+	}
+
+	return op, nil
+}
+
+func (b *builder) addSecurityScheme(name string) {
+	if b.doc.Components.SecuritySchemes == nil {
+		b.doc.Components.SecuritySchemes = make(map[string]*SecurityScheme)
+	}
+
+	b.doc.Components.SecuritySchemes[name] = &SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+	}
+}
+
+func (b *builder) buildPaths() error {
+	tags := make(map[string]struct{})
+	for _, group := range b.api.Service.Groups {
+		prefix := strings.TrimSuffix(unquote(group.GetAnnotation(prefixProperty)), "/")
+		for _, route := range group.Routes {
+			op, err := b.buildOperation(group, route)
+			if err != nil {
+				return err
+			}
+
+			for _, tag := range op.Tags {
+				tags[tag] = struct{}{}
+			}
+
+			path, params := convertPath(prefix + route.Path)
+			if err = b.fillPathParameters(op, params); err != nil {
+				return err
+			}
+
+			if err = b.addOperation(route.Method, path, op); err != nil {
+				return err
+			}
+		}
+	}
+
+	names := make([]string, 0, len(tags))
+	for tag := range tags {
+		names = append(names, tag)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.doc.Tags = append(b.doc.Tags, Tag{Name: name})
+	}
+
+	return nil
+}
+
+func (b *builder) buildSchemas() error {
+	for name, tp := range b.types {
+		schema, err := b.structSchema(tp, jsonTagKey)
+		if err != nil {
+			return err
+		}
+
+		b.doc.Components.Schemas[name] = schema
+	}
+
+	return nil
+}
+
+// collectFields collects the fields with the given tag keys, including the ones in anonymous members.
+func (b *builder) collectFields(tp spec.DefineStruct, keys ...string) ([]field, error) {
+	var fields []field
+	for _, member := range tp.Members {
+		if member.IsInline {
+			inline, ok := b.types[member.Type.Name()]
+			if !ok {
+				return nil, fmt.Errorf("type %s not defined", member.Type.Name())
+			}
+
+			inlineFields, err := b.collectFields(inline, keys...)
+			if err != nil {
+				return nil, err
+			}
+
+			fields = append(fields, inlineFields...)
+			continue
+		}
+
+		tags, err := spec.Parse(member.Tag)
+		if err != nil {
+			return nil, fmt.Errorf("type %s field %s: %v", tp.Name(), member.Name, err)
+		}
+
+		for _, key := range keys {
+			tag, err := tags.Get(key)
+			if err != nil || tag.Name == ignoredPropertyTag {
+				continue
+			}
+
+			fields = append(fields, field{
+				name:     stringx.TakeOne(tag.Name, member.Name),
+				in:       key,
+				member:   member,
+				options:  tag.Options,
+				required: isRequired(tag.Options),
+			})
+			break
+		}
+	}
+
+	return fields, nil
+}
+
+func (b *builder) fieldSchema(f field) (*Schema, error) {
+	schema, err := b.typeSchema(f.member.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(schema.Ref) > 0 {
+		return schema, nil
+	}
+
+	schema.Description = memberDescription(f.member)
+	if err = applyOptions(schema, f.options); err != nil {
+		return nil, fmt.Errorf("field %s: %v", f.member.Name, err)
+	}
+
+	return schema, nil
+}
+
+func (b *builder) fillPathParameters(op *Operation, params []string) error {
+	declared := make(map[string]bool)
+	for _, param := range op.Parameters {
+		if param.In == inPath {
+			declared[param.Name] = true
+		}
+	}
+
+	var parameters []*Parameter
+	for _, name := range params {
+		if declared[name] {
+			continue
+		}
+
+		parameters = append(parameters, &Parameter{
+			Name:     name,
+			In:       inPath,
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	// path parameters come first, like the order in the path.
+	op.Parameters = append(parameters, op.Parameters...)
+	for _, param := range op.Parameters {
+		if param.In == inPath && !stringx.Contains(params, param.Name) {
+			return fmt.Errorf("path parameter %s of %s not in path", param.Name, op.OperationId)
+		}
+	}
+
+	return nil
+}
+
+func (b *builder) fillRequest(op *Operation, route spec.Route) error {
+	if route.RequestType == nil || len(route.RequestType.Name()) == 0 {
+		return nil
+	}
+
+	tp, ok := b.types[route.RequestType.Name()]
+	if !ok {
+		return fmt.Errorf("type %s not defined", route.RequestType.Name())
+	}
+
+	fields, err := b.collectFields(tp, pathTagKey, formTagKey, headerTagKey)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		schema, err := b.fieldSchema(f)
+		if err != nil {
+			return err
+		}
+
+		param := &Parameter{
+			Name:        f.name,
+			Description: schema.Description,
+			Required:    f.required,
+			Schema:      schema,
+		}
+		switch f.in {
+		case pathTagKey:
+			param.In = inPath
+			param.Required = true
+		case formTagKey:
+			param.In = inQuery
+		case headerTagKey:
+			param.In = inHeader
+		}
+		op.Parameters = append(op.Parameters, param)
+	}
+
+	bodyFields, err := b.collectFields(tp, jsonTagKey)
+	if err != nil {
+		return err
+	}
+
+	if len(bodyFields) == 0 {
+		return nil
+	}
+
+	op.RequestBody = &RequestBody{
+		Required: true,
+		Content: map[string]*MediaType{
+			applicationJson: {
+				Schema: &Schema{Ref: schemaRefPrefix + tp.Name()},
+			},
+		},
+	}
+
+	return nil
+}
+
+func (b *builder) structSchema(tp spec.DefineStruct, key string) (*Schema, error) {
+	fields, err := b.collectFields(tp, key)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{
+		Type:        "object",
+		Description: strings.TrimSpace(strings.Join(trimComments(tp.Docs), " ")),
+		Properties:  make(map[string]*Schema),
+	}
+	for _, f := range fields {
+		property, err := b.fieldSchema(f)
+		if err != nil {
+			return nil, fmt.Errorf("type %s %v", tp.Name(), err)
+		}
+
+		schema.Properties[f.name] = property
+		if f.required {
+			schema.Required = append(schema.Required, f.name)
+		}
+	}
+
+	return schema, nil
+}
+
+func (b *builder) typeSchema(tp spec.Type) (*Schema, error) {
+	switch v := tp.(type) {
+	case spec.PrimitiveType:
+		return primitiveSchema(v.RawName)
+	case spec.DefineStruct:
+		return &Schema{Ref: schemaRefPrefix + v.Name()}, nil
+	case spec.PointerType:
+		return b.typeSchema(v.Type)
+	case spec.ArrayType:
+		items, err := b.typeSchema(v.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Schema{
+			Type:  "array",
+			Items: items,
+		}, nil
+	case spec.MapType:
+		value, err := b.typeSchema(v.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Schema{
+			Type:                 "object",
+			AdditionalProperties: value,
+		}, nil
+	case spec.InterfaceType:
+		return &Schema{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", tp.Name())
+	}
+}
+
+func applyOptions(schema *Schema, options []string) error {
+	for _, option := range options {
+		kv := strings.SplitN(option, equalToken, 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		name, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		switch name {
+		case defaultOption:
+			val, err := convertValue(schema, value)
+			if err != nil {
+				return err
+			}
+
+			schema.Default = val
+		case optionsOption:
+			for _, item := range strings.Split(value, optionSeparator) {
+				val, err := convertValue(schema, item)
+				if err != nil {
+					return err
+				}
+
+				schema.Enum = append(schema.Enum, val)
+			}
+		case rangeOption:
+			if err := applyRange(schema, value); err != nil {
+				return err
+			}
+		case minLenOption, maxLenOption, minItemsOption, maxItemsOption:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("wrong %s value %q", name, value)
+			}
+
+			switch name {
+			case minLenOption:
+				schema.MinLength = &n
+			case maxLenOption:
+				schema.MaxLength = &n
+			case minItemsOption:
+				schema.MinItems = &n
+			case maxItemsOption:
+				schema.MaxItems = &n
+			}
+		case patternOption:
+			schema.Pattern = value
+		case formatOption:
+			if format, ok := formats[value]; ok {
+				schema.Format = format
+			}
+		}
+	}
+
+	return nil
+}
+
+func applyRange(schema *Schema, value string) error {
+	if len(value) < 2 {
+		return fmt.Errorf("wrong range %q", value)
+	}
+
+	left, right := value[0], value[len(value)-1]
+	if (left != '[' && left != '(') || (right != ']' && right != ')') {
+		return fmt.Errorf("wrong range %q", value)
+	}
+
+	bounds := strings.Split(value[1:len(value)-1], rangeSeparator)
+	if len(bounds) != 2 {
+		return fmt.Errorf("wrong range %q", value)
+	}
+
+	if len(bounds[0]) > 0 {
+		min, err := strconv.ParseFloat(strings.TrimSpace(bounds[0]), 64)
+		if err != nil {
+			return err
+		}
+
+		schema.Minimum = &min
+		schema.ExclusiveMinimum = left == '('
+	}
+	if len(bounds[1]) > 0 {
+		max, err := strconv.ParseFloat(strings.TrimSpace(bounds[1]), 64)
+		if err != nil {
+			return err
+		}
+
+		schema.Maximum = &max
+		schema.ExclusiveMaximum = right == ')'
+	}
+
+	return nil
+}
+
+func buildInfo(api *spec.ApiSpec) Info {
+	properties := api.Info.Properties
+	info := Info{
+		Title:       stringx.TakeOne(stringx.TakeOne(unquote(properties["title"]), api.Service.Name), defaultApiTitle),
+		Description: unquote(properties["desc"]),
+		Version:     stringx.TakeOne(unquote(properties["version"]), defaultApiVersion),
+	}
+
+	author := unquote(properties["author"])
+	email := unquote(properties["email"])
+	if len(author) > 0 || len(email) > 0 {
+		info.Contact = &Contact{
+			Name:  author,
+			Email: email,
+		}
+	}
+
+	return info
+}
+
+func convertValue(schema *Schema, value string) (interface{}, error) {
+	switch schema.Type {
+	case "integer":
+		return strconv.ParseInt(value, 10, 64)
+	case "number":
+		return strconv.ParseFloat(value, 64)
+	case "boolean":
+		return strconv.ParseBool(value)
+	default:
+		return value, nil
+	}
+}
+
+// convertPath converts the path like /users/:id into /users/{id}, and returns the path parameters.
+func convertPath(path string) (string, []string) {
+	var params []string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+func isRequired(options []string) bool {
+	for _, option := range options {
+		if option == omitEmptyOption || strings.HasPrefix(option, optionalOption) ||
+			strings.HasPrefix(option, defaultOption+equalToken) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func memberDescription(member spec.Member) string {
+	desc := strings.TrimSpace(strings.Join(trimComments(member.Docs), " "))
+	comment := strings.TrimSpace(strings.Join(trimComments([]string{member.GetComment()}), " "))
+	if len(desc) == 0 {
+		return comment
+	}
+	if len(comment) == 0 {
+		return desc
+	}
+
+	return desc + " " + comment
+}
+
+func primitiveSchema(name string) (*Schema, error) {
+	switch name {
+	case "bool":
+		return &Schema{Type: "boolean"}, nil
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32", "byte", "rune":
+		return &Schema{Type: "integer", Format: "int32"}, nil
+	case "int64", "uint64":
+		return &Schema{Type: "integer", Format: "int64"}, nil
+	case "float32":
+		return &Schema{Type: "number", Format: "float"}, nil
+	case "float64":
+		return &Schema{Type: "number", Format: "double"}, nil
+	case "string":
+		return &Schema{Type: "string"}, nil
+	default:
+		return nil, fmt.Errorf("unsupported primitive type %s", name)
+	}
+}
+
+func trimComments(lines []string) []string {
+	var result []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "//")
+		line = strings.TrimPrefix(line, "/*")
+		line = strings.TrimSuffix(line, "*/")
+		line = strings.TrimSpace(line)
+		if len(line) > 0 {
+			result = append(result, line)
+		}
+	}
+
+	return result
+}
+
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, `"`)
+	return strings.TrimSuffix(s, `"`)
+}
@@ -0,0 +1,376 @@
+package openapigen
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lukebull/go-zero-extern/tools/goctl/api/parser"
+	"github.com/lukebull/go-zero-extern/tools/goctl/api/spec"
+	"gopkg.in/yaml.v2"
+)
+
+const fixtureDir = "../parser/g4/test/apis"
+
+// example.api and types.api have fields without tags, which the parser rejects,
+// test.api imports a file that doesn't exist.
+var fixtures = []string{
+	"empty.api",
+	"info.api",
+	"service.api",
+	"syntax.api",
+}
+
+const richApi = `
+syntax = "v1"
+
+info(
+    title: "user api"
+    desc: "user service"
+    author: "dev"
+    email: "dev@example.com"
+    version: "2.1"
+)
+
+type Base {
+    Id int64 ` + "`" + `path:"id"` + "`" + `
+}
+
+type (
+    // user request
+    UserReq {
+        Base
+        Name   string          ` + "`" + `json:"name,minlen=2,maxlen=10"` + "`" + ` // user name
+        Age    int             ` + "`" + `json:"age,range=[1:150)"` + "`" + `
+        Gender string          ` + "`" + `json:"gender,options=male|female,default=male"` + "`" + `
+        Email  string          ` + "`" + `json:"email,optional,format=email"` + "`" + `
+        Tags   []string        ` + "`" + `json:"tags,optional,maxitems=5"` + "`" + `
+        Page   int             ` + "`" + `form:"page,default=1"` + "`" + `
+        Token  string          ` + "`" + `header:"x-token,optional"` + "`" + `
+        Extra  map[string]Item ` + "`" + `json:"extra,optional"` + "`" + `
+    }
+
+    Item {
+        Value interface{} ` + "`" + `json:"value"` + "`" + `
+    }
+
+    UserReply {
+        Name  string  ` + "`" + `json:"name"` + "`" + `
+        Items []*Item ` + "`" + `json:"items"` + "`" + `
+    }
+)
+
+@server(
+    jwt: Auth
+    group: user
+    prefix: /api/v1
+)
+service user-api {
+    @doc "update user"
+    @handler updateUser
+    put /users/:id (UserReq) returns (UserReply)
+
+    @handler listUsers
+    get /users/:org/list returns ([]UserReply)
+}
+
+service user-api {
+    @handler ping
+    get /ping
+}
+`
+
+func TestBuildDocumentFixtures(t *testing.T) {
+	for _, fixture := range fixtures {
+		fixture := fixture
+		t.Run(fixture, func(t *testing.T) {
+			api, err := parser.Parse(filepath.Join(fixtureDir, fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			doc := buildAndRoundTrip(t, api)
+			checkDocument(t, api, doc)
+		})
+	}
+}
+
+func TestBuildDocument(t *testing.T) {
+	api, err := parser.ParseContent(richApi)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := buildAndRoundTrip(t, api)
+	checkDocument(t, api, doc)
+
+	if doc.Info.Title != "user api" || doc.Info.Version != "2.1" || doc.Info.Description != "user service" ||
+		doc.Info.Contact == nil || doc.Info.Contact.Email != "dev@example.com" {
+		t.Fatalf("unexpected info %+v", doc.Info)
+	}
+
+	item, ok := doc.Paths["/api/v1/users/{id}"]
+	if !ok || item.Put == nil {
+		t.Fatalf("prefixed path not found in %v", pathKeys(doc))
+	}
+	op := item.Put
+	if op.OperationId != "updateUser" || op.Summary != "update user" {
+		t.Fatalf("unexpected operation %+v", op)
+	}
+	if len(op.Tags) != 1 || op.Tags[0] != "user" {
+		t.Fatalf("unexpected tags %v", op.Tags)
+	}
+	if len(op.Security) != 1 {
+		t.Fatalf("unexpected security %v", op.Security)
+	}
+	if _, ok := op.Security[0]["Auth"]; !ok {
+		t.Fatalf("unexpected security %v", op.Security)
+	}
+	scheme := doc.Components.SecuritySchemes["Auth"]
+	if scheme == nil || scheme.Type != "http" || scheme.Scheme != "bearer" {
+		t.Fatalf("unexpected security scheme %+v", scheme)
+	}
+
+	params := make(map[string]*Parameter)
+	for _, param := range op.Parameters {
+		params[param.In+":"+param.Name] = param
+	}
+	if p := params["path:id"]; p == nil || !p.Required || p.Schema.Type != "integer" || p.Schema.Format != "int64" {
+		t.Fatalf("unexpected path parameter %+v", p)
+	}
+	if p := params["query:page"]; p == nil || p.Required || p.Schema.Default != int64(1) {
+		t.Fatalf("unexpected query parameter %+v", p)
+	}
+	if p := params["header:x-token"]; p == nil || p.Required {
+		t.Fatalf("unexpected header parameter %+v", p)
+	}
+	if len(params) != 3 {
+		t.Fatalf("unexpected parameters %v", params)
+	}
+
+	if op.RequestBody == nil || op.RequestBody.Content[applicationJson].Schema.Ref != schemaRefPrefix+"UserReq" {
+		t.Fatalf("unexpected request body %+v", op.RequestBody)
+	}
+	if op.Responses["200"].Content[applicationJson].Schema.Ref != schemaRefPrefix+"UserReply" {
+		t.Fatalf("unexpected response %+v", op.Responses["200"])
+	}
+
+	list := doc.Paths["/api/v1/users/{org}/list"]
+	if list == nil || list.Get == nil {
+		t.Fatalf("list path not found in %v", pathKeys(doc))
+	}
+	if len(list.Get.Parameters) != 1 || list.Get.Parameters[0].Name != "org" || !list.Get.Parameters[0].Required {
+		t.Fatalf("undeclared path parameters should be added, got %+v", list.Get.Parameters)
+	}
+	resp := list.Get.Responses["200"].Content[applicationJson].Schema
+	if resp.Type != "array" || resp.Items.Ref != schemaRefPrefix+"UserReply" {
+		t.Fatalf("unexpected array response %+v", resp)
+	}
+
+	ping := doc.Paths["/ping"]
+	if ping == nil || ping.Get == nil || len(ping.Get.Security) > 0 || ping.Get.RequestBody != nil {
+		t.Fatalf("unexpected ping operation %+v", ping)
+	}
+
+	req := doc.Components.Schemas["UserReq"]
+	if req.Description != "user request" {
+		t.Fatalf("unexpected description %q", req.Description)
+	}
+	if strings.Join(req.Required, ",") != "name,age" {
+		t.Fatalf("unexpected required %v", req.Required)
+	}
+	if _, ok := req.Properties["page"]; ok {
+		t.Fatal("form fields should not be in the body schema")
+	}
+	if name := req.Properties["name"]; *name.MinLength != 2 || *name.MaxLength != 10 || name.Description != "user name" {
+		t.Fatalf("unexpected name schema %+v", name)
+	}
+	if age := req.Properties["age"]; *age.Minimum != 1 || *age.Maximum != 150 ||
+		age.ExclusiveMinimum || !age.ExclusiveMaximum {
+		t.Fatalf("unexpected age schema %+v", age)
+	}
+	if gender := req.Properties["gender"]; len(gender.Enum) != 2 || gender.Default != "male" {
+		t.Fatalf("unexpected gender schema %+v", gender)
+	}
+	if email := req.Properties["email"]; email.Format != "email" {
+		t.Fatalf("unexpected email schema %+v", email)
+	}
+	if tags := req.Properties["tags"]; tags.Type != "array" || *tags.MaxItems != 5 {
+		t.Fatalf("unexpected tags schema %+v", tags)
+	}
+	if extra := req.Properties["extra"]; extra.AdditionalProperties.Ref != schemaRefPrefix+"Item" {
+		t.Fatalf("unexpected extra schema %+v", extra)
+	}
+}
+
+func TestBuildDocumentErrors(t *testing.T) {
+	tests := map[string]string{
+		"path parameter not in path": `
+type Req {
+    Id int64 ` + "`" + `path:"id"` + "`" + `
+}
+
+service foo-api {
+    @handler foo
+    get /foo (Req)
+}
+`,
+		"bad range": `
+type Req {
+    Age int ` + "`" + `json:"age,range=1:2"` + "`" + `
+}
+
+service foo-api {
+    @handler foo
+    post /foo (Req)
+}
+`,
+	}
+
+	for name, content := range tests {
+		content := content
+		t.Run(name, func(t *testing.T) {
+			api, err := parser.ParseContent(content)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = BuildDocument(api); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func buildAndRoundTrip(t *testing.T, api *spec.ApiSpec) *Document {
+	doc, err := BuildDocument(api)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{formatJson, formatYaml} {
+		content, err := Marshal(doc, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var back Document
+		if format == formatJson {
+			err = json.Unmarshal(content, &back)
+		} else {
+			err = yaml.Unmarshal(content, &back)
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		actual, err := json.Marshal(&back)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(actual) != string(expect) {
+			t.Fatalf("%s round trip mismatch:\n%s\n%s", format, expect, actual)
+		}
+	}
+
+	return doc
+}
+
+// checkDocument checks the invariants that every valid document holds.
+func checkDocument(t *testing.T, api *spec.ApiSpec, doc *Document) {
+	if doc.OpenApi != openApiVersion || len(doc.Info.Title) == 0 || len(doc.Info.Version) == 0 {
+		t.Fatalf("unexpected header %s %+v", doc.OpenApi, doc.Info)
+	}
+
+	var count int
+	for path, item := range doc.Paths {
+		if !strings.HasPrefix(path, "/") || strings.Contains(path, ":") {
+			t.Fatalf("bad path %s", path)
+		}
+
+		for _, op := range []*Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch} {
+			if op == nil {
+				continue
+			}
+
+			count++
+			if len(op.Responses) == 0 {
+				t.Fatalf("%s has no responses", path)
+			}
+
+			declared := make(map[string]bool)
+			for _, param := range op.Parameters {
+				if param.In == "path" {
+					if !param.Required {
+						t.Fatalf("path parameter %s of %s must be required", param.Name, path)
+					}
+					declared[param.Name] = true
+				}
+				checkRefs(t, doc, param.Schema)
+			}
+			for _, segment := range strings.Split(path, "/") {
+				if strings.HasPrefix(segment, "{") && !declared[strings.Trim(segment, "{}")] {
+					t.Fatalf("path parameter %s of %s not declared", segment, path)
+				}
+			}
+
+			if op.RequestBody != nil {
+				for _, media := range op.RequestBody.Content {
+					checkRefs(t, doc, media.Schema)
+				}
+			}
+			for _, resp := range op.Responses {
+				for _, media := range resp.Content {
+					checkRefs(t, doc, media.Schema)
+				}
+			}
+			for _, requirement := range op.Security {
+				for name := range requirement {
+					if _, ok := doc.Components.SecuritySchemes[name]; !ok {
+						t.Fatalf("security scheme %s not defined", name)
+					}
+				}
+			}
+		}
+	}
+	if count != len(api.Service.Routes()) {
+		t.Fatalf("expected %d operations, got %d", len(api.Service.Routes()), count)
+	}
+
+	for _, schema := range doc.Components.Schemas {
+		checkRefs(t, doc, schema)
+	}
+}
+
+func checkRefs(t *testing.T, doc *Document, schema *Schema) {
+	if schema == nil {
+		return
+	}
+
+	if len(schema.Ref) > 0 {
+		if _, ok := doc.Components.Schemas[strings.TrimPrefix(schema.Ref, schemaRefPrefix)]; !ok {
+			t.Fatalf("dangling $ref %s", schema.Ref)
+		}
+	}
+
+	checkRefs(t, doc, schema.Items)
+	checkRefs(t, doc, schema.AdditionalProperties)
+	for _, property := range schema.Properties {
+		checkRefs(t, doc, property)
+	}
+}
+
+func pathKeys(doc *Document) []string {
+	var keys []string
+	for key := range doc.Paths {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
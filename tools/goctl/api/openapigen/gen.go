@@ -0,0 +1,86 @@
+package openapigen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/lukebull/go-zero-extern/tools/goctl/api/parser"
+	"github.com/lukebull/go-zero-extern/tools/goctl/util"
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	formatJson = "json"
+	formatYaml = "yaml"
+)
+
+// OpenApiCommand generates the OpenAPI 3 document of the api file.
+func OpenApiCommand(c *cli.Context) error {
+	return Generate(c.String("api"), c.String("dir"), c.String("o"), c.String("format"))
+}
+
+// Generate generates the OpenAPI 3 document of apiFile into dir with the given format, json or yaml.
+func Generate(apiFile, dir, filename, format string) error {
+	if len(apiFile) == 0 {
+		return errors.New("missing -api")
+	}
+
+	if len(dir) == 0 {
+		return errors.New("missing -dir")
+	}
+
+	format = strings.ToLower(format)
+	if len(format) == 0 {
+		format = formatJson
+	}
+	if format != formatJson && format != formatYaml {
+		return fmt.Errorf("unsupported format %q, only json and yaml are supported", format)
+	}
+
+	api, err := parser.Parse(apiFile)
+	if err != nil {
+		return err
+	}
+
+	doc, err := BuildDocument(api)
+	if err != nil {
+		return err
+	}
+
+	content, err := Marshal(doc, format)
+	if err != nil {
+		return err
+	}
+
+	if len(filename) == 0 {
+		base := filepath.Base(apiFile)
+		filename = strings.TrimSuffix(base, filepath.Ext(base)) + "." + format
+	}
+
+	if err = util.MkdirIfNotExist(dir); err != nil {
+		return err
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(dir, filename), content, os.ModePerm); err != nil {
+		return err
+	}
+
+	fmt.Println(aurora.Green("Done."))
+	return nil
+}
+
+// Marshal marshals the document into the given format, json or yaml.
+func Marshal(doc *Document, format string) ([]byte, error) {
+	if format == formatYaml {
+		return yaml.Marshal(doc)
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
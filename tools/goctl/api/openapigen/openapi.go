@@ -0,0 +1,118 @@
+package openapigen
+
+const openApiVersion = "3.0.3"
+
+type (
+	// Document is the root object of an OpenAPI 3 document.
+	Document struct {
+		OpenApi    string               `json:"openapi" yaml:"openapi"`
+		Info       Info                 `json:"info" yaml:"info"`
+		Paths      map[string]*PathItem `json:"paths" yaml:"paths"`
+		Components Components           `json:"components,omitempty" yaml:"components,omitempty"`
+		Tags       []Tag                `json:"tags,omitempty" yaml:"tags,omitempty"`
+	}
+
+	// Info is the metadata of the api.
+	Info struct {
+		Title       string   `json:"title" yaml:"title"`
+		Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+		Version     string   `json:"version" yaml:"version"`
+		Contact     *Contact `json:"contact,omitempty" yaml:"contact,omitempty"`
+	}
+
+	// Contact is the contact information of the api.
+	Contact struct {
+		Name  string `json:"name,omitempty" yaml:"name,omitempty"`
+		Email string `json:"email,omitempty" yaml:"email,omitempty"`
+	}
+
+	// Tag is used to group the operations.
+	Tag struct {
+		Name string `json:"name" yaml:"name"`
+	}
+
+	// PathItem holds the operations on a path.
+	PathItem struct {
+		Get     *Operation `json:"get,omitempty" yaml:"get,omitempty"`
+		Put     *Operation `json:"put,omitempty" yaml:"put,omitempty"`
+		Post    *Operation `json:"post,omitempty" yaml:"post,omitempty"`
+		Delete  *Operation `json:"delete,omitempty" yaml:"delete,omitempty"`
+		Options *Operation `json:"options,omitempty" yaml:"options,omitempty"`
+		Head    *Operation `json:"head,omitempty" yaml:"head,omitempty"`
+		Patch   *Operation `json:"patch,omitempty" yaml:"patch,omitempty"`
+	}
+
+	// Operation is a single api operation on a path.
+	Operation struct {
+		Tags        []string              `json:"tags,omitempty" yaml:"tags,omitempty"`
+		Summary     string                `json:"summary,omitempty" yaml:"summary,omitempty"`
+		Description string                `json:"description,omitempty" yaml:"description,omitempty"`
+		OperationId string                `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+		Parameters  []*Parameter          `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+		RequestBody *RequestBody          `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+		Responses   map[string]*Response  `json:"responses" yaml:"responses"`
+		Security    []map[string][]string `json:"security,omitempty" yaml:"security,omitempty"`
+	}
+
+	// Parameter is a single operation parameter.
+	Parameter struct {
+		Name        string  `json:"name" yaml:"name"`
+		In          string  `json:"in" yaml:"in"`
+		Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+		Required    bool    `json:"required,omitempty" yaml:"required,omitempty"`
+		Schema      *Schema `json:"schema" yaml:"schema"`
+	}
+
+	// RequestBody is the request body of an operation.
+	RequestBody struct {
+		Required bool                  `json:"required,omitempty" yaml:"required,omitempty"`
+		Content  map[string]*MediaType `json:"content" yaml:"content"`
+	}
+
+	// Response is a single response of an operation.
+	Response struct {
+		Description string                `json:"description" yaml:"description"`
+		Content     map[string]*MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+	}
+
+	// MediaType describes the content of a request body or a response.
+	MediaType struct {
+		Schema *Schema `json:"schema" yaml:"schema"`
+	}
+
+	// Components holds the reusable objects.
+	Components struct {
+		Schemas         map[string]*Schema         `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+		SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty" yaml:"securitySchemes,omitempty"`
+	}
+
+	// SecurityScheme is a security scheme that can be used by the operations.
+	SecurityScheme struct {
+		Type         string `json:"type" yaml:"type"`
+		Scheme       string `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+		BearerFormat string `json:"bearerFormat,omitempty" yaml:"bearerFormat,omitempty"`
+	}
+
+	// Schema describes a data type.
+	Schema struct {
+		Ref                  string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+		Type                 string             `json:"type,omitempty" yaml:"type,omitempty"`
+		Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+		Description          string             `json:"description,omitempty" yaml:"description,omitempty"`
+		Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+		AdditionalProperties *Schema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+		Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+		Enum                 []interface{}      `json:"enum,omitempty" yaml:"enum,omitempty"`
+		Default              interface{}        `json:"default,omitempty" yaml:"default,omitempty"`
+		Minimum              *float64           `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+		Maximum              *float64           `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+		ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty"`
+		MinLength            *int               `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+		MaxLength            *int               `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+		Pattern              string             `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+		MinItems             *int               `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+		MaxItems             *int               `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+	}
+)
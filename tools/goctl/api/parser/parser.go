@@ -3,13 +3,22 @@ package parser
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"unicode"
 
+	"github.com/lukebull/go-zero-extern/core/mapping"
 	"github.com/lukebull/go-zero-extern/tools/goctl/api/parser/g4/ast"
 	"github.com/lukebull/go-zero-extern/tools/goctl/api/parser/g4/gen/api"
 	"github.com/lukebull/go-zero-extern/tools/goctl/api/spec"
 )
 
+var validationTagKeys = map[string]bool{
+	"json":   true,
+	"form":   true,
+	"path":   true,
+	"header": true,
+}
+
 type parser struct {
 	ast  *ast.Api
 	spec *spec.ApiSpec
@@ -104,6 +113,9 @@ func (p parser) fillTypes() error {
 			for _, item := range v.Fields {
 				members = append(members, p.fieldToMember(item))
 			}
+			if err := checkValidationTags(v.Name.Text(), members); err != nil {
+				return err
+			}
 			p.spec.Types = append(p.spec.Types, spec.DefineStruct{
 				RawName: v.Name.Text(),
 				Members: members,
@@ -142,6 +154,51 @@ func (p parser) fillTypes() error {
 	return nil
 }
 
+// checkValidationTags makes sure the validation options in the member tags are well-formed,
+// so that the generated code doesn't fail on parsing requests.
+func checkValidationTags(typeName string, members []spec.Member) error {
+	names := make(map[string]struct{}, len(members))
+	for _, member := range members {
+		names[member.Name] = struct{}{}
+	}
+
+	for _, member := range members {
+		if len(member.Tag) == 0 {
+			continue
+		}
+
+		tags, err := spec.Parse(member.Tag)
+		if err != nil {
+			return fmt.Errorf("type %s field %s: %v", typeName, member.Name, err)
+		}
+
+		for _, tag := range tags.Tags() {
+			if !validationTagKeys[tag.Key] {
+				continue
+			}
+
+			value := strings.Join(append([]string{tag.Name}, tag.Options...), ",")
+			if err := mapping.ValidateTagOptions(value); err != nil {
+				return fmt.Errorf("type %s field %s: %v", typeName, member.Name, err)
+			}
+
+			for _, option := range tag.Options {
+				kv := strings.SplitN(option, "=", 2)
+				if len(kv) != 2 || !strings.HasSuffix(kv[0], "field") {
+					continue
+				}
+
+				if _, ok := names[kv[1]]; !ok {
+					return fmt.Errorf("type %s field %s: field %s in %s not exists",
+						typeName, member.Name, kv[1], kv[0])
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 func (p parser) findDefinedType(name string) (*spec.Type, error) {
 	for _, item := range p.spec.Types {
 		if _, ok := item.(spec.DefineStruct); ok {
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseValidationTags(t *testing.T) {
+	const tmpl = `syntax = "v1"
+
+type Request {
+	Min int ` + "`json:\"min\"`" + `
+	Max int ` + "`json:\"max,%s\"`" + `
+}
+`
+
+	tests := []struct {
+		name   string
+		option string
+		err    string
+	}{
+		{
+			name:   "valid",
+			option: "gtfield=Min",
+		},
+		{
+			name:   "bad int option",
+			option: "minlen=a",
+			err:    "type Request field Max",
+		},
+		{
+			name:   "unknown format",
+			option: "format=phone",
+			err:    "unknown format",
+		},
+		{
+			name:   "missing cross field",
+			option: "gtfield=Other",
+			err:    "field Other in gtfield not exists",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseContent(strings.Replace(tmpl, "%s", test.option, 1))
+			if len(test.err) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("expected error with %q, got %v", test.err, err)
+			}
+		})
+	}
+}
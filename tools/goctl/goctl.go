@@ -14,9 +14,11 @@ import (
 	"github.com/lukebull/go-zero-extern/tools/goctl/api/docgen"
 	"github.com/lukebull/go-zero-extern/tools/goctl/api/format"
 	"github.com/lukebull/go-zero-extern/tools/goctl/api/gogen"
+	"github.com/lukebull/go-zero-extern/tools/goctl/api/importer"
 	"github.com/lukebull/go-zero-extern/tools/goctl/api/javagen"
 	"github.com/lukebull/go-zero-extern/tools/goctl/api/ktgen"
 	"github.com/lukebull/go-zero-extern/tools/goctl/api/new"
+	"github.com/lukebull/go-zero-extern/tools/goctl/api/openapigen"
 	"github.com/lukebull/go-zero-extern/tools/goctl/api/tsgen"
 	"github.com/lukebull/go-zero-extern/tools/goctl/api/validate"
 	"github.com/lukebull/go-zero-extern/tools/goctl/configgen"
@@ -101,6 +103,49 @@ var (
 					},
 					Action: docgen.DocCommand,
 				},
+				{
+					Name:  "openapi",
+					Usage: "generate OpenAPI 3 document for provided api file",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "api",
+							Usage: "the api file",
+						},
+						cli.StringFlag{
+							Name:  "dir",
+							Usage: "the target dir",
+						},
+						cli.StringFlag{
+							Name:  "o",
+							Usage: "the output file name, defaults to the api file name with the format extension",
+						},
+						cli.StringFlag{
+							Name:  "format",
+							Usage: "the output format, json or yaml",
+							Value: "json",
+						},
+					},
+					Action: openapigen.OpenApiCommand,
+				},
+				{
+					Name:  "import",
+					Usage: "generate api file from the Swagger 2 or OpenAPI 3 spec, json or yaml",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "spec",
+							Usage: "the Swagger 2 or OpenAPI 3 spec file",
+						},
+						cli.StringFlag{
+							Name:  "dir",
+							Usage: "the target dir",
+						},
+						cli.StringFlag{
+							Name:  "o",
+							Usage: "the output file name, defaults to the spec file name with the .api extension",
+						},
+					},
+					Action: importer.ImportCommand,
+				},
 				{
 					Name:  "go",
 					Usage: "generate go files for provided api in yaml file",
@@ -317,6 +362,12 @@ var (
 							Usage: "the nodePort of the deployment to expose",
 							Value: 0,
 						},
+						cli.IntFlag{
+							Name: "healthPort",
+							Usage: "the port of the health server to probe /healthz and /readyz, " +
+								"tcp probes on port if not set",
+							Value: 0,
+						},
 						cli.IntFlag{
 							Name:  "minReplicas",
 							Usage: "the min replicas to deploy",
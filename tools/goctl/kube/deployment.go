@@ -26,7 +26,21 @@ spec:
             exec:
               command: ["sh","-c","sleep 5"]
         ports:
-        - containerPort: {{.Port}}
+        - containerPort: {{.Port}}{{if .HealthPort}}
+        - name: health
+          containerPort: {{.HealthPort}}
+        readinessProbe:
+          httpGet:
+            path: /readyz
+            port: health
+          initialDelaySeconds: 5
+          periodSeconds: 10
+        livenessProbe:
+          httpGet:
+            path: /healthz
+            port: health
+          initialDelaySeconds: 15
+          periodSeconds: 20{{else}}
         readinessProbe:
           tcpSocket:
             port: {{.Port}}
@@ -36,7 +50,7 @@ spec:
           tcpSocket:
             port: {{.Port}}
           initialDelaySeconds: 15
-          periodSeconds: 20
+          periodSeconds: 20{{end}}
         resources:
           requests:
             cpu: {{.RequestCpu}}m
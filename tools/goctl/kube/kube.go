@@ -27,6 +27,7 @@ type Deployment struct {
 	Replicas    int
 	Revisions   int
 	Port        int
+	HealthPort  int
 	NodePort    int
 	UseNodePort bool
 	RequestCpu  int
@@ -71,6 +72,7 @@ func DeploymentCommand(c *cli.Context) error {
 		Replicas:    c.Int("replicas"),
 		Revisions:   c.Int("revisions"),
 		Port:        c.Int("port"),
+		HealthPort:  c.Int("healthPort"),
 		NodePort:    nodePort,
 		UseNodePort: nodePort > 0,
 		RequestCpu:  c.Int("requestCpu"),
@@ -0,0 +1,86 @@
+package kube
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+type probe struct {
+	HttpGet struct {
+		Path string
+		Port interface{}
+	} `yaml:"httpGet"`
+	TcpSocket struct {
+		Port int
+	} `yaml:"tcpSocket"`
+}
+
+type container struct {
+	Ports []struct {
+		Name          string
+		ContainerPort int `yaml:"containerPort"`
+	}
+	ReadinessProbe probe `yaml:"readinessProbe"`
+	LivenessProbe  probe `yaml:"livenessProbe"`
+}
+
+func renderContainer(t *testing.T, d Deployment) container {
+	var buf bytes.Buffer
+	tpl := template.Must(template.New("deployment").Parse(deploymentTemplate))
+	if err := tpl.Execute(&buf, d); err != nil {
+		t.Fatal(err)
+	}
+
+	var deployment struct {
+		Spec struct {
+			Template struct {
+				Spec struct {
+					Containers []container
+				}
+			}
+		}
+	}
+	doc := strings.Split(buf.String(), "\n---\n")[0]
+	if err := yaml.Unmarshal([]byte(doc), &deployment); err != nil {
+		t.Fatal(err)
+	}
+	if len(deployment.Spec.Template.Spec.Containers) != 1 {
+		t.Fatalf("expected 1 container, got %s", doc)
+	}
+
+	return deployment.Spec.Template.Spec.Containers[0]
+}
+
+func TestDeploymentHealthProbes(t *testing.T) {
+	c := renderContainer(t, Deployment{
+		Name:       "user",
+		Port:       8888,
+		HealthPort: 6061,
+	})
+	if len(c.Ports) != 2 || c.Ports[1].Name != "health" || c.Ports[1].ContainerPort != 6061 {
+		t.Fatalf("unexpected ports %+v", c.Ports)
+	}
+	if c.ReadinessProbe.HttpGet.Path != "/readyz" || c.ReadinessProbe.HttpGet.Port != "health" {
+		t.Fatalf("unexpected readiness probe %+v", c.ReadinessProbe)
+	}
+	if c.LivenessProbe.HttpGet.Path != "/healthz" || c.LivenessProbe.HttpGet.Port != "health" {
+		t.Fatalf("unexpected liveness probe %+v", c.LivenessProbe)
+	}
+}
+
+func TestDeploymentTcpProbes(t *testing.T) {
+	c := renderContainer(t, Deployment{
+		Name: "user",
+		Port: 8888,
+	})
+	if len(c.Ports) != 1 || c.ReadinessProbe.TcpSocket.Port != 8888 || c.LivenessProbe.TcpSocket.Port != 8888 {
+		t.Fatalf("unexpected probes %+v", c)
+	}
+	if len(c.ReadinessProbe.HttpGet.Path) > 0 {
+		t.Fatalf("unexpected http probe %+v", c.ReadinessProbe)
+	}
+}
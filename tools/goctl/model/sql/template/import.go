@@ -3,6 +3,7 @@ package template
 var (
 	// Imports defines a import template for model in cache case
 	Imports = `import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -17,6 +18,7 @@ var (
 `
 	// ImportsNoCache defines a import template for model in normal case
 	ImportsNoCache = `import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
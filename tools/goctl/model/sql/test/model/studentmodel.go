@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -26,12 +27,12 @@ var (
 type (
 	// StudentModel only for test
 	StudentModel interface {
-		Insert(data Student) (sql.Result, error)
-		FindOne(id int64) (*Student, error)
-		FindOneByClassName(class, name string) (*Student, error)
-		Update(data Student) error
+		Insert(ctx context.Context, data Student) (sql.Result, error)
+		FindOne(ctx context.Context, id int64) (*Student, error)
+		FindOneByClassName(ctx context.Context, class, name string) (*Student, error)
+		Update(ctx context.Context, data Student) error
 		// only for test
-		Delete(id int64, className, studentName string) error
+		Delete(ctx context.Context, id int64, className, studentName string) error
 	}
 
 	defaultStudentModel struct {
@@ -59,21 +60,21 @@ func NewStudentModel(conn sqlx.SqlConn, c cache.CacheConf) StudentModel {
 	}
 }
 
-func (m *defaultStudentModel) Insert(data Student) (sql.Result, error) {
+func (m *defaultStudentModel) Insert(ctx context.Context, data Student) (sql.Result, error) {
 	studentClassNameKey := fmt.Sprintf("%s%v%v", cacheStudentClassNamePrefix, data.Class, data.Name)
-	ret, err := m.Exec(func(conn sqlx.SqlConn) (result sql.Result, err error) {
+	ret, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
 		query := fmt.Sprintf("insert into %s (%s) values (?, ?, ?, ?)", m.table, studentRowsExpectAutoSet)
-		return conn.Exec(query, data.Class, data.Name, data.Age, data.Score)
+		return conn.ExecCtx(ctx, query, data.Class, data.Name, data.Age, data.Score)
 	}, studentClassNameKey)
 	return ret, err
 }
 
-func (m *defaultStudentModel) FindOne(id int64) (*Student, error) {
+func (m *defaultStudentModel) FindOne(ctx context.Context, id int64) (*Student, error) {
 	studentIdKey := fmt.Sprintf("%s%v", cacheStudentIdPrefix, id)
 	var resp Student
-	err := m.QueryRow(&resp, studentIdKey, func(conn sqlx.SqlConn, v interface{}) error {
+	err := m.QueryRowCtx(ctx, &resp, studentIdKey, func(ctx context.Context, conn sqlx.SqlConn, v interface{}) error {
 		query := fmt.Sprintf("select %s from %s where `id` = ? limit 1", studentRows, m.table)
-		return conn.QueryRow(v, query, id)
+		return conn.QueryRowCtx(ctx, v, query, id)
 	})
 	switch err {
 	case nil:
@@ -85,12 +86,12 @@ func (m *defaultStudentModel) FindOne(id int64) (*Student, error) {
 	}
 }
 
-func (m *defaultStudentModel) FindOneByClassName(class, name string) (*Student, error) {
+func (m *defaultStudentModel) FindOneByClassName(ctx context.Context, class, name string) (*Student, error) {
 	studentClassNameKey := fmt.Sprintf("%s%v%v", cacheStudentClassNamePrefix, class, name)
 	var resp Student
-	err := m.QueryRowIndex(&resp, studentClassNameKey, m.formatPrimary, func(conn sqlx.SqlConn, v interface{}) (i interface{}, e error) {
+	err := m.QueryRowIndexCtx(ctx, &resp, studentClassNameKey, m.formatPrimary, func(ctx context.Context, conn sqlx.SqlConn, v interface{}) (i interface{}, e error) {
 		query := fmt.Sprintf("select %s from %s where `class` = ? and `name` = ? limit 1", studentRows, m.table)
-		if err := conn.QueryRow(&resp, query, class, name); err != nil {
+		if err := conn.QueryRowCtx(ctx, &resp, query, class, name); err != nil {
 			return nil, err
 		}
 		return resp.Id, nil
@@ -105,21 +106,21 @@ func (m *defaultStudentModel) FindOneByClassName(class, name string) (*Student,
 	}
 }
 
-func (m *defaultStudentModel) Update(data Student) error {
+func (m *defaultStudentModel) Update(ctx context.Context, data Student) error {
 	studentIdKey := fmt.Sprintf("%s%v", cacheStudentIdPrefix, data.Id)
-	_, err := m.Exec(func(conn sqlx.SqlConn) (result sql.Result, err error) {
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
 		query := fmt.Sprintf("update %s set %s where `id` = ?", m.table, studentRowsWithPlaceHolder)
-		return conn.Exec(query, data.Class, data.Name, data.Age, data.Score, data.Id)
+		return conn.ExecCtx(ctx, query, data.Class, data.Name, data.Age, data.Score, data.Id)
 	}, studentIdKey)
 	return err
 }
 
-func (m *defaultStudentModel) Delete(id int64, className, studentName string) error {
+func (m *defaultStudentModel) Delete(ctx context.Context, id int64, className, studentName string) error {
 	studentIdKey := fmt.Sprintf("%s%v", cacheStudentIdPrefix, id)
 	studentClassNameKey := fmt.Sprintf("%s%v%v", cacheStudentClassNamePrefix, className, studentName)
-	_, err := m.Exec(func(conn sqlx.SqlConn) (result sql.Result, err error) {
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
 		query := fmt.Sprintf("delete from %s where `id` = ?", m.table)
-		return conn.Exec(query, id)
+		return conn.ExecCtx(ctx, query, id)
 	}, studentIdKey, studentClassNameKey)
 	return err
 }
@@ -128,7 +129,7 @@ func (m *defaultStudentModel) formatPrimary(primary interface{}) string {
 	return fmt.Sprintf("%s%v", cacheStudentIdPrefix, primary)
 }
 
-func (m *defaultStudentModel) queryPrimary(conn sqlx.SqlConn, v, primary interface{}) error {
+func (m *defaultStudentModel) queryPrimary(ctx context.Context, conn sqlx.SqlConn, v, primary interface{}) error {
 	query := fmt.Sprintf("select %s from %s where `id` = ? limit 1", studentRows, m.table)
-	return conn.QueryRow(v, query, primary)
+	return conn.QueryRowCtx(ctx, v, query, primary)
 }
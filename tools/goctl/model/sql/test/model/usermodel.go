@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -22,13 +23,13 @@ var (
 type (
 	// UserModel defines a model for user
 	UserModel interface {
-		Insert(data User) (sql.Result, error)
-		FindOne(id int64) (*User, error)
-		FindOneByUser(user string) (*User, error)
-		FindOneByMobile(mobile string) (*User, error)
-		FindOneByName(name string) (*User, error)
-		Update(data User) error
-		Delete(id int64) error
+		Insert(ctx context.Context, data User) (sql.Result, error)
+		FindOne(ctx context.Context, id int64) (*User, error)
+		FindOneByUser(ctx context.Context, user string) (*User, error)
+		FindOneByMobile(ctx context.Context, mobile string) (*User, error)
+		FindOneByName(ctx context.Context, name string) (*User, error)
+		Update(ctx context.Context, data User) error
+		Delete(ctx context.Context, id int64) error
 	}
 
 	defaultUserModel struct {
@@ -58,16 +59,16 @@ func NewUserModel(conn sqlx.SqlConn) UserModel {
 	}
 }
 
-func (m *defaultUserModel) Insert(data User) (sql.Result, error) {
+func (m *defaultUserModel) Insert(ctx context.Context, data User) (sql.Result, error) {
 	query := fmt.Sprintf("insert into %s (%s) values (?, ?, ?, ?, ?, ?)", m.table, userRowsExpectAutoSet)
-	ret, err := m.conn.Exec(query, data.User, data.Name, data.Password, data.Mobile, data.Gender, data.Nickname)
+	ret, err := m.conn.ExecCtx(ctx, query, data.User, data.Name, data.Password, data.Mobile, data.Gender, data.Nickname)
 	return ret, err
 }
 
-func (m *defaultUserModel) FindOne(id int64) (*User, error) {
+func (m *defaultUserModel) FindOne(ctx context.Context, id int64) (*User, error) {
 	query := fmt.Sprintf("select %s from %s where `id` = ? limit 1", userRows, m.table)
 	var resp User
-	err := m.conn.QueryRow(&resp, query, id)
+	err := m.conn.QueryRowCtx(ctx, &resp, query, id)
 	switch err {
 	case nil:
 		return &resp, nil
@@ -78,10 +79,10 @@ func (m *defaultUserModel) FindOne(id int64) (*User, error) {
 	}
 }
 
-func (m *defaultUserModel) FindOneByUser(user string) (*User, error) {
+func (m *defaultUserModel) FindOneByUser(ctx context.Context, user string) (*User, error) {
 	var resp User
 	query := fmt.Sprintf("select %s from %s where `user` = ? limit 1", userRows, m.table)
-	err := m.conn.QueryRow(&resp, query, user)
+	err := m.conn.QueryRowCtx(ctx, &resp, query, user)
 	switch err {
 	case nil:
 		return &resp, nil
@@ -92,10 +93,10 @@ func (m *defaultUserModel) FindOneByUser(user string) (*User, error) {
 	}
 }
 
-func (m *defaultUserModel) FindOneByMobile(mobile string) (*User, error) {
+func (m *defaultUserModel) FindOneByMobile(ctx context.Context, mobile string) (*User, error) {
 	var resp User
 	query := fmt.Sprintf("select %s from %s where `mobile` = ? limit 1", userRows, m.table)
-	err := m.conn.QueryRow(&resp, query, mobile)
+	err := m.conn.QueryRowCtx(ctx, &resp, query, mobile)
 	switch err {
 	case nil:
 		return &resp, nil
@@ -106,10 +107,10 @@ func (m *defaultUserModel) FindOneByMobile(mobile string) (*User, error) {
 	}
 }
 
-func (m *defaultUserModel) FindOneByName(name string) (*User, error) {
+func (m *defaultUserModel) FindOneByName(ctx context.Context, name string) (*User, error) {
 	var resp User
 	query := fmt.Sprintf("select %s from %s where `name` = ? limit 1", userRows, m.table)
-	err := m.conn.QueryRow(&resp, query, name)
+	err := m.conn.QueryRowCtx(ctx, &resp, query, name)
 	switch err {
 	case nil:
 		return &resp, nil
@@ -120,14 +121,14 @@ func (m *defaultUserModel) FindOneByName(name string) (*User, error) {
 	}
 }
 
-func (m *defaultUserModel) Update(data User) error {
+func (m *defaultUserModel) Update(ctx context.Context, data User) error {
 	query := fmt.Sprintf("update %s set %s where `id` = ?", m.table, userRowsWithPlaceHolder)
-	_, err := m.conn.Exec(query, data.User, data.Name, data.Password, data.Mobile, data.Gender, data.Nickname, data.ID)
+	_, err := m.conn.ExecCtx(ctx, query, data.User, data.Name, data.Password, data.Mobile, data.Gender, data.Nickname, data.ID)
 	return err
 }
 
-func (m *defaultUserModel) Delete(id int64) error {
+func (m *defaultUserModel) Delete(ctx context.Context, id int64) error {
 	query := fmt.Sprintf("delete from %s where `id` = ?", m.table)
-	_, err := m.conn.Exec(query, id)
+	_, err := m.conn.ExecCtx(ctx, query, id)
 	return err
 }
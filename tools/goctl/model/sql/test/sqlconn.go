@@ -3,6 +3,7 @@
 package mocksql
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/lukebull/go-zero-extern/core/stores/sqlx"
@@ -28,12 +29,22 @@ func (conn *MockConn) Exec(query string, args ...interface{}) (sql.Result, error
 	return exec(conn.db, query, args...)
 }
 
+// ExecCtx executes sql and returns the result, ctx is ignored
+func (conn *MockConn) ExecCtx(_ context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return conn.Exec(query, args...)
+}
+
 // Prepare executes sql by sql.DB
 func (conn *MockConn) Prepare(query string) (sqlx.StmtSession, error) {
 	st, err := conn.db.Prepare(query)
 	return statement{stmt: st}, err
 }
 
+// PrepareCtx executes sql by sql.DB, ctx is ignored
+func (conn *MockConn) PrepareCtx(_ context.Context, query string) (sqlx.StmtSession, error) {
+	return conn.Prepare(query)
+}
+
 // QueryRow executes sql and returns a query row
 func (conn *MockConn) QueryRow(v interface{}, q string, args ...interface{}) error {
 	return query(conn.db, func(rows *sql.Rows) error {
@@ -41,6 +52,11 @@ func (conn *MockConn) QueryRow(v interface{}, q string, args ...interface{}) err
 	}, q, args...)
 }
 
+// QueryRowCtx executes sql and returns a query row, ctx is ignored
+func (conn *MockConn) QueryRowCtx(_ context.Context, v interface{}, q string, args ...interface{}) error {
+	return conn.QueryRow(v, q, args...)
+}
+
 // QueryRowPartial executes sql and returns a partial query row
 func (conn *MockConn) QueryRowPartial(v interface{}, q string, args ...interface{}) error {
 	return query(conn.db, func(rows *sql.Rows) error {
@@ -48,6 +64,11 @@ func (conn *MockConn) QueryRowPartial(v interface{}, q string, args ...interface
 	}, q, args...)
 }
 
+// QueryRowPartialCtx executes sql and returns a partial query row, ctx is ignored
+func (conn *MockConn) QueryRowPartialCtx(_ context.Context, v interface{}, q string, args ...interface{}) error {
+	return conn.QueryRowPartial(v, q, args...)
+}
+
 // QueryRows executes sql and returns  query rows
 func (conn *MockConn) QueryRows(v interface{}, q string, args ...interface{}) error {
 	return query(conn.db, func(rows *sql.Rows) error {
@@ -55,6 +76,11 @@ func (conn *MockConn) QueryRows(v interface{}, q string, args ...interface{}) er
 	}, q, args...)
 }
 
+// QueryRowsCtx executes sql and returns query rows, ctx is ignored
+func (conn *MockConn) QueryRowsCtx(_ context.Context, v interface{}, q string, args ...interface{}) error {
+	return conn.QueryRows(v, q, args...)
+}
+
 // QueryRowsPartial executes sql and returns partial query rows
 func (conn *MockConn) QueryRowsPartial(v interface{}, q string, args ...interface{}) error {
 	return query(conn.db, func(rows *sql.Rows) error {
@@ -62,11 +88,21 @@ func (conn *MockConn) QueryRowsPartial(v interface{}, q string, args ...interfac
 	}, q, args...)
 }
 
+// QueryRowsPartialCtx executes sql and returns partial query rows, ctx is ignored
+func (conn *MockConn) QueryRowsPartialCtx(_ context.Context, v interface{}, q string, args ...interface{}) error {
+	return conn.QueryRowsPartial(v, q, args...)
+}
+
 // Transact is the implemention of sqlx.SqlConn, nothing to do
 func (conn *MockConn) Transact(func(session sqlx.Session) error) error {
 	return nil
 }
 
+// TransactCtx is the implemention of sqlx.SqlConn, nothing to do
+func (conn *MockConn) TransactCtx(context.Context, func(context.Context, sqlx.Session) error) error {
+	return nil
+}
+
 func (s statement) Close() error {
 	return s.stmt.Close()
 }
@@ -75,26 +111,46 @@ func (s statement) Exec(args ...interface{}) (sql.Result, error) {
 	return execStmt(s.stmt, args...)
 }
 
+func (s statement) ExecCtx(_ context.Context, args ...interface{}) (sql.Result, error) {
+	return s.Exec(args...)
+}
+
 func (s statement) QueryRow(v interface{}, args ...interface{}) error {
 	return queryStmt(s.stmt, func(rows *sql.Rows) error {
 		return unmarshalRow(v, rows, true)
 	}, args...)
 }
 
+func (s statement) QueryRowCtx(_ context.Context, v interface{}, args ...interface{}) error {
+	return s.QueryRow(v, args...)
+}
+
 func (s statement) QueryRowPartial(v interface{}, args ...interface{}) error {
 	return queryStmt(s.stmt, func(rows *sql.Rows) error {
 		return unmarshalRow(v, rows, false)
 	}, args...)
 }
 
+func (s statement) QueryRowPartialCtx(_ context.Context, v interface{}, args ...interface{}) error {
+	return s.QueryRowPartial(v, args...)
+}
+
 func (s statement) QueryRows(v interface{}, args ...interface{}) error {
 	return queryStmt(s.stmt, func(rows *sql.Rows) error {
 		return unmarshalRows(v, rows, true)
 	}, args...)
 }
 
+func (s statement) QueryRowsCtx(_ context.Context, v interface{}, args ...interface{}) error {
+	return s.QueryRows(v, args...)
+}
+
 func (s statement) QueryRowsPartial(v interface{}, args ...interface{}) error {
 	return queryStmt(s.stmt, func(rows *sql.Rows) error {
 		return unmarshalRows(v, rows, false)
 	}, args...)
 }
+
+func (s statement) QueryRowsPartialCtx(_ context.Context, v interface{}, args ...interface{}) error {
+	return s.QueryRowsPartial(v, args...)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/lukebull/go-zero-extern/core/discov"
 	"github.com/lukebull/go-zero-extern/zrpc/internal"
 	"github.com/lukebull/go-zero-extern/zrpc/internal/auth"
+	"github.com/lukebull/go-zero-extern/zrpc/internal/tlsx"
 	"google.golang.org/grpc"
 )
 
@@ -17,6 +18,8 @@ var (
 	WithTimeout = internal.WithTimeout
 	// WithUnaryClientInterceptor is an alias of internal.WithUnaryClientInterceptor.
 	WithUnaryClientInterceptor = internal.WithUnaryClientInterceptor
+	// WithTransportCredentials is an alias of internal.WithTransportCredentials.
+	WithTransportCredentials = internal.WithTransportCredentials
 )
 
 type (
@@ -53,11 +56,22 @@ func NewClient(c RpcClientConf, options ...ClientOption) (Client, error) {
 	if c.Timeout > 0 {
 		opts = append(opts, WithTimeout(time.Duration(c.Timeout)*time.Millisecond))
 	}
+	if c.HasTls() {
+		creds, err := tlsx.NewClientCredentials(c.Tls.CertFile, c.Tls.KeyFile, c.Tls.CaFile,
+			c.Tls.ServerName, c.Tls.InsecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, WithTransportCredentials(creds))
+	}
 	opts = append(opts, options...)
 
 	var client Client
 	var err error
-	if len(c.Endpoints) > 0 {
+	if len(c.Target) > 0 {
+		client, err = internal.NewClient(c.Target, opts...)
+	} else if len(c.Endpoints) > 0 {
 		client, err = internal.NewClient(internal.BuildDirectTarget(c.Endpoints), opts...)
 	} else if err = c.Etcd.Validate(); err == nil {
 		if c.Etcd.Tls == true {
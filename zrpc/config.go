@@ -1,9 +1,19 @@
 package zrpc
 
 import (
+	"errors"
+
 	"github.com/lukebull/go-zero-extern/core/discov"
 	"github.com/lukebull/go-zero-extern/core/service"
 	"github.com/lukebull/go-zero-extern/core/stores/redis"
+	"github.com/lukebull/go-zero-extern/zrpc/internal"
+)
+
+var (
+	// ErrRateLimitConfig is an error that indicates bad config for rate limit.
+	ErrRateLimitConfig = errors.New("bad config for RateLimit")
+	// ErrRateLimitByApp is an error that indicates limiting by app without authenticating the apps.
+	ErrRateLimitByApp = errors.New("RateLimit by app requires Auth and StrictControl")
 )
 
 type (
@@ -15,18 +25,57 @@ type (
 		Auth          bool               `json:",optional"`
 		Redis         redis.RedisKeyConf `json:",optional"`
 		StrictControl bool               `json:",optional"`
+		Tls           TlsConf            `json:",optional"`
 		// setting 0 means no timeout
-		Timeout      int64 `json:",default=2000"`
-		CpuThreshold int64 `json:",default=900,range=[0:1000]"`
+		Timeout      int64         `json:",default=2000"`
+		CpuThreshold int64         `json:",default=900,range=[0:1000]"`
+		RateLimit    RateLimitConf `json:",optional"`
+		// LogFields are the keys of the log fields accepted from the clients, like request_id,
+		// the fields are logged on the server side too, none accepted if empty.
+		LogFields []string `json:",optional"`
+	}
+
+	// A RateLimitConf is the config of rate limiting, disabled if Quota is 0.
+	// The requests are limited per method, and per client ip or per app credential in the methods.
+	RateLimitConf struct {
+		// Period is the period in seconds that Quota requests are allowed.
+		Period int `json:",default=1"`
+		Quota  int `json:",optional"`
+		// KeyBy is how to share the quota in a method, the requests from the clients
+		// without the app credential are limited by ip. Limiting by app requires Auth
+		// and StrictControl, otherwise the clients could claim any apps.
+		KeyBy string `json:",default=ip,options=method|ip|app"`
+		// Redis shares the quotas across the instances, the requests are limited in process
+		// if not set or unreachable.
+		Redis     redis.RedisConf `json:",optional"`
+		KeyPrefix string          `json:",default=ratelimit:"`
 	}
 
 	// A RpcClientConf is a rpc client config.
 	RpcClientConf struct {
 		Etcd      discov.EtcdConf `json:",optional"`
-		Endpoints []string        `json:",optional=!Etcd"`
-		App       string          `json:",optional"`
-		Token     string          `json:",optional"`
-		Timeout   int64           `json:",default=2000"`
+		Endpoints []string        `json:",optional"`
+		// Target is a grpc target like k8s://namespace/service:port,
+		// takes precedence over Endpoints and Etcd if set.
+		Target  string  `json:",optional"`
+		App     string  `json:",optional"`
+		Token   string  `json:",optional"`
+		Timeout int64   `json:",default=2000"`
+		Tls     TlsConf `json:",optional"`
+	}
+
+	// A TlsConf is the tls config of the grpc connections, not the etcd connections.
+	// The certificate files are reloaded automatically if they are rotated on disk.
+	TlsConf struct {
+		CertFile string `json:",optional"`
+		KeyFile  string `json:",optional"`
+		CaFile   string `json:",optional"`
+		// VerifyClient is for servers, requires clients to present certificates signed by CaFile.
+		VerifyClient bool `json:",optional"`
+		// ServerName is for clients, overrides the server name to verify.
+		ServerName string `json:",optional"`
+		// InsecureSkipVerify is for clients, only for testing.
+		InsecureSkipVerify bool `json:",optional"`
 	}
 )
 
@@ -51,6 +100,16 @@ func NewEtcdClientConf(hosts []string, key, app, token string) RpcClientConf {
 	}
 }
 
+// NewKubeClientConf returns a RpcClientConf that resolves the endpoints of
+// service in namespace through the Kubernetes API.
+func NewKubeClientConf(namespace, service string, port int, app, token string) RpcClientConf {
+	return RpcClientConf{
+		Target: internal.BuildKubeTarget(namespace, service, port),
+		App:    app,
+		Token:  token,
+	}
+}
+
 // HasEtcd checks if there is etcd settings in config.
 func (sc RpcServerConf) HasEtcd() bool {
 	return len(sc.Etcd.Hosts) > 0 && len(sc.Etcd.Key) > 0
@@ -58,6 +117,12 @@ func (sc RpcServerConf) HasEtcd() bool {
 
 // Validate validates the config.
 func (sc RpcServerConf) Validate() error {
+	if sc.RateLimit.Quota > 0 && sc.RateLimit.Period <= 0 {
+		return ErrRateLimitConfig
+	}
+	if sc.RateLimit.Quota > 0 && sc.RateLimit.KeyBy == rateLimitByApp && (!sc.Auth || !sc.StrictControl) {
+		return ErrRateLimitByApp
+	}
 	if !sc.Auth {
 		return nil
 	}
@@ -69,3 +134,14 @@ func (sc RpcServerConf) Validate() error {
 func (cc RpcClientConf) HasCredential() bool {
 	return len(cc.App) > 0 && len(cc.Token) > 0
 }
+
+// HasTls checks if the server side tls is configured.
+func (sc RpcServerConf) HasTls() bool {
+	return len(sc.Tls.CertFile) > 0
+}
+
+// HasTls checks if the client side tls is configured.
+func (cc RpcClientConf) HasTls() bool {
+	return len(cc.Tls.CaFile) > 0 || len(cc.Tls.CertFile) > 0 || len(cc.Tls.ServerName) > 0 ||
+		cc.Tls.InsecureSkipVerify
+}
@@ -36,6 +36,21 @@ func NewAuthenticator(store *redis.Redis, key string, strict bool) (*Authenticat
 	}, nil
 }
 
+// AppFromContext returns the app of the credential in ctx, empty if not set.
+// The app is not authenticated unless the Authenticator is used.
+func AppFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if apps := md[appKey]; len(apps) > 0 {
+		return apps[0]
+	}
+
+	return ""
+}
+
 // Authenticate authenticates the given ctx.
 func (a *Authenticator) Authenticate(ctx context.Context) error {
 	md, ok := metadata.FromIncomingContext(ctx)
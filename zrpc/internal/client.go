@@ -11,6 +11,7 @@ import (
 	"github.com/lukebull/go-zero-extern/zrpc/internal/clientinterceptors"
 	"github.com/lukebull/go-zero-extern/zrpc/internal/resolver"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 const (
@@ -30,8 +31,9 @@ type (
 
 	// A ClientOptions is a client options.
 	ClientOptions struct {
-		Timeout     time.Duration
-		DialOptions []grpc.DialOption
+		Timeout              time.Duration
+		TransportCredentials credentials.TransportCredentials
+		DialOptions          []grpc.DialOption
 	}
 
 	// ClientOption defines the method to customize a ClientOptions.
@@ -74,11 +76,17 @@ func (c *client) buildDialOptions(opts ...ClientOption) []grpc.DialOption {
 		opt(&cliOpts)
 	}
 
+	transport := grpc.WithInsecure()
+	if cliOpts.TransportCredentials != nil {
+		transport = grpc.WithTransportCredentials(cliOpts.TransportCredentials)
+	}
+
 	options := []grpc.DialOption{
-		grpc.WithInsecure(),
+		transport,
 		grpc.WithBlock(),
 		WithUnaryClientInterceptors(
 			clientinterceptors.TracingInterceptor,
+			clientinterceptors.LogFieldsInterceptor,
 			clientinterceptors.DurationInterceptor,
 			clientinterceptors.PrometheusInterceptor,
 			clientinterceptors.BreakerInterceptor,
@@ -125,6 +133,13 @@ func WithTimeout(timeout time.Duration) ClientOption {
 	}
 }
 
+// WithTransportCredentials returns a func to customize a ClientOptions with given transport credentials.
+func WithTransportCredentials(creds credentials.TransportCredentials) ClientOption {
+	return func(options *ClientOptions) {
+		options.TransportCredentials = creds
+	}
+}
+
 // WithUnaryClientInterceptor returns a func to customize a ClientOptions with given interceptor.
 func WithUnaryClientInterceptor(interceptor grpc.UnaryClientInterceptor) ClientOption {
 	return func(options *ClientOptions) {
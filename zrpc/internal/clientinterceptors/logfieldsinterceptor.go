@@ -0,0 +1,14 @@
+package clientinterceptors
+
+import (
+	"context"
+
+	"github.com/lukebull/go-zero-extern/zrpc/internal/logfields"
+	"google.golang.org/grpc"
+)
+
+// LogFieldsInterceptor is an interceptor that passes the log fields of ctx to the servers.
+func LogFieldsInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(logfields.Inject(ctx), method, req, reply, cc, opts...)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 
 	"github.com/lukebull/go-zero-extern/core/trace"
+	"github.com/lukebull/go-zero-extern/core/trace/tracespec"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // TracingInterceptor is an interceptor that handles tracing.
@@ -13,6 +15,8 @@ func TracingInterceptor(ctx context.Context, method string, req, reply interface
 	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 	ctx, span := trace.StartClientSpan(ctx, cc.Target(), method)
 	defer span.Finish()
+	span.SetAttribute("rpc.system", "grpc")
+	span.SetAttribute("rpc.method", method)
 
 	var pairs []string
 	span.Visit(func(key, val string) bool {
@@ -21,5 +25,12 @@ func TracingInterceptor(ctx context.Context, method string, req, reply interface
 	})
 	ctx = metadata.AppendToOutgoingContext(ctx, pairs...)
 
-	return invoker(ctx, method, req, reply, cc, opts...)
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err != nil {
+		st := status.Convert(err)
+		span.SetAttribute("rpc.grpc.status_code", int(st.Code()))
+		span.SetStatus(tracespec.StatusError, st.Message())
+	}
+
+	return err
 }
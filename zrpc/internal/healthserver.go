@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"context"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/health"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const healthWatchInterval = time.Second
+
+// healthServer is the grpc.health.v1 service backed by the readiness probes of core/health,
+// the empty service name means the whole server.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	services map[string]bool
+}
+
+// registerHealthServer registers the health service if not registered by the users.
+func registerHealthServer(server *grpc.Server) {
+	info := server.GetServiceInfo()
+	if _, ok := info[healthpb.Health_ServiceDesc.ServiceName]; ok {
+		return
+	}
+
+	services := make(map[string]bool, len(info))
+	for name := range info {
+		services[name] = true
+	}
+	healthpb.RegisterHealthServer(server, &healthServer{
+		services: services,
+	})
+}
+
+func (s *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (
+	*healthpb.HealthCheckResponse, error) {
+	if !s.known(req.Service) {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.Service)
+	}
+
+	return &healthpb.HealthCheckResponse{
+		Status: servingStatus(ctx),
+	}, nil
+}
+
+// Watch sends the serving status on changes, the unknown services are reported as SERVICE_UNKNOWN.
+func (s *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ticker := time.NewTicker(healthWatchInterval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		current := healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		if s.known(req.Service) {
+			current = servingStatus(stream.Context())
+		}
+		if current != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+			last = current
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *healthServer) known(service string) bool {
+	return len(service) == 0 || s.services[service]
+}
+
+func servingStatus(ctx context.Context) healthpb.HealthCheckResponse_ServingStatus {
+	if health.CheckReadiness(ctx).Status == health.StatusUp {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
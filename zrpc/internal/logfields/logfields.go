@@ -0,0 +1,96 @@
+package logfields
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/lukebull/go-zero-extern/core/lang"
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// the metadata key that carries the log fields in json.
+	fieldsKey = "x-log-fields"
+	// the limits of the log fields from the clients, to keep the log lines small.
+	maxFieldsLen = 4096
+	maxFields    = 32
+)
+
+// Extract returns a context that carries the log fields in the incoming metadata of ctx,
+// only the fields with the given keys are accepted, because the clients are not trusted.
+// The fields are dropped if the metadata is larger than 4096 bytes or has more than 32 fields.
+func Extract(ctx context.Context, keys map[string]lang.PlaceholderType) context.Context {
+	if len(keys) == 0 {
+		return ctx
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	vals := md.Get(fieldsKey)
+	if len(vals) == 0 {
+		return ctx
+	}
+
+	if len(vals[0]) > maxFieldsLen {
+		logx.WithContext(ctx).Errorf("log fields in metadata over %d bytes, dropped", maxFieldsLen)
+		return ctx
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(vals[0]), &m); err != nil {
+		logx.WithContext(ctx).Errorf("bad log fields in metadata: %s, error: %v", vals[0], err)
+		return ctx
+	}
+	if len(m) > maxFields {
+		logx.WithContext(ctx).Errorf("log fields in metadata over %d, dropped", maxFields)
+		return ctx
+	}
+
+	fieldKeys := make([]string, 0, len(m))
+	for key := range m {
+		if _, ok := keys[key]; ok {
+			fieldKeys = append(fieldKeys, key)
+		}
+	}
+	if len(fieldKeys) == 0 {
+		return ctx
+	}
+	sort.Strings(fieldKeys)
+
+	fields := make([]logx.LogField, 0, len(fieldKeys))
+	for _, key := range fieldKeys {
+		fields = append(fields, logx.Field(key, m[key]))
+	}
+
+	return logx.ContextWithFields(ctx, fields...)
+}
+
+// Inject returns a context that carries the log fields of ctx in the outgoing metadata.
+func Inject(ctx context.Context) context.Context {
+	fields := logx.ContextFields(ctx)
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	m := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if err, ok := field.Value.(error); ok {
+			m[field.Key] = err.Error()
+		} else {
+			m[field.Key] = field.Value
+		}
+	}
+
+	content, err := json.Marshal(m)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("failed to marshal log fields, error: %v", err)
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, fieldsKey, string(content))
+}
@@ -0,0 +1,79 @@
+package logfields
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lukebull/go-zero-extern/core/lang"
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestInjectExtract(t *testing.T) {
+	ctx := logx.ContextWithFields(context.Background(), logx.Field("uid", 1),
+		logx.Field("err", errors.New("bad")), logx.Field("app", "foo"))
+	md, ok := metadata.FromOutgoingContext(Inject(ctx))
+	assert.True(t, ok)
+
+	ctx = Extract(metadata.NewIncomingContext(context.Background(), md), map[string]lang.PlaceholderType{
+		"app": lang.Placeholder,
+		"err": lang.Placeholder,
+		"uid": lang.Placeholder,
+	})
+	assert.Equal(t, []logx.LogField{
+		logx.Field("app", "foo"),
+		logx.Field("err", "bad"),
+		logx.Field("uid", float64(1)),
+	}, logx.ContextFields(ctx))
+}
+
+func TestInjectExtractWithoutFields(t *testing.T) {
+	keys := map[string]lang.PlaceholderType{"uid": lang.Placeholder}
+	ctx := context.Background()
+	assert.Equal(t, ctx, Inject(ctx))
+	assert.Equal(t, ctx, Extract(ctx, keys))
+
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(fieldsKey, "not json"))
+	assert.Nil(t, logx.ContextFields(Extract(ctx, keys)))
+}
+
+func TestExtractOnlyAllowedKeys(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(fieldsKey, `{"request_id":"abc","uid":1,"level":"fatal"}`))
+
+	assert.Equal(t, []logx.LogField{
+		logx.Field("request_id", "abc"),
+	}, logx.ContextFields(Extract(ctx, map[string]lang.PlaceholderType{
+		"request_id": lang.Placeholder,
+	})))
+	assert.Nil(t, logx.ContextFields(Extract(ctx, map[string]lang.PlaceholderType{
+		"trace": lang.Placeholder,
+	})))
+	assert.Equal(t, ctx, Extract(ctx, nil))
+}
+
+func TestExtractOverLimits(t *testing.T) {
+	keys := map[string]lang.PlaceholderType{"uid": lang.Placeholder}
+
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(fieldsKey, fmt.Sprintf(`{"uid":"%s"}`, strings.Repeat("a", maxFieldsLen))))
+	assert.Nil(t, logx.ContextFields(Extract(ctx, keys)))
+
+	fields := []string{`"uid":1`}
+	for i := 0; i < maxFields; i++ {
+		fields = append(fields, fmt.Sprintf(`"k%d":%d`, i, i))
+	}
+	ctx = metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(fieldsKey, "{"+strings.Join(fields, ",")+"}"))
+	assert.Nil(t, logx.ContextFields(Extract(ctx, keys)))
+
+	ctx = metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(fieldsKey, "{"+strings.Join(fields[:maxFields], ",")+"}"))
+	assert.Equal(t, []logx.LogField{
+		logx.Field("uid", float64(1)),
+	}, logx.ContextFields(Extract(ctx, keys)))
+}
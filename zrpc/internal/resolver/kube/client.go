@@ -0,0 +1,212 @@
+package kube
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	tokenFile         = serviceAccountDir + "/token"
+	caFile            = serviceAccountDir + "/ca.crt"
+	hostEnv           = "KUBERNETES_SERVICE_HOST"
+	portEnv           = "KUBERNETES_SERVICE_PORT"
+	dialTimeout       = time.Second * 5
+	// the watch requests are long-lived, so only the response headers are bounded.
+	responseHeaderTimeout = time.Second * 10
+)
+
+var (
+	// ErrNotInCluster is an error that indicates not running inside a kubernetes cluster.
+	ErrNotInCluster = errors.New("not running inside kubernetes, missing " + hostEnv + " or " + portEnv)
+	// ErrNotFound is an error that indicates the endpoints object doesn't exist.
+	ErrNotFound = errors.New("endpoints not found")
+)
+
+type (
+	// A Client fetches and watches the Endpoints objects from the Kubernetes API server.
+	Client interface {
+		GetEndpoints(ctx context.Context, namespace, name string) (*Endpoints, error)
+		// WatchEndpoints returns a channel of events, which is closed when the stream ends.
+		WatchEndpoints(ctx context.Context, namespace, name, resourceVersion string) (<-chan Event, error)
+	}
+
+	apiClient struct {
+		host  string
+		token func() string
+		cli   *http.Client
+	}
+
+	watchEvent struct {
+		Type   string          `json:"type"`
+		Object json.RawMessage `json:"object"`
+	}
+)
+
+// NewClient returns a Client that talks to the API server on host,
+// like https://10.0.0.1:443, token and tlsConfig are optional.
+func NewClient(host, token string, tlsConfig *tls.Config) Client {
+	return newApiClient(host, func() string {
+		return token
+	}, tlsConfig)
+}
+
+// NewInClusterClient returns a Client with the service account that kubernetes mounts into pods.
+func NewInClusterClient() (Client, error) {
+	host, port := os.Getenv(hostEnv), os.Getenv(portEnv)
+	if len(host) == 0 || len(port) == 0 {
+		return nil, ErrNotInCluster
+	}
+
+	ca, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+
+	// tokens are rotated by kubelet, so read it on every request.
+	return newApiClient("https://"+net.JoinHostPort(host, port), func() string {
+		token, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			return ""
+		}
+
+		return strings.TrimSpace(string(token))
+	}, &tls.Config{
+		RootCAs: pool,
+	}), nil
+}
+
+func newApiClient(host string, token func() string, tlsConfig *tls.Config) *apiClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: time.Second * 30,
+	}).DialContext
+	transport.ResponseHeaderTimeout = responseHeaderTimeout
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &apiClient{
+		host:  strings.TrimSuffix(host, "/"),
+		token: token,
+		cli: &http.Client{
+			Transport: transport,
+		},
+	}
+}
+
+func (c *apiClient) GetEndpoints(ctx context.Context, namespace, name string) (*Endpoints, error) {
+	resp, err := c.do(ctx, fmt.Sprintf("/api/v1/namespaces/%s/endpoints/%s",
+		url.PathEscape(namespace), url.PathEscape(name)), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var endpoints Endpoints
+	if err := json.NewDecoder(resp.Body).Decode(&endpoints); err != nil {
+		return nil, err
+	}
+
+	return &endpoints, nil
+}
+
+func (c *apiClient) WatchEndpoints(ctx context.Context, namespace, name, resourceVersion string) (
+	<-chan Event, error) {
+	query := url.Values{}
+	query.Set("watch", "true")
+	query.Set("fieldSelector", "metadata.name="+name)
+	if len(resourceVersion) > 0 {
+		query.Set("resourceVersion", resourceVersion)
+	}
+
+	resp, err := c.do(ctx, fmt.Sprintf("/api/v1/namespaces/%s/endpoints", url.PathEscape(namespace)), query)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var evt watchEvent
+			if err := decoder.Decode(&evt); err != nil {
+				return
+			}
+
+			event := Event{
+				Type: evt.Type,
+			}
+			if evt.Type != EventError {
+				var endpoints Endpoints
+				if err := json.Unmarshal(evt.Object, &endpoints); err != nil {
+					return
+				}
+				event.Object = &endpoints
+			}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func (c *apiClient) do(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := c.host + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if token := c.token(); len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("kubernetes api %s, status: %d, body: %s", path, resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,95 @@
+package kube
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lukebull/go-zero-extern/core/lang"
+)
+
+// An EventHandler keeps the addresses of a service and notifies the changes.
+type EventHandler struct {
+	update    func([]string)
+	port      int
+	endpoints map[string]lang.PlaceholderType
+	lock      sync.Mutex
+}
+
+// NewEventHandler returns an EventHandler, port is used if the endpoints don't specify one.
+func NewEventHandler(update func([]string), port int) *EventHandler {
+	return &EventHandler{
+		update:    update,
+		port:      port,
+		endpoints: make(map[string]lang.PlaceholderType),
+	}
+}
+
+// OnAdd handles the endpoints add events.
+func (h *EventHandler) OnAdd(endpoints *Endpoints) {
+	h.Update(endpoints)
+}
+
+// OnDelete handles the endpoints delete events.
+func (h *EventHandler) OnDelete(_ *Endpoints) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if len(h.endpoints) == 0 {
+		return
+	}
+
+	h.endpoints = make(map[string]lang.PlaceholderType)
+	h.notify()
+}
+
+// OnUpdate handles the endpoints update events.
+func (h *EventHandler) OnUpdate(endpoints *Endpoints) {
+	h.Update(endpoints)
+}
+
+// Update updates the endpoints.
+func (h *EventHandler) Update(endpoints *Endpoints) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	latest := make(map[string]lang.PlaceholderType)
+	for _, sub := range endpoints.Subsets {
+		port := h.port
+		if port == 0 && len(sub.Ports) > 0 {
+			port = sub.Ports[0].Port
+		}
+		if port == 0 {
+			continue
+		}
+
+		for _, addr := range sub.Addresses {
+			latest[fmt.Sprintf("%s:%d", addr.IP, port)] = lang.Placeholder
+		}
+	}
+
+	var changed bool
+	if len(latest) != len(h.endpoints) {
+		changed = true
+	} else {
+		for key := range latest {
+			if _, ok := h.endpoints[key]; !ok {
+				changed = true
+				break
+			}
+		}
+	}
+
+	if changed {
+		h.endpoints = latest
+		h.notify()
+	}
+}
+
+func (h *EventHandler) notify() {
+	var targets []string
+	for key := range h.endpoints {
+		targets = append(targets, key)
+	}
+
+	h.update(targets)
+}
@@ -0,0 +1,47 @@
+package kube
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/resolver"
+)
+
+const colon = ":"
+
+// Service represents a service with namespace, name and port.
+type Service struct {
+	Namespace string
+	Name      string
+	Port      int
+}
+
+// ParseTarget parses the resolver.Target like k8s://namespace/service:port.
+func ParseTarget(target resolver.Target) (Service, error) {
+	var service Service
+	service.Namespace = target.Authority
+	if len(service.Namespace) == 0 {
+		return Service{}, fmt.Errorf("target %q must specify the namespace", target.Endpoint)
+	}
+
+	segs := strings.SplitN(target.Endpoint, colon, 2)
+	if len(segs) < 1 || len(segs[0]) == 0 {
+		return Service{}, fmt.Errorf("bad endpoint: %s", target.Endpoint)
+	}
+
+	service.Name = segs[0]
+	if len(segs) > 1 {
+		port, err := strconv.Atoi(segs[1])
+		if err != nil {
+			return Service{}, err
+		}
+		if port <= 0 || port > 65535 {
+			return Service{}, fmt.Errorf("bad port: %d", port)
+		}
+
+		service.Port = port
+	}
+
+	return service, nil
+}
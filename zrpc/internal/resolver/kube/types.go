@@ -0,0 +1,50 @@
+package kube
+
+const (
+	// EventAdded indicates the endpoints object is added.
+	EventAdded = "ADDED"
+	// EventModified indicates the endpoints object is modified.
+	EventModified = "MODIFIED"
+	// EventDeleted indicates the endpoints object is deleted.
+	EventDeleted = "DELETED"
+	// EventError indicates the watch stream failed, the watcher should relist.
+	EventError = "ERROR"
+)
+
+type (
+	// Endpoints is the subset of the Kubernetes Endpoints object that resolving needs.
+	Endpoints struct {
+		Metadata ObjectMeta       `json:"metadata"`
+		Subsets  []EndpointSubset `json:"subsets"`
+	}
+
+	// ObjectMeta is the subset of the Kubernetes object metadata.
+	ObjectMeta struct {
+		Name            string `json:"name"`
+		Namespace       string `json:"namespace"`
+		ResourceVersion string `json:"resourceVersion"`
+	}
+
+	// EndpointSubset is a group of addresses with a common set of ports.
+	EndpointSubset struct {
+		Addresses []EndpointAddress `json:"addresses"`
+		Ports     []EndpointPort    `json:"ports"`
+	}
+
+	// EndpointAddress is a single IP address of an endpoint.
+	EndpointAddress struct {
+		IP string `json:"ip"`
+	}
+
+	// EndpointPort is a port that an endpoint exposes.
+	EndpointPort struct {
+		Name string `json:"name"`
+		Port int    `json:"port"`
+	}
+
+	// An Event is a change of the watched Endpoints object.
+	Event struct {
+		Type   string
+		Object *Endpoints
+	}
+)
@@ -0,0 +1,103 @@
+package kube
+
+import (
+	"context"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/logx"
+)
+
+const (
+	minRetryInterval = time.Second
+	maxRetryInterval = time.Second * 30
+)
+
+// syncTimeout is a variable for faster tests.
+var syncTimeout = time.Second * 10
+
+// Sync fetches the endpoints of svc once and feeds them into handler.
+// It returns the resource version to start watching from.
+// The list is bounded by syncTimeout, so that an unresponsive API server doesn't hang the callers.
+func Sync(ctx context.Context, cli Client, svc Service, handler *EventHandler) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, syncTimeout)
+	defer cancel()
+
+	endpoints, err := cli.GetEndpoints(ctx, svc.Namespace, svc.Name)
+	switch err {
+	case nil:
+		handler.OnUpdate(endpoints)
+		return endpoints.Metadata.ResourceVersion, nil
+	case ErrNotFound:
+		// the service might be deployed later, keep watching.
+		handler.OnDelete(nil)
+		return "", nil
+	default:
+		return "", err
+	}
+}
+
+// Watch watches the endpoints of svc starting from resourceVersion until ctx is done,
+// relists and rewatches with backoff if the stream breaks.
+func Watch(ctx context.Context, cli Client, svc Service, resourceVersion string, handler *EventHandler) {
+	interval := minRetryInterval
+	for {
+		if err := watchOnce(ctx, cli, svc, resourceVersion, handler, func() {
+			interval = minRetryInterval
+		}); err != nil && ctx.Err() == nil {
+			logx.Errorf("watch kubernetes endpoints %s/%s error: %v", svc.Namespace, svc.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		if interval < maxRetryInterval {
+			interval *= 2
+			if interval > maxRetryInterval {
+				interval = maxRetryInterval
+			}
+		}
+
+		rv, err := Sync(ctx, cli, svc, handler)
+		if err != nil {
+			if ctx.Err() == nil {
+				logx.Errorf("list kubernetes endpoints %s/%s error: %v", svc.Namespace, svc.Name, err)
+			}
+			resourceVersion = ""
+			continue
+		}
+
+		resourceVersion = rv
+	}
+}
+
+func watchOnce(ctx context.Context, cli Client, svc Service, resourceVersion string,
+	handler *EventHandler, onEvent func()) error {
+	// cancel the stream on returning, otherwise the response body is leaked after an ERROR event.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events, err := cli.WatchEndpoints(ctx, svc.Namespace, svc.Name, resourceVersion)
+	if err != nil {
+		return err
+	}
+
+	for event := range events {
+		onEvent()
+		switch event.Type {
+		case EventAdded:
+			handler.OnAdd(event.Object)
+		case EventModified:
+			handler.OnUpdate(event.Object)
+		case EventDeleted:
+			handler.OnDelete(event.Object)
+		case EventError:
+			// usually the resource version is too old, relist.
+			return nil
+		}
+	}
+
+	return nil
+}
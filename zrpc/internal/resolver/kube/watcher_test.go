@@ -0,0 +1,376 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+const (
+	testNamespace = "ns"
+	testService   = "svc"
+)
+
+type fakeApiServer struct {
+	*httptest.Server
+	lock      sync.Mutex
+	endpoints *Endpoints
+	lists     int
+	watches   chan chan watchEvent
+	token     string
+}
+
+func newFakeApiServer(t *testing.T, endpoints *Endpoints) *fakeApiServer {
+	s := &fakeApiServer{
+		endpoints: endpoints,
+		watches:   make(chan chan watchEvent, 10),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(s.token) > 0 && r.Header.Get("Authorization") != "Bearer "+s.token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case fmt.Sprintf("/api/v1/namespaces/%s/endpoints/%s", testNamespace, testService):
+			s.lock.Lock()
+			s.lists++
+			eps := s.endpoints
+			s.lock.Unlock()
+			if eps == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(eps)
+		case fmt.Sprintf("/api/v1/namespaces/%s/endpoints", testNamespace):
+			if r.URL.Query().Get("watch") != "true" ||
+				r.URL.Query().Get("fieldSelector") != "metadata.name="+testService {
+				t.Errorf("unexpected watch query: %s", r.URL.RawQuery)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			events := make(chan watchEvent)
+			s.watches <- events
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			encoder := json.NewEncoder(w)
+			for {
+				select {
+				case evt, ok := <-events:
+					if !ok {
+						return
+					}
+					_ = encoder.Encode(evt)
+					w.(http.Flusher).Flush()
+				case <-r.Context().Done():
+					return
+				}
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return s
+}
+
+func (s *fakeApiServer) listCount() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.lists
+}
+
+func (s *fakeApiServer) nextWatch(t *testing.T) chan watchEvent {
+	select {
+	case events := <-s.watches:
+		return events
+	case <-time.After(time.Second * 5):
+		t.Fatal("timeout waiting for watch request")
+		return nil
+	}
+}
+
+func (s *fakeApiServer) setEndpoints(eps *Endpoints) {
+	s.lock.Lock()
+	s.endpoints = eps
+	s.lock.Unlock()
+}
+
+type addressRecorder struct {
+	updates chan []string
+}
+
+func newAddressRecorder() *addressRecorder {
+	return &addressRecorder{
+		updates: make(chan []string, 10),
+	}
+}
+
+func (r *addressRecorder) update(addrs []string) {
+	sort.Strings(addrs)
+	r.updates <- addrs
+}
+
+func (r *addressRecorder) expect(t *testing.T, expected ...string) {
+	t.Helper()
+	select {
+	case addrs := <-r.updates:
+		if strings.Join(addrs, ",") != strings.Join(expected, ",") {
+			t.Fatalf("expected %v, got %v", expected, addrs)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("timeout waiting for %v", expected)
+	}
+}
+
+func makeEndpoints(version string, port int, ips ...string) *Endpoints {
+	var addrs []EndpointAddress
+	for _, ip := range ips {
+		addrs = append(addrs, EndpointAddress{IP: ip})
+	}
+
+	return &Endpoints{
+		Metadata: ObjectMeta{
+			Name:            testService,
+			Namespace:       testNamespace,
+			ResourceVersion: version,
+		},
+		Subsets: []EndpointSubset{
+			{
+				Addresses: addrs,
+				Ports: []EndpointPort{
+					{Name: "grpc", Port: port},
+				},
+			},
+		},
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) json.RawMessage {
+	val, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return val
+}
+
+func TestSyncAndWatch(t *testing.T) {
+	server := newFakeApiServer(t, makeEndpoints("1", 8080, "10.0.0.1", "10.0.0.2"))
+	server.token = "token"
+	defer server.Close()
+
+	cli := NewClient(server.URL, "token", nil)
+	svc := Service{Namespace: testNamespace, Name: testService}
+	recorder := newAddressRecorder()
+	handler := NewEventHandler(recorder.update, 0)
+
+	rv, err := Sync(context.Background(), cli, svc, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rv != "1" {
+		t.Fatalf("expected resource version 1, got %s", rv)
+	}
+	// the port falls back to subsets[].ports
+	recorder.expect(t, "10.0.0.1:8080", "10.0.0.2:8080")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		Watch(ctx, cli, svc, rv, handler)
+		close(done)
+	}()
+
+	events := server.nextWatch(t)
+	events <- watchEvent{
+		Type:   EventAdded,
+		Object: mustMarshal(t, makeEndpoints("2", 8080, "10.0.0.3")),
+	}
+	recorder.expect(t, "10.0.0.3:8080")
+
+	events <- watchEvent{
+		Type:   EventModified,
+		Object: mustMarshal(t, makeEndpoints("3", 8080, "10.0.0.3", "10.0.0.4")),
+	}
+	recorder.expect(t, "10.0.0.3:8080", "10.0.0.4:8080")
+
+	events <- watchEvent{
+		Type:   EventDeleted,
+		Object: mustMarshal(t, makeEndpoints("4", 8080)),
+	}
+	recorder.expect(t)
+
+	// an ERROR event makes the watcher relist and rewatch.
+	lists := server.listCount()
+	server.setEndpoints(makeEndpoints("10", 9090, "10.0.0.5"))
+	events <- watchEvent{
+		Type:   EventError,
+		Object: json.RawMessage(`{"kind":"Status","code":410}`),
+	}
+	recorder.expect(t, "10.0.0.5:9090")
+	if server.listCount() != lists+1 {
+		t.Fatalf("expected a relist after ERROR event")
+	}
+
+	events = server.nextWatch(t)
+	events <- watchEvent{
+		Type:   EventModified,
+		Object: mustMarshal(t, makeEndpoints("11", 9090, "10.0.0.6")),
+	}
+	recorder.expect(t, "10.0.0.6:9090")
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("watch didn't stop after cancel")
+	}
+}
+
+func TestSyncWithPort(t *testing.T) {
+	server := newFakeApiServer(t, makeEndpoints("1", 8080, "10.0.0.1"))
+	defer server.Close()
+
+	recorder := newAddressRecorder()
+	handler := NewEventHandler(recorder.update, 3456)
+	_, err := Sync(context.Background(), NewClient(server.URL, "", nil),
+		Service{Namespace: testNamespace, Name: testService}, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.expect(t, "10.0.0.1:3456")
+}
+
+func TestSyncNotFound(t *testing.T) {
+	server := newFakeApiServer(t, nil)
+	defer server.Close()
+
+	recorder := newAddressRecorder()
+	handler := NewEventHandler(recorder.update, 0)
+	rv, err := Sync(context.Background(), NewClient(server.URL, "", nil),
+		Service{Namespace: testNamespace, Name: testService}, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rv) > 0 {
+		t.Fatalf("expected empty resource version, got %s", rv)
+	}
+}
+
+func TestSyncUnauthorized(t *testing.T) {
+	server := newFakeApiServer(t, makeEndpoints("1", 8080, "10.0.0.1"))
+	server.token = "token"
+	defer server.Close()
+
+	_, err := Sync(context.Background(), NewClient(server.URL, "bad", nil),
+		Service{Namespace: testNamespace, Name: testService}, NewEventHandler(func([]string) {}, 0))
+	if err == nil {
+		t.Fatal("expected error on unauthorized request")
+	}
+}
+
+func TestSyncTimeout(t *testing.T) {
+	old := syncTimeout
+	syncTimeout = time.Millisecond * 100
+	defer func() {
+		syncTimeout = old
+	}()
+
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(block)
+
+	start := time.Now()
+	_, err := Sync(context.Background(), NewClient(server.URL, "", nil),
+		Service{Namespace: testNamespace, Name: testService}, NewEventHandler(func([]string) {}, 0))
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if time.Since(start) > time.Second*5 {
+		t.Fatal("sync should be bounded by syncTimeout")
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  resolver.Target
+		expect  Service
+		wantErr bool
+	}{
+		{
+			name:   "with port",
+			target: resolver.Target{Authority: "ns", Endpoint: "svc:8080"},
+			expect: Service{Namespace: "ns", Name: "svc", Port: 8080},
+		},
+		{
+			name:   "without port",
+			target: resolver.Target{Authority: "ns", Endpoint: "svc"},
+			expect: Service{Namespace: "ns", Name: "svc"},
+		},
+		{
+			name:    "no namespace",
+			target:  resolver.Target{Endpoint: "svc:8080"},
+			wantErr: true,
+		},
+		{
+			name:    "no service",
+			target:  resolver.Target{Authority: "ns", Endpoint: ":8080"},
+			wantErr: true,
+		},
+		{
+			name:    "bad port",
+			target:  resolver.Target{Authority: "ns", Endpoint: "svc:abc"},
+			wantErr: true,
+		},
+		{
+			name:    "port out of range",
+			target:  resolver.Target{Authority: "ns", Endpoint: "svc:70000"},
+			wantErr: true,
+		},
+		{
+			name:    "zero port",
+			target:  resolver.Target{Authority: "ns", Endpoint: "svc:0"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			svc, err := ParseTarget(test.target)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", svc)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if svc != test.expect {
+				t.Fatalf("expected %+v, got %+v", test.expect, svc)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package resolver
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lukebull/go-zero-extern/core/threading"
+	"github.com/lukebull/go-zero-extern/zrpc/internal/resolver/kube"
+	"google.golang.org/grpc/resolver"
+)
+
+type (
+	kubeBuilder struct {
+		client kube.Client
+		lock   sync.Mutex
+	}
+
+	kubeResolver struct {
+		cancel context.CancelFunc
+	}
+)
+
+func (b *kubeBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (
+	resolver.Resolver, error) {
+	svc, err := kube.ParseTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := b.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	handler := kube.NewEventHandler(func(endpoints []string) {
+		var addrs []resolver.Address
+		for _, val := range subset(endpoints, subsetSize) {
+			addrs = append(addrs, resolver.Address{
+				Addr: val,
+			})
+		}
+
+		cc.UpdateState(resolver.State{
+			Addresses: addrs,
+		})
+	}, svc.Port)
+
+	// the initial list is bounded inside kube.Sync, ctx lives until the resolver is closed.
+	ctx, cancel := context.WithCancel(context.Background())
+	rv, err := kube.Sync(ctx, cli, svc, handler)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	threading.GoSafe(func() {
+		kube.Watch(ctx, cli, svc, rv, handler)
+	})
+
+	return &kubeResolver{cancel: cancel}, nil
+}
+
+func (b *kubeBuilder) Scheme() string {
+	return KubernetesScheme
+}
+
+func (b *kubeBuilder) getClient() (kube.Client, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.client != nil {
+		return b.client, nil
+	}
+
+	cli, err := kube.NewInClusterClient()
+	if err != nil {
+		return nil, err
+	}
+
+	b.client = cli
+	return cli, nil
+}
+
+func (b *kubeBuilder) setClient(cli kube.Client) {
+	b.lock.Lock()
+	b.client = cli
+	b.lock.Unlock()
+}
+
+func (r *kubeResolver) Close() {
+	r.cancel()
+}
+
+func (r *kubeResolver) ResolveNow(options resolver.ResolveNowOptions) {
+}
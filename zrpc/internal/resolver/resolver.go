@@ -3,6 +3,7 @@ package resolver
 import (
 	"fmt"
 
+	"github.com/lukebull/go-zero-extern/zrpc/internal/resolver/kube"
 	"google.golang.org/grpc/resolver"
 )
 
@@ -11,6 +12,8 @@ const (
 	DirectScheme = "direct"
 	// DiscovScheme stands for discov scheme.
 	DiscovScheme = "discov"
+	// KubernetesScheme stands for k8s scheme.
+	KubernetesScheme = "k8s"
 	// EndpointSepChar is the separator cha in endpoints.
 	EndpointSepChar = ','
 
@@ -23,12 +26,14 @@ var (
 
 	dirBuilder directBuilder
 	disBuilder discovBuilder
+	k8sBuilder kubeBuilder
 )
 
-// RegisterResolver registers the direct and discov schemes to the resolver.
+// RegisterResolver registers the direct, discov and k8s schemes to the resolver.
 func RegisterResolver() {
 	resolver.Register(&dirBuilder)
 	resolver.Register(&disBuilder)
+	resolver.Register(&k8sBuilder)
 }
 
 func SetCertFile(cafile, certfile, keyfile string) {
@@ -37,6 +42,12 @@ func SetCertFile(cafile, certfile, keyfile string) {
 	disBuilder.Keyfile = keyfile
 }
 
+// SetKubeClient sets the client that the k8s scheme uses to watch endpoints,
+// the in-cluster client is used if not set.
+func SetKubeClient(cli kube.Client) {
+	k8sBuilder.setClient(cli)
+}
+
 type nopResolver struct {
 	cc resolver.ClientConn
 }
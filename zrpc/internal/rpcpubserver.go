@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"os"
 	"strings"
 
@@ -14,15 +15,11 @@ const (
 )
 
 func NewRpcPubServerExtern(etcd *discov.EtcdConf, listenOn string, opts ...ServerOption) (Server, error) {
-	registerEtcd := func() error {
-		pubListenOn := figureOutListenOn(listenOn)
-		pubClient := discov.NewPublisher(etcd.Hosts, etcd.Key, pubListenOn, etcd.Tls, etcd.Cafile, etcd.Certfile, etcd.Keyfile)
-		return pubClient.KeepAlive()
-	}
-
+	pubListenOn := figureOutListenOn(listenOn)
 	server := keepAliveServer{
-		registerEtcd: registerEtcd,
-		Server:       NewRpcServer(listenOn, opts...),
+		publisher: discov.NewPublisher(etcd.Hosts, etcd.Key, pubListenOn, etcd.Tls,
+			etcd.Cafile, etcd.Certfile, etcd.Keyfile),
+		Server: NewRpcServer(listenOn, opts...),
 	}
 
 	return server, nil
@@ -30,26 +27,29 @@ func NewRpcPubServerExtern(etcd *discov.EtcdConf, listenOn string, opts ...Serve
 
 // NewRpcPubServer returns a Server.
 func NewRpcPubServer(etcdEndpoints []string, etcdKey, listenOn string, opts ...ServerOption) (Server, error) {
-	registerEtcd := func() error {
-		pubListenOn := figureOutListenOn(listenOn)
-		pubClient := discov.NewPublisher(etcdEndpoints, etcdKey, pubListenOn, false, "", "", "")
-		return pubClient.KeepAlive()
-	}
+	pubListenOn := figureOutListenOn(listenOn)
 	server := keepAliveServer{
-		registerEtcd: registerEtcd,
-		Server:       NewRpcServer(listenOn, opts...),
+		publisher: discov.NewPublisher(etcdEndpoints, etcdKey, pubListenOn, false, "", "", ""),
+		Server:    NewRpcServer(listenOn, opts...),
 	}
 
 	return server, nil
 }
 
 type keepAliveServer struct {
-	registerEtcd func() error
+	publisher *discov.Publisher
 	Server
 }
 
+// Drain deregisters from etcd before draining, the publisher is stopped on the unready phase
+// on signals, but not on stopping by service.ServiceGroup.
+func (ags keepAliveServer) Drain(ctx context.Context) error {
+	ags.publisher.Stop()
+	return ags.Server.Drain(ctx)
+}
+
 func (ags keepAliveServer) Start(fn RegisterFn) error {
-	if err := ags.registerEtcd(); err != nil {
+	if err := ags.publisher.KeepAlive(); err != nil {
 		return err
 	}
 
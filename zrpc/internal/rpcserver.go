@@ -1,10 +1,17 @@
 package internal
 
 import (
+	"context"
 	"net"
+	"sync"
 
+	"github.com/lukebull/go-zero-extern/core/health"
+	"github.com/lukebull/go-zero-extern/core/lang"
+	"github.com/lukebull/go-zero-extern/core/logx"
 	"github.com/lukebull/go-zero-extern/core/proc"
 	"github.com/lukebull/go-zero-extern/core/stat"
+	"github.com/lukebull/go-zero-extern/core/syncx"
+	"github.com/lukebull/go-zero-extern/core/threading"
 	"github.com/lukebull/go-zero-extern/zrpc/internal/serverinterceptors"
 	"google.golang.org/grpc"
 )
@@ -14,11 +21,16 @@ type (
 	ServerOption func(options *rpcServerOptions)
 
 	rpcServerOptions struct {
-		metrics *stat.Metrics
+		metrics   *stat.Metrics
+		logFields []string
 	}
 
 	rpcServer struct {
-		name string
+		name      string
+		lock      sync.Mutex
+		server    *grpc.Server
+		drained   *syncx.DoneChan
+		logFields []string
 		*baseRpcServer
 	}
 )
@@ -38,6 +50,8 @@ func NewRpcServer(address string, opts ...ServerOption) Server {
 	}
 
 	return &rpcServer{
+		drained:       syncx.NewDoneChan(),
+		logFields:     options.logFields,
 		baseRpcServer: newBaseRpcServer(address, options.metrics),
 	}
 }
@@ -55,29 +69,82 @@ func (s *rpcServer) Start(register RegisterFn) error {
 
 	unaryInterceptors := []grpc.UnaryServerInterceptor{
 		serverinterceptors.UnaryTracingInterceptor(s.name),
+	}
+	var streamInterceptors []grpc.StreamServerInterceptor
+	if len(s.logFields) > 0 {
+		unaryInterceptors = append(unaryInterceptors, serverinterceptors.UnaryLogFieldsInterceptor(s.logFields))
+		streamInterceptors = append(streamInterceptors, serverinterceptors.StreamLogFieldsInterceptor(s.logFields))
+	}
+	unaryInterceptors = append(unaryInterceptors,
 		serverinterceptors.UnaryCrashInterceptor(),
 		serverinterceptors.UnaryStatInterceptor(s.metrics),
 		serverinterceptors.UnaryPrometheusInterceptor(),
 		serverinterceptors.UnaryBreakerInterceptor(),
-	}
+	)
 	unaryInterceptors = append(unaryInterceptors, s.unaryInterceptors...)
-	streamInterceptors := []grpc.StreamServerInterceptor{
+	streamInterceptors = append(streamInterceptors,
 		serverinterceptors.StreamCrashInterceptor,
 		serverinterceptors.StreamBreakerInterceptor,
-	}
+	)
 	streamInterceptors = append(streamInterceptors, s.streamInterceptors...)
 	options := append(s.options, WithUnaryServerInterceptors(unaryInterceptors...),
 		WithStreamServerInterceptors(streamInterceptors...))
 	server := grpc.NewServer(options...)
 	register(server)
-	// we need to make sure all others are wrapped up
-	// so we do graceful stop at shutdown phase instead of wrap up phase
-	waitForCalled := proc.AddWrapUpListener(func() {
+	registerHealthServer(server)
+	s.lock.Lock()
+	s.server = server
+	s.lock.Unlock()
+
+	proc.AddUnreadyListener(health.MarkUnready)
+	proc.AddWrapUpListener(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), proc.DrainTimeout())
+		defer cancel()
+		_ = s.Drain(ctx)
+	})
+	// Serve returns ErrServerStopped if drained before serving.
+	if err = server.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+		return err
+	}
+
+	// returns after the in-flight requests are drained.
+	<-s.drained.Done()
+	return nil
+}
+
+// Drain stops accepting new requests and waits for the in-flight requests to finish,
+// the requests are cancelled if ctx is done before that.
+func (s *rpcServer) Drain(ctx context.Context) error {
+	s.lock.Lock()
+	server := s.server
+	s.lock.Unlock()
+	if server == nil {
+		return nil
+	}
+
+	defer s.drained.Close()
+
+	done := make(chan lang.PlaceholderType)
+	threading.GoSafe(func() {
 		server.GracefulStop()
+		close(done)
 	})
-	defer waitForCalled()
 
-	return server.Serve(lis)
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		logx.Errorf("Error on draining rpc server %s, stopping: %v", s.address, ctx.Err())
+		server.Stop()
+		return ctx.Err()
+	}
+}
+
+// WithLogFields returns a func that accepts the log fields with the given keys from the clients.
+func WithLogFields(keys []string) ServerOption {
+	return func(options *rpcServerOptions) {
+		options.logFields = keys
+	}
 }
 
 // WithMetrics returns a func that sets metrics to a Server.
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestRpcServerDrain(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := lis.Addr().String()
+	lis.Close()
+
+	server := NewRpcServer(address)
+	registered := make(chan struct{})
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.Start(func(*grpc.Server) {
+			close(registered)
+		})
+	}()
+	<-registered
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err = server.Drain(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err = <-errs:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Start returned after draining")
+	}
+}
+
+func TestRpcServerDrainNotStarted(t *testing.T) {
+	if err := NewRpcServer("127.0.0.1:0").Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
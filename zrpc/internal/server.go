@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"context"
+
 	"github.com/lukebull/go-zero-extern/core/stat"
 	"google.golang.org/grpc"
 )
@@ -14,6 +16,7 @@ type (
 		AddOptions(options ...grpc.ServerOption)
 		AddStreamInterceptors(interceptors ...grpc.StreamServerInterceptor)
 		AddUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor)
+		Drain(ctx context.Context) error
 		SetName(string)
 		Start(register RegisterFn) error
 	}
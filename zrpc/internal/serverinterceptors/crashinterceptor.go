@@ -14,7 +14,7 @@ import (
 func StreamCrashInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
 	handler grpc.StreamHandler) (err error) {
 	defer handleCrash(func(r interface{}) {
-		err = toPanicError(r)
+		err = toPanicError(stream.Context(), r)
 	})
 
 	return handler(srv, stream)
@@ -25,7 +25,7 @@ func UnaryCrashInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler) (resp interface{}, err error) {
 		defer handleCrash(func(r interface{}) {
-			err = toPanicError(r)
+			err = toPanicError(ctx, r)
 		})
 
 		return handler(ctx, req)
@@ -38,7 +38,7 @@ func handleCrash(handler func(interface{})) {
 	}
 }
 
-func toPanicError(r interface{}) error {
-	logx.Errorf("%+v %s", r, debug.Stack())
+func toPanicError(ctx context.Context, r interface{}) error {
+	logx.WithContext(ctx).Errorf("%+v %s", r, debug.Stack())
 	return status.Errorf(codes.Internal, "panic: %v", r)
 }
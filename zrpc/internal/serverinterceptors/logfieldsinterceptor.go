@@ -0,0 +1,55 @@
+package serverinterceptors
+
+import (
+	"context"
+
+	"github.com/lukebull/go-zero-extern/core/lang"
+	"github.com/lukebull/go-zero-extern/zrpc/internal/logfields"
+	"google.golang.org/grpc"
+)
+
+// StreamLogFieldsInterceptor returns a func that carries the log fields with the given keys
+// from the clients in the stream context.
+func StreamLogFieldsInterceptor(keys []string) grpc.StreamServerInterceptor {
+	allowed := toKeySet(keys)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		ctx := logfields.Extract(stream.Context(), allowed)
+		if ctx == stream.Context() {
+			return handler(srv, stream)
+		}
+
+		return handler(srv, &fieldsServerStream{
+			ServerStream: stream,
+			ctx:          ctx,
+		})
+	}
+}
+
+// UnaryLogFieldsInterceptor returns a func that carries the log fields with the given keys
+// from the clients in ctx.
+func UnaryLogFieldsInterceptor(keys []string) grpc.UnaryServerInterceptor {
+	allowed := toKeySet(keys)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(logfields.Extract(ctx, allowed), req)
+	}
+}
+
+func toKeySet(keys []string) map[string]lang.PlaceholderType {
+	set := make(map[string]lang.PlaceholderType, len(keys))
+	for _, key := range keys {
+		set[key] = lang.Placeholder
+	}
+
+	return set
+}
+
+type fieldsServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fieldsServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,76 @@
+package serverinterceptors
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/limit"
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	rateLimitLimit     = "x-ratelimit-limit"
+	rateLimitRemaining = "x-ratelimit-remaining"
+	rateLimitReset     = "x-ratelimit-reset"
+	retryAfter         = "retry-after"
+)
+
+// RateLimitKeyFunc defines the method to get the rate limit key of the requests to method.
+type RateLimitKeyFunc func(ctx context.Context, method string) string
+
+// StreamRateLimitInterceptor returns a func that limits the stream requests by the keys from keyFunc.
+func StreamRateLimitInterceptor(limiter *limit.QuotaLimiter, keyFunc RateLimitKeyFunc) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		quota := limiter.Take(keyFunc(stream.Context(), info.FullMethod))
+		if err := stream.SetHeader(quotaHeader(quota)); err != nil {
+			logx.WithContext(stream.Context()).Error(err)
+		}
+		if quota.State == limit.OverQuota {
+			return rateLimited(stream.Context(), info.FullMethod)
+		}
+
+		return handler(srv, stream)
+	}
+}
+
+// UnaryRateLimitInterceptor returns a func that limits the unary requests by the keys from keyFunc,
+// responds ResourceExhausted with retry-after in the header if over quota.
+func UnaryRateLimitInterceptor(limiter *limit.QuotaLimiter, keyFunc RateLimitKeyFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		quota := limiter.Take(keyFunc(ctx, info.FullMethod))
+		if err := grpc.SetHeader(ctx, quotaHeader(quota)); err != nil {
+			logx.WithContext(ctx).Error(err)
+		}
+		if quota.State == limit.OverQuota {
+			return nil, rateLimited(ctx, info.FullMethod)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func quotaHeader(quota limit.Quota) metadata.MD {
+	reset := strconv.FormatInt(int64((quota.Reset+time.Second-1)/time.Second), 10)
+	md := metadata.Pairs(
+		rateLimitLimit, strconv.Itoa(quota.Limit),
+		rateLimitRemaining, strconv.Itoa(quota.Remaining),
+		rateLimitReset, reset,
+	)
+	if quota.State == limit.OverQuota {
+		md.Set(retryAfter, reset)
+	}
+
+	return md
+}
+
+func rateLimited(ctx context.Context, method string) error {
+	logx.WithContext(ctx).Errorf("[rpc] rate limited, %s", method)
+	return status.Error(codes.ResourceExhausted, "rate limited")
+}
@@ -0,0 +1,76 @@
+package serverinterceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lukebull/go-zero-extern/core/limit"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type mockServerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *mockServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *mockServerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestUnaryRateLimitInterceptor(t *testing.T) {
+	limiter := limit.NewQuotaLimiter(10, 1, nil, "")
+	interceptor := UnaryRateLimitInterceptor(limiter, func(ctx context.Context, method string) string {
+		return method
+	})
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	}
+
+	resp, err := interceptor(context.Background(), "req", info, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, "req", resp)
+
+	_, err = interceptor(context.Background(), "req", info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	resp, err = interceptor(context.Background(), "req", &grpc.UnaryServerInfo{FullMethod: "/svc/Other"}, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, "req", resp)
+}
+
+func TestStreamRateLimitInterceptor(t *testing.T) {
+	limiter := limit.NewQuotaLimiter(10, 1, nil, "")
+	interceptor := StreamRateLimitInterceptor(limiter, func(ctx context.Context, method string) string {
+		return method
+	})
+	info := &grpc.StreamServerInfo{FullMethod: "/svc/Stream"}
+	var called int
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		called++
+		return nil
+	}
+
+	stream := &mockServerStream{ctx: context.Background()}
+	assert.Nil(t, interceptor(nil, stream, info, handler))
+	assert.Equal(t, []string{"1"}, stream.header.Get(rateLimitLimit))
+	assert.Equal(t, []string{"0"}, stream.header.Get(rateLimitRemaining))
+	assert.Empty(t, stream.header.Get(retryAfter))
+
+	stream = &mockServerStream{ctx: context.Background()}
+	err := interceptor(nil, stream, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"10"}, stream.header.Get(retryAfter))
+	assert.Equal(t, []string{"10"}, stream.header.Get(rateLimitReset))
+	assert.Equal(t, 1, called)
+}
@@ -19,7 +19,7 @@ func UnaryStatInterceptor(metrics *stat.Metrics) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler) (resp interface{}, err error) {
 		defer handleCrash(func(r interface{}) {
-			err = toPanicError(r)
+			err = toPanicError(ctx, r)
 		})
 
 		startTime := timex.Now()
@@ -4,14 +4,16 @@ import (
 	"context"
 
 	"github.com/lukebull/go-zero-extern/core/trace"
+	"github.com/lukebull/go-zero-extern/core/trace/tracespec"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // UnaryTracingInterceptor returns a func that handles tracing with given service name.
 func UnaryTracingInterceptor(serviceName string) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
-		handler grpc.UnaryHandler) (resp interface{}, err error) {
+		handler grpc.UnaryHandler) (interface{}, error) {
 		md, ok := metadata.FromIncomingContext(ctx)
 		if !ok {
 			return handler(ctx, req)
@@ -24,6 +26,16 @@ func UnaryTracingInterceptor(serviceName string) grpc.UnaryServerInterceptor {
 
 		ctx, span := trace.StartServerSpan(ctx, carrier, serviceName, info.FullMethod)
 		defer span.Finish()
-		return handler(ctx, req)
+		span.SetAttribute("rpc.system", "grpc")
+		span.SetAttribute("rpc.method", info.FullMethod)
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			st := status.Convert(err)
+			span.SetAttribute("rpc.grpc.status_code", int(st.Code()))
+			span.SetStatus(tracespec.StatusError, st.Message())
+		}
+
+		return resp, err
 	}
 }
@@ -18,3 +18,8 @@ func BuildDiscovTarget(endpoints []string, key string) string {
 	return fmt.Sprintf("%s://%s/%s", resolver.DiscovScheme,
 		strings.Join(endpoints, resolver.EndpointSep), key)
 }
+
+// BuildKubeTarget returns a string that represents the given service with k8s schema.
+func BuildKubeTarget(namespace, service string, port int) string {
+	return fmt.Sprintf("%s://%s/%s:%d", resolver.KubernetesScheme, namespace, service, port)
+}
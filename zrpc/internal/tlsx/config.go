@@ -0,0 +1,137 @@
+package tlsx
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// grpc runs on http2, the returned config of GetConfigForClient doesn't inherit NextProtos.
+const http2Proto = "h2"
+
+// ErrNoClientCa is an error that indicates client verification is required without a ca file.
+var ErrNoClientCa = errors.New("ca file is required to verify client certificates")
+
+// NewServerConfig returns a tls.Config for servers, the certificate files and
+// the ca file are reloaded if they are changed on disk.
+// If verifyClient is true, clients must present certificates signed by caFile.
+func NewServerConfig(certFile, keyFile, caFile string, verifyClient bool) (*tls.Config, error) {
+	keyPair, err := newKeyPairReloader(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	getCertificate := func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return keyPair.getCertificate(), nil
+	}
+	if len(caFile) == 0 {
+		if verifyClient {
+			return nil, ErrNoClientCa
+		}
+
+		return &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: getCertificate,
+		}, nil
+	}
+
+	ca, err := newCaReloader(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	clientAuth := tls.VerifyClientCertIfGiven
+	if verifyClient {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// returns a fresh config on each handshake, to pick up the reloaded ca.
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return &tls.Config{
+				MinVersion:     tls.VersionTLS12,
+				NextProtos:     []string{http2Proto},
+				GetCertificate: getCertificate,
+				ClientAuth:     clientAuth,
+				ClientCAs:      ca.getPool(),
+			}, nil
+		},
+	}, nil
+}
+
+// NewClientCredentials returns the transport credentials for clients, the certificate files and
+// the ca file are reloaded if they are changed on disk.
+// certFile and keyFile are optional, they are only required by servers verifying clients.
+// caFile is optional, the system roots are used if not given.
+func NewClientCredentials(certFile, keyFile, caFile, serverName string, insecureSkipVerify bool) (
+	credentials.TransportCredentials, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         serverName,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+
+	if len(certFile) > 0 || len(keyFile) > 0 {
+		keyPair, err := newKeyPairReloader(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return keyPair.getCertificate(), nil
+		}
+	}
+
+	creds := &clientCredentials{
+		TransportCredentials: credentials.NewTLS(cfg),
+		config:               cfg,
+	}
+	if len(caFile) == 0 || insecureSkipVerify {
+		return creds, nil
+	}
+
+	ca, err := newCaReloader(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	creds.ca = ca
+	return creds, nil
+}
+
+// clientCredentials handshakes with a fresh tls.Config on each connection, because RootCAs can't be
+// reloaded in place, so that the server chain and name are verified by tls against the reloaded ca.
+type clientCredentials struct {
+	credentials.TransportCredentials
+	config *tls.Config
+	ca     *caReloader
+}
+
+func (c *clientCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (
+	net.Conn, credentials.AuthInfo, error) {
+	if c.ca == nil {
+		return c.TransportCredentials.ClientHandshake(ctx, authority, conn)
+	}
+
+	cfg := c.config.Clone()
+	cfg.RootCAs = c.ca.getPool()
+	return credentials.NewTLS(cfg).ClientHandshake(ctx, authority, conn)
+}
+
+func (c *clientCredentials) Clone() credentials.TransportCredentials {
+	cfg := c.config.Clone()
+	return &clientCredentials{
+		TransportCredentials: credentials.NewTLS(cfg),
+		config:               cfg,
+		ca:                   c.ca,
+	}
+}
+
+func (c *clientCredentials) OverrideServerName(serverName string) error {
+	c.config.ServerName = serverName
+	return c.TransportCredentials.OverrideServerName(serverName)
+}
@@ -0,0 +1,348 @@
+package tlsx
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+type testCa struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCa(t *testing.T, name string) *testCa {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+
+	return &testCa{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue returns the pem encoded certificate and key signed by ca.
+func (ca *testCa) issue(t *testing.T, tmpl *x509.Certificate) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func (ca *testCa) issueServer(t *testing.T) ([]byte, []byte) {
+	return ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "server"},
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+	})
+}
+
+func (ca *testCa) issueClient(t *testing.T) ([]byte, []byte) {
+	uri, err := url.Parse("spiffe://example.org/client")
+	assert.Nil(t, err)
+
+	return ca.issue(t, &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:   "client",
+			Organization: []string{"example"},
+		},
+		DNSNames: []string{"client.example.org"},
+		URIs:     []*url.URL{uri},
+	})
+}
+
+// writeFiles writes the contents into dir, the modification time is increased on every call,
+// so that the changes are picked up even if the file system has a coarse time granularity.
+func writeFiles(t *testing.T, dir string, contents map[string][]byte) {
+	info, err := os.Stat(dir)
+	assert.Nil(t, err)
+	modTime := info.ModTime().Add(time.Second)
+
+	for name, content := range contents {
+		file := filepath.Join(dir, name)
+		assert.Nil(t, ioutil.WriteFile(file, content, 0600))
+		assert.Nil(t, os.Chtimes(file, modTime, modTime))
+	}
+	assert.Nil(t, os.Chtimes(dir, modTime, modTime))
+}
+
+type handshakeResult struct {
+	serverInfo credentials.AuthInfo
+	serverErr  error
+	clientErr  error
+}
+
+func handshake(t *testing.T, server, client credentials.TransportCredentials,
+	authority string) handshakeResult {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	var result handshakeResult
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		conn, err := listener.Accept()
+		if err != nil {
+			result.serverErr = err
+			return
+		}
+		defer conn.Close()
+
+		assert.Nil(t, conn.SetDeadline(time.Now().Add(time.Second*5)))
+		var tlsConn net.Conn
+		tlsConn, result.serverInfo, result.serverErr = server.ServerHandshake(conn)
+		if result.serverErr == nil {
+			// wait for the client to finish verifying the server.
+			_, _ = tlsConn.Read(make([]byte, 1))
+		}
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	assert.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	tlsConn, _, err := client.ClientHandshake(ctx, authority, conn)
+	result.clientErr = err
+	if err == nil {
+		tlsConn.Close()
+	} else {
+		conn.Close()
+	}
+	<-done
+
+	return result
+}
+
+type tlsFiles struct {
+	dir        string
+	serverCert string
+	serverKey  string
+	clientCert string
+	clientKey  string
+	ca         string
+}
+
+func newTlsFiles(t *testing.T, ca *testCa) tlsFiles {
+	dir := t.TempDir()
+	serverCert, serverKey := ca.issueServer(t)
+	clientCert, clientKey := ca.issueClient(t)
+	writeFiles(t, dir, map[string][]byte{
+		"server.crt": serverCert,
+		"server.key": serverKey,
+		"client.crt": clientCert,
+		"client.key": clientKey,
+		"ca.crt":     ca.pem,
+	})
+
+	return tlsFiles{
+		dir:        dir,
+		serverCert: filepath.Join(dir, "server.crt"),
+		serverKey:  filepath.Join(dir, "server.key"),
+		clientCert: filepath.Join(dir, "client.crt"),
+		clientKey:  filepath.Join(dir, "client.key"),
+		ca:         filepath.Join(dir, "ca.crt"),
+	}
+}
+
+func newServerCredentials(t *testing.T, files tlsFiles, verifyClient bool) credentials.TransportCredentials {
+	cfg, err := NewServerConfig(files.serverCert, files.serverKey, files.ca, verifyClient)
+	assert.Nil(t, err)
+	return credentials.NewTLS(cfg)
+}
+
+func TestMutualTls(t *testing.T) {
+	ca := newTestCa(t, "ca")
+	files := newTlsFiles(t, ca)
+	server := newServerCredentials(t, files, true)
+
+	client, err := NewClientCredentials(files.clientCert, files.clientKey, files.ca, "", false)
+	assert.Nil(t, err)
+	result := handshake(t, server, client, "localhost:8080")
+	assert.Nil(t, result.clientErr)
+	assert.Nil(t, result.serverErr)
+
+	identity, ok := PeerIdentityFromContext(peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: result.serverInfo,
+	}))
+	assert.True(t, ok)
+	assert.Equal(t, "client", identity.CommonName)
+	assert.Equal(t, []string{"example"}, identity.Organization)
+	assert.Equal(t, []string{"client.example.org"}, identity.DNSNames)
+	assert.Equal(t, []string{"spiffe://example.org/client"}, identity.URIs)
+	assert.Equal(t, identity.Certificate.SerialNumber.Text(16), identity.SerialNumber)
+
+	// the ip is verified against the ip addresses of the server certificate.
+	result = handshake(t, server, client, "127.0.0.1:8080")
+	assert.Nil(t, result.clientErr)
+	assert.Nil(t, result.serverErr)
+}
+
+func TestMutualTlsRejectUnknownClient(t *testing.T) {
+	files := newTlsFiles(t, newTestCa(t, "ca"))
+	server := newServerCredentials(t, files, true)
+
+	other := newTlsFiles(t, newTestCa(t, "other"))
+	client, err := NewClientCredentials(other.clientCert, other.clientKey, files.ca, "", false)
+	assert.Nil(t, err)
+	result := handshake(t, server, client, "localhost:8080")
+	assert.NotNil(t, result.serverErr)
+
+	// no client certificate
+	client, err = NewClientCredentials("", "", files.ca, "", false)
+	assert.Nil(t, err)
+	result = handshake(t, server, client, "localhost:8080")
+	assert.NotNil(t, result.serverErr)
+}
+
+func TestTlsRejectServer(t *testing.T) {
+	files := newTlsFiles(t, newTestCa(t, "ca"))
+	server := newServerCredentials(t, files, false)
+	other := newTlsFiles(t, newTestCa(t, "other"))
+
+	tests := []struct {
+		name       string
+		ca         string
+		serverName string
+		authority  string
+	}{
+		{
+			name:      "wrong ca",
+			ca:        other.ca,
+			authority: "localhost:8080",
+		},
+		{
+			name:       "wrong server name",
+			ca:         files.ca,
+			serverName: "other.example.org",
+			authority:  "localhost:8080",
+		},
+		{
+			name:      "wrong host",
+			ca:        files.ca,
+			authority: "other.example.org:8080",
+		},
+		{
+			name:      "wrong ip",
+			ca:        files.ca,
+			authority: "127.0.0.2:8080",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			client, err := NewClientCredentials("", "", test.ca, test.serverName, false)
+			assert.Nil(t, err)
+			result := handshake(t, server, client, test.authority)
+			assert.NotNil(t, result.clientErr)
+		})
+	}
+}
+
+func TestTlsReload(t *testing.T) {
+	interval := checkInterval
+	checkInterval = 0
+	defer func() {
+		checkInterval = interval
+	}()
+
+	files := newTlsFiles(t, newTestCa(t, "ca"))
+	server := newServerCredentials(t, files, true)
+	client, err := NewClientCredentials(files.clientCert, files.clientKey, files.ca, "", false)
+	assert.Nil(t, err)
+	result := handshake(t, server, client, "localhost:8080")
+	assert.Nil(t, result.clientErr)
+	assert.Nil(t, result.serverErr)
+
+	// rotate all the certificates and the ca, shared by the server and the client.
+	rotated := newTestCa(t, "rotated")
+	serverCert, serverKey := rotated.issueServer(t)
+	clientCert, clientKey := rotated.issueClient(t)
+	writeFiles(t, files.dir, map[string][]byte{
+		"server.crt": serverCert,
+		"server.key": serverKey,
+		"client.crt": clientCert,
+		"client.key": clientKey,
+		"ca.crt":     rotated.pem,
+	})
+
+	result = handshake(t, server, client, "localhost:8080")
+	assert.Nil(t, result.clientErr)
+	assert.Nil(t, result.serverErr)
+	identity, ok := PeerIdentityFromContext(peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: result.serverInfo,
+	}))
+	assert.True(t, ok)
+	certs, err := tls.X509KeyPair(clientCert, clientKey)
+	assert.Nil(t, err)
+	assert.Equal(t, certs.Certificate[0], identity.Certificate.Raw)
+
+	// the clients with the previous ca are rejected.
+	stale := newTlsFiles(t, newTestCa(t, "ca"))
+	client, err = NewClientCredentials(stale.clientCert, stale.clientKey, stale.ca, "", false)
+	assert.Nil(t, err)
+	result = handshake(t, server, client, "localhost:8080")
+	assert.NotNil(t, result.clientErr)
+}
+
+func TestNewConfigErrors(t *testing.T) {
+	files := newTlsFiles(t, newTestCa(t, "ca"))
+
+	_, err := NewServerConfig(files.serverCert, files.serverKey, "", true)
+	assert.Equal(t, ErrNoClientCa, err)
+	_, err = NewServerConfig(files.serverCert, filepath.Join(files.dir, "none"), files.ca, true)
+	assert.NotNil(t, err)
+	_, err = NewServerConfig(files.serverCert, files.serverKey, files.serverKey, true)
+	assert.NotNil(t, err)
+	_, err = NewClientCredentials("", "", filepath.Join(files.dir, "none"), "", false)
+	assert.NotNil(t, err)
+
+	cfg, err := NewServerConfig(files.serverCert, files.serverKey, "", false)
+	assert.Nil(t, err)
+	assert.NotNil(t, cfg.GetCertificate)
+}
@@ -0,0 +1,47 @@
+package tlsx
+
+import (
+	"context"
+	"crypto/x509"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// A PeerIdentity is the identity from the verified certificate of the peer.
+type PeerIdentity struct {
+	CommonName   string
+	Organization []string
+	DNSNames     []string
+	URIs         []string
+	SerialNumber string
+	Certificate  *x509.Certificate
+}
+
+// PeerIdentityFromContext returns the identity of the peer on the grpc connection in ctx.
+// It returns false if the connection is not tls or the peer didn't present a certificate.
+func PeerIdentityFromContext(ctx context.Context) (PeerIdentity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return PeerIdentity{}, false
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.PeerCertificates) == 0 {
+		return PeerIdentity{}, false
+	}
+
+	cert := info.State.PeerCertificates[0]
+	identity := PeerIdentity{
+		CommonName:   cert.Subject.CommonName,
+		Organization: cert.Subject.Organization,
+		DNSNames:     cert.DNSNames,
+		SerialNumber: cert.SerialNumber.Text(16),
+		Certificate:  cert,
+	}
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+
+	return identity, true
+}
@@ -0,0 +1,37 @@
+package tlsx
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+type plainAuthInfo struct{}
+
+func (plainAuthInfo) AuthType() string {
+	return "plain"
+}
+
+func TestPeerIdentityFromContextWithoutCertificate(t *testing.T) {
+	_, ok := PeerIdentityFromContext(context.Background())
+	assert.False(t, ok)
+
+	_, ok = PeerIdentityFromContext(peer.NewContext(context.Background(), &peer.Peer{}))
+	assert.False(t, ok)
+
+	_, ok = PeerIdentityFromContext(peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: plainAuthInfo{},
+	}))
+	assert.False(t, ok)
+
+	_, ok = PeerIdentityFromContext(peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{},
+		},
+	}))
+	assert.False(t, ok)
+}
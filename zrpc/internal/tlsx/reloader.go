@@ -0,0 +1,176 @@
+package tlsx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lukebull/go-zero-extern/core/logx"
+	"github.com/lukebull/go-zero-extern/core/timex"
+)
+
+// files are checked at most once in checkInterval, to avoid stat on every handshake.
+var checkInterval = time.Second * 10
+
+type (
+	// a fileWatcher reloads the given files with load if any of them changed on disk.
+	fileWatcher struct {
+		files     []string
+		load      func() error
+		modTimes  []time.Time
+		lastCheck time.Duration
+		lock      sync.Mutex
+	}
+
+	keyPairReloader struct {
+		*fileWatcher
+		certFile string
+		keyFile  string
+		cert     *tls.Certificate
+	}
+
+	caReloader struct {
+		*fileWatcher
+		caFile string
+		pool   *x509.CertPool
+	}
+)
+
+func newFileWatcher(load func() error, files ...string) (*fileWatcher, error) {
+	w := &fileWatcher{
+		files: files,
+		load:  load,
+	}
+	modTimes, err := w.stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := load(); err != nil {
+		return nil, err
+	}
+
+	w.modTimes = modTimes
+	w.lastCheck = timex.Now()
+	return w, nil
+}
+
+// check must be called with lock held.
+func (w *fileWatcher) check() {
+	if timex.Since(w.lastCheck) < checkInterval {
+		return
+	}
+
+	w.lastCheck = timex.Now()
+	modTimes, err := w.stat()
+	if err != nil {
+		// files might be in the middle of rotating, keep the loaded ones.
+		logx.Errorf("tls files stat error: %v", err)
+		return
+	}
+
+	var changed bool
+	for i := range modTimes {
+		if !modTimes[i].Equal(w.modTimes[i]) {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return
+	}
+
+	if err := w.load(); err != nil {
+		logx.Errorf("tls files %v reload error: %v", w.files, err)
+		return
+	}
+
+	w.modTimes = modTimes
+	logx.Infof("tls files %v reloaded", w.files)
+}
+
+func (w *fileWatcher) stat() ([]time.Time, error) {
+	modTimes := make([]time.Time, len(w.files))
+	for i, file := range w.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+
+		modTimes[i] = info.ModTime()
+	}
+
+	return modTimes, nil
+}
+
+func newKeyPairReloader(certFile, keyFile string) (*keyPairReloader, error) {
+	r := &keyPairReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	watcher, err := newFileWatcher(r.load, certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	r.fileWatcher = watcher
+	return r, nil
+}
+
+func (r *keyPairReloader) getCertificate() *tls.Certificate {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.check()
+	return r.cert
+}
+
+func (r *keyPairReloader) load() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.cert = &cert
+	return nil
+}
+
+func newCaReloader(caFile string) (*caReloader, error) {
+	r := &caReloader{
+		caFile: caFile,
+	}
+	watcher, err := newFileWatcher(r.load, caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	r.fileWatcher = watcher
+	return r, nil
+}
+
+func (r *caReloader) getPool() *x509.CertPool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.check()
+	return r.pool
+}
+
+func (r *caReloader) load() error {
+	content, err := ioutil.ReadFile(r.caFile)
+	if err != nil {
+		return err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(content) {
+		return fmt.Errorf("no certificates found in %s", r.caFile)
+	}
+
+	r.pool = pool
+	return nil
+}
@@ -1,23 +1,44 @@
 package zrpc
 
 import (
+	"context"
 	"log"
+	"net"
 	"time"
 
+	"github.com/lukebull/go-zero-extern/core/limit"
 	"github.com/lukebull/go-zero-extern/core/load"
 	"github.com/lukebull/go-zero-extern/core/logx"
 	"github.com/lukebull/go-zero-extern/core/stat"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
 	"github.com/lukebull/go-zero-extern/zrpc/internal"
 	"github.com/lukebull/go-zero-extern/zrpc/internal/auth"
 	"github.com/lukebull/go-zero-extern/zrpc/internal/serverinterceptors"
+	"github.com/lukebull/go-zero-extern/zrpc/internal/tlsx"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
 )
 
-// A RpcServer is a rpc server.
-type RpcServer struct {
-	server   internal.Server
-	register internal.RegisterFn
-}
+const (
+	rateLimitByMethod = "method"
+	rateLimitByApp    = "app"
+)
+
+// PeerIdentityFromContext is an alias of tlsx.PeerIdentityFromContext.
+// Server interceptors and handlers use it to get the identity of the client certificate.
+var PeerIdentityFromContext = tlsx.PeerIdentityFromContext
+
+type (
+	// PeerIdentity is an alias of tlsx.PeerIdentity.
+	PeerIdentity = tlsx.PeerIdentity
+
+	// A RpcServer is a rpc server.
+	RpcServer struct {
+		server   internal.Server
+		register internal.RegisterFn
+	}
+)
 
 // MustNewServer returns a RpcSever, exits on any error.
 func MustNewServer(c RpcServerConf, register internal.RegisterFn) *RpcServer {
@@ -37,20 +58,32 @@ func NewServer(c RpcServerConf, register internal.RegisterFn) (*RpcServer, error
 	}
 	var server internal.Server
 	metrics := stat.NewMetrics(c.ListenOn)
+	serverOpts := []internal.ServerOption{
+		internal.WithMetrics(metrics),
+		internal.WithLogFields(c.LogFields),
+	}
 	if c.HasEtcd() {
 		if c.Etcd.Tls == true {
-			server, err = internal.NewRpcPubServerExtern(&c.Etcd, c.ListenOn, internal.WithMetrics(metrics))
+			server, err = internal.NewRpcPubServerExtern(&c.Etcd, c.ListenOn, serverOpts...)
 		} else {
-			server, err = internal.NewRpcPubServer(c.Etcd.Hosts, c.Etcd.Key, c.ListenOn, internal.WithMetrics(metrics))
+			server, err = internal.NewRpcPubServer(c.Etcd.Hosts, c.Etcd.Key, c.ListenOn, serverOpts...)
 		}
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		server = internal.NewRpcServer(c.ListenOn, internal.WithMetrics(metrics))
+		server = internal.NewRpcServer(c.ListenOn, serverOpts...)
 	}
 
 	server.SetName(c.Name)
+	if c.HasTls() {
+		tlsConfig, err := tlsx.NewServerConfig(c.Tls.CertFile, c.Tls.KeyFile, c.Tls.CaFile, c.Tls.VerifyClient)
+		if err != nil {
+			return nil, err
+		}
+
+		server.AddOptions(grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
 	if err = setupInterceptors(server, c, metrics); err != nil {
 		return nil, err
 	}
@@ -81,9 +114,16 @@ func (rs *RpcServer) AddUnaryInterceptors(interceptors ...grpc.UnaryServerInterc
 	rs.server.AddUnaryInterceptors(interceptors...)
 }
 
+// Drain stops accepting new requests and waits for the in-flight requests to finish until ctx is done.
+// It's called by service.ServiceGroup on stopping.
+func (rs *RpcServer) Drain(ctx context.Context) error {
+	return rs.server.Drain(ctx)
+}
+
 // Start starts the RpcServer.
-// Graceful shutdown is enabled by default.
-// Use proc.SetTimeToForceQuit to customize the graceful shutdown period.
+// Graceful shutdown is enabled by default, the server is deregistered and marked unready first,
+// then drains after RpcServerConf.Shutdown.Delay. Use RpcServerConf.Shutdown.DrainTimeout
+// and proc.SetTimeToForceQuit to customize the graceful shutdown period.
 func (rs *RpcServer) Start() {
 	if err := rs.server.Start(rs.register); err != nil {
 		logx.Error(err)
@@ -117,5 +157,43 @@ func setupInterceptors(server internal.Server, c RpcServerConf, metrics *stat.Me
 		server.AddUnaryInterceptors(serverinterceptors.UnaryAuthorizeInterceptor(authenticator))
 	}
 
+	// after the authenticator, so that the apps are authenticated on limiting by app,
+	// and the unauthenticated requests don't consume the quotas.
+	if c.RateLimit.Quota > 0 {
+		var store *redis.Redis
+		if len(c.RateLimit.Redis.Host) > 0 {
+			store = c.RateLimit.Redis.NewRedis()
+		}
+		limiter := limit.NewQuotaLimiter(c.RateLimit.Period, c.RateLimit.Quota, store, c.RateLimit.KeyPrefix)
+		keyFunc := rateLimitKeyFunc(c.RateLimit.KeyBy)
+		server.AddStreamInterceptors(serverinterceptors.StreamRateLimitInterceptor(limiter, keyFunc))
+		server.AddUnaryInterceptors(serverinterceptors.UnaryRateLimitInterceptor(limiter, keyFunc))
+	}
+
 	return nil
 }
+
+func rateLimitKeyFunc(keyBy string) serverinterceptors.RateLimitKeyFunc {
+	return func(ctx context.Context, method string) string {
+		switch keyBy {
+		case rateLimitByMethod:
+			return method
+		case rateLimitByApp:
+			if app := auth.AppFromContext(ctx); len(app) > 0 {
+				return method + ":app:" + app
+			}
+		}
+
+		p, ok := peer.FromContext(ctx)
+		if !ok {
+			return method
+		}
+
+		addr := p.Addr.String()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			addr = host
+		}
+
+		return method + ":" + addr
+	}
+}
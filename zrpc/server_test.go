@@ -0,0 +1,126 @@
+package zrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/lukebull/go-zero-extern/core/stores/redis"
+	"github.com/lukebull/go-zero-extern/zrpc/internal"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimitKeyFunc(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234},
+	})
+	appCtx := metadata.NewIncomingContext(ctx, metadata.Pairs("app", "foo"))
+
+	tests := []struct {
+		keyBy  string
+		ctx    context.Context
+		expect string
+	}{
+		{keyBy: rateLimitByMethod, ctx: appCtx, expect: "/svc/M"},
+		{keyBy: "ip", ctx: appCtx, expect: "/svc/M:10.0.0.1"},
+		{keyBy: rateLimitByApp, ctx: appCtx, expect: "/svc/M:app:foo"},
+		{keyBy: rateLimitByApp, ctx: ctx, expect: "/svc/M:10.0.0.1"},
+		{keyBy: "ip", ctx: context.Background(), expect: "/svc/M"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expect, rateLimitKeyFunc(test.keyBy)(test.ctx, "/svc/M"))
+	}
+}
+
+func TestRpcServerConfValidateRateLimit(t *testing.T) {
+	c := RpcServerConf{
+		RateLimit: RateLimitConf{Quota: 1},
+	}
+	assert.Equal(t, ErrRateLimitConfig, c.Validate())
+
+	c.RateLimit.Period = 1
+	assert.Nil(t, c.Validate())
+
+	c.RateLimit.KeyBy = rateLimitByApp
+	assert.Equal(t, ErrRateLimitByApp, c.Validate())
+
+	c.Auth = true
+	c.Redis = redis.RedisKeyConf{
+		RedisConf: redis.RedisConf{
+			Host: "localhost:6379",
+			Type: redis.NodeType,
+		},
+		Key: "apps",
+	}
+	assert.Equal(t, ErrRateLimitByApp, c.Validate())
+
+	c.StrictControl = true
+	assert.Nil(t, c.Validate())
+}
+
+type interceptorServer struct {
+	internal.Server
+	unaries []grpc.UnaryServerInterceptor
+}
+
+func (s *interceptorServer) AddStreamInterceptors(...grpc.StreamServerInterceptor) {}
+
+func (s *interceptorServer) AddUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) {
+	s.unaries = append(s.unaries, interceptors...)
+}
+
+func (s *interceptorServer) call(ctx context.Context) error {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	for i := len(s.unaries) - 1; i >= 0; i-- {
+		interceptor, next := s.unaries[i], handler
+		handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+			return interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/svc/M"}, next)
+		}
+	}
+
+	_, err := handler(ctx, nil)
+	return err
+}
+
+func TestSetupInterceptorsRateLimitAfterAuth(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+	mr.HSet("apps", "foo", "token")
+
+	server := new(interceptorServer)
+	assert.Nil(t, setupInterceptors(server, RpcServerConf{
+		Auth:          true,
+		StrictControl: true,
+		Redis: redis.RedisKeyConf{
+			RedisConf: redis.RedisConf{
+				Host: mr.Addr(),
+				Type: redis.NodeType,
+			},
+			Key: "apps",
+		},
+		RateLimit: RateLimitConf{
+			Period: 100,
+			Quota:  1,
+			KeyBy:  rateLimitByApp,
+		},
+	}, nil))
+
+	forged := metadata.NewIncomingContext(context.Background(), metadata.Pairs("app", "foo", "token", "bad"))
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, codes.Unauthenticated, status.Code(server.call(forged)))
+	}
+
+	// the forged requests didn't consume the quota of foo.
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("app", "foo", "token", "token"))
+	assert.Nil(t, server.call(ctx))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(server.call(ctx)))
+}